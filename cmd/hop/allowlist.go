@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// HostAllowlist holds the approved external destination hosts of --external-allowlist
+type HostAllowlist struct {
+	hosts    map[string]bool
+	suffixes []string // ".example.com" for an entry *.example.com
+}
+
+// readAllowlist reads an allowlist file
+func readAllowlist(path string) (*HostAllowlist, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	allowlist, err := parseAllowlist(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing '%s': %v", path, err)
+	}
+	return allowlist, nil
+}
+
+// Side effect free functions
+
+// parseAllowlist parses one host per line; *.example.com allows every subdomain of example.com but not example.com itself.
+// Hosts ignore case, blank lines and lines starting with # are skipped.
+func parseAllowlist(data []byte) (*HostAllowlist, error) {
+	allowlist := &HostAllowlist{hosts: make(map[string]bool)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if strings.ContainsAny(entry, "/ \t:") {
+			return nil, fmt.Errorf("line %d: '%s' is not a host name", line, entry)
+		}
+		if suffix, ok := strings.CutPrefix(entry, "*."); ok {
+			if suffix == "" || strings.Contains(suffix, "*") {
+				return nil, fmt.Errorf("line %d: invalid wildcard '%s', use *.example.com", line, entry)
+			}
+			allowlist.suffixes = append(allowlist.suffixes, "."+canonicalHost(suffix))
+			continue
+		}
+		if strings.Contains(entry, "*") {
+			return nil, fmt.Errorf("line %d: invalid wildcard '%s', use *.example.com", line, entry)
+		}
+		allowlist.hosts[canonicalHost(entry)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return allowlist, nil
+}
+
+// allows reports whether a destination host (optionally with port) is on the allowlist
+func (a *HostAllowlist) allows(host string) bool {
+	if a == nil {
+		return false
+	}
+	host = canonicalHost(host)
+	if a.hosts[host] {
+		return true
+	}
+	for _, suffix := range a.suffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
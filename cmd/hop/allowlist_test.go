@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAllowlist(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "hosts, wildcards and comments", data: "# partners\npartner.com\n\n*.Shop.Example.com\n"},
+		{name: "empty file", data: ""},
+		{name: "url instead of host", data: "https://partner.com/", wantErr: "line 1"},
+		{name: "wildcard in the middle", data: "ok.com\nshop.*.com", wantErr: "line 2"},
+		{name: "bare wildcard", data: "*.", wantErr: "invalid wildcard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseAllowlist([]byte(tt.data))
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("parseAllowlist() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("parseAllowlist() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHostAllowlistAllows(t *testing.T) {
+	allowlist, err := parseAllowlist([]byte("partner.com\n*.Shop.Example.com\nLEGACY.org.\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "partner.com", want: true},
+		{host: "PARTNER.com:443", want: true},
+		{host: "www.partner.com", want: false},
+		{host: "eu.shop.example.com", want: true},
+		{host: "a.b.shop.example.com", want: true},
+		{host: "shop.example.com", want: false},
+		{host: "evilshop.example.com", want: false},
+		{host: "legacy.org", want: true},
+		{host: "other.com", want: false},
+	}
+	for _, tt := range tests {
+		if got := allowlist.allows(tt.host); got != tt.want {
+			t.Errorf("allows(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+
+	var none *HostAllowlist
+	if none.allows("partner.com") {
+		t.Error("nil allowlist allows a host")
+	}
+}
+
+func TestCheckSecurityIssuesExternalAllowlist(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/partner", "https://partner.com/offer"),
+		redirectRule("r2", "/other", "https://other.com/"),
+	}
+	allowlist, err := parseAllowlist([]byte("partner.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		allowlist *HostAllowlist
+		want      map[string]string // rule GUID to severity of its external domain issue
+	}{
+		{name: "no allowlist", allowlist: nil, want: map[string]string{"r1": "info", "r2": "info"}},
+		{name: "allowlist", allowlist: allowlist, want: map[string]string{"r2": "warning"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			for _, issue := range checkSecurityIssues(rules, nil, tt.allowlist, "") {
+				if strings.Contains(issue.Message, "external domain") {
+					got[issue.Rule.Guid] = issue.Severity
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("external domain issues = %v, want %v", got, tt.want)
+			}
+			for guid, severity := range tt.want {
+				if got[guid] != severity {
+					t.Errorf("external domain issues = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeGitHubAnnotations prints a GitHub Actions workflow command for every issue of a check result.
+// Info findings about a rule become notices, info results without a rule such as DNS OK are left out.
+func writeGitHubAnnotations(w io.Writer, result CheckResult) error {
+	issues := append([]CheckIssue{}, result.Issues...)
+	for _, issue := range result.Successful {
+		if issue.Rule != nil {
+			issues = append(issues, issue)
+		}
+	}
+	for _, issue := range issues {
+		if _, err := fmt.Fprintln(w, githubAnnotation(issue)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Side effect free functions
+
+// githubAnnotationLevel maps a severity to a workflow command, ranking severities like --fail-on
+func githubAnnotationLevel(severity string) string {
+	switch {
+	case severityAtLeast(severity, SeverityError):
+		return "error"
+	case severityAtLeast(severity, SeverityWarning):
+		return "warning"
+	default:
+		return "notice"
+	}
+}
+
+// githubAnnotation renders an issue as a workflow command, the message names the rule's source path and GUID
+func githubAnnotation(issue CheckIssue) string {
+	message := issue.Message
+	if issue.Rule != nil {
+		message = fmt.Sprintf("%s (source %s, GUID %s)", message, extractSourceURL(*issue.Rule), issue.Rule.Guid)
+	}
+	return fmt.Sprintf("::%s title=%s::%s", githubAnnotationLevel(issue.Severity), escapeWorkflowProperty("hop "+issue.Type), escapeWorkflowData(message))
+}
+
+// escapeWorkflowData escapes the message of a workflow command, a newline would otherwise end the command
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeWorkflowProperty escapes a property value of a workflow command, where ':' and ',' are delimiters
+func escapeWorkflowProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGitHubAnnotationLevel(t *testing.T) {
+	tests := map[string]string{
+		SeverityCritical: "error",
+		SeverityError:    "error",
+		SeverityWarning:  "warning",
+		SeverityInfo:     "notice",
+		"":               "notice",
+	}
+	for severity, want := range tests {
+		if got := githubAnnotationLevel(severity); got != want {
+			t.Errorf("githubAnnotationLevel(%q) = %q, want %q", severity, got, want)
+		}
+	}
+}
+
+func TestEscapeWorkflowData(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "100% broken", want: "100%25 broken"},
+		{in: "line one\nline two\r\n", want: "line one%0Aline two%0D%0A"},
+		{in: "already %0A escaped", want: "already %250A escaped"},
+		{in: "a::b, c:d", want: "a::b, c:d"}, // only the first :: after the properties is a delimiter
+	}
+	for _, tt := range tests {
+		if got := escapeWorkflowData(tt.in); got != tt.want {
+			t.Errorf("escapeWorkflowData(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeWorkflowProperty(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "hop url_health", want: "hop url_health"},
+		{in: "a::b", want: "a%3A%3Ab"},
+		{in: "x,y=1%\n", want: "x%2Cy=1%25%0A"},
+	}
+	for _, tt := range tests {
+		if got := escapeWorkflowProperty(tt.in); got != tt.want {
+			t.Errorf("escapeWorkflowProperty(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	rule := redirectRule("r1", "/old", "https://example.com/new")
+	result := CheckResult{
+		Issues: []CheckIssue{
+			{Type: IssueURLHealth, Severity: SeverityCritical, Message: "Broken destination URL (HTTP 503)", Rule: &rule},
+			{Type: IssueRedirectChain, Severity: SeverityWarning, Message: "Chain:: 100%\ndone", Rule: &rule},
+		},
+		Successful: []CheckIssue{
+			{Type: IssueSecurity, Severity: SeverityInfo, Message: "Open redirect to external domain detected", Rule: &rule},
+			{Type: IssueDNSOK, Severity: SeverityInfo, Message: "DNS ok"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeGitHubAnnotations(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	want := "::error title=hop url_health::Broken destination URL (HTTP 503) (source /old, GUID r1)\n" +
+		"::warning title=hop redirect_chain::Chain:: 100%25%0Adone (source /old, GUID r1)\n" +
+		"::notice title=hop security::Open redirect to external domain detected (source /old, GUID r1)\n"
+	if buf.String() != want {
+		t.Errorf("annotations =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// hashedAssetsRuleDescription marks the hop-managed edge rule that sets Cache-Control for content-hashed files
+const hashedAssetsRuleDescription = "hop-hashed-assets"
+
+// immutableCacheControl lets browsers and proxies cache content-hashed files for a year without revalidating
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// defaultHashedAssetsPattern matches file names with at least 8 hex characters right before the extension, e.g. app.3f9a2c1b.js
+const defaultHashedAssetsPattern = `[.-][0-9a-fA-F]{8,}\.[A-Za-z0-9]+$`
+
+// maxHashedAssetsPatterns keeps the hop-managed rule within Bunny's limit for URL patterns per trigger
+const maxHashedAssetsPatterns = 50
+
+// Side effect free functions
+
+// selectHashedAssets returns the URL paths of the files whose name matches the hashed assets pattern, sorted
+func selectHashedAssets(relPaths []string, pattern *regexp.Regexp) []string {
+	var hashed []string
+	for _, relPath := range relPaths {
+		urlPath := "/" + strings.TrimPrefix(strings.ReplaceAll(relPath, "\\", "/"), "/")
+		if pattern.MatchString(path.Base(urlPath)) {
+			hashed = append(hashed, urlPath)
+		}
+	}
+	sort.Strings(hashed)
+	return hashed
+}
+
+// pushedAssetPaths returns the URL paths of the files a push left on the storage, uploaded or unchanged, and the URL directory the push serves them under.
+// Files of a release are served from the release root, other files under their storage path.
+func pushedAssetPaths(results []FileUploadStatus, remoteDir string, release bool) ([]string, string) {
+	base := "/" + strings.Trim(remoteDir, "/")
+	if release {
+		base = "/"
+	}
+	var urlPaths []string
+	for _, result := range results {
+		if !result.Success || result.Deleted || result.Planned || result.Reason == oversizeSkipReason {
+			continue
+		}
+		storagePath := result.RelPath
+		if !release {
+			storagePath = remoteFilePath(remoteDir, result.RelPath)
+		}
+		urlPaths = append(urlPaths, "/"+strings.TrimPrefix(storagePath, "/"))
+	}
+	sort.Strings(urlPaths)
+	return urlPaths, base
+}
+
+// compressAssetPatterns turns hashed asset paths into as few URL patterns as possible.
+// Each path is covered by the shortest "<dir>/*<ext>" wildcard below base that matches no other known file,
+// or by its exact path when every wildcard would also mark an unhashed file as immutable. A wildcard never covers base itself,
+// which holds files hop does not know about, like those of other pushes to the zone.
+func compressAssetPatterns(hashed, all []string, base string) []string {
+	baseDepth := 0
+	if trimmed := strings.Trim(base, "/"); trimmed != "" {
+		baseDepth = len(strings.Split(trimmed, "/"))
+	}
+
+	isHashed := make(map[string]bool, len(hashed))
+	for _, p := range hashed {
+		isHashed[strings.ToLower(p)] = true
+	}
+
+	safe := make(map[string]bool)
+	isSafe := func(pattern string) bool {
+		if ok, checked := safe[pattern]; checked {
+			return ok
+		}
+		ok := true
+		for _, p := range all {
+			urlPath := "/" + strings.TrimPrefix(strings.ReplaceAll(p, "\\", "/"), "/")
+			if !isHashed[strings.ToLower(urlPath)] && matchesTriggerPattern(pattern, urlPath) {
+				ok = false
+				break
+			}
+		}
+		safe[pattern] = ok
+		return ok
+	}
+
+	seen := make(map[string]bool)
+	var patterns []string
+	for _, p := range hashed {
+		chosen := p
+		if ext := path.Ext(p); ext != "" {
+			var segments []string
+			if dir := strings.Trim(path.Dir(p), "/"); dir != "" {
+				segments = strings.Split(dir, "/")
+			}
+			for depth := baseDepth + 1; depth <= len(segments); depth++ {
+				candidate := path.Join("/", strings.Join(segments[:depth], "/"), "*"+ext)
+				if isSafe(candidate) {
+					chosen = candidate
+					break
+				}
+			}
+		}
+		if !seen[chosen] {
+			seen[chosen] = true
+			patterns = append(patterns, chosen)
+		}
+	}
+
+	sort.Strings(patterns)
+	return patterns
+}
+
+// buildHashedAssetsRule builds the hop-managed rule that marks the matched assets as immutable
+func buildHashedAssetsRule(guid string, patterns []string) EdgeRule {
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          5, // SetResponseHeader
+		ActionParameter1:    "Cache-Control",
+		ActionParameter2:    immutableCacheControl,
+		TriggerMatchingType: 0, // MatchAny
+		Description:         hashedAssetsRuleDescription,
+		Enabled:             true,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      patterns,
+				PatternMatchingType: 0, // MatchAny
+			},
+		},
+	}
+}
+
+// findHashedAssetsRule returns the hop-managed hashed assets rule, or nil if the zone has none
+func findHashedAssetsRule(rules []EdgeRuleResponse) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if rule.Description == hashedAssetsRuleDescription {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// planHashedAssetsRule returns the rule to create or update, or nil if the existing rule already matches the patterns
+func planHashedAssetsRule(rules []EdgeRuleResponse, patterns []string) *EdgeRule {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	existing := findHashedAssetsRule(rules)
+	if existing == nil {
+		rule := buildHashedAssetsRule("", patterns)
+		return &rule
+	}
+
+	upToDate := existing.Enabled &&
+		existing.ActionType == 5 &&
+		existing.ActionParameter1 == "Cache-Control" &&
+		existing.ActionParameter2 == immutableCacheControl &&
+		len(existing.Triggers) == 1 &&
+		existing.Triggers[0].Type == 0 &&
+		slices.Equal(sortedCopy(existing.Triggers[0].PatternMatches), patterns)
+	if upToDate {
+		return nil
+	}
+
+	rule := buildHashedAssetsRule(existing.Guid, patterns)
+	return &rule
+}
+
+// sortedCopy returns a sorted copy of a string slice
+func sortedCopy(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Side effect functions (HTTP calls)
+
+// syncHashedAssetsRule creates or updates the hop-managed rule so it covers the hashed assets, reporting whether it changed
+func syncHashedAssetsRule(ctx context.Context, apiKey, zoneID string, patterns []string) (bool, error) {
+	if len(patterns) > maxHashedAssetsPatterns {
+		return false, fmt.Errorf("hashed assets need %d URL patterns, more than the limit of %d", len(patterns), maxHashedAssetsPatterns)
+	}
+
+	rules, err := listEdgeRules(ctx, apiKey, zoneID)
+	if err != nil {
+		return false, fmt.Errorf("error listing edge rules: %v", err)
+	}
+
+	rule := planHashedAssetsRule(rules, patterns)
+	if rule == nil {
+		return false, nil
+	}
+	if err := addEdgeRule(ctx, apiKey, zoneID, *rule); err != nil {
+		return false, fmt.Errorf("error updating hashed assets rule: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSelectHashedAssets(t *testing.T) {
+	pattern := regexp.MustCompile(defaultHashedAssetsPattern)
+	relPaths := []string{
+		"index.html",
+		"assets/app.3f9a2c1b.js",
+		"assets/app.js",
+		"assets/vendor-0123456789abcdef.css",
+		"assets/short.3f9a2c.js",
+		`img\logo.deadbeef.png`,
+		"assets/notes.3f9a2c1b",
+	}
+	got := strings.Join(selectHashedAssets(relPaths, pattern), ",")
+	want := "/assets/app.3f9a2c1b.js,/assets/vendor-0123456789abcdef.css,/img/logo.deadbeef.png"
+	if got != want {
+		t.Errorf("selectHashedAssets() = %s, want %s", got, want)
+	}
+}
+
+func TestCompressAssetPatterns(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		hashed []string
+		other  []string
+		want   string
+	}{
+		{
+			name:   "every file of an extension is hashed",
+			hashed: []string{"/assets/app.3f9a2c1b.js", "/assets/chunk.0badf00d.js", "/assets/js/deep.12345678.js"},
+			other:  []string{"/index.html"},
+			want:   "/assets/*.js",
+		},
+		{
+			name:   "files at the root stay exact paths",
+			hashed: []string{"/app.3f9a2c1b.js", "/chunk.0badf00d.js"},
+			want:   "/app.3f9a2c1b.js,/chunk.0badf00d.js",
+		},
+		{
+			name:   "wildcards stay below the remote directory",
+			base:   "/site-a",
+			hashed: []string{"/site-a/app.3f9a2c1b.js", "/site-a/assets/chunk.0badf00d.js"},
+			want:   "/site-a/app.3f9a2c1b.js,/site-a/assets/*.js",
+		},
+		{
+			name:   "unhashed file elsewhere limits the wildcard to the directory",
+			hashed: []string{"/assets/app.3f9a2c1b.js", "/assets/chunk.0badf00d.js"},
+			other:  []string{"/sw.js"},
+			want:   "/assets/*.js",
+		},
+		{
+			name:   "unhashed sibling falls back to exact paths",
+			hashed: []string{"/assets/app.3f9a2c1b.js", "/assets/chunk.0badf00d.js"},
+			other:  []string{"/assets/app.js"},
+			want:   "/assets/app.3f9a2c1b.js,/assets/chunk.0badf00d.js",
+		},
+		{
+			name:   "unhashed file in a subdirectory blocks the parent wildcard",
+			hashed: []string{"/static/app.3f9a2c1b.js", "/static/js/x.0badf00d.js"},
+			other:  []string{"/static/legacy/old.js"},
+			want:   "/static/app.3f9a2c1b.js,/static/js/*.js",
+		},
+		{
+			name:   "extensions are compressed separately",
+			hashed: []string{"/a/app.3f9a2c1b.js", "/a/app.3f9a2c1b.css"},
+			other:  []string{"/a/print.css"},
+			want:   "/a/*.js,/a/app.3f9a2c1b.css",
+		},
+		{
+			name:   "matching ignores case",
+			hashed: []string{"/assets/app.3f9a2c1b.js"},
+			other:  []string{"/Assets/App.js"},
+			want:   "/assets/app.3f9a2c1b.js",
+		},
+		{
+			name: "no hashed files",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := append(append([]string{}, tt.hashed...), tt.other...)
+			base := tt.base
+			if base == "" {
+				base = "/"
+			}
+			patterns := compressAssetPatterns(tt.hashed, all, base)
+			if got := strings.Join(patterns, ","); got != tt.want {
+				t.Fatalf("compressAssetPatterns() = %s, want %s", got, tt.want)
+			}
+			// The patterns must cover every hashed file and no other file
+			for _, p := range all {
+				covered := false
+				for _, pattern := range patterns {
+					if matchesTriggerPattern(pattern, p) {
+						covered = true
+					}
+				}
+				if hashed := slices.Contains(tt.hashed, p); covered != hashed {
+					t.Errorf("%s covered = %v, want %v", p, covered, hashed)
+				}
+			}
+		})
+	}
+}
+
+func TestPushedAssetPaths(t *testing.T) {
+	results := []FileUploadStatus{
+		{RelPath: "assets/app.3f9a2c1b.js", Success: true},
+		{RelPath: "index.html", Success: true, Skipped: true, Reason: "checksum match"},
+		{RelPath: "video.mp4", Success: true, Skipped: true, Reason: oversizeSkipReason},
+		{RelPath: "broken.js", Error: errors.New("upload failed")},
+		{RelPath: "old.js", Success: true, Deleted: true},
+	}
+	tests := []struct {
+		name      string
+		remoteDir string
+		release   bool
+		want      []string
+		wantBase  string
+	}{
+		{name: "storage root", want: []string{"/assets/app.3f9a2c1b.js", "/index.html"}, wantBase: "/"},
+		{name: "remote directory", remoteDir: "site-a", want: []string{"/site-a/assets/app.3f9a2c1b.js", "/site-a/index.html"}, wantBase: "/site-a"},
+		{name: "release", remoteDir: "releases/r1", release: true, want: []string{"/assets/app.3f9a2c1b.js", "/index.html"}, wantBase: "/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, base := pushedAssetPaths(results, tt.remoteDir, tt.release)
+			if !slices.Equal(got, tt.want) || base != tt.wantBase {
+				t.Errorf("pushedAssetPaths() = %v, %q, want %v, %q", got, base, tt.want, tt.wantBase)
+			}
+		})
+	}
+}
+
+func TestPlanHashedAssetsRule(t *testing.T) {
+	patterns := []string{"/assets/*.js", "/img/*.png"}
+	existing := func(patterns ...string) []EdgeRuleResponse {
+		rule := buildHashedAssetsRule("hashed-guid", patterns)
+		return []EdgeRuleResponse{
+			redirectRule("r1", "/old", "https://example.com/new"),
+			{Guid: rule.Guid, ActionType: rule.ActionType, ActionParameter1: rule.ActionParameter1, ActionParameter2: rule.ActionParameter2,
+				Triggers: rule.Triggers, Description: rule.Description, Enabled: rule.Enabled},
+		}
+	}
+
+	if plan := planHashedAssetsRule(nil, patterns); plan == nil || plan.Guid != "" {
+		t.Errorf("without a rule the plan should create one, got %+v", plan)
+	}
+	if plan := planHashedAssetsRule(existing("/img/*.png", "/assets/*.js"), patterns); plan != nil {
+		t.Errorf("same patterns in another order should be up to date, got %+v", plan)
+	}
+	if plan := planHashedAssetsRule(existing("/assets/*.js"), patterns); plan == nil || plan.Guid != "hashed-guid" {
+		t.Errorf("changed patterns should update the existing rule, got %+v", plan)
+	}
+	disabled := existing(patterns...)
+	disabled[1].Enabled = false
+	if plan := planHashedAssetsRule(disabled, patterns); plan == nil || !plan.Enabled {
+		t.Errorf("a disabled rule should be re-enabled, got %+v", plan)
+	}
+	if plan := planHashedAssetsRule(existing(patterns...), nil); plan != nil {
+		t.Errorf("no patterns should leave the rule alone, got %+v", plan)
+	}
+}
+
+func TestSyncHashedAssetsRuleIsIdempotent(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+
+	for i, want := range []bool{true, false} {
+		changed, err := syncHashedAssetsRule(ctx, fakeAPIKey, "1", []string{"/assets/*.js"})
+		if err != nil {
+			t.Fatalf("syncHashedAssetsRule() error = %v", err)
+		}
+		if changed != want {
+			t.Errorf("run %d changed = %v, want %v", i+1, changed, want)
+		}
+	}
+
+	if _, err := syncHashedAssetsRule(ctx, fakeAPIKey, "1", []string{"/assets/*.js", "/img/*.png"}); err != nil {
+		t.Fatal(err)
+	}
+	rules := fb.edgeRules(1)
+	if len(rules) != 1 {
+		t.Fatalf("got %d rules, want the single hop-managed rule", len(rules))
+	}
+	if got := strings.Join(rules[0].Triggers[0].PatternMatches, ","); got != "/assets/*.js,/img/*.png" {
+		t.Errorf("rule patterns = %s", got)
+	}
+	if rules[0].ActionParameter2 != immutableCacheControl {
+		t.Errorf("Cache-Control = %s", rules[0].ActionParameter2)
+	}
+
+	tooMany := make([]string, maxHashedAssetsPatterns+1)
+	if _, err := syncHashedAssetsRule(ctx, fakeAPIKey, "1", tooMany); err == nil {
+		t.Error("syncHashedAssetsRule() should refuse more patterns than the limit")
+	}
+}
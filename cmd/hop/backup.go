@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RestorePlan is what rules restore changes to bring a zone back to a backup
+type RestorePlan struct {
+	Recreate  []EdgeRuleResponse // in the backup but no longer in the zone
+	Update    []EdgeRuleResponse // same GUID in the zone, but changed since the backup
+	Unchanged int
+	Extra     []EdgeRuleResponse // in the zone but not in the backup, left untouched
+}
+
+// readBackupFile reads the edge rules of a backup written by rules backup
+func readBackupFile(path string) ([]EdgeRuleResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []EdgeRuleResponse
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("error parsing backup '%s': %v", path, err)
+	}
+	return rules, nil
+}
+
+// backupEdgeRules writes every edge rule of a pull zone, exactly as returned by the API, to a timestamped file in dir
+func backupEdgeRules(ctx context.Context, apiKey, zoneID, zoneName, dir string, now time.Time) (string, error) {
+	body, err := getPullZoneJSON(ctx, apiKey, zoneID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := edgeRulesBackup(body)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("error creating backup directory: %v", err)
+	}
+	path := filepath.Join(dir, backupFileName(zoneName, now))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("error writing backup: %v", err)
+	}
+	return path, nil
+}
+
+// Side effect free functions
+
+// backupFileName returns the name of a rules backup, sortable by time
+func backupFileName(zoneName string, now time.Time) string {
+	return fmt.Sprintf("hop-backup-%s-%s.json", zoneName, now.UTC().Format("20060102T150405Z"))
+}
+
+// edgeRulesBackup extracts the EdgeRules array of a pull zone response without decoding the rules,
+// so fields hop does not know about are kept
+func edgeRulesBackup(pullZoneJSON []byte) ([]byte, error) {
+	var pullZone struct {
+		EdgeRules json.RawMessage `json:"EdgeRules"`
+	}
+	if err := json.Unmarshal(pullZoneJSON, &pullZone); err != nil {
+		return nil, fmt.Errorf("error parsing JSON response: %v", err)
+	}
+	if len(pullZone.EdgeRules) == 0 || string(pullZone.EdgeRules) == "null" {
+		return []byte("[]\n"), nil
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, pullZone.EdgeRules, "", "  "); err != nil {
+		return nil, fmt.Errorf("error formatting edge rules: %v", err)
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// restoredRule turns a backed up rule into the request that re-creates it, keeping its GUID
+func restoredRule(rule EdgeRuleResponse) EdgeRule {
+	triggers := make([]Trigger, len(rule.Triggers))
+	for i, trigger := range rule.Triggers {
+		triggers[i] = trigger
+		if triggers[i].PatternMatches == nil {
+			triggers[i].PatternMatches = []string{}
+		}
+	}
+	return EdgeRule{
+		Guid:                rule.Guid,
+		ActionType:          rule.ActionType,
+		ActionParameter1:    rule.ActionParameter1,
+		ActionParameter2:    rule.ActionParameter2,
+		Triggers:            triggers,
+		TriggerMatchingType: rule.TriggerMatchingType,
+		Description:         rule.Description,
+		Enabled:             rule.Enabled,
+	}
+}
+
+// ruleContentKey identifies what a rule does, ignoring its GUID
+func ruleContentKey(rule EdgeRuleResponse) string {
+	restored := restoredRule(rule)
+	restored.Guid = ""
+	data, _ := json.Marshal(restored)
+	return string(data)
+}
+
+// planRestore compares a backup with the current rules. Rules are matched by GUID first and then by content,
+// because Bunny may assign a new GUID to a re-created rule and a second restore must not duplicate it.
+func planRestore(backup, current []EdgeRuleResponse) RestorePlan {
+	var plan RestorePlan
+
+	byGuid := make(map[string]int)
+	byContent := make(map[string][]int)
+	for i, rule := range current {
+		byGuid[rule.Guid] = i
+		key := ruleContentKey(rule)
+		byContent[key] = append(byContent[key], i)
+	}
+	matched := make(map[int]bool)
+
+	// Match every GUID that still exists before matching by content, so a content match cannot steal a GUID match
+	var unmatched []EdgeRuleResponse
+	for _, rule := range backup {
+		i, exists := byGuid[rule.Guid]
+		if !exists || rule.Guid == "" || matched[i] {
+			unmatched = append(unmatched, rule)
+			continue
+		}
+		matched[i] = true
+		if ruleContentKey(rule) == ruleContentKey(current[i]) {
+			plan.Unchanged++
+		} else {
+			plan.Update = append(plan.Update, rule)
+		}
+	}
+
+	for _, rule := range unmatched {
+		found := false
+		for _, i := range byContent[ruleContentKey(rule)] {
+			if !matched[i] {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if found {
+			plan.Unchanged++
+		} else {
+			plan.Recreate = append(plan.Recreate, rule)
+		}
+	}
+
+	for i, rule := range current {
+		if !matched[i] {
+			plan.Extra = append(plan.Extra, rule)
+		}
+	}
+	return plan
+}
+
+// describeRule summarizes a rule on one line for plans
+func describeRule(rule EdgeRuleResponse) string {
+	summary := fmt.Sprintf("%s %s", rule.Guid, formatActionType(rule.ActionType))
+	if patterns := urlTriggerPatterns(rule); len(patterns) > 0 {
+		summary += " " + strings.Join(patterns, ", ")
+	}
+	if rule.ActionParameter1 != "" {
+		summary += " -> " + rule.ActionParameter1
+	}
+	if rule.Description != "" {
+		summary += fmt.Sprintf(" (%s)", rule.Description)
+	}
+	return summary
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupFileName(t *testing.T) {
+	now := time.Date(2024, 6, 1, 14, 5, 9, 0, time.FixedZone("CEST", 2*60*60))
+	if got, want := backupFileName("prod", now), "hop-backup-prod-20240601T120509Z.json"; got != want {
+		t.Errorf("backupFileName() = %s, want %s", got, want)
+	}
+}
+
+func TestEdgeRulesBackup(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "keeps unknown fields",
+			input: `{"Id":1,"EdgeRules":[{"Guid":"g1","ActionType":5,"FutureField":{"a":1}}]}`,
+			want:  "[\n  {\n    \"Guid\": \"g1\",\n    \"ActionType\": 5,\n    \"FutureField\": {\n      \"a\": 1\n    }\n  }\n]\n",
+		},
+		{name: "no rules", input: `{"Id":1,"EdgeRules":null}`, want: "[]\n"},
+		{name: "missing rules", input: `{"Id":1}`, want: "[]\n"},
+		{name: "invalid JSON", input: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := edgeRulesBackup([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("edgeRulesBackup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("edgeRulesBackup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupEdgeRules(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+
+	for _, rule := range []EdgeRule{
+		{ActionType: 1, ActionParameter1: "https://example.com/new", ActionParameter2: "302", Enabled: true,
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/old"}}}},
+		{ActionType: 5, ActionParameter1: "Cache-Control", ActionParameter2: "no-store", Enabled: false,
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/api/*"}}}},
+	} {
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", rule); err != nil {
+			t.Fatalf("addEdgeRule() error = %v", err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	path, err := backupEdgeRules(ctx, fakeAPIKey, "1", "prod", dir, time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("backupEdgeRules() error = %v", err)
+	}
+	if !strings.HasSuffix(path, "hop-backup-prod-20240601T120000Z.json") {
+		t.Errorf("backup path = %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rules []EdgeRuleResponse
+	if err := json.Unmarshal(data, &rules); err != nil {
+		t.Fatalf("backup is not a JSON array of rules: %v", err)
+	}
+	if len(rules) != 2 || rules[0].ActionType != 1 || rules[1].ActionType != 5 || rules[1].Enabled {
+		t.Errorf("backup rules = %+v, want both rules of every action type", rules)
+	}
+}
+
+func TestPlanRestore(t *testing.T) {
+	a := redirectRule("a", "/a", "https://example.com/a")
+	b := redirectRule("b", "/b", "https://example.com/b")
+	c := redirectRule("c", "/c", "https://example.com/c")
+	changedB := b
+	changedB.ActionParameter1 = "https://example.com/changed"
+	recreatedC := c
+	recreatedC.Guid = "new-guid"
+	extra := redirectRule("x", "/x", "https://example.com/x")
+
+	guids := func(rules []EdgeRuleResponse) string {
+		var out []string
+		for _, rule := range rules {
+			out = append(out, rule.Guid)
+		}
+		return strings.Join(out, ",")
+	}
+
+	tests := []struct {
+		name          string
+		backup        []EdgeRuleResponse
+		current       []EdgeRuleResponse
+		wantRecreate  string
+		wantUpdate    string
+		wantUnchanged int
+		wantExtra     string
+	}{
+		{name: "nothing changed", backup: []EdgeRuleResponse{a, b}, current: []EdgeRuleResponse{a, b}, wantUnchanged: 2},
+		{name: "deleted rule is recreated", backup: []EdgeRuleResponse{a, b}, current: []EdgeRuleResponse{a}, wantRecreate: "b", wantUnchanged: 1},
+		{name: "changed rule is updated", backup: []EdgeRuleResponse{a, b}, current: []EdgeRuleResponse{a, changedB}, wantUpdate: "b", wantUnchanged: 1},
+		{name: "recreated rule with a new GUID is unchanged", backup: []EdgeRuleResponse{c}, current: []EdgeRuleResponse{recreatedC}, wantUnchanged: 1},
+		{name: "rules not in the backup are reported", backup: []EdgeRuleResponse{a}, current: []EdgeRuleResponse{a, extra}, wantUnchanged: 1, wantExtra: "x"},
+		{name: "identical copies are matched one to one", backup: []EdgeRuleResponse{c, func() EdgeRuleResponse { r := c; r.Guid = "c2"; return r }()}, current: []EdgeRuleResponse{recreatedC}, wantRecreate: "c2", wantUnchanged: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planRestore(tt.backup, tt.current)
+			if got := guids(plan.Recreate); got != tt.wantRecreate {
+				t.Errorf("Recreate = %s, want %s", got, tt.wantRecreate)
+			}
+			if got := guids(plan.Update); got != tt.wantUpdate {
+				t.Errorf("Update = %s, want %s", got, tt.wantUpdate)
+			}
+			if plan.Unchanged != tt.wantUnchanged {
+				t.Errorf("Unchanged = %d, want %d", plan.Unchanged, tt.wantUnchanged)
+			}
+			if got := guids(plan.Extra); got != tt.wantExtra {
+				t.Errorf("Extra = %s, want %s", got, tt.wantExtra)
+			}
+		})
+	}
+}
+
+func TestRestoreRoundTrip(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+
+	for _, source := range []string{"/a", "/b"} {
+		rule := EdgeRule{ActionType: 1, ActionParameter1: "https://example.com" + source, ActionParameter2: "302", Enabled: true,
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{source}}}}
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path, err := backupEdgeRules(ctx, fakeAPIKey, "1", "prod", t.TempDir(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := readBackupFile(path)
+	if err != nil {
+		t.Fatalf("readBackupFile() error = %v", err)
+	}
+
+	// Lose one rule and change the other
+	rules := fb.edgeRules(1)
+	if err := deleteEdgeRule(ctx, fakeAPIKey, "1", rules[0].Guid); err != nil {
+		t.Fatal(err)
+	}
+	changed := restoredRule(rules[1])
+	changed.ActionParameter1 = "https://example.com/oops"
+	if err := addEdgeRule(ctx, fakeAPIKey, "1", changed); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := planRestore(backup, fb.edgeRules(1))
+	if len(plan.Recreate) != 1 || len(plan.Update) != 1 {
+		t.Fatalf("plan = %+v, want one rule to recreate and one to update", plan)
+	}
+	for _, rule := range append(plan.Recreate, plan.Update...) {
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", restoredRule(rule)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	again := planRestore(backup, fb.edgeRules(1))
+	if len(again.Recreate) != 0 || len(again.Update) != 0 || len(again.Extra) != 0 || again.Unchanged != 2 {
+		t.Errorf("second restore plan = %+v, want everything unchanged", again)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Baseline lists accepted issues of rules check, matching issues are suppressed instead of failing the run
+type Baseline struct {
+	Issues []BaselineIssue `json:"issues"`
+}
+
+// BaselineIssue identifies an accepted issue by its type, the GUID of its rule and its message.
+// Source is informational, it makes the file readable but does not take part in matching.
+type BaselineIssue struct {
+	Type    string `json:"type"`
+	Guid    string `json:"guid,omitempty"`
+	Message string `json:"message"`
+	Source  string `json:"source,omitempty"`
+}
+
+// readBaseline reads a baseline file written by --write-baseline
+func readBaseline(path string) (*Baseline, error) {
+	// #nosec G304 - the baseline path is supplied by the user on purpose
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("error parsing '%s': %v", path, err)
+	}
+	return &baseline, nil
+}
+
+// writeBaseline records issues as the accepted baseline
+func writeBaseline(path string, issues []CheckIssue) error {
+	data, err := json.MarshalIndent(buildBaseline(issues), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding baseline: %v", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Side effect free functions
+
+// buildBaseline returns the baseline of issues, sorted so the file diffs cleanly when rules are re-ordered
+func buildBaseline(issues []CheckIssue) Baseline {
+	baseline := Baseline{Issues: []BaselineIssue{}}
+	seen := make(map[BaselineIssue]bool)
+	for _, issue := range issues {
+		key := baselineKey(issue)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		entry := BaselineIssue{Type: issue.Type, Message: issue.Message, Guid: key.Guid}
+		if issue.Rule != nil {
+			entry.Source = extractSourceURL(*issue.Rule)
+		}
+		baseline.Issues = append(baseline.Issues, entry)
+	}
+	slices.SortFunc(baseline.Issues, func(a, b BaselineIssue) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Guid, b.Guid), cmp.Compare(a.Message, b.Message))
+	})
+	return baseline
+}
+
+// baselineKey returns what identifies an issue in a baseline: its type, its rule's GUID and its normalized message.
+// Rule positions are not part of it, so re-ordering rules keeps the matches.
+func baselineKey(issue CheckIssue) BaselineIssue {
+	key := BaselineIssue{Type: issue.Type, Message: normalizeBaselineMessage(issue.Message)}
+	if issue.Rule != nil {
+		key.Guid = issue.Rule.Guid
+	}
+	return key
+}
+
+// baselineNumber matches numbers in issue messages, with a duration unit attached like in 850ms or 1m30s
+var baselineNumber = regexp.MustCompile(`(\d+(\.\d+)?(ns|µs|us|ms|s|m|h)?)+`)
+
+// normalizeBaselineMessage reduces a message to its stable template: it ignores case and differences in whitespace,
+// replaces numbers and durations with # and drops the error text after the first ": ", so response times and
+// network errors that change from run to run keep matching
+func normalizeBaselineMessage(message string) string {
+	message, _, _ = strings.Cut(message, ": ")
+	message = baselineNumber.ReplaceAllString(message, "#")
+	return strings.ToLower(strings.Join(strings.Fields(message), " "))
+}
+
+// applyBaseline moves the issues matching the baseline from Issues to Suppressed; a nil baseline keeps every issue
+func applyBaseline(result CheckResult, baseline *Baseline) CheckResult {
+	if baseline == nil {
+		return result
+	}
+	accepted := make(map[BaselineIssue]bool)
+	for _, entry := range baseline.Issues {
+		accepted[BaselineIssue{Type: entry.Type, Guid: entry.Guid, Message: normalizeBaselineMessage(entry.Message)}] = true
+	}
+
+	var issues []CheckIssue
+	for _, issue := range result.Issues {
+		if accepted[baselineKey(issue)] {
+			result.Suppressed = append(result.Suppressed, issue)
+		} else {
+			issues = append(issues, issue)
+		}
+	}
+	result.Issues = issues
+	return result
+}
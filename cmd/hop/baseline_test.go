@@ -0,0 +1,117 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyBaseline(t *testing.T) {
+	chain := redirectRule("chain", "/a", "https://example.com/b")
+	external := redirectRule("ext", "/partner", "https://partner.example.net/")
+	other := redirectRule("other", "/c", "https://example.com/d")
+
+	accepted := []CheckIssue{
+		{Type: IssueRedirectChain, Severity: SeverityWarning, Message: "Redirect chain of 2 hops", Rule: &chain},
+		{Type: IssueSecurity, Severity: SeverityWarning, Message: "Redirect to external domain not on the allowlist", Rule: &external},
+	}
+	baseline := buildBaseline(accepted)
+
+	tests := []struct {
+		name           string
+		issues         []CheckIssue
+		wantIssues     int
+		wantSuppressed int
+	}{
+		{
+			name:           "accepted issues are suppressed",
+			issues:         accepted,
+			wantSuppressed: 2,
+		},
+		{
+			name:           "message case and whitespace are ignored",
+			issues:         []CheckIssue{{Type: IssueRedirectChain, Severity: SeverityWarning, Message: "redirect  chain of 2 HOPS", Rule: &chain}},
+			wantSuppressed: 1,
+		},
+		{
+			name:       "same message on another rule is new",
+			issues:     []CheckIssue{{Type: IssueRedirectChain, Severity: SeverityWarning, Message: "Redirect chain of 2 hops", Rule: &other}},
+			wantIssues: 1,
+		},
+		{
+			name:       "same rule with another type is new",
+			issues:     []CheckIssue{{Type: IssueConfiguration, Severity: SeverityWarning, Message: "Redirect chain of 2 hops", Rule: &chain}},
+			wantIssues: 1,
+		},
+		{
+			name:           "changed number is the same issue",
+			issues:         []CheckIssue{{Type: IssueRedirectChain, Severity: SeverityError, Message: "Redirect chain of 3 hops", Rule: &chain}},
+			wantSuppressed: 1,
+		},
+		{
+			name:       "changed message is new",
+			issues:     []CheckIssue{{Type: IssueRedirectChain, Severity: SeverityError, Message: "Redirect loop of 2 hops", Rule: &chain}},
+			wantIssues: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := applyBaseline(CheckResult{Issues: tt.issues}, &baseline)
+			if len(result.Issues) != tt.wantIssues || len(result.Suppressed) != tt.wantSuppressed {
+				t.Errorf("applyBaseline() kept %d and suppressed %d issues, want %d and %d", len(result.Issues), len(result.Suppressed), tt.wantIssues, tt.wantSuppressed)
+			}
+			if hasIssueAtLeast(result.Issues, SeverityWarning) != (tt.wantIssues > 0) {
+				t.Error("suppressed issues still count for the exit code")
+			}
+		})
+	}
+
+	if result := applyBaseline(CheckResult{Issues: accepted}, nil); len(result.Issues) != 2 || len(result.Suppressed) != 0 {
+		t.Errorf("applyBaseline() without a baseline = %+v", result)
+	}
+}
+
+func TestNormalizeBaselineMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		same bool
+	}{
+		{"changing duration", "Slow destination URL (first byte after 850ms, threshold 500ms)", "Slow destination URL (first byte after 3.2s, threshold 500ms)", true},
+		{"compound duration", "Slow destination URL (first byte after 1m30s, threshold 3s)", "Slow destination URL (first byte after 4.1s, threshold 3s)", true},
+		{"changing error text", `Origin https://example.com is unreachable: Get "https://example.com": dial tcp 10.0.0.1:443: i/o timeout`, `Origin https://example.com is unreachable: Get "https://example.com": connection refused`, true},
+		{"case and whitespace", "Redirect  chain of 2 HOPS", "redirect chain of 2 hops", true},
+		{"other host", "Origin https://a.example.com is unreachable: timeout", "Origin https://b.example.com is unreachable: timeout", false},
+		{"other wording", "Broken destination URL (HTTP 404)", "Slow destination URL (HTTP 404)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := normalizeBaselineMessage(tt.a), normalizeBaselineMessage(tt.b)
+			if (a == b) != tt.same {
+				t.Errorf("normalizeBaselineMessage() = %q and %q, want equal %v", a, b, tt.same)
+			}
+		})
+	}
+}
+
+func TestBaselineSurvivesReordering(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("a", "/a", "https://example.com/a"),
+		redirectRule("b", "/b", "https://example.com/b"),
+	}
+	rules[0].ActionParameter2 = "301"
+	rules[1].ActionParameter2 = "301"
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := writeBaseline(path, checkBasicRedirectIssues(rules)); err != nil {
+		t.Fatalf("writeBaseline() error = %v", err)
+	}
+	baseline, err := readBaseline(path)
+	if err != nil {
+		t.Fatalf("readBaseline() error = %v", err)
+	}
+
+	reordered := []EdgeRuleResponse{rules[1], rules[0]}
+	result := applyBaseline(CheckResult{Issues: checkBasicRedirectIssues(reordered)}, baseline)
+	if len(result.Issues) != 0 || len(result.Suppressed) != 2 {
+		t.Errorf("after re-ordering %d issues remain and %d are suppressed, want 0 and 2", len(result.Issues), len(result.Suppressed))
+	}
+}
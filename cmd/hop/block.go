@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Side effect free functions
+
+// blockDescription is the generated description of a block rule
+func blockDescription(from string) string {
+	return fmt.Sprintf("Block requests to %s", from)
+}
+
+// buildBlockRule builds an edge rule that denies every request matching from at the edge
+func buildBlockRule(guid, from, desc string) EdgeRule {
+	if desc == "" {
+		desc = blockDescription(from)
+	}
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          4, // BlockRequest
+		TriggerMatchingType: 0, // MatchAny
+		Description:         desc,
+		Enabled:             true,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      []string{from},
+				PatternMatchingType: 0, // MatchAny
+			},
+		},
+	}
+}
+
+// findBlockRule returns the block rule for a source pattern, or nil if there is none
+func findBlockRule(rules []EdgeRuleResponse, from string) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if rule.ActionType == 4 && extractSourceURL(rule) == from {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// patternsOverlap reports whether some request path matches both trigger patterns, ignoring case and trailing slashes
+func patternsOverlap(a, b string) bool {
+	return globsIntersect(normalizeURL(triggerPatternPath(a)), normalizeURL(triggerPatternPath(b)))
+}
+
+// globsIntersect reports whether a string exists that matches both patterns, where '*' matches any sequence
+func globsIntersect(a, b string) bool {
+	memo := make(map[[2]int]bool)
+	var match func(i, j int) bool
+	match = func(i, j int) bool {
+		key := [2]int{i, j}
+		if result, ok := memo[key]; ok {
+			return result
+		}
+
+		var result bool
+		switch {
+		case i == len(a) && j == len(b):
+			result = true
+		case i < len(a) && a[i] == '*':
+			// The star matches nothing, or swallows the next character (or star) of b
+			result = match(i+1, j) || (j < len(b) && match(i, j+1))
+		case j < len(b) && b[j] == '*':
+			result = match(i, j+1) || (i < len(a) && match(i+1, j))
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			result = match(i+1, j+1)
+		}
+		memo[key] = result
+		return result
+	}
+	return match(0, 0)
+}
+
+// checkBlockRedirectOverlaps flags enabled block rules whose pattern overlaps an enabled redirect source.
+// Which of the two applies to a request depends on rule order, so the pair is almost always a mistake.
+func checkBlockRedirectOverlaps(rules []EdgeRuleResponse) []CheckIssue {
+	var issues []CheckIssue
+	for i := range rules {
+		block := &rules[i]
+		if block.ActionType != 4 || !block.Enabled {
+			continue
+		}
+		for j := range rules {
+			redirect := &rules[j]
+			if redirect.ActionType != 1 || !redirect.Enabled {
+				continue
+			}
+			blockPattern, source, found := overlappingPatterns(urlTriggerPatterns(*block), urlTriggerPatterns(*redirect))
+			if !found {
+				continue
+			}
+			first := block
+			if redirect.OrderIndex < block.OrderIndex {
+				first = redirect
+			}
+			issues = append(issues, CheckIssue{
+				Type:     IssueConfiguration,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Block rule for %s overlaps the redirect from %s, which one applies depends on rule order (%s comes first)", blockPattern, source, first.Guid),
+				Rule:     redirect,
+				Details:  map[string]interface{}{"block_guid": block.Guid, "redirect_guid": redirect.Guid, "block_pattern": blockPattern},
+			})
+		}
+	}
+	return issues
+}
+
+// overlappingPatterns returns the first pair of patterns from two lists that can match the same path
+func overlappingPatterns(as, bs []string) (string, string, bool) {
+	for _, a := range as {
+		for _, b := range bs {
+			if patternsOverlap(a, b) {
+				return a, b, true
+			}
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func blockRule(guid, from string) EdgeRuleResponse {
+	return EdgeRuleResponse{
+		Guid:       guid,
+		ActionType: 4,
+		Enabled:    true,
+		Triggers:   []Trigger{{Type: 0, PatternMatches: []string{from}}},
+	}
+}
+
+func TestBuildBlockRule(t *testing.T) {
+	rule := buildBlockRule("", "/wp-admin*", "")
+	if rule.ActionType != 4 || rule.ActionParameter1 != "" || !rule.Enabled {
+		t.Errorf("buildBlockRule() = %+v, want an enabled BlockRequest rule without parameters", rule)
+	}
+	if len(rule.Triggers) != 1 || rule.Triggers[0].Type != 0 || rule.Triggers[0].PatternMatches[0] != "/wp-admin*" {
+		t.Errorf("buildBlockRule() triggers = %+v, want one URL trigger for /wp-admin*", rule.Triggers)
+	}
+	if rule.Description != "Block requests to /wp-admin*" {
+		t.Errorf("buildBlockRule() description = %s", rule.Description)
+	}
+}
+
+func TestFindBlockRule(t *testing.T) {
+	rules := []EdgeRuleResponse{redirectRule("redirect", "/wp-admin*", "https://example.com/"), blockRule("block", "/wp-admin*")}
+	if got := findBlockRule(rules, "/wp-admin*"); got == nil || got.Guid != "block" {
+		t.Errorf("findBlockRule() = %+v, want the block rule", got)
+	}
+	if got := findBlockRule(rules, "/xmlrpc.php"); got != nil {
+		t.Errorf("findBlockRule() = %+v, want nil", got)
+	}
+}
+
+func TestPatternsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "/wp-admin*", b: "/wp-admin/old-login", want: true},
+		{a: "/wp-admin*", b: "/WP-ADMIN/", want: true},
+		{a: "/wp-admin*", b: "/blog/*", want: false},
+		{a: "*/xmlrpc.php", b: "/blog/*", want: true},
+		{a: "/a/*/edit", b: "/a/b/*", want: true},
+		{a: "/a/*.php", b: "/a/*.html", want: false},
+		{a: "*://www.example.com/admin*", b: "/admin/users", want: true},
+		{a: "/exact", b: "/exact", want: true},
+		{a: "/exact", b: "/other", want: false},
+	}
+	for _, tt := range tests {
+		if got := patternsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("patternsOverlap(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := patternsOverlap(tt.b, tt.a); got != tt.want {
+			t.Errorf("patternsOverlap(%s, %s) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestCheckBlockRedirectOverlaps(t *testing.T) {
+	disabledBlock := blockRule("disabled-block", "/old/*")
+	disabledBlock.Enabled = false
+	rules := []EdgeRuleResponse{
+		blockRule("block", "/wp-admin*"),
+		redirectRule("overlap", "/wp-admin/login", "https://example.com/login"),
+		redirectRule("elsewhere", "/blog/*", "https://blog.example.com/"),
+		disabledBlock,
+		redirectRule("old", "/old/page", "https://example.com/new"),
+	}
+	for i := range rules {
+		rules[i].OrderIndex = i
+	}
+
+	issues := checkBlockRedirectOverlaps(rules)
+	if len(issues) != 1 {
+		t.Fatalf("checkBlockRedirectOverlaps() = %+v, want one issue", issues)
+	}
+	issue := issues[0]
+	if issue.Rule.Guid != "overlap" || issue.Details["block_guid"] != "block" || issue.Severity != "warning" {
+		t.Errorf("issue = %+v, want a warning for the redirect overlapping the block", issue)
+	}
+	if !strings.Contains(issue.Message, "block comes first") {
+		t.Errorf("message = %s, want the rule that comes first named", issue.Message)
+	}
+
+	configIssues := checkConfigurationIssues(rules)
+	found := false
+	for _, configIssue := range configIssues {
+		if configIssue.Details["block_guid"] == "block" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("checkConfigurationIssues() should include the block overlap")
+	}
+}
@@ -1,16 +1,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"reflect"
 	"strings"
 	"time"
 )
 
+// Base URLs of the Bunny API and storage endpoints, variables so tests can point them at a fake server
+var (
+	apiBaseURL     = "https://api.bunny.net"
+	storageBaseURL = "https://storage.bunnycdn.com"
+	logBaseURL     = "https://logging.bunnycdn.com"
+)
+
 // BunnyTime handles the non-standard timestamp format from Bunny CDN API
 type BunnyTime struct {
 	time.Time
@@ -45,6 +54,7 @@ type PullZone struct {
 type PullZoneDetails struct {
 	Id        int64              `json:"Id"`
 	Name      string             `json:"Name"`
+	OriginUrl string             `json:"OriginUrl"`
 	EdgeRules []EdgeRuleResponse `json:"EdgeRules"`
 	Hostnames []Hostname         `json:"Hostnames"`
 }
@@ -60,10 +70,10 @@ type StorageZone struct {
 	Password string `json:"Password"`
 }
 
-func findPullZoneByName(ctx context.Context, apiKey, name string) (int64, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.bunny.net/pullzone", nil)
+func listPullZones(ctx context.Context, apiKey string) ([]PullZone, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiBaseURL+"/pullzone", nil)
 	if err != nil {
-		return 0, fmt.Errorf("error creating request: %v", err)
+		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
 	req.Header.Set("AccessKey", apiKey)
@@ -71,40 +81,115 @@ func findPullZoneByName(ctx context.Context, apiKey, name string) (int64, error)
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return 0, fmt.Errorf("error making request: %v", err)
+		return nil, fmt.Errorf("error making request: %v", err)
 	}
 	if resp == nil {
-		return 0, fmt.Errorf("received nil response")
+		return nil, fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("API request failed with status %s: %s", resp.Status, string(body))
+		return nil, fmt.Errorf("API request failed with status %s: %s", resp.Status, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, fmt.Errorf("error reading response: %v", err)
+		return nil, fmt.Errorf("error reading response: %v", err)
 	}
 
 	var pullZones []PullZone
 	if err := json.Unmarshal(body, &pullZones); err != nil {
-		return 0, fmt.Errorf("error parsing JSON response: %v", err)
+		return nil, fmt.Errorf("error parsing JSON response: %v", err)
 	}
 
-	// Search for the pull zone by name
+	return pullZones, nil
+}
+
+// pullZoneIDByName returns the ID of the pull zone with the given name, ignoring case
+func pullZoneIDByName(pullZones []PullZone, name string) (int64, bool) {
 	for _, zone := range pullZones {
 		if strings.EqualFold(zone.Name, name) {
-			return zone.Id, nil
+			return zone.Id, true
 		}
 	}
+	return 0, false
+}
+
+func findPullZoneByName(ctx context.Context, apiKey, name string) (int64, error) {
+	pullZones, err := listPullZones(ctx, apiKey)
+	if err != nil {
+		return 0, err
+	}
+
+	// Search for the pull zone by name
+	if id, found := pullZoneIDByName(pullZones, name); found {
+		return id, nil
+	}
 
 	return 0, fmt.Errorf("pull zone with name '%s' not found", name)
 }
 
+// createPullZone creates a pull zone pulling from originURL and returns its ID
+func createPullZone(ctx context.Context, apiKey, name, originURL string) (int64, error) {
+	jsonData, err := json.Marshal(map[string]string{"Name": name, "OriginUrl": originURL})
+	if err != nil {
+		return 0, fmt.Errorf("error marshaling JSON: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", apiBaseURL+"/pullzone", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("AccessKey", apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error making request: %v", err)
+	}
+	if resp == nil {
+		return 0, fmt.Errorf("received nil response")
+	}
+	defer drainAndClose(resp.Body)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("error reading response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return 0, fmt.Errorf("API request failed with status %s: %s", resp.Status, string(body))
+	}
+
+	var pullZone PullZone
+	if err := json.Unmarshal(body, &pullZone); err != nil {
+		return 0, fmt.Errorf("error parsing JSON response: %v", err)
+	}
+
+	return pullZone.Id, nil
+}
+
 func getPullZoneDetails(ctx context.Context, apiKey, zoneID string) (*PullZoneDetails, error) {
-	url := fmt.Sprintf("https://api.bunny.net/pullzone/%s", zoneID)
+	body, err := getPullZoneJSON(ctx, apiKey, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	var pullZone PullZoneDetails
+	if err := strictUnmarshal(body, &pullZone); err != nil {
+		return nil, fmt.Errorf("error parsing JSON response: %v", err)
+	}
+
+	sortRulesByOrder(pullZone.EdgeRules)
+	return &pullZone, nil
+}
+
+// getPullZoneJSON returns the pull zone exactly as the API sends it
+func getPullZoneJSON(ctx context.Context, apiKey, zoneID string) ([]byte, error) {
+	url := fmt.Sprintf("%s/pullzone/%s", apiBaseURL, zoneID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -121,7 +206,7 @@ func getPullZoneDetails(ctx context.Context, apiKey, zoneID string) (*PullZoneDe
 	if resp == nil {
 		return nil, fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -133,12 +218,7 @@ func getPullZoneDetails(ctx context.Context, apiKey, zoneID string) (*PullZoneDe
 		return nil, fmt.Errorf("error reading response: %v", err)
 	}
 
-	var pullZone PullZoneDetails
-	if err := strictUnmarshal(body, &pullZone); err != nil {
-		return nil, fmt.Errorf("error parsing JSON response: %v", err)
-	}
-
-	return &pullZone, nil
+	return body, nil
 }
 
 func getStorageZoneByPullZone(ctx context.Context, apiKey string, pullZoneID int64) (*StorageZone, error) {
@@ -148,7 +228,7 @@ func getStorageZoneByPullZone(ctx context.Context, apiKey string, pullZoneID int
 	}
 
 	// Get all storage zones
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.bunny.net/storagezone", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiBaseURL+"/storagezone", nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
@@ -163,7 +243,7 @@ func getStorageZoneByPullZone(ctx context.Context, apiKey string, pullZoneID int
 	if resp == nil {
 		return nil, fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -191,6 +271,18 @@ func getStorageZoneByPullZone(ctx context.Context, apiKey string, pullZoneID int
 	return nil, fmt.Errorf("no storage zone found for pull zone '%s'", pullZoneDetails.Name)
 }
 
+// maxDrainBytes bounds how much of an unread response body is discarded to keep the connection reusable
+const maxDrainBytes = 1 << 20
+
+// drainAndClose reads the rest of a response body and closes it so keep-alive connections can be reused
+func drainAndClose(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	_ = body.Close()
+}
+
 // strictUnmarshal unmarshals JSON and fails if our struct has fields that don't exist in the API response
 func strictUnmarshal(data []byte, v interface{}) error {
 	// First, unmarshal into a map to get API fields
@@ -234,7 +326,6 @@ func getJSONFieldNames(t reflect.Type) []string {
 	return fields
 }
 
-
 // formatBoolStatus formats a boolean as a human-readable status
 func formatBoolStatus(enabled bool) string {
 	if enabled {
@@ -269,6 +360,8 @@ func testSSLConnectivity(ctx context.Context, hostname string) bool {
 			TLSHandshakeTimeout: 5 * time.Second,
 		},
 	}
+	// The transport is private to this call, so release its connections when done
+	defer client.CloseIdleConnections()
 
 	url := fmt.Sprintf("https://%s/", hostname)
 	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
@@ -281,7 +374,7 @@ func testSSLConnectivity(ctx context.Context, hostname string) bool {
 		return false
 	}
 	if resp != nil {
-		_ = resp.Body.Close()
+		drainAndClose(resp.Body)
 	}
 
 	// Any HTTP response code means HTTPS is working (SSL handshake succeeded)
@@ -311,7 +404,7 @@ func testForceSSLRedirect(ctx context.Context, hostname string) bool {
 	if resp == nil {
 		return false
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	// Check if we get a redirect status code and if Location header points to HTTPS
 	if resp.StatusCode == 301 || resp.StatusCode == 302 {
@@ -325,14 +418,18 @@ func testForceSSLRedirect(ctx context.Context, hostname string) bool {
 // checkSSLConfiguration validates SSL configuration for hostnames
 func checkSSLConfiguration(ctx context.Context, hostnames []Hostname) CheckResult {
 	var result CheckResult
+	if len(hostnames) == 0 {
+		result.Coverage = []CheckCoverage{{Check: "ssl", Status: "skipped", Reason: "no hostnames"}}
+		return result
+	}
 
 	for _, hostname := range hostnames {
 		// Test HTTPS connectivity for all hostnames
 		httpsWorking := testSSLConnectivity(ctx, hostname.Value)
 		if !httpsWorking {
 			result.Issues = append(result.Issues, CheckIssue{
-				Type:     "ssl_https_broken",
-				Severity: "error",
+				Type:     IssueSSLHTTPSBroken,
+				Severity: SeverityError,
 				Message:  fmt.Sprintf("ERROR %s - HTTPS not working", hostname.Value),
 				Details:  map[string]interface{}{"hostname": hostname.Value},
 			})
@@ -343,20 +440,83 @@ func checkSSLConfiguration(ctx context.Context, hostnames []Hostname) CheckResul
 		forceSSLWorking := testForceSSLRedirect(ctx, hostname.Value)
 		if !forceSSLWorking {
 			result.Issues = append(result.Issues, CheckIssue{
-				Type:     "ssl_force_ssl_disabled",
-				Severity: "warning",
+				Type:     IssueSSLForceSSLOff,
+				Severity: SeverityWarning,
 				Message:  fmt.Sprintf("WARN %s - Force SSL redirect not configured", hostname.Value),
 				Details:  map[string]interface{}{"hostname": hostname.Value},
 			})
 		} else {
 			result.Successful = append(result.Successful, CheckIssue{
-				Type:     "ssl_ok",
-				Severity: "info",
+				Type:     IssueSSLOK,
+				Severity: SeverityInfo,
 				Message:  fmt.Sprintf("OK %s", hostname.Value),
 				Details:  map[string]interface{}{"hostname": hostname.Value},
 			})
 		}
 	}
 
+	result.Coverage = []CheckCoverage{hostnameCoverage(ctx, "ssl", len(hostnames))}
 	return result
 }
+
+// sendAPIRequest sends a request without a response body of interest to the Bunny API, payload may be nil
+func sendAPIRequest(ctx context.Context, apiKey, method, url string, payload interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON: %v", err)
+		}
+		body = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("AccessKey", apiKey)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %v", err)
+	}
+	if resp == nil {
+		return fmt.Errorf("received nil response")
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API request failed with status %s: %s", resp.Status, string(respBody))
+	}
+	return nil
+}
+
+// addPullZoneHostname attaches a custom hostname to a pull zone
+func addPullZoneHostname(ctx context.Context, apiKey, zoneID, hostname string) error {
+	url := fmt.Sprintf("%s/pullzone/%s/addHostname", apiBaseURL, zoneID)
+	return sendAPIRequest(ctx, apiKey, "POST", url, map[string]string{"Hostname": hostname})
+}
+
+// loadFreeCertificate requests a free Let's Encrypt certificate for a hostname attached to a pull zone
+func loadFreeCertificate(ctx context.Context, apiKey, hostname string) error {
+	endpoint := fmt.Sprintf("%s/pullzone/loadFreeCertificate?hostname=%s", apiBaseURL, url.QueryEscape(hostname))
+	return sendAPIRequest(ctx, apiKey, "GET", endpoint, nil)
+}
+
+// purgeURL removes one URL from the CDN cache, the URL may end in '*' to purge everything below it
+func purgeURL(ctx context.Context, apiKey, targetURL string) error {
+	endpoint := fmt.Sprintf("%s/purge?url=%s&async=false", apiBaseURL, url.QueryEscape(targetURL))
+	return sendAPIRequest(ctx, apiKey, "POST", endpoint, nil)
+}
+
+// purgePullZoneCache removes every file of a pull zone from the CDN cache
+func purgePullZoneCache(ctx context.Context, apiKey, zoneID string) error {
+	endpoint := fmt.Sprintf("%s/pullzone/%s/purgeCache", apiBaseURL, zoneID)
+	return sendAPIRequest(ctx, apiKey, "POST", endpoint, nil)
+}
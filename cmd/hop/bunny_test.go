@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -178,12 +183,12 @@ func TestStrictUnmarshal(t *testing.T) {
 	}{
 		{
 			name:        "valid JSON matching struct",
-			jsonData:    `{"Id": 123, "Name": "test", "EdgeRules": [], "Hostnames": []}`,
+			jsonData:    `{"Id": 123, "Name": "test", "OriginUrl": "https://origin.example.com", "EdgeRules": [], "Hostnames": []}`,
 			expectError: false,
 		},
 		{
 			name:        "JSON with extra field - should be allowed",
-			jsonData:    `{"Id": 123, "Name": "test", "EdgeRules": [], "Hostnames": [], "ExtraField": "value"}`,
+			jsonData:    `{"Id": 123, "Name": "test", "OriginUrl": "https://origin.example.com", "EdgeRules": [], "Hostnames": [], "ExtraField": "value"}`,
 			expectError: false, // Extra API fields are now OK
 		},
 		{
@@ -213,3 +218,134 @@ func TestStrictUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+// countingListener counts accepted connections so tests can detect connections that are not reused
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int64
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+func newCountingServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *countingListener) {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(handler)
+	listener := &countingListener{Listener: server.Listener}
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return server, listener
+}
+
+func TestResponseBodiesAreDrainedForConnectionReuse(t *testing.T) {
+	body := strings.Repeat("x", 512<<10)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		call    func(ctx context.Context, server *httptest.Server)
+	}{
+		{
+			name: "health check with unread body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(body))
+			},
+			call: func(ctx context.Context, server *httptest.Server) {
+				_, _, _, _ = performHealthCheck(ctx, server.URL+"/page")
+			},
+		},
+		{
+			name: "force SSL redirect probe",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Location", "https://example.com/")
+				w.WriteHeader(http.StatusMovedPermanently)
+				_, _ = w.Write([]byte(body))
+			},
+			call: func(ctx context.Context, server *httptest.Server) {
+				testForceSSLRedirect(ctx, strings.TrimPrefix(server.URL, "http://"))
+			},
+		},
+		{
+			name: "API error branch",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, body, http.StatusInternalServerError)
+			},
+			call: func(ctx context.Context, server *httptest.Server) {
+				apiBaseURL = server.URL
+				_, _ = getPullZoneDetails(ctx, "key", "1")
+			},
+		},
+		{
+			name: "storage listing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`[{"ObjectName":"a.txt","IsDirectory":false,"Length":1}]`))
+			},
+			call: func(ctx context.Context, server *httptest.Server) {
+				storageBaseURL = server.URL
+				_, _ = listRemoteFiles(ctx, &StorageZone{Name: "zone"}, "")
+			},
+		},
+	}
+
+	oldAPI, oldStorage := apiBaseURL, storageBaseURL
+	defer func() { apiBaseURL, storageBaseURL = oldAPI, oldStorage }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, listener := newCountingServer(t, tt.handler)
+			ctx := context.Background()
+
+			for range 100 {
+				tt.call(ctx, server)
+			}
+
+			if accepted := listener.accepted.Load(); accepted > 2 {
+				t.Errorf("100 sequential calls opened %d connections, want connections to be reused", accepted)
+			}
+		})
+	}
+}
+
+func TestPurgeURL(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+
+	target := "https://site.b-cdn.net/docs/a b.html?v=1"
+	if err := purgeURL(context.Background(), fakeAPIKey, target); err != nil {
+		t.Fatalf("purgeURL() error = %v", err)
+	}
+	if len(fb.purged) != 1 || fb.purged[0] != target {
+		t.Errorf("purged %v, want [%s]", fb.purged, target)
+	}
+	if log := fb.requestLog(); log[len(log)-1] != "POST /api/purge" {
+		t.Errorf("last request %q, want POST /api/purge", log[len(log)-1])
+	}
+
+	fb.purgeStatus = http.StatusBadRequest
+	if err := purgeURL(context.Background(), fakeAPIKey, target); err == nil {
+		t.Error("purgeURL() succeeded on a failed purge")
+	}
+}
+
+func TestPurgePullZoneCache(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+
+	if err := purgePullZoneCache(context.Background(), fakeAPIKey, "1"); err != nil {
+		t.Fatalf("purgePullZoneCache() error = %v", err)
+	}
+	if fb.zonePurges != 1 {
+		t.Errorf("%d zone purges, want 1", fb.zonePurges)
+	}
+	if err := purgePullZoneCache(context.Background(), fakeAPIKey, "2"); err == nil {
+		t.Error("purgePullZoneCache() succeeded for an unknown zone")
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Side effect free functions
+
+// checkCanonicalHost flags redirects to one of the zone's hostnames other than the canonical host, with the destination moved to it as suggestion.
+// Relative destinations and external hosts are left alone; without a canonical host nothing is reported.
+// The zone's b-cdn.net system hostname is reported by checkSystemHostnameDestinations, which suggests the canonical host too.
+func checkCanonicalHost(rules []EdgeRuleResponse, zoneHostnames []Hostname, canonical string) []CheckIssue {
+	if canonical == "" {
+		return nil
+	}
+	canonical = canonicalHost(canonical)
+
+	var issues []CheckIssue
+	for i, rule := range rules {
+		if rule.ActionType != 1 || rule.ActionParameter1 == "" {
+			continue
+		}
+		destURL, err := url.Parse(rule.ActionParameter1)
+		if err != nil || destURL.Host == "" || !isZoneHost(destURL.Host, zoneHostnames) || isSystemHostname(destURL.Host) {
+			continue
+		}
+		host := canonicalHost(destURL.Host)
+		if host == canonical {
+			continue
+		}
+		suggested := customHostnameURL(destURL, canonical)
+		issues = append(issues, CheckIssue{
+			Type:     IssueConfiguration,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Redirect lands on %s instead of the canonical host %s - use %s", host, canonical, suggested),
+			Rule:     &rules[i],
+			Details:  map[string]interface{}{"host": host, "suggested_destination": suggested},
+		})
+	}
+	return issues
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestCheckCanonicalHost(t *testing.T) {
+	hostnames := []Hostname{{Value: "site.b-cdn.net"}, {Value: "example.com"}, {Value: "www.example.com"}}
+
+	tests := []struct {
+		name          string
+		destination   string
+		canonical     string
+		wantSuggested string // "" for no issue
+	}{
+		{name: "apex", destination: "https://Example.com/docs?page=2", canonical: "www.example.com", wantSuggested: "https://www.example.com/docs?page=2"},
+		{name: "apex over http", destination: "http://example.com:80/docs", canonical: "WWW.example.com", wantSuggested: "https://www.example.com/docs"},
+		{name: "canonical host", destination: "https://www.example.com/docs", canonical: "www.example.com"},
+		{name: "relative", destination: "/docs", canonical: "www.example.com"},
+		{name: "external", destination: "https://partner.example.org/docs", canonical: "www.example.com"},
+		{name: "system hostname", destination: "https://site.b-cdn.net/docs", canonical: "www.example.com"},
+		{name: "no canonical host", destination: "https://example.com/docs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []EdgeRuleResponse{redirectRule("r1", "/old", tt.destination)}
+			issues := checkCanonicalHost(rules, hostnames, tt.canonical)
+			if tt.wantSuggested == "" {
+				if len(issues) != 0 {
+					t.Errorf("got %+v, want no issues", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Severity != SeverityWarning {
+				t.Fatalf("got %+v, want one warning", issues)
+			}
+			if got := issues[0].Details["suggested_destination"]; got != tt.wantSuggested {
+				t.Errorf("suggested_destination = %v, want %q", got, tt.wantSuggested)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// checkCategories are the issue types of rules check, selectable with --only and --skip
+var checkCategories = []string{"basic", "configuration", "ordering", "security", "self_redirect", "redirect_loop", "redirect_chain", "url_health", "source_verification", "origin"}
+
+// coverageCategories maps each check of the coverage block to the issue categories it reports
+var coverageCategories = map[string][]string{
+	"basic":               {"basic"},
+	"configuration":       {"configuration"},
+	"trailing_slash":      {"configuration"},
+	"ordering":            {"ordering"},
+	"security":            {"security"},
+	"loops":               {"self_redirect", "redirect_loop", "redirect_chain"},
+	"origin":              {"origin"},
+	"system_hostname":     {"configuration"},
+	"url_health":          {"url_health"},
+	"source_verification": {"source_verification"},
+	"origin_health":       {"origin"},
+}
+
+// notSelectedReason is the coverage reason of checks left out by --only or --skip
+const notSelectedReason = "not selected (--only/--skip)"
+
+// CheckSelection is the set of issue categories a rules check reports, nil selects every category
+type CheckSelection map[string]bool
+
+// Side effect free functions
+
+// parseCheckSelection builds the selection of --only and --skip, unknown categories are rejected.
+// Without --only every category starts selected, --skip then removes categories.
+func parseCheckSelection(only, skip []string) (CheckSelection, error) {
+	if len(only) == 0 && len(skip) == 0 {
+		return nil, nil
+	}
+	for _, category := range append(append([]string{}, only...), skip...) {
+		if !slices.Contains(checkCategories, category) {
+			return nil, fmt.Errorf("unknown check category '%s', valid categories: %s", category, strings.Join(checkCategories, ", "))
+		}
+	}
+
+	selection := CheckSelection{}
+	if len(only) == 0 {
+		only = checkCategories
+	}
+	for _, category := range only {
+		selection[category] = true
+	}
+	for _, category := range skip {
+		delete(selection, category)
+	}
+	if len(selection) == 0 {
+		return nil, fmt.Errorf("--only and --skip leave no check category selected")
+	}
+	return selection, nil
+}
+
+// includes reports whether issues of a category are reported
+func (s CheckSelection) includes(category string) bool {
+	return s == nil || s[category]
+}
+
+// runsCheck reports whether a check of the coverage block reports any selected category
+func (s CheckSelection) runsCheck(check string) bool {
+	for _, category := range coverageCategories[check] {
+		if s.includes(category) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the issues of selected categories
+func (s CheckSelection) filter(issues []CheckIssue) []CheckIssue {
+	if s == nil {
+		return issues
+	}
+	var selected []CheckIssue
+	for _, issue := range issues {
+		if s.includes(issue.Type) {
+			selected = append(selected, issue)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCheckSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		only    []string
+		skip    []string
+		want    CheckSelection
+		wantErr string
+	}{
+		{name: "nothing selects all", want: nil},
+		{name: "only", only: []string{"basic", "configuration"}, want: CheckSelection{"basic": true, "configuration": true}},
+		{name: "skip", skip: []string{"url_health", "security"}, want: CheckSelection{"basic": true, "configuration": true, "ordering": true, "self_redirect": true, "redirect_loop": true, "redirect_chain": true, "source_verification": true, "origin": true}},
+		{name: "only and skip", only: []string{"redirect_loop", "redirect_chain"}, skip: []string{"redirect_chain"}, want: CheckSelection{"redirect_loop": true}},
+		{name: "unknown only", only: []string{"loops"}, wantErr: "unknown check category 'loops'"},
+		{name: "unknown skip", skip: []string{"health"}, wantErr: "unknown check category 'health'"},
+		{name: "nothing left", only: []string{"basic"}, skip: []string{"basic"}, wantErr: "no check category"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCheckSelection(tt.only, tt.skip)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseCheckSelection() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCheckSelection() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseCheckSelection() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSelectionFilter(t *testing.T) {
+	issues := []CheckIssue{{Type: "basic"}, {Type: "redirect_loop"}, {Type: "url_health"}}
+
+	if got := CheckSelection(nil).filter(issues); len(got) != 3 {
+		t.Errorf("nil selection kept %d issues, want 3", len(got))
+	}
+	got := CheckSelection{"redirect_loop": true, "url_health": true}.filter(issues)
+	if len(got) != 2 || got[0].Type != "redirect_loop" || got[1].Type != "url_health" {
+		t.Errorf("filter() = %+v, want the loop and health issues", got)
+	}
+}
+
+func TestCheckSelectionRunsCheck(t *testing.T) {
+	selection := CheckSelection{"redirect_chain": true, "configuration": true}
+	for check, want := range map[string]bool{
+		"loops":           true,
+		"trailing_slash":  true,
+		"system_hostname": true,
+		"basic":           false,
+		"url_health":      false,
+		"origin_health":   false,
+	} {
+		if got := selection.runsCheck(check); got != want {
+			t.Errorf("runsCheck(%q) = %v, want %v", check, got, want)
+		}
+	}
+	if !CheckSelection(nil).runsCheck("url_health") {
+		t.Error("nil selection does not run url_health")
+	}
+}
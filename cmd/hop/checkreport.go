@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Issue severities, part of the JSON output contract of the checks
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Issue types, part of the JSON output contract of the checks
+const (
+	IssueBasic              = "basic"
+	IssueConfiguration      = "configuration"
+	IssueOrdering           = "ordering"
+	IssueSecurity           = "security"
+	IssueSelfRedirect       = "self_redirect"
+	IssueRedirectLoop       = "redirect_loop"
+	IssueRedirectChain      = "redirect_chain"
+	IssueURLHealth          = "url_health"
+	IssueSourceVerification = "source_verification"
+	IssueOrigin             = "origin"
+	IssueSitemap            = "sitemap"
+	IssueDNSSkip            = "dns_skip"
+	IssueDNSMissingRecord   = "dns_missing_record"
+	IssueDNSOK              = "dns_ok"
+	IssueSSLHTTPSBroken     = "ssl_https_broken"
+	IssueSSLForceSSLOff     = "ssl_force_ssl_disabled"
+	IssueSSLOK              = "ssl_ok"
+)
+
+// CheckReport is the JSON output of rules check and check with --format json
+type CheckReport struct {
+	Zone       string                `json:"zone"`
+	Passed     bool                  `json:"passed"` // no issue reached --fail-on
+	Issues     []CheckReportIssue    `json:"issues"`
+	Successful []CheckReportIssue    `json:"successful"`
+	Suppressed []CheckReportIssue    `json:"suppressed,omitempty"` // issues accepted by --baseline
+	Coverage   []CheckReportCoverage `json:"coverage"`
+	Summary    *CheckSummary         `json:"summary,omitempty"` // only set by check
+}
+
+// CheckReportIssue is one issue or successful check of a CheckReport, rule fields are empty for issues without a rule
+type CheckReportIssue struct {
+	Type        string                 `json:"type"`
+	Severity    string                 `json:"severity"`
+	Message     string                 `json:"message"`
+	Guid        string                 `json:"guid,omitempty"`
+	Source      string                 `json:"source,omitempty"`
+	Destination string                 `json:"destination,omitempty"`
+	Details     map[string]interface{} `json:"details,omitempty"`
+}
+
+// CheckReportCoverage is one coverage line of a CheckReport
+type CheckReportCoverage struct {
+	Check  string `json:"check"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// writeJSONReport writes a report as one indented JSON document
+func writeJSONReport(w io.Writer, report interface{}) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %v", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// issuesCSVHeader is the header row of the --report-csv export
+var issuesCSVHeader = []string{"severity", "type", "source", "destination", "status_code", "guid", "message"}
+
+// writeIssuesCSVFile writes the issues of a check to a CSV file, successful checks only with includeOK; redactor redacts it when --redact is set
+func writeIssuesCSVFile(path string, result CheckResult, includeOK bool, redactor *Redactor) error {
+	issues := result.Issues
+	if includeOK {
+		issues = append(append([]CheckIssue{}, issues...), result.Successful...)
+	}
+	var buf bytes.Buffer
+	if err := writeIssuesCSV(&buf, issues); err != nil {
+		return err
+	}
+	return os.WriteFile(path, redactor.redactFile(buf.Bytes()), 0600)
+}
+
+// writeIssuesCSV writes one row per issue, rule columns are empty for issues without a rule
+func writeIssuesCSV(w io.Writer, issues []CheckIssue) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(issuesCSVHeader); err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		var source, destination, statusCode, guid string
+		if issue.Rule != nil {
+			source = extractSourceURL(*issue.Rule)
+			destination = issue.Rule.ActionParameter1
+			statusCode = issue.Rule.ActionParameter2
+			guid = issue.Rule.Guid
+		}
+		if err := writer.Write([]string{issue.Severity, issue.Type, source, destination, statusCode, guid, issue.Message}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Side effect free functions
+
+// buildCheckReport converts check results to their JSON form, the lists are never nil
+func buildCheckReport(zone string, result CheckResult, failOn string) CheckReport {
+	report := CheckReport{
+		Zone:       zone,
+		Passed:     !hasIssueAtLeast(result.Issues, failOn),
+		Issues:     checkReportIssues(result.Issues),
+		Successful: checkReportIssues(result.Successful),
+		Coverage:   []CheckReportCoverage{},
+	}
+	if len(result.Suppressed) > 0 {
+		report.Suppressed = checkReportIssues(result.Suppressed)
+	}
+	for _, c := range result.Coverage {
+		report.Coverage = append(report.Coverage, CheckReportCoverage{Check: c.Check, Status: c.Status, Reason: c.Reason})
+	}
+	return report
+}
+
+// checkReportIssues converts issues to their JSON form
+func checkReportIssues(issues []CheckIssue) []CheckReportIssue {
+	entries := []CheckReportIssue{}
+	for _, issue := range issues {
+		entry := CheckReportIssue{
+			Type:     issue.Type,
+			Severity: issue.Severity,
+			Message:  issue.Message,
+			Details:  issue.Details,
+		}
+		if issue.Rule != nil {
+			entry.Guid = issue.Rule.Guid
+			entry.Source = extractSourceURL(*issue.Rule)
+			entry.Destination = issue.Rule.ActionParameter1
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// mergeCheckResults combines the results of several checks into one, in order
+func mergeCheckResults(results ...CheckResult) CheckResult {
+	var merged CheckResult
+	for _, result := range results {
+		merged.Issues = append(merged.Issues, result.Issues...)
+		merged.Successful = append(merged.Successful, result.Successful...)
+		merged.Suppressed = append(merged.Suppressed, result.Suppressed...)
+		merged.Coverage = append(merged.Coverage, result.Coverage...)
+		merged.Slowest = append(merged.Slowest, result.Slowest...)
+	}
+	return merged
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestCheckReportSchema pins the JSON output of the checks, dashboards parse it; change it on purpose only
+func TestCheckReportSchema(t *testing.T) {
+	rule := redirectRule("r1", "/old", "https://example.com/new")
+	result := CheckResult{
+		Issues: []CheckIssue{{
+			Type:     IssueURLHealth,
+			Severity: SeverityCritical,
+			Message:  "Broken destination URL (HTTP 503)",
+			Rule:     &rule,
+			Details:  map[string]interface{}{"attempts": 3},
+		}},
+		Successful: []CheckIssue{{Type: IssueDNSOK, Severity: SeverityInfo, Message: "DNS ok"}},
+		Coverage:   []CheckCoverage{{Check: "url_health", Status: "ran", Reason: "1 destinations"}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, buildCheckReport("shop", result, SeverityError)); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "zone": "shop",
+  "passed": false,
+  "issues": [
+    {
+      "type": "url_health",
+      "severity": "critical",
+      "message": "Broken destination URL (HTTP 503)",
+      "guid": "r1",
+      "source": "/old",
+      "destination": "https://example.com/new",
+      "details": {
+        "attempts": 3
+      }
+    }
+  ],
+  "successful": [
+    {
+      "type": "dns_ok",
+      "severity": "info",
+      "message": "DNS ok"
+    }
+  ],
+  "coverage": [
+    {
+      "check": "url_health",
+      "status": "ran",
+      "reason": "1 destinations"
+    }
+  ]
+}
+`
+	if buf.String() != want {
+		t.Errorf("JSON report changed:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestCheckReportEmptyListsAreArrays(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, buildCheckReport("shop", CheckResult{}, SeverityError)); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"zone\": \"shop\",\n  \"passed\": true,\n  \"issues\": [],\n  \"successful\": [],\n  \"coverage\": []\n}\n"
+	if buf.String() != want {
+		t.Errorf("empty report = %s, want %s", buf.String(), want)
+	}
+}
+
+func TestCheckReportWriterSilencesTextOutput(t *testing.T) {
+	var buf bytes.Buffer
+	previous := output
+	output = &buf
+	t.Cleanup(func() { output = previous })
+
+	if w := checkReportWriter("text"); w != &buf || output != &buf {
+		t.Error("text format changed the output")
+	}
+	if w := checkReportWriter("json"); w != &buf || output != io.Discard {
+		t.Error("json format kept the human-readable output")
+	}
+}
+
+func TestWriteIssuesCSVRoundTrip(t *testing.T) {
+	rule := redirectRule("r1", "/old,page", "https://example.com/new?a=1,b=2")
+	issues := []CheckIssue{
+		{Type: IssueSecurity, Severity: SeverityWarning, Message: `Suspicious destination URL: contains "quotes", commas`, Rule: &rule},
+		{Type: IssueURLHealth, Severity: SeverityInfo, Message: "Skipped 2 health checks:\nfail fast"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeIssuesCSV(&buf, issues); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV does not parse: %v", err)
+	}
+	want := [][]string{
+		issuesCSVHeader,
+		{"warning", "security", "/old,page", "https://example.com/new?a=1,b=2", "302", "r1", `Suspicious destination URL: contains "quotes", commas`},
+		{"info", "url_health", "", "", "", "", "Skipped 2 health checks:\nfail fast"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
+
+func TestWriteIssuesCSVFileIncludeOK(t *testing.T) {
+	result := CheckResult{
+		Issues:     []CheckIssue{{Type: IssueBasic, Severity: SeverityError, Message: "broken"}},
+		Successful: []CheckIssue{{Type: IssueOrigin, Severity: SeverityInfo, Message: "reachable"}},
+	}
+	for _, tt := range []struct {
+		includeOK bool
+		wantRows  int
+	}{{includeOK: false, wantRows: 2}, {includeOK: true, wantRows: 3}} {
+		path := filepath.Join(t.TempDir(), "issues.csv")
+		if err := writeIssuesCSVFile(path, result, tt.includeOK, nil); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil || len(records) != tt.wantRows {
+			t.Errorf("includeOK %v: %d rows (error %v), want %d", tt.includeOK, len(records), err, tt.wantRows)
+		}
+	}
+}
+
+func TestWriteIssuesCSVFileRedacted(t *testing.T) {
+	rule := redirectRule("g1", "https://www.acme.com/old", "https://blog.acme.com/new")
+	result := CheckResult{Issues: []CheckIssue{
+		{Type: IssueURLHealth, Severity: SeverityError, Message: "Destination https://blog.acme.com/new returned 404", Rule: &rule},
+		{Type: IssueConfiguration, Severity: SeverityInfo, Message: "Redirect points at acme-site.b-cdn.net, the system hostname of pull zone 'acme-site'"},
+	}}
+
+	path := filepath.Join(t.TempDir(), "issues.csv")
+	if err := writeIssuesCSVFile(path, result, false, newTestRedactor()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.ToLower(string(data))
+	for _, original := range []string{"acme-site", "www.acme.com", "blog.acme.com"} {
+		if strings.Contains(content, original) {
+			t.Errorf("redacted CSV report still contains %q:\n%s", original, data)
+		}
+	}
+	if records, err := csv.NewReader(bytes.NewReader(data)).ReadAll(); err != nil || len(records) != 3 {
+		t.Errorf("redacted CSV has %d rows (error %v), want 3", len(records), err)
+	}
+}
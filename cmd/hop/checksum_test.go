@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestUploadSendsChecksumHeader(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	localPath := filepath.Join(t.TempDir(), "index.html")
+	writeTestFile(t, localPath, "hello")
+	checksum, err := calculateFileChecksum(localPath)
+	if err != nil {
+		t.Fatalf("calculateFileChecksum() error = %v", err)
+	}
+	task := FileUploadTask{LocalFile: LocalFileInfo{Path: localPath, RelPath: "index.html", Checksum: checksum}, RemotePath: "index.html"}
+	ctx := context.Background()
+
+	if err := uploadVerified(ctx, storageZone, task, PushOptions{}); err != nil {
+		t.Fatalf("uploadVerified() error = %v", err)
+	}
+	got := fb.uploadHeader("site/index.html", "Checksum")
+	if !regexp.MustCompile(`^[0-9A-F]{64}$`).MatchString(got) || got != checksum {
+		t.Errorf("Checksum header = %q, want the uppercase SHA256 %q", got, checksum)
+	}
+
+	if err := uploadVerified(ctx, storageZone, task, PushOptions{NoVerifyChecksum: true}); err != nil {
+		t.Fatalf("uploadVerified() error = %v", err)
+	}
+	if got := fb.uploadHeader("site/index.html", "Checksum"); got != "" {
+		t.Errorf("Checksum header = %q with --no-verify-checksum, want none", got)
+	}
+}
+
+func TestUploadRetriesChecksumMismatch(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	localPath := filepath.Join(t.TempDir(), "app.js")
+	writeTestFile(t, localPath, "console.log(1)")
+	checksum, err := calculateFileChecksum(localPath)
+	if err != nil {
+		t.Fatalf("calculateFileChecksum() error = %v", err)
+	}
+	task := FileUploadTask{LocalFile: LocalFileInfo{Path: localPath, RelPath: "app.js", Checksum: checksum}, RemotePath: "app.js"}
+	ctx := context.Background()
+
+	// One truncated transfer is rejected and repeated
+	fb.truncations = 1
+	if err := uploadVerified(ctx, storageZone, task, PushOptions{}); err != nil {
+		t.Fatalf("uploadVerified() error = %v, want the retry to succeed", err)
+	}
+
+	// A transfer truncated on every attempt fails with the mismatch
+	fb.truncations = checksumRetries + 1
+	err = uploadVerified(ctx, storageZone, task, PushOptions{})
+	if !errors.Is(err, errChecksumMismatch) {
+		t.Errorf("uploadVerified() error = %v, want a checksum mismatch", err)
+	}
+	if fb.truncations != 0 {
+		t.Errorf("%d truncated attempts left, want every attempt made", fb.truncations)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// checksumCacheName is the checksum cache cdn push keeps in the --from directory unless --cache-file moves it
+const checksumCacheName = ".hop-cache.json"
+
+// checksumCacheFormat marks a file as a hop checksum cache, a file without it is treated as corrupt
+const checksumCacheFormat = "hop-checksum-cache/1"
+
+// ChecksumCache remembers the SHA256 of local files by path, size and modification time, so unchanged files are not hashed again by the next push.
+// It is safe for concurrent use by the skip checker and the uploaders; a nil cache never hits and stores nothing.
+type ChecksumCache struct {
+	path string
+
+	mu     sync.Mutex
+	cached map[string]checksumCacheEntry // entries read from the file
+	seen   map[string]checksumCacheEntry // entries of the files of this push, the ones save writes
+	hits   int
+	hashed int
+
+	hiddenNotice bool // the notice about skipped hidden files was shown for this directory
+}
+
+// checksumCacheEntry is the checksum of one local file, valid while its size and modification time stay the same
+type checksumCacheEntry struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+	SHA256  string    `json:"sha256"`
+}
+
+// checksumCacheFile is the JSON document of the cache, files are keyed by their path relative to --from
+type checksumCacheFile struct {
+	Format            string                        `json:"format"`
+	HiddenNoticeShown bool                          `json:"hidden_notice_shown,omitempty"`
+	Files             map[string]checksumCacheEntry `json:"files"`
+}
+
+// loadChecksumCache reads the cache at path, a missing file starts an empty cache.
+// A corrupt file starts an empty cache too, the error says why it was ignored.
+func loadChecksumCache(path string) (*ChecksumCache, error) {
+	cache := &ChecksumCache{path: path, cached: make(map[string]checksumCacheEntry), seen: make(map[string]checksumCacheEntry)}
+	// #nosec G304 - the cache path is supplied by the user on purpose
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cache, nil
+	}
+	if err != nil {
+		return cache, fmt.Errorf("ignoring checksum cache '%s': %v", path, err)
+	}
+	var file checksumCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return cache, fmt.Errorf("ignoring corrupt checksum cache '%s', every file is hashed again: %v", path, err)
+	}
+	if file.Format != checksumCacheFormat {
+		return cache, fmt.Errorf("ignoring checksum cache '%s', it is not a %s file", path, checksumCacheFormat)
+	}
+	if file.Files != nil {
+		cache.cached = file.Files
+	}
+	cache.hiddenNotice = file.HiddenNoticeShown
+	return cache, nil
+}
+
+// lookup returns the file with its cached checksum when its size and modification time are unchanged, otherwise the file as is
+func (c *ChecksumCache) lookup(file LocalFileInfo) LocalFileInfo {
+	if c == nil {
+		return file
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.cached[file.RelPath]
+	if !ok || !entry.matches(file) {
+		return file
+	}
+	c.hits++
+	c.seen[file.RelPath] = entry
+	file.Checksum = entry.SHA256
+	file.hashed = true
+	return file
+}
+
+// store records the checksum of a file that was just hashed
+func (c *ChecksumCache) store(file LocalFileInfo) {
+	if c == nil || file.Checksum == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hashed++
+	c.seen[file.RelPath] = checksumCacheEntry{Size: file.Size, ModTime: file.ModTime, SHA256: file.Checksum}
+}
+
+// hiddenNoticeShown reports whether an earlier push from the directory already showed the notice about skipped hidden files, never for a nil cache
+func (c *ChecksumCache) hiddenNoticeShown() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hiddenNotice
+}
+
+// markHiddenNoticeShown records that the notice about skipped hidden files was shown
+func (c *ChecksumCache) markHiddenNoticeShown() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hiddenNotice = true
+}
+
+// summary describes how many checksums came from the cache, "" when no checksum was needed
+func (c *ChecksumCache) summary() string {
+	if c == nil {
+		return ""
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return formatCacheHitRatio(c.hits, c.hits+c.hashed)
+}
+
+// save writes the entries of the files of this push to a temporary file and renames it over the cache, so an interrupted run never leaves a partial file.
+// Entries of files that no longer exist locally are dropped.
+func (c *ChecksumCache) save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	data, err := json.MarshalIndent(checksumCacheFile{Format: checksumCacheFormat, HiddenNoticeShown: c.hiddenNotice, Files: c.seen}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("error encoding checksum cache: %v", err)
+	}
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating checksum cache: %v", err)
+	}
+	// the temporary file is gone after a successful rename, removing it then fails harmlessly
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing checksum cache: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing checksum cache: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("error replacing checksum cache: %v", err)
+	}
+	return nil
+}
+
+// checksumCachePath returns where the checksum cache of a push from localDir lives, cacheFile or .hop-cache.json in localDir.
+// A cacheFile inside localDir under another name would be uploaded with the site, so it is refused.
+func checksumCachePath(localDir, cacheFile string) (string, error) {
+	if cacheFile == "" {
+		return filepath.Join(localDir, checksumCacheName), nil
+	}
+	absDir, err := filepath.Abs(localDir)
+	if err != nil {
+		return "", err
+	}
+	absFile, err := filepath.Abs(cacheFile)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(absDir, absFile); err == nil && filepath.IsLocal(rel) && !isHopStateFile(rel) {
+		return "", fmt.Errorf("'%s' is inside the --from directory and would be uploaded, move it outside or name it %s", cacheFile, checksumCacheName)
+	}
+	return cacheFile, nil
+}
+
+// Side effect free functions
+
+// matches reports whether an entry still describes a local file: same size and modification time
+func (e checksumCacheEntry) matches(file LocalFileInfo) bool {
+	return e.SHA256 != "" && e.Size == file.Size && e.ModTime.Equal(file.ModTime)
+}
+
+// formatCacheHitRatio renders a line like "Checksum cache: 950 of 1000 checksums reused (95%)", "" when no checksum was needed
+func formatCacheHitRatio(hits, total int) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Checksum cache: %d of %d checksums reused (%d%%)", hits, total, hits*100/total)
+}
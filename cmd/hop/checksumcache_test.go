@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecksumCacheLookup(t *testing.T) {
+	modTime := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache, err := loadChecksumCache(filepath.Join(t.TempDir(), checksumCacheName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache.cached["a.html"] = checksumCacheEntry{Size: 4, ModTime: modTime, SHA256: "ABCD"}
+
+	tests := []struct {
+		name    string
+		file    LocalFileInfo
+		wantHit bool
+	}{
+		{"unchanged", LocalFileInfo{RelPath: "a.html", Size: 4, ModTime: modTime.In(time.Local)}, true},
+		{"size changed", LocalFileInfo{RelPath: "a.html", Size: 5, ModTime: modTime}, false},
+		{"modified", LocalFileInfo{RelPath: "a.html", Size: 4, ModTime: modTime.Add(time.Nanosecond)}, false},
+		{"unknown file", LocalFileInfo{RelPath: "b.html", Size: 4, ModTime: modTime}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cache.lookup(tt.file)
+			if hit := got.Checksum == "ABCD" && got.hashed; hit != tt.wantHit {
+				t.Errorf("lookup() = %+v, want hit %v", got, tt.wantHit)
+			}
+		})
+	}
+
+	var nilCache *ChecksumCache
+	if got := nilCache.lookup(LocalFileInfo{RelPath: "a.html"}); got.hashed {
+		t.Error("nil cache hit")
+	}
+}
+
+func TestLoadChecksumCache(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		writeTestFile(t, path, content)
+		return path
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantErr     string
+		wantEntries int
+	}{
+		{"missing", filepath.Join(dir, "missing.json"), "", 0},
+		{"valid", write("valid.json", `{"format": "hop-checksum-cache/1", "files": {"a.html": {"size": 4, "mtime": "2025-03-01T12:00:00Z", "sha256": "ABCD"}}}`), "", 1},
+		{"corrupt", write("corrupt.json", `{"format": "hop-checksum-cache/1", "files": {`), "ignoring corrupt checksum cache", 0},
+		{"other json", write("other.json", `{"files": {}}`), "not a hop-checksum-cache/1 file", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := loadChecksumCache(tt.path)
+			if cache == nil {
+				t.Fatal("loadChecksumCache() returned no cache")
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("loadChecksumCache() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("loadChecksumCache() error = %v, want %q", err, tt.wantErr)
+			}
+			if len(cache.cached) != tt.wantEntries {
+				t.Errorf("loaded %d entries, want %d", len(cache.cached), tt.wantEntries)
+			}
+		})
+	}
+}
+
+func TestChecksumCacheSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, checksumCacheName)
+	modTime := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache, _ := loadChecksumCache(path)
+	cache.cached["kept.html"] = checksumCacheEntry{Size: 4, ModTime: modTime, SHA256: "AAAA"}
+	cache.cached["removed.html"] = checksumCacheEntry{Size: 4, ModTime: modTime, SHA256: "BBBB"}
+	cache.lookup(LocalFileInfo{RelPath: "kept.html", Size: 4, ModTime: modTime})
+	cache.store(LocalFileInfo{RelPath: "new.html", Size: 3, ModTime: modTime, Checksum: "CCCC"})
+	cache.store(LocalFileInfo{RelPath: "unreadable.html", Size: 3, ModTime: modTime})
+	if err := cache.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded, err := loadChecksumCache(path)
+	if err != nil {
+		t.Fatalf("loadChecksumCache() error = %v", err)
+	}
+	var names []string
+	for name := range loaded.cached {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if want := []string{"kept.html", "new.html"}; !slices.Equal(names, want) {
+		t.Errorf("saved entries %v, want %v", names, want)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("cache directory holds %d files, want no temporary file left", len(entries))
+	}
+}
+
+func TestChecksumCachePath(t *testing.T) {
+	localDir := t.TempDir()
+	tests := []struct {
+		name      string
+		cacheFile string
+		want      string
+		wantErr   bool
+	}{
+		{"default", "", filepath.Join(localDir, checksumCacheName), false},
+		{"outside", filepath.Join(t.TempDir(), "cache.json"), "", false},
+		{"inside under the cache name", filepath.Join(localDir, "sub", checksumCacheName), "", false},
+		{"inside under another name", filepath.Join(localDir, "cache.json"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checksumCachePath(localDir, tt.cacheFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checksumCachePath() error = %v, want error %v", err, tt.wantErr)
+			}
+			want := tt.want
+			if want == "" && !tt.wantErr {
+				want = tt.cacheFile
+			}
+			if !tt.wantErr && got != want {
+				t.Errorf("checksumCachePath() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFormatCacheHitRatio(t *testing.T) {
+	tests := []struct {
+		hits, total int
+		want        string
+	}{
+		{0, 0, ""},
+		{950, 1000, "Checksum cache: 950 of 1000 checksums reused (95%)"},
+		{0, 3, "Checksum cache: 0 of 3 checksums reused (0%)"},
+	}
+	for _, tt := range tests {
+		if got := formatCacheHitRatio(tt.hits, tt.total); got != tt.want {
+			t.Errorf("formatCacheHitRatio(%d, %d) = %q, want %q", tt.hits, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestPushChecksumCache(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, "css", "site.css"), "body {}")
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	push := func() *ChecksumCache {
+		cache, err := loadChecksumCache(filepath.Join(localDir, checksumCacheName))
+		if err != nil {
+			t.Fatalf("loadChecksumCache() error = %v", err)
+		}
+		for _, result := range uploadDirectoryOptimized(context.Background(), storageZone, localDir, "", PushOptions{Cache: cache}).Files {
+			if !result.Success {
+				t.Fatalf("%s failed: %v", result.RelPath, result.Error)
+			}
+		}
+		if err := cache.save(); err != nil {
+			t.Fatalf("save() error = %v", err)
+		}
+		return cache
+	}
+
+	if first := push(); first.hits != 0 || first.hashed != 2 {
+		t.Errorf("first push: %d hits, %d hashed, want 0 and 2", first.hits, first.hashed)
+	}
+	if second := push(); second.hits != 2 || second.hashed != 0 {
+		t.Errorf("second push: %d hits, %d hashed, want 2 and 0", second.hits, second.hashed)
+	}
+
+	edited := filepath.Join(localDir, "index.html")
+	writeTestFile(t, edited, "home page")
+	if err := os.Chtimes(edited, time.Now().Add(time.Minute), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if third := push(); third.hits != 1 || third.hashed != 1 {
+		t.Errorf("push after an edit: %d hits, %d hashed, want 1 and 1", third.hits, third.hashed)
+	}
+	if got := string(fb.files["site/index.html"].content); got != "home page" {
+		t.Errorf("remote index.html = %q, want the edit", got)
+	}
+	if slices.Contains(fb.fileNames(), "site/"+checksumCacheName) {
+		t.Error("the checksum cache was uploaded")
+	}
+}
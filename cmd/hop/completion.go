@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// completionTimeout bounds how long a completion hook or desktop notification may delay the exit
+const completionTimeout = 5 * time.Second
+
+// Completion statuses passed to the --on-complete hook
+const (
+	completionSuccess = "success"
+	completionPartial = "partial"
+	completionFailed  = "failed"
+)
+
+// CompletionHook reports the end of a long-running push or check through --on-complete and --notify-desktop
+type CompletionHook struct {
+	Title         string // e.g. "hop cdn push"
+	Command       string // --on-complete template
+	NotifyDesktop bool
+	Status        string
+	Summary       string
+	Report        string // path of the report file the command wrote, "" if none was written
+}
+
+// completion is the hook of the running command, nil when no hook was requested
+var completion *CompletionHook
+
+// newCompletionHook returns the hook for a command, nil when neither --on-complete nor --notify-desktop is set
+func newCompletionHook(title, command string, notifyDesktop bool) *CompletionHook {
+	if command == "" && !notifyDesktop {
+		return nil
+	}
+	return &CompletionHook{Title: title, Command: command, NotifyDesktop: notifyDesktop}
+}
+
+// finish records the outcome the hook reports
+func (h *CompletionHook) finish(status, summary string) {
+	if h == nil {
+		return
+	}
+	h.Status = status
+	h.Summary = summary
+}
+
+// setReport records the report file {report} is replaced with
+func (h *CompletionHook) setReport(path string) {
+	if h == nil {
+		return
+	}
+	h.Report = path
+}
+
+// runCompletion runs the pending hook once; a run that ends without a recorded outcome is reported by its exit code
+func runCompletion(code int) {
+	h := completion
+	if h == nil {
+		return
+	}
+	completion = nil
+
+	if h.Status == "" {
+		h.Status = completionSuccess
+		h.Summary = "completed"
+		if code != 0 {
+			h.Status = completionFailed
+			h.Summary = fmt.Sprintf("exited with code %d", code)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+
+	if h.Command != "" {
+		if err := runHookCommand(ctx, expandCompletionCommand(h.Command, h.Status, h.Summary, h.Report), os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN: --on-complete command failed: %v\n", err)
+		}
+	}
+	if h.NotifyDesktop {
+		if name, args := desktopNotification(runtime.GOOS, h.Title, h.Status+": "+h.Summary); name != "" {
+			if _, err := exec.LookPath(name); err == nil {
+				// Best effort, a missing notification daemon is not worth a warning
+				_ = exec.CommandContext(ctx, name, args...).Run()
+			}
+		}
+	}
+}
+
+// runHookCommand runs a shell command with its output on w, killing it when ctx ends
+func runHookCommand(ctx context.Context, command string, w io.Writer) error {
+	// #nosec G204 - the command is supplied by the user on purpose
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout = w
+	cmd.Stderr = w
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("stopped after %s", completionTimeout)
+		}
+		return err
+	}
+	return nil
+}
+
+// fatalf logs an error and exits with code 1 like log.Fatalf, but runs the completion hook and --stats footer first
+func fatalf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	log.Print(message)
+	completion.finish(completionFailed, message)
+	exit(1)
+}
+
+// Side effect free functions
+
+// expandCompletionCommand substitutes {status}, {summary} and {report} in one pass, each value shell-quoted
+func expandCompletionCommand(template, status, summary, report string) string {
+	return strings.NewReplacer(
+		"{status}", shellQuote(status),
+		"{summary}", shellQuote(summary),
+		"{report}", shellQuote(report),
+	).Replace(template)
+}
+
+// desktopNotification returns the command that shows a desktop notification on an OS, "" where there is none
+func desktopNotification(goos, title, message string) (string, []string) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{title, message}
+	case "darwin":
+		return "osascript", []string{"-e", fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))}
+	default:
+		return "", nil
+	}
+}
+
+// appleScriptString quotes a value as an AppleScript string literal
+func appleScriptString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// pushCompletion returns the status and summary of a finished upload
+func pushCompletion(uploaded, skipped, failed int) (string, string) {
+	summary := fmt.Sprintf("%d uploaded, %d skipped, %d failed", uploaded, skipped, failed)
+	switch {
+	case failed == 0:
+		return completionSuccess, summary
+	case uploaded+skipped > 0:
+		return completionPartial, summary
+	default:
+		return completionFailed, summary
+	}
+}
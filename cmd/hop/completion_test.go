@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpandCompletionCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		status   string
+		summary  string
+		report   string
+		want     string
+	}{
+		{
+			name:     "all placeholders",
+			template: "notify {status} {summary} {report}",
+			status:   "success",
+			summary:  "3 uploaded, 0 skipped, 0 failed",
+			report:   "/tmp/report.json",
+			want:     "notify 'success' '3 uploaded, 0 skipped, 0 failed' '/tmp/report.json'",
+		},
+		{
+			name:     "shell metacharacters stay inside the quotes",
+			template: "echo {summary}",
+			status:   "failed",
+			summary:  `it's $(rm -rf /); "done"`,
+			want:     `echo 'it'\''s $(rm -rf /); "done"'`,
+		},
+		{
+			name:     "values are not expanded again",
+			template: "echo {summary} {status}",
+			status:   "partial",
+			summary:  "{status}",
+			want:     "echo '{status}' 'partial'",
+		},
+		{
+			name:     "missing report is an empty argument",
+			template: "echo {report}",
+			want:     "echo ''",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandCompletionCommand(tt.template, tt.status, tt.summary, tt.report); got != tt.want {
+				t.Errorf("expandCompletionCommand() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunHookCommandPassesQuotedValues(t *testing.T) {
+	var out bytes.Buffer
+	command := expandCompletionCommand(`printf '%s|' {status} {summary}`, "failed", `it's $(echo injected)`, "")
+	if err := runHookCommand(context.Background(), command, &out); err != nil {
+		t.Fatalf("runHookCommand() error = %v", err)
+	}
+	if want := `failed|it's $(echo injected)|`; out.String() != want {
+		t.Errorf("hook output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunHookCommandStopsAtTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := runHookCommand(ctx, "sleep 10", &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "stopped after") {
+		t.Errorf("runHookCommand() error = %v, want the timeout reported", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("runHookCommand() took %s, want it killed at the timeout", elapsed)
+	}
+}
+
+func TestRunCompletion(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "hook.txt")
+	tests := []struct {
+		name string
+		hook *CompletionHook
+		code int
+		want string
+	}{
+		{
+			name: "recorded outcome",
+			hook: &CompletionHook{Command: "printf '%s %s' {status} {summary} > " + shellQuote(marker), Status: completionPartial, Summary: "1 failed"},
+			want: "partial 1 failed",
+		},
+		{
+			name: "failure path without an outcome",
+			hook: &CompletionHook{Command: "printf '%s %s' {status} {summary} > " + shellQuote(marker)},
+			code: 1,
+			want: "failed exited with code 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completion = tt.hook
+			t.Cleanup(func() { completion = nil })
+
+			runCompletion(tt.code)
+			got, err := os.ReadFile(marker)
+			if err != nil {
+				t.Fatalf("hook did not run: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("hook wrote %q, want %q", got, tt.want)
+			}
+			if completion != nil {
+				t.Error("completion should be cleared so the hook runs once")
+			}
+		})
+	}
+}
+
+func TestNewCompletionHook(t *testing.T) {
+	if hook := newCompletionHook("hop check", "", false); hook != nil {
+		t.Errorf("newCompletionHook() = %+v, want nil without --on-complete and --notify-desktop", hook)
+	}
+	// finish and setReport are safe without a hook
+	var none *CompletionHook
+	none.finish(completionSuccess, "done")
+	none.setReport("report.html")
+
+	hook := newCompletionHook("hop check", "", true)
+	hook.finish(completionSuccess, "all checks passed")
+	if hook.Status != completionSuccess || hook.Summary != "all checks passed" {
+		t.Errorf("finish() recorded %s %s", hook.Status, hook.Summary)
+	}
+}
+
+func TestDesktopNotification(t *testing.T) {
+	tests := []struct {
+		goos     string
+		wantName string
+		wantArgs []string
+	}{
+		{goos: "linux", wantName: "notify-send", wantArgs: []string{"hop cdn push", `failed: "x" \ y`}},
+		{goos: "darwin", wantName: "osascript", wantArgs: []string{"-e", `display notification "failed: \"x\" \\ y" with title "hop cdn push"`}},
+		{goos: "windows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args := desktopNotification(tt.goos, "hop cdn push", `failed: "x" \ y`)
+			if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("desktopNotification() = %s %q, want %s %q", name, args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPushCompletion(t *testing.T) {
+	tests := []struct {
+		uploaded, skipped, failed int
+		want                      string
+	}{
+		{uploaded: 3, want: completionSuccess},
+		{skipped: 3, want: completionSuccess},
+		{uploaded: 2, failed: 1, want: completionPartial},
+		{failed: 3, want: completionFailed},
+	}
+	for _, tt := range tests {
+		status, summary := pushCompletion(tt.uploaded, tt.skipped, tt.failed)
+		if status != tt.want {
+			t.Errorf("pushCompletion(%d, %d, %d) status = %s, want %s", tt.uploaded, tt.skipped, tt.failed, status, tt.want)
+		}
+		if want := fmt.Sprintf("%d uploaded, %d skipped, %d failed", tt.uploaded, tt.skipped, tt.failed); summary != want {
+			t.Errorf("pushCompletion() summary = %s, want %s", summary, want)
+		}
+	}
+}
+
+func TestPushCompletionHookGetsReport(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	dir := t.TempDir()
+	summaryPath := filepath.Join(dir, "summary.json")
+	marker := filepath.Join(dir, "hook.txt")
+	t.Cleanup(func() { completion = nil })
+
+	code := run([]string{"cdn", "push", "--key", fakeAPIKey, "--zone", "site", "--from", localDir,
+		"--summary-json", summaryPath, "--on-complete", "printf '%s %s' {status} {report} > " + shellQuote(marker)})
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	runCompletion(code)
+
+	got, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	if want := "success " + summaryPath; string(got) != want {
+		t.Errorf("hook wrote %q, want %q", got, want)
+	}
+}
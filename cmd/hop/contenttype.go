@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// contentTypeOverrides fixes the types of web files that mime.TypeByExtension gets wrong or does not know on some systems
+var contentTypeOverrides = map[string]string{
+	".css":         "text/css; charset=utf-8",
+	".html":        "text/html; charset=utf-8",
+	".js":          "text/javascript; charset=utf-8",
+	".mjs":         "text/javascript; charset=utf-8",
+	".json":        "application/json",
+	".map":         "application/json",
+	".webmanifest": "application/manifest+json",
+	".svg":         "image/svg+xml",
+	".wasm":        "application/wasm",
+	".webp":        "image/webp",
+	".avif":        "image/avif",
+	".ico":         "image/x-icon",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+	".txt":         "text/plain; charset=utf-8",
+	".xml":         "application/xml",
+}
+
+// readContentTypeMap reads a --content-type-map file, a JSON object of extensions and their Content-Type: {".glb": "model/gltf-binary"}
+func readContentTypeMap(path string) (map[string]string, error) {
+	// #nosec G304 - the content type map path is supplied by the user on purpose
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing '%s': %v", path, err)
+	}
+	return normalizeContentTypeMap(raw)
+}
+
+// Side effect free functions
+
+// normalizeContentTypeMap lowercases the extensions of a content type map, adds their leading dot and rejects malformed types
+func normalizeContentTypeMap(raw map[string]string) (map[string]string, error) {
+	types := make(map[string]string, len(raw))
+	for ext, contentType := range raw {
+		ext = strings.ToLower(strings.TrimSpace(ext))
+		if ext == "" || ext == "." {
+			return nil, fmt.Errorf("empty extension in the content type map")
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if _, _, err := mime.ParseMediaType(contentType); err != nil {
+			return nil, fmt.Errorf("invalid Content-Type '%s' for %s: %v", contentType, ext, err)
+		}
+		types[ext] = contentType
+	}
+	return types, nil
+}
+
+// contentTypeFor returns the Content-Type of an uploaded file: from the user's map, the override table or the extension,
+// and for unknown extensions from sniffing the start of the content
+func contentTypeFor(name string, content []byte, custom map[string]string) string {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(name, "\\", "/")))
+	if contentType, ok := custom[ext]; ok {
+		return contentType
+	}
+	if contentType, ok := contentTypeOverrides[ext]; ok {
+		return contentType
+	}
+	if contentType := mime.TypeByExtension(ext); ext != "" && contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(content[:min(len(content), 512)])
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestContentTypeFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"index.html", "<!doctype html>", "text/html; charset=utf-8"},
+		{"css/site.css", "body{}", "text/css; charset=utf-8"},
+		{"js/app.js", "export {}", "text/javascript; charset=utf-8"},
+		{"js/app.mjs", "export {}", "text/javascript; charset=utf-8"},
+		{"img/logo.svg", "<svg></svg>", "image/svg+xml"},
+		{"IMG/LOGO.SVG", "<svg></svg>", "image/svg+xml"},
+		{"site.webmanifest", "{}", "application/manifest+json"},
+		{"app.wasm", "\x00asm", "application/wasm"},
+		{"fonts/inter.woff2", "wOF2", "font/woff2"},
+		{"img/photo.png", "\x89PNG\r\n\x1a\n", "image/png"},
+		{`img\photo.webp`, "RIFF", "image/webp"},
+		{"LICENSE", "MIT License", "text/plain; charset=utf-8"},
+		{"blob", "\x89PNG\r\n\x1a\n", "image/png"},
+		{"data.unknownext", "\x00\x01\x02", "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentTypeFor(tt.name, []byte(tt.content), nil); got != tt.want {
+				t.Errorf("contentTypeFor(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentTypeMapOverrides(t *testing.T) {
+	custom, err := normalizeContentTypeMap(map[string]string{"GLB": "model/gltf-binary", ".svg": "text/plain"})
+	if err != nil {
+		t.Fatalf("normalizeContentTypeMap() error = %v", err)
+	}
+	if got := contentTypeFor("models/ship.glb", nil, custom); got != "model/gltf-binary" {
+		t.Errorf("contentTypeFor(.glb) = %q, want the mapped type", got)
+	}
+	if got := contentTypeFor("logo.svg", nil, custom); got != "text/plain" {
+		t.Errorf("contentTypeFor(.svg) = %q, want the map to win over the built-in type", got)
+	}
+
+	if _, err := normalizeContentTypeMap(map[string]string{".glb": "not a type;;"}); err == nil {
+		t.Error("normalizeContentTypeMap() accepted a malformed Content-Type")
+	}
+}
+
+func TestReadContentTypeMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "types.json")
+	writeTestFile(t, path, `{".glb": "model/gltf-binary"}`)
+	types, err := readContentTypeMap(path)
+	if err != nil {
+		t.Fatalf("readContentTypeMap() error = %v", err)
+	}
+	if types[".glb"] != "model/gltf-binary" {
+		t.Errorf("types = %v", types)
+	}
+
+	writeTestFile(t, path, `[".glb"]`)
+	if _, err := readContentTypeMap(path); err == nil {
+		t.Error("readContentTypeMap() accepted a JSON array")
+	}
+}
+
+func TestUploadSetsContentType(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	localDir := t.TempDir()
+
+	uploads := map[string]string{
+		"site.css":    "text/css; charset=utf-8",
+		"logo.svg":    "image/svg+xml",
+		"app.mjs":     "text/javascript; charset=utf-8",
+		"ship.glb":    "model/gltf-binary",
+		"favicon.ico": "image/x-icon",
+	}
+	for name, want := range uploads {
+		localPath := filepath.Join(localDir, name)
+		writeTestFile(t, localPath, name)
+		if err := uploadFileToStorage(context.Background(), storageZone, localPath, name, uploadOptions{ContentTypes: map[string]string{".glb": "model/gltf-binary"}}); err != nil {
+			t.Fatalf("uploadFileToStorage(%s) error = %v", name, err)
+		}
+		if got := fb.uploadHeader("site/"+name, "Content-Type"); got != want {
+			t.Errorf("Content-Type of %s = %q, want %q", name, got, want)
+		}
+	}
+}
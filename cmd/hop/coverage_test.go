@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func formatCoverage(coverage []CheckCoverage) string {
+	var lines []string
+	for _, c := range coverage {
+		lines = append(lines, fmt.Sprintf("%s %s %s", c.Check, c.Status, c.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestRulesCheckCoverage(t *testing.T) {
+	destinations := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer destinations.Close()
+
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+	for i, path := range []string{"/broken", "/a", "/b", "/c"} {
+		rule := EdgeRule{ActionType: 1, ActionParameter1: destinations.URL + path, ActionParameter2: "302", Enabled: true,
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{fmt.Sprintf("/old-%d", i)}}}}
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	static := "basic ran 4 rules\nconfiguration ran 4 rules\ntrailing_slash ran 4 rules\nordering ran 4 rules\nsecurity ran 4 rules\nloops ran 4 rules\norigin ran 4 rules\nsystem_hostname ran 4 rules\n"
+	tests := []struct {
+		name string
+		opts RulesCheckOptions
+		want string
+	}{
+		{name: "everything", opts: RulesCheckOptions{}, want: static + "url_health ran 4 destinations"},
+		{name: "skip health flag", opts: RulesCheckOptions{SkipHealth: true}, want: static + "url_health skipped --skip-health"},
+		{name: "skip health with a reason", opts: RulesCheckOptions{SkipHealth: true, SkipReason: "fail fast after hostname errors"}, want: static + "url_health skipped fail fast after hostname errors"},
+		{name: "sampled", opts: RulesCheckOptions{HealthSample: &HealthSample{Percent: 50}, SampleSeed: "seed"}, want: static + "url_health ran sampled 50%"},
+		{name: "only loops", opts: RulesCheckOptions{Selection: CheckSelection{"redirect_loop": true, "redirect_chain": true}},
+			want: "basic skipped not selected (--only/--skip)\nconfiguration skipped not selected (--only/--skip)\ntrailing_slash skipped not selected (--only/--skip)\n" +
+				"ordering skipped not selected (--only/--skip)\nsecurity skipped not selected (--only/--skip)\nloops ran 4 rules\norigin skipped not selected (--only/--skip)\n" +
+				"system_hostname skipped not selected (--only/--skip)\nurl_health skipped not selected (--only/--skip)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := checkRulesStructured(ctx, fakeAPIKey, "1", tt.opts)
+			if err != nil {
+				t.Fatalf("checkRulesStructured() error = %v", err)
+			}
+			if got := formatCoverage(result.Coverage); got != tt.want {
+				t.Errorf("coverage =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("fail fast", func(t *testing.T) {
+		fb.addZone(2, "broken")
+		for _, destination := range []string{"", destinations.URL + "/a"} {
+			rule := EdgeRule{ActionType: 1, ActionParameter1: destination, ActionParameter2: "302", Enabled: true,
+				Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/old" + destination}}}}
+			if err := addEdgeRule(ctx, fakeAPIKey, "2", rule); err != nil {
+				t.Fatal(err)
+			}
+		}
+		result, err := checkRulesStructured(ctx, fakeAPIKey, "2", RulesCheckOptions{FailFast: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		health := result.Coverage[len(result.Coverage)-1]
+		want := CheckCoverage{Check: "url_health", Status: "partial", Reason: "0 of 1 destinations, fail fast after a finding at or above 'error'"}
+		if health != want {
+			t.Errorf("url_health coverage = %+v, want %+v", health, want)
+		}
+	})
+}
+
+func TestHostnameChecksCoverage(t *testing.T) {
+	ctx := context.Background()
+	if got := formatCoverage(checkSSLConfiguration(ctx, nil).Coverage); got != "ssl skipped no hostnames" {
+		t.Errorf("ssl coverage = %s", got)
+	}
+	if got := formatCoverage(checkDNSRecordsStructured(ctx, fakeAPIKey, nil).Coverage); got != "dns skipped no hostnames" {
+		t.Errorf("dns coverage = %s", got)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := hostnameCoverage(cancelled, "ssl", 3); got.Status != "partial" || !strings.HasPrefix(got.Reason, "aborted: ") {
+		t.Errorf("hostnameCoverage() after cancel = %+v", got)
+	}
+	if got := hostnameCoverage(ctx, "dns", 3); got != (CheckCoverage{Check: "dns", Status: "ran", Reason: "3 hostnames"}) {
+		t.Errorf("hostnameCoverage() = %+v", got)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import "strings"
+
+// DedupeGroup is a set of redirects for the same source path and what rules dedupe does with them
+type DedupeGroup struct {
+	Source   string
+	Keep     *EdgeRuleResponse
+	Remove   []*EdgeRuleResponse
+	Conflict bool // the duplicates redirect to different destinations, ignoring case and trailing slashes
+}
+
+// Side effect free functions
+
+// majorityDestination returns the most common destination of a group, the earliest one on a tie
+func majorityDestination(group []*EdgeRuleResponse) string {
+	counts := make(map[string]int)
+	majority := ""
+	for _, rule := range group {
+		counts[rule.ActionParameter1]++
+		if counts[rule.ActionParameter1] > counts[majority] {
+			majority = rule.ActionParameter1
+		}
+	}
+	return majority
+}
+
+// keeperScore ranks the rule to keep: enabled rules first, then rules redirecting to the majority destination
+func keeperScore(rule *EdgeRuleResponse, majority string) int {
+	score := 0
+	if rule.Enabled {
+		score += 2
+	}
+	if rule.ActionParameter1 == majority {
+		score++
+	}
+	return score
+}
+
+// planDedupe groups redirects by source path, ignoring case, and picks one rule to keep per duplicate group:
+// an enabled rule first, then one using the most common spelling of the destination, then the earliest.
+// Sources differing only by a trailing slash are not duplicates, they match different requests.
+// Groups with different destinations are conflicts and keep the rule Bunny currently applies (the first
+// enabled one) only when keepFirst is set, otherwise they are left untouched.
+func planDedupe(rules []EdgeRuleResponse, keepFirst bool) []DedupeGroup {
+	groups := make(map[string][]*EdgeRuleResponse)
+	var order []string
+	for i, rule := range rules {
+		if rule.ActionType != 1 {
+			continue
+		}
+		source := extractSourceURL(rule)
+		if source == "" {
+			continue
+		}
+		key := strings.ToLower(source)
+		if _, exists := groups[key]; !exists {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], &rules[i])
+	}
+
+	var plan []DedupeGroup
+	for _, key := range order {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+
+		conflict := false
+		for _, rule := range group[1:] {
+			if normalizeURL(rule.ActionParameter1) != normalizeURL(group[0].ActionParameter1) {
+				conflict = true
+				break
+			}
+		}
+
+		result := DedupeGroup{Source: extractSourceURL(*group[0]), Conflict: conflict}
+		if conflict && !keepFirst {
+			plan = append(plan, result)
+			continue
+		}
+
+		if conflict {
+			result.Keep = winningRedirect(group)
+			if result.Keep == nil {
+				result.Keep = group[0]
+			}
+		} else {
+			majority := majorityDestination(group)
+			result.Keep = group[0]
+			for _, rule := range group[1:] {
+				if keeperScore(rule, majority) > keeperScore(result.Keep, majority) {
+					result.Keep = rule
+				}
+			}
+		}
+
+		for _, rule := range group {
+			if rule != result.Keep {
+				result.Remove = append(result.Remove, rule)
+			}
+		}
+		plan = append(plan, result)
+	}
+
+	return plan
+}
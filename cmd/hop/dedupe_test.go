@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPlanDedupe(t *testing.T) {
+	disabled := func(rule EdgeRuleResponse) EdgeRuleResponse {
+		rule.Enabled = false
+		return rule
+	}
+
+	tests := []struct {
+		name       string
+		rules      []EdgeRuleResponse
+		keepFirst  bool
+		wantGroups int
+		wantKeep   string
+		wantRemove []string
+		wantSkip   bool
+	}{
+		{
+			name:  "no duplicates",
+			rules: []EdgeRuleResponse{redirectRule("a", "/a", "https://example.com/a"), redirectRule("b", "/b", "https://example.com/b")},
+		},
+		{
+			name:       "keeps the first of identical rules",
+			rules:      []EdgeRuleResponse{redirectRule("a", "/page", "https://example.com/new"), redirectRule("b", "/page", "https://example.com/new")},
+			wantGroups: 1,
+			wantKeep:   "a",
+			wantRemove: []string{"b"},
+		},
+		{
+			name:       "prefers an enabled rule",
+			rules:      []EdgeRuleResponse{disabled(redirectRule("a", "/page", "https://example.com/new")), redirectRule("b", "/Page", "https://example.com/new")},
+			wantGroups: 1,
+			wantKeep:   "b",
+			wantRemove: []string{"a"},
+		},
+		{
+			name: "prefers the majority spelling of the destination",
+			rules: []EdgeRuleResponse{
+				redirectRule("a", "/page", "https://example.com/new/"),
+				redirectRule("b", "/page", "https://example.com/new"),
+				redirectRule("c", "/page", "https://example.com/new"),
+			},
+			wantGroups: 1,
+			wantKeep:   "b",
+			wantRemove: []string{"a", "c"},
+		},
+		{
+			name:       "different destinations are skipped",
+			rules:      []EdgeRuleResponse{redirectRule("a", "/page", "https://example.com/one"), redirectRule("b", "/page", "https://example.com/two")},
+			wantGroups: 1,
+			wantSkip:   true,
+		},
+		{
+			name:       "keep first keeps the rule in effect",
+			rules:      []EdgeRuleResponse{disabled(redirectRule("a", "/page", "https://example.com/one")), redirectRule("b", "/page", "https://example.com/two"), redirectRule("c", "/page", "https://example.com/one")},
+			keepFirst:  true,
+			wantGroups: 1,
+			wantKeep:   "b",
+			wantRemove: []string{"a", "c"},
+		},
+		{
+			name:  "trailing-slash variants are not duplicates",
+			rules: []EdgeRuleResponse{redirectRule("a", "/page", "https://example.com/new"), redirectRule("b", "/page/", "https://example.com/new")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planDedupe(tt.rules, tt.keepFirst)
+			if len(plan) != tt.wantGroups {
+				t.Fatalf("planDedupe() returned %d groups, want %d", len(plan), tt.wantGroups)
+			}
+			if tt.wantGroups == 0 {
+				return
+			}
+			group := plan[0]
+			if tt.wantSkip {
+				if group.Keep != nil || len(group.Remove) != 0 || !group.Conflict {
+					t.Errorf("conflicting group should be skipped, got keep=%v remove=%d", group.Keep, len(group.Remove))
+				}
+				return
+			}
+			if group.Keep == nil || group.Keep.Guid != tt.wantKeep {
+				t.Fatalf("kept %v, want %s", group.Keep, tt.wantKeep)
+			}
+			var removed []string
+			for _, rule := range group.Remove {
+				removed = append(removed, rule.Guid)
+			}
+			if len(removed) != len(tt.wantRemove) {
+				t.Fatalf("removed %v, want %v", removed, tt.wantRemove)
+			}
+			for i := range removed {
+				if removed[i] != tt.wantRemove[i] {
+					t.Errorf("removed %v, want %v", removed, tt.wantRemove)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteEdgeRule(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+
+	for range 2 {
+		rule := EdgeRule{ActionType: 1, ActionParameter1: "https://example.com/new", ActionParameter2: "302", Enabled: true,
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/page"}}}}
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", rule); err != nil {
+			t.Fatalf("addEdgeRule() error = %v", err)
+		}
+	}
+
+	plan := planDedupe(fb.edgeRules(1), false)
+	if len(plan) != 1 || len(plan[0].Remove) != 1 {
+		t.Fatalf("planDedupe() = %+v, want one rule to remove", plan)
+	}
+	if err := deleteEdgeRule(ctx, fakeAPIKey, "1", plan[0].Remove[0].Guid); err != nil {
+		t.Fatalf("deleteEdgeRule() error = %v", err)
+	}
+
+	remaining := fb.edgeRules(1)
+	if len(remaining) != 1 || remaining[0].Guid != plan[0].Keep.Guid {
+		t.Errorf("remaining rules = %+v, want only %s", remaining, plan[0].Keep.Guid)
+	}
+	if err := deleteEdgeRule(ctx, fakeAPIKey, "1", "missing"); err == nil {
+		t.Error("deleteEdgeRule() of an unknown rule should fail")
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// confirm asks a yes/no question and reports whether the answer was yes, anything else including EOF is no
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// Side effect free functions
+
+// selectRulesToDelete returns the redirects with a URL trigger whose path starts with prefix or matches glob.
+// Rules of other action types are never selected, even when their triggers match.
+func selectRulesToDelete(rules []EdgeRuleResponse, prefix, glob string) []EdgeRuleResponse {
+	var selected []EdgeRuleResponse
+	for _, rule := range rules {
+		if rule.ActionType != 1 {
+			continue
+		}
+		for _, pattern := range urlTriggerPatterns(rule) {
+			path := triggerPatternPath(pattern)
+			if (prefix != "" && strings.HasPrefix(path, prefix)) || (glob != "" && wildcardMatch(glob, path)) {
+				selected = append(selected, rule)
+				break
+			}
+		}
+	}
+	return selected
+}
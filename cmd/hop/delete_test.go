@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSelectRulesToDelete(t *testing.T) {
+	header := EdgeRuleResponse{Guid: "h1", ActionType: 5, Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/old-blog/*"}}}}
+	secondPattern := redirectRule("r4", "/keep", "https://example.com/k")
+	secondPattern.Triggers[0].PatternMatches = append(secondPattern.Triggers[0].PatternMatches, "/old-blog/legacy")
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/old-blog/post-1", "https://example.com/1"),
+		redirectRule("r2", "*://www.example.com/old-blog/post-2", "https://example.com/2"),
+		redirectRule("r3", "/old-blogger", "https://example.com/3"),
+		secondPattern,
+		redirectRule("r5", "/blog/new", "https://example.com/5"),
+		header,
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		glob   string
+		want   string
+	}{
+		{name: "prefix", prefix: "/old-blog/", want: "r1,r2,r4"},
+		{name: "short prefix", prefix: "/old-blog", want: "r1,r2,r3,r4"},
+		{name: "glob", glob: "/old-blog/*", want: "r1,r2,r4"},
+		{name: "glob within path", glob: "/*/new", want: "r5"},
+		{name: "no match", prefix: "/nothing", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var guids []string
+			for _, rule := range selectRulesToDelete(rules, tt.prefix, tt.glob) {
+				guids = append(guids, rule.Guid)
+			}
+			if got := strings.Join(guids, ","); got != tt.want {
+				t.Errorf("selectRulesToDelete() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "y\n", want: true},
+		{input: " YES \n", want: true},
+		{input: "n\n", want: false},
+		{input: "\n", want: false},
+		{input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if got := confirm(strings.NewReader(tt.input), &out, "Delete?"); got != tt.want {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+		if out.String() != "Delete? [y/N]: " {
+			t.Errorf("prompt = %q", out.String())
+		}
+	}
+}
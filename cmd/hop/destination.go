@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Side effect free functions
+
+// destinationSyntaxProblem returns what is malformed about a redirect destination and the offending portion, or "" if it is well-formed.
+// Request variables such as %{Url.Path} are filled in before parsing, Bunny replaces them before sending the redirect.
+func destinationSyntaxProblem(destination string) (string, string) {
+	for i, c := range destination {
+		if c == ' ' {
+			return "unencoded space", excerptAround(destination, i)
+		}
+		if c < 0x20 || c == 0x7f {
+			return fmt.Sprintf("control character %q", c), excerptAround(destination, i)
+		}
+	}
+
+	preserved := preservesQuery(destination)
+	base := stripPreservedQuery(destination)
+	if first := strings.Index(base, "?"); first >= 0 {
+		if second := strings.Index(base[first+1:], "?"); second >= 0 {
+			return "more than one '?'", base[first:]
+		}
+	}
+	if preserved {
+		if hash := strings.Index(base, "#"); hash >= 0 {
+			return "fragment with query preservation, the forwarded query ends up inside the fragment", destination[hash:]
+		}
+	}
+
+	if _, err := url.Parse(placeholderPattern.ReplaceAllString(destination, "x")); err != nil {
+		return fmt.Sprintf("unparsable URL: %v", err), destination
+	}
+	return "", ""
+}
+
+// excerptAround returns the part of s around index i, quoted so spaces and control characters stay visible
+func excerptAround(s string, i int) string {
+	start := max(i-10, 0)
+	end := min(i+10, len(s))
+	return fmt.Sprintf("%q", s[start:end])
+}
+
+// checkDestinationSyntax reports redirect destinations that parse badly or carry a malformed query string.
+// It complements isValidDomain, which only checks that the destination has a host.
+func checkDestinationSyntax(rules []EdgeRuleResponse) []CheckIssue {
+	var issues []CheckIssue
+	for i, rule := range rules {
+		if rule.ActionType != 1 || rule.ActionParameter1 == "" {
+			continue
+		}
+		problem, portion := destinationSyntaxProblem(rule.ActionParameter1)
+		if problem == "" {
+			continue
+		}
+		issues = append(issues, CheckIssue{
+			Type:     IssueConfiguration,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Malformed destination URL, %s: %s", problem, portion),
+			Rule:     &rules[i],
+			Details:  map[string]interface{}{"portion": portion},
+		})
+	}
+	return issues
+}
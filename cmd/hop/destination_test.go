@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDestinationSyntaxProblem(t *testing.T) {
+	tests := []struct {
+		destination string
+		wantProblem string
+		wantPortion string
+	}{
+		{destination: "https://new.example.com/page"},
+		{destination: "https://new.example.com/page?ref=old"},
+		{destination: "/new%{Url.Path}"},
+		{destination: "https://new.example.com/page?%{Url.Query}"},
+		{destination: "https://new.example.com/page#section"},
+		{destination: "https://new.example.com/page?ref=old?utm_source=x", wantProblem: "more than one '?'", wantPortion: "?ref=old?utm_source=x"},
+		{destination: "https://new.example.com/my page", wantProblem: "unencoded space", wantPortion: `"ple.com/my page"`},
+		{destination: "https://new.example.com/page\tx", wantProblem: "control character", wantPortion: `"e.com/page\tx"`},
+		{destination: "https://new.example.com/page#top?%{Url.Query}", wantProblem: "fragment with query preservation", wantPortion: "#top?%{Url.Query}"},
+		{destination: "https://new.example.com/100%", wantProblem: "unparsable URL", wantPortion: "https://new.example.com/100%"},
+		{destination: "https://[::1/page", wantProblem: "unparsable URL", wantPortion: "https://[::1/page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.destination, func(t *testing.T) {
+			problem, portion := destinationSyntaxProblem(tt.destination)
+			if tt.wantProblem == "" {
+				if problem != "" {
+					t.Errorf("destinationSyntaxProblem() = %q (%s), want no problem", problem, portion)
+				}
+				return
+			}
+			if !strings.Contains(problem, tt.wantProblem) || portion != tt.wantPortion {
+				t.Errorf("destinationSyntaxProblem() = %q, %s; want %q, %s", problem, portion, tt.wantProblem, tt.wantPortion)
+			}
+		})
+	}
+}
+
+func TestCheckDestinationSyntax(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("ok", "/a", "https://new.example.com/page"),
+		redirectRule("double", "/b", "https://new.example.com/page?ref=old?utm_source=x"),
+		blockRule("block", "/c"),
+	}
+	issues := checkDestinationSyntax(rules)
+	if len(issues) != 1 || issues[0].Rule.Guid != "double" || issues[0].Type != "configuration" || issues[0].Severity != "error" {
+		t.Fatalf("checkDestinationSyntax() = %+v, want one configuration error for double", issues)
+	}
+	if !strings.Contains(issues[0].Message, "?ref=old?utm_source=x") {
+		t.Errorf("message %q does not show the offending portion", issues[0].Message)
+	}
+}
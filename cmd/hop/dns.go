@@ -32,6 +32,8 @@ type DNSRecord struct {
 	Name  string `json:"Name"`
 	Value string `json:"Value"`
 	TTL   int    `json:"Ttl"`
+
+	PullZoneId int64 `json:"PullZoneId,omitempty"` // the pull zone a pull zone record (type 7) serves
 }
 
 type DNSRecordFormatted struct {
@@ -82,7 +84,7 @@ func formatDNSRecordType(recordType int) string {
 }
 
 func isTargetRecordType(recordType int) bool {
-	return recordType == 0 || recordType == 2 // A or CNAME
+	return recordType == 0 || recordType == 2 || recordType == 7 // A, CNAME or pull zone record
 }
 
 func normalizeHostname(hostname string) string {
@@ -111,8 +113,13 @@ func filterMatchingDNSRecords(dnsZones []DNSZone, hostnameMap map[string]bool) [
 			// Handle both full domain names and relative names
 			recordNames := []string{record.Name} // Always check the name as-is
 
+			// An empty name is the zone apex
+			if record.Name == "" {
+				recordNames = []string{zone.Domain}
+			}
+
 			// If it's a relative name (doesn't contain dots or is different from zone), also try full name
-			if record.Name != zone.Domain && !strings.Contains(record.Name, ".") {
+			if record.Name != "" && record.Name != zone.Domain && !strings.Contains(record.Name, ".") {
 				fullName := record.Name + "." + zone.Domain
 				recordNames = append(recordNames, fullName)
 			}
@@ -146,7 +153,7 @@ type DNSZoneListResponse struct {
 }
 
 func getAllDNSZones(ctx context.Context, apiKey string) ([]DNSZone, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.bunny.net/dnszone", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiBaseURL+"/dnszone", nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
@@ -161,7 +168,7 @@ func getAllDNSZones(ctx context.Context, apiKey string) ([]DNSZone, error) {
 	if resp == nil {
 		return nil, fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -287,6 +294,10 @@ func checkDNSRecordsForHostnames(ctx context.Context, apiKey string, hostnames [
 // checkDNSRecordsStructured validates DNS records and returns structured results
 func checkDNSRecordsStructured(ctx context.Context, apiKey string, hostnames []Hostname) CheckResult {
 	var result CheckResult
+	if len(hostnames) == 0 {
+		result.Coverage = []CheckCoverage{{Check: "dns", Status: "skipped", Reason: "no hostnames"}}
+		return result
+	}
 
 	validationResults := checkDNSRecordsForHostnames(ctx, apiKey, hostnames)
 
@@ -294,8 +305,8 @@ func checkDNSRecordsStructured(ctx context.Context, apiKey string, hostnames []H
 		// Skip .b-cdn.net hostnames as they're managed by Bunny
 		if strings.HasSuffix(validation.Hostname, ".b-cdn.net") {
 			result.Successful = append(result.Successful, CheckIssue{
-				Type:     "dns_skip",
-				Severity: "info",
+				Type:     IssueDNSSkip,
+				Severity: SeverityInfo,
 				Message:  fmt.Sprintf("SKIP %s (Bunny-managed)", validation.Hostname),
 				Details:  map[string]interface{}{"hostname": validation.Hostname},
 			})
@@ -304,15 +315,15 @@ func checkDNSRecordsStructured(ctx context.Context, apiKey string, hostnames []H
 
 		if !validation.HasRecord {
 			result.Issues = append(result.Issues, CheckIssue{
-				Type:     "dns_missing_record",
-				Severity: "error",
+				Type:     IssueDNSMissingRecord,
+				Severity: SeverityError,
 				Message:  fmt.Sprintf("MISSING %s - No DNS record found", validation.Hostname),
 				Details:  map[string]interface{}{"hostname": validation.Hostname},
 			})
 		} else {
 			result.Successful = append(result.Successful, CheckIssue{
-				Type:     "dns_ok",
-				Severity: "info",
+				Type:     IssueDNSOK,
+				Severity: SeverityInfo,
 				Message:  fmt.Sprintf("OK %s (%s -> %s)", validation.Hostname, validation.RecordType, validation.RecordValue),
 				Details: map[string]interface{}{
 					"hostname":     validation.Hostname,
@@ -323,5 +334,21 @@ func checkDNSRecordsStructured(ctx context.Context, apiKey string, hostnames []H
 		}
 	}
 
+	result.Coverage = []CheckCoverage{hostnameCoverage(ctx, "dns", len(hostnames))}
 	return result
 }
+
+// addDNSRecord creates a record in a Bunny DNS zone
+func addDNSRecord(ctx context.Context, apiKey string, dnsZoneID int64, record DNSRecord) error {
+	url := fmt.Sprintf("%s/dnszone/%d/records", apiBaseURL, dnsZoneID)
+	body := map[string]interface{}{
+		"Type":  record.Type,
+		"Name":  record.Name,
+		"Value": record.Value,
+		"Ttl":   record.TTL,
+	}
+	if record.PullZoneId != 0 {
+		body["PullZoneId"] = record.PullZoneId
+	}
+	return sendAPIRequest(ctx, apiKey, "PUT", url, body)
+}
@@ -59,6 +59,11 @@ func TestIsTargetRecordType(t *testing.T) {
 			recordType: 2,
 			expected:   true,
 		},
+		{
+			name:       "pull zone record should be target",
+			recordType: 7,
+			expected:   true,
+		},
 		{
 			name:       "MX record should not be target",
 			recordType: 15,
@@ -236,6 +241,22 @@ func TestFilterMatchingDNSRecords(t *testing.T) {
 				{Name: "example.com", Type: "A", Value: "1.2.3.4", TTL: 300},
 			},
 		},
+		{
+			name: "empty name is the zone apex",
+			dnsZones: []DNSZone{
+				{
+					Id:     1,
+					Domain: "example.com",
+					Records: []DNSRecord{
+						{Id: 1, Type: 2, Name: "", Value: "zone.b-cdn.net", TTL: 300},
+					},
+				},
+			},
+			hostnameMap: map[string]bool{"example.com": true},
+			expected: []DNSRecordFormatted{
+				{Name: "example.com", Type: "CNAME", Value: "zone.b-cdn.net", TTL: 300},
+			},
+		},
 		{
 			name: "single CNAME record match",
 			dnsZones: []DNSZone{
@@ -3,13 +3,18 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +27,7 @@ type EdgeRule struct {
 	TriggerMatchingType int       `json:"TriggerMatchingType"`
 	Description         string    `json:"Description,omitempty"`
 	Enabled             bool      `json:"Enabled"`
+	OrderIndex          *int      `json:"OrderIndex,omitempty"` // only sent when moving a rule, nil keeps the position
 }
 
 type Trigger struct {
@@ -40,6 +46,7 @@ type EdgeRuleResponse struct {
 	TriggerMatchingType int       `json:"TriggerMatchingType"`
 	Description         string    `json:"Description"`
 	Enabled             bool      `json:"Enabled"`
+	OrderIndex          int       `json:"OrderIndex"` // Bunny evaluates rules in ascending order
 }
 
 type CheckIssue struct {
@@ -54,6 +61,16 @@ type CheckIssue struct {
 type CheckResult struct {
 	Issues     []CheckIssue
 	Successful []CheckIssue
+	Suppressed []CheckIssue // issues accepted by --baseline, they do not fail the check
+	Coverage   []CheckCoverage
+	Slowest    []DestinationTiming // the slowest destinations of the health checks, slowest first
+}
+
+// CheckCoverage records whether a check category ran, so a green run shows what it actually verified
+type CheckCoverage struct {
+	Check  string
+	Status string // "ran", "partial" or "skipped"
+	Reason string
 }
 
 type RedirectMap struct {
@@ -67,7 +84,7 @@ func addEdgeRule(ctx context.Context, apiKey, zoneID string, rule EdgeRule) erro
 		return fmt.Errorf("error marshaling JSON: %v", err)
 	}
 
-	url := fmt.Sprintf("https://api.bunny.net/pullzone/%s/edgerules/addOrUpdate", zoneID)
+	url := fmt.Sprintf("%s/pullzone/%s/edgerules/addOrUpdate", apiBaseURL, zoneID)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
@@ -85,7 +102,7 @@ func addEdgeRule(ctx context.Context, apiKey, zoneID string, rule EdgeRule) erro
 	if resp == nil {
 		return fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -103,7 +120,7 @@ func addEdgeRule(ctx context.Context, apiKey, zoneID string, rule EdgeRule) erro
 }
 
 func listEdgeRules(ctx context.Context, apiKey, zoneID string) ([]EdgeRuleResponse, error) {
-	url := fmt.Sprintf("https://api.bunny.net/pullzone/%s", zoneID)
+	url := fmt.Sprintf("%s/pullzone/%s", apiBaseURL, zoneID)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -120,7 +137,7 @@ func listEdgeRules(ctx context.Context, apiKey, zoneID string) ([]EdgeRuleRespon
 	if resp == nil {
 		return nil, fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
@@ -137,10 +154,48 @@ func listEdgeRules(ctx context.Context, apiKey, zoneID string) ([]EdgeRuleRespon
 		return nil, fmt.Errorf("error parsing JSON response: %v", err)
 	}
 
+	sortRulesByOrder(pullZone.EdgeRules)
 	return pullZone.EdgeRules, nil
 }
 
-func performHealthCheck(ctx context.Context, targetURL string) (int, bool, error) {
+func deleteEdgeRule(ctx context.Context, apiKey, zoneID, guid string) error {
+	url := fmt.Sprintf("%s/pullzone/%s/edgerules/%s", apiBaseURL, zoneID, guid)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("AccessKey", apiKey)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making request: %v", err)
+	}
+	if resp == nil {
+		return fmt.Errorf("received nil response")
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API request failed with status %s: %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// performHealthCheck requests a destination, following up to 3 redirects, and returns the status,
+// whether the last response still redirects and the time until its headers arrived
+func performHealthCheck(ctx context.Context, targetURL string) (int, bool, time.Duration, error) {
+	statusCode, hasRedirect, elapsed, _, err := fetchDestination(ctx, targetURL, 0)
+	return statusCode, hasRedirect, elapsed, err
+}
+
+// fetchDestination performs the request of performHealthCheck and also returns up to bodyLimit bytes of the body.
+// The body read is cut short when ctx is done; a body that cannot be read is returned empty.
+func fetchDestination(ctx context.Context, targetURL string, bodyLimit int64) (int, bool, time.Duration, []byte, error) {
 	client := &http.Client{
 		Timeout: 10 * time.Second,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -153,20 +208,248 @@ func performHealthCheck(ctx context.Context, targetURL string) (int, bool, error
 
 	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
 	if err != nil {
-		return 0, false, err
+		return 0, false, 0, nil, err
 	}
 
+	// time.Since reads the monotonic clock, wall clock jumps do not skew the measurement
+	start := time.Now()
 	resp, err := client.Do(req)
+	elapsed := time.Since(start)
 	if err != nil {
-		return 0, false, err
+		return 0, false, elapsed, nil, err
 	}
 	if resp == nil {
-		return 0, false, fmt.Errorf("received nil response")
+		return 0, false, elapsed, nil, fmt.Errorf("received nil response")
+	}
+	defer drainAndClose(resp.Body)
+
+	var body []byte
+	if bodyLimit > 0 {
+		body, err = io.ReadAll(io.LimitReader(resp.Body, bodyLimit))
+		if err != nil {
+			body = nil
+		}
 	}
-	defer resp.Body.Close()
 
 	hasRedirect := resp.StatusCode >= 300 && resp.StatusCode < 400
-	return resp.StatusCode, hasRedirect, nil
+	return resp.StatusCode, hasRedirect, elapsed, body, nil
+}
+
+func formatActionType(actionType int) string {
+	switch actionType {
+	case 0:
+		return "ForceSSL"
+	case 1:
+		return "Redirect"
+	case 2:
+		return "OriginUrl"
+	case 3:
+		return "OverrideCacheTime"
+	case 4:
+		return "BlockRequest"
+	case 5:
+		return "SetResponseHeader"
+	case 6:
+		return "SetRequestHeader"
+	case 7:
+		return "ForceDownload"
+	case 8:
+		return "DisableTokenAuthentication"
+	case 9:
+		return "EnableTokenAuthentication"
+	case 10:
+		return "OverrideCacheTimePublic"
+	case 11:
+		return "IgnoreQueryString"
+	case 12:
+		return "DisableOptimizer"
+	case 13:
+		return "ForceCompression"
+	case 14:
+		return "SetStatusCode"
+	case 15:
+		return "BypassPermaCache"
+	case 16:
+		return "OverrideBrowserCacheTime"
+	case 17:
+		return "OriginStorage"
+	case 18:
+		return "SetNetworkRateLimit"
+	case 19:
+		return "SetConnectionLimit"
+	case 20:
+		return "SetRequestsPerSecondLimit"
+	default:
+		return fmt.Sprintf("Action%d", actionType)
+	}
+}
+
+func formatTriggerType(triggerType int) string {
+	switch triggerType {
+	case 0:
+		return "Url"
+	case 1:
+		return "RequestHeader"
+	case 2:
+		return "ResponseHeader"
+	case 3:
+		return "UrlExtension"
+	case 4:
+		return "CountryCode"
+	case 5:
+		return "RemoteIP"
+	case 6:
+		return "UrlQueryString"
+	case 7:
+		return "RandomChance"
+	case 8:
+		return "StatusCode"
+	case 9:
+		return "RequestMethod"
+	case 10:
+		return "CookieValue"
+	case 11:
+		return "CountryStateCode"
+	default:
+		return fmt.Sprintf("Trigger%d", triggerType)
+	}
+}
+
+func formatPatternMatchingType(matchingType int) string {
+	switch matchingType {
+	case 0:
+		return "MatchAny"
+	case 1:
+		return "MatchAll"
+	case 2:
+		return "MatchNone"
+	default:
+		return fmt.Sprintf("Match%d", matchingType)
+	}
+}
+
+// RuleFilter selects rules by enabled state and a case-insensitive description or source substring
+type RuleFilter struct {
+	Disabled bool
+	Match    string
+}
+
+// active reports whether the filter excludes anything
+func (f RuleFilter) active() bool {
+	return f.Disabled || f.Match != ""
+}
+
+// matches reports whether a rule passes every filter
+func (f RuleFilter) matches(rule EdgeRuleResponse) bool {
+	if f.Disabled && rule.Enabled {
+		return false
+	}
+	if f.Match == "" {
+		return true
+	}
+	needle := strings.ToLower(f.Match)
+	if strings.Contains(strings.ToLower(rule.Description), needle) {
+		return true
+	}
+	for _, pattern := range urlTriggerPatterns(rule) {
+		if strings.Contains(strings.ToLower(pattern), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRules returns the rules that pass the filter, in their original order
+func filterRules(rules []EdgeRuleResponse, filter RuleFilter) []EdgeRuleResponse {
+	filtered := []EdgeRuleResponse{}
+	for _, rule := range rules {
+		if filter.matches(rule) {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+// ruleSortKey returns the normalized key a rule is sorted by: "source", "destination" or "description"
+func ruleSortKey(rule EdgeRuleResponse, by string) string {
+	switch by {
+	case "destination":
+		return normalizeURL(rule.ActionParameter1)
+	case "description":
+		return strings.ToLower(rule.Description)
+	default:
+		return normalizeURL(extractSourceURL(rule))
+	}
+}
+
+// lessRuleSortKey orders sort keys alphabetically with empty keys last
+func lessRuleSortKey(a, b string) bool {
+	if a == "" || b == "" {
+		return a != "" && b == ""
+	}
+	return a < b
+}
+
+// sortRules stably sorts rules by source, destination, description or evaluation order
+func sortRules(rules []EdgeRuleResponse, by string) {
+	if by == "order" {
+		sortRulesByOrder(rules)
+		return
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return lessRuleSortKey(ruleSortKey(rules[i], by), ruleSortKey(rules[j], by))
+	})
+}
+
+// rulesCSVHeader is the header row of the CSV rule export
+var rulesCSVHeader = []string{"guid", "from", "to", "status", "enabled", "description"}
+
+// writeRulesCSV writes rules as CSV, multiple source patterns of a rule are joined with "|".
+// The export is for review only, there is no CSV import.
+func writeRulesCSV(w io.Writer, rules []EdgeRuleResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(rulesCSVHeader); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		record := []string{
+			rule.Guid,
+			strings.Join(urlTriggerPatterns(rule), "|"),
+			rule.ActionParameter1,
+			rule.ActionParameter2,
+			fmt.Sprintf("%t", rule.Enabled),
+			rule.Description,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// groupRulesByActionType groups rules by action type, returning the action types in ascending order
+func groupRulesByActionType(rules []EdgeRuleResponse) ([]int, map[int][]EdgeRuleResponse) {
+	groups := make(map[int][]EdgeRuleResponse)
+	var actionTypes []int
+	for _, rule := range rules {
+		if _, exists := groups[rule.ActionType]; !exists {
+			actionTypes = append(actionTypes, rule.ActionType)
+		}
+		groups[rule.ActionType] = append(groups[rule.ActionType], rule)
+	}
+	sort.Ints(actionTypes)
+	return actionTypes, groups
+}
+
+// findRuleByGuid returns the rule with the given GUID, or nil if the zone has none
+func findRuleByGuid(rules []EdgeRuleResponse, guid string) *EdgeRuleResponse {
+	for i := range rules {
+		if strings.EqualFold(rules[i].Guid, guid) {
+			return &rules[i]
+		}
+	}
+	return nil
 }
 
 func isValidDomain(urlStr string) bool {
@@ -213,6 +496,214 @@ func extractSourceURL(rule EdgeRuleResponse) string {
 	return ""
 }
 
+// caseInsensitivePatterns returns the trigger patterns used for a case-insensitive source: the pattern as given plus its lowercase form.
+// Bunny URL triggers have no case-insensitivity switch, so the lowercase variant is added as a second MatchAny pattern.
+func caseInsensitivePatterns(pattern string) []string {
+	lower := strings.ToLower(pattern)
+	if lower == pattern {
+		return []string{pattern}
+	}
+	return []string{pattern, lower}
+}
+
+// hasLowercaseVariant reports whether the rule's first trigger also matches the lowercase form of its source
+func hasLowercaseVariant(rule EdgeRuleResponse, source string) bool {
+	if len(rule.Triggers) == 0 {
+		return false
+	}
+	lower := strings.ToLower(source)
+	for _, pattern := range rule.Triggers[0].PatternMatches {
+		if pattern == lower {
+			return true
+		}
+	}
+	return false
+}
+
+// triggerPatternPath strips scheme and host from a full-URL trigger pattern so it can be matched against a path
+func triggerPatternPath(pattern string) string {
+	idx := strings.Index(pattern, "://")
+	if idx < 0 {
+		return pattern
+	}
+	rest := pattern[idx+3:]
+	slash := strings.Index(rest, "/")
+	if slash < 0 {
+		return "/"
+	}
+	return rest[slash:]
+}
+
+// triggerPatternHost returns the lowercase host of a URL trigger pattern like *://www.example.com/*, "" for a path pattern
+func triggerPatternHost(pattern string) string {
+	idx := strings.Index(pattern, "://")
+	if idx < 0 {
+		return ""
+	}
+	rest := pattern[idx+3:]
+	if slash := strings.Index(rest, "/"); slash >= 0 {
+		rest = rest[:slash]
+	}
+	return strings.ToLower(rest)
+}
+
+// wildcardMatch reports whether s matches pattern, where '*' matches any sequence of characters
+func wildcardMatch(pattern, s string) bool {
+	p, i := 0, 0
+	star, match := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, match = p, i
+			p++
+		case p < len(pattern) && pattern[p] == s[i]:
+			p++
+			i++
+		case star >= 0:
+			p = star + 1
+			match++
+			i = match
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// patternPathPart returns the path portion of a trigger pattern without Bunny's leading "*" host wildcard
+func patternPathPart(pattern string) string {
+	p := triggerPatternPath(pattern)
+	if strings.HasPrefix(p, "*/") {
+		p = p[1:]
+	}
+	return p
+}
+
+// hasPathWildcard reports whether a trigger pattern contains a wildcard in its path portion
+func hasPathWildcard(pattern string) bool {
+	return strings.Contains(patternPathPart(pattern), "*")
+}
+
+// validateRedirectPattern checks a source pattern for `rules add`, returning warnings for likely mistakes
+// and an error for complex wildcards unless they are explicitly allowed
+func validateRedirectPattern(pattern string, allowComplex bool) ([]string, error) {
+	var warnings []string
+
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("source pattern must not be empty")
+	}
+
+	pathPart := patternPathPart(pattern)
+
+	if !strings.Contains(pathPart, "*") && strings.HasSuffix(pathPart, "/") && pathPart != "/" {
+		warnings = append(warnings, fmt.Sprintf("pattern '%s' ends with '/' but has no wildcard, it only matches that exact path (did you mean '%s*'?)", pattern, pattern))
+	}
+
+	if allowComplex {
+		return warnings, nil
+	}
+
+	if strings.Count(pathPart, "*") > 1 {
+		return warnings, fmt.Errorf("pattern '%s' contains more than one wildcard (use --allow-complex-patterns to permit it)", pattern)
+	}
+
+	for i := 0; i < len(pathPart); i++ {
+		if pathPart[i] != '*' {
+			continue
+		}
+		if i > 0 && pathPart[i-1] != '/' && i+1 < len(pathPart) && pathPart[i+1] != '/' {
+			return warnings, fmt.Errorf("pattern '%s' has a wildcard in the middle of a path segment (use --allow-complex-patterns to permit it)", pattern)
+		}
+	}
+
+	return warnings, nil
+}
+
+// placeholderPattern matches the ways a destination can reference the captured part of the request
+var placeholderPattern = regexp.MustCompile(`\$\d|\{\{[^}]*\}\}|%\{[^}]+\}`)
+
+// hasPathPlaceholder reports whether a destination references a wildcard capture or a request variable
+func hasPathPlaceholder(destination string) bool {
+	return placeholderPattern.MatchString(destination)
+}
+
+// queryPreserveSuffix is appended to a redirect destination so Bunny forwards the request query string
+const queryPreserveSuffix = "?%{Url.Query}"
+
+// withPreservedQuery returns the destination configured to carry the request query string
+func withPreservedQuery(destination string) string {
+	if preservesQuery(destination) {
+		return destination
+	}
+	return destination + queryPreserveSuffix
+}
+
+// preservesQuery reports whether a redirect destination forwards the request query string
+func preservesQuery(destination string) bool {
+	return strings.HasSuffix(destination, queryPreserveSuffix)
+}
+
+// stripPreservedQuery returns the destination without the query forwarding suffix
+func stripPreservedQuery(destination string) string {
+	return strings.TrimSuffix(destination, queryPreserveSuffix)
+}
+
+// matchesTriggerPattern reports whether a request path matches a URL trigger pattern, ignoring case and trailing slashes
+func matchesTriggerPattern(pattern, path string) bool {
+	return wildcardMatch(normalizeURL(triggerPatternPath(pattern)), normalizeURL(path))
+}
+
+// findRedirectsForPath returns the redirect rules whose URL triggers match the path, in rule order
+func findRedirectsForPath(rules []EdgeRuleResponse, path string) []*EdgeRuleResponse {
+	var matches []*EdgeRuleResponse
+	for i, rule := range rules {
+		if rule.ActionType != 1 {
+			continue
+		}
+		for _, trigger := range rule.Triggers {
+			if trigger.Type != 0 { // Url trigger
+				continue
+			}
+			matched := false
+			for _, pattern := range trigger.PatternMatches {
+				if matchesTriggerPattern(pattern, path) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				matches = append(matches, &rules[i])
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// urlTriggerPatterns returns the patterns of all URL triggers of a rule
+func urlTriggerPatterns(rule EdgeRuleResponse) []string {
+	var patterns []string
+	for _, trigger := range rule.Triggers {
+		if trigger.Type == 0 { // Url trigger
+			patterns = append(patterns, trigger.PatternMatches...)
+		}
+	}
+	return patterns
+}
+
+// winningRedirect returns the redirect Bunny applies for a set of matches, the first enabled one in evaluation order
+func winningRedirect(matches []*EdgeRuleResponse) *EdgeRuleResponse {
+	for _, rule := range matches {
+		if rule.Enabled {
+			return rule
+		}
+	}
+	return nil
+}
+
 func buildRedirectMap(rules []EdgeRuleResponse) *RedirectMap {
 	rm := &RedirectMap{
 		SourceToDestination: make(map[string]string),
@@ -231,28 +722,103 @@ func buildRedirectMap(rules []EdgeRuleResponse) *RedirectMap {
 	return rm
 }
 
+// existingRedirectGuid returns the GUID of the redirect rule for the given source path, or "" if there is none
+func existingRedirectGuid(rules []EdgeRuleResponse, source string) string {
+	if rule, exists := buildRedirectMap(rules).Rules[source]; exists {
+		return rule.Guid
+	}
+	return ""
+}
+
+// RuleChange pairs the redirect for a source path in two rule sets, either side is nil if the source is missing there
+type RuleChange struct {
+	Source string
+	From   *EdgeRuleResponse
+	To     *EdgeRuleResponse
+}
+
+// RulesDiff describes how the redirects of a target rule set differ from a source rule set
+type RulesDiff struct {
+	Missing   []RuleChange // only in the source
+	Extra     []RuleChange // only in the target
+	Changed   []RuleChange // destination, status code or enabled state differ
+	Unchanged int
+}
+
+// redirectsBySource indexes redirect rules by normalized source path, keeping the first rule per source
+func redirectsBySource(rules []EdgeRuleResponse) (map[string]*EdgeRuleResponse, []string) {
+	bySource := make(map[string]*EdgeRuleResponse)
+	var order []string
+	for i, rule := range rules {
+		if rule.ActionType != 1 {
+			continue
+		}
+		source := extractSourceURL(rule)
+		if source == "" {
+			continue
+		}
+		key := normalizeURL(source)
+		if _, exists := bySource[key]; !exists {
+			bySource[key] = &rules[i]
+			order = append(order, key)
+		}
+	}
+	return bySource, order
+}
+
+// diffRedirectRules compares the redirects of two rule sets by normalized source path
+func diffRedirectRules(from, to []EdgeRuleResponse) RulesDiff {
+	var diff RulesDiff
+	fromRules, fromOrder := redirectsBySource(from)
+	toRules, toOrder := redirectsBySource(to)
+
+	for _, key := range fromOrder {
+		fromRule := fromRules[key]
+		toRule, exists := toRules[key]
+		change := RuleChange{Source: extractSourceURL(*fromRule), From: fromRule, To: toRule}
+		switch {
+		case !exists:
+			diff.Missing = append(diff.Missing, change)
+		case fromRule.ActionParameter1 != toRule.ActionParameter1 ||
+			fromRule.ActionParameter2 != toRule.ActionParameter2 ||
+			fromRule.Enabled != toRule.Enabled:
+			diff.Changed = append(diff.Changed, change)
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	for _, key := range toOrder {
+		if _, exists := fromRules[key]; !exists {
+			diff.Extra = append(diff.Extra, RuleChange{Source: extractSourceURL(*toRules[key]), To: toRules[key]})
+		}
+	}
+
+	return diff
+}
+
 func checkBasicRedirectIssues(rules []EdgeRuleResponse) []CheckIssue {
 	var issues []CheckIssue
 
-	for _, rule := range rules {
+	for i, rule := range rules {
 		if rule.ActionType == 1 { // Redirect action
 			// Check for 301 redirects (should be 302)
 			if rule.ActionParameter2 == "301" {
 				issues = append(issues, CheckIssue{
-					Type:     "basic",
-					Severity: "warning",
+					Type:     IssueBasic,
+					Severity: SeverityWarning,
 					Message:  "301 redirect detected (should be 302 for temporary redirects)",
-					Rule:     &rule,
+					Rule:     &rules[i],
 				})
 			}
 
 			// Check for 302 redirects without destination URL
 			if rule.ActionParameter2 == "302" && rule.ActionParameter1 == "" {
 				issues = append(issues, CheckIssue{
-					Type:     "basic",
-					Severity: "error",
+					Type:     IssueBasic,
+					Severity: SeverityError,
 					Message:  "302 redirect without destination URL",
-					Rule:     &rule,
+					Rule:     &rules[i],
 				})
 			}
 
@@ -260,17 +826,17 @@ func checkBasicRedirectIssues(rules []EdgeRuleResponse) []CheckIssue {
 			if rule.ActionParameter1 != "" && rule.ActionParameter2 != "302" {
 				if rule.ActionParameter2 == "" {
 					issues = append(issues, CheckIssue{
-						Type:     "basic",
-						Severity: "error",
+						Type:     IssueBasic,
+						Severity: SeverityError,
 						Message:  "Destination URL set but no redirect status code specified",
-						Rule:     &rule,
+						Rule:     &rules[i],
 					})
 				} else if rule.ActionParameter2 != "301" {
 					issues = append(issues, CheckIssue{
-						Type:     "basic",
-						Severity: "warning",
+						Type:     IssueBasic,
+						Severity: SeverityWarning,
 						Message:  fmt.Sprintf("Destination URL set but status code is %s (should be 302)", rule.ActionParameter2),
-						Rule:     &rule,
+						Rule:     &rules[i],
 					})
 				}
 			}
@@ -291,10 +857,10 @@ func checkConfigurationIssues(rules []EdgeRuleResponse) []CheckIssue {
 			if source != "" {
 				sourceURLs[source] = append(sourceURLs[source], &rules[i])
 
-				// Also check normalized version for case/slash issues
-				normalized := normalizeURL(source)
-				if normalized != source {
-					sourceURLs[normalized] = append(sourceURLs[normalized], &rules[i])
+				// Also check the lowercase version for case issues, trailing-slash pairs are checked by checkTrailingSlashPairs
+				lower := strings.ToLower(source)
+				if lower != source {
+					sourceURLs[lower] = append(sourceURLs[lower], &rules[i])
 				}
 			}
 		}
@@ -304,8 +870,8 @@ func checkConfigurationIssues(rules []EdgeRuleResponse) []CheckIssue {
 	for source, ruleList := range sourceURLs {
 		if len(ruleList) > 1 {
 			issues = append(issues, CheckIssue{
-				Type:     "configuration",
-				Severity: "error",
+				Type:     IssueConfiguration,
+				Severity: SeverityError,
 				Message:  fmt.Sprintf("Duplicate/conflicting rules for source path: %s", source),
 				Rule:     ruleList[0],
 				Details:  map[string]interface{}{"conflict_count": len(ruleList)},
@@ -320,10 +886,10 @@ func checkConfigurationIssues(rules []EdgeRuleResponse) []CheckIssue {
 			if source != "" {
 				// Check for case sensitivity issues
 				lowerSource := strings.ToLower(source)
-				if lowerSource != source {
+				if lowerSource != source && !hasLowercaseVariant(rule, source) {
 					issues = append(issues, CheckIssue{
-						Type:     "configuration",
-						Severity: "warning",
+						Type:     IssueConfiguration,
+						Severity: SeverityWarning,
 						Message:  "Mixed case in source URL may cause matching issues",
 						Rule:     &rules[i],
 					})
@@ -332,20 +898,168 @@ func checkConfigurationIssues(rules []EdgeRuleResponse) []CheckIssue {
 				// Check for trailing slash inconsistencies
 				if strings.HasSuffix(source, "/") && source != "/" {
 					issues = append(issues, CheckIssue{
-						Type:     "configuration",
-						Severity: "info",
+						Type:     IssueConfiguration,
+						Severity: SeverityInfo,
 						Message:  "Source URL has trailing slash - ensure this matches expected traffic",
 						Rule:     &rules[i],
 					})
 				}
+
+				// Check for wildcard sources collapsing onto a fixed page
+				destination := stripPreservedQuery(rule.ActionParameter1)
+				if hasPathWildcard(source) && destination != "" && !hasPathPlaceholder(destination) && !strings.HasSuffix(destination, "/") {
+					issues = append(issues, CheckIssue{
+						Type:     IssueConfiguration,
+						Severity: SeverityWarning,
+						Message:  "Wildcard source redirects to a fixed page - the matched remainder of the path is dropped",
+						Rule:     &rules[i],
+					})
+				}
+
+				// Check for query preservation on a destination that already has a query string
+				if preservesQuery(rule.ActionParameter1) && strings.Contains(destination, "?") {
+					issues = append(issues, CheckIssue{
+						Type:     IssueConfiguration,
+						Severity: SeverityWarning,
+						Message:  "Destination already contains '?' and query preservation is enabled - this produces malformed URLs",
+						Rule:     &rules[i],
+					})
+				}
+			}
+		}
+	}
+
+	// Check for block rules competing with redirects for the same paths
+	issues = append(issues, checkBlockRedirectOverlaps(rules)...)
+
+	// Check for destinations that parse badly or carry a malformed query string
+	issues = append(issues, checkDestinationSyntax(rules)...)
+
+	// Check for MatchAll triggers that no request can satisfy
+	issues = append(issues, checkMatchAllContradictions(rules)...)
+
+	return issues
+}
+
+// slashVariant returns the source that differs from a redirect source only by a trailing slash.
+// Root and wildcard sources have no meaningful variant, a query string stays attached to the variant.
+func slashVariant(source string) (string, bool) {
+	if source == "" || strings.Contains(source, "*") {
+		return "", false
+	}
+	base, query, hasQuery := strings.Cut(source, "?")
+	if triggerPatternPath(base) == "/" {
+		return "", false
+	}
+
+	variant := base + "/"
+	if strings.HasSuffix(base, "/") {
+		variant = strings.TrimSuffix(base, "/")
+	}
+	if hasQuery {
+		variant += "?" + query
+	}
+	return variant, true
+}
+
+// slashPairKey identifies the two trailing-slash variants of a source as one pair
+func slashPairKey(source string) string {
+	base, query, hasQuery := strings.Cut(source, "?")
+	key := normalizeURL(base)
+	if hasQuery {
+		key += "?" + query
+	}
+	return key
+}
+
+// checkTrailingSlashPairs pairs redirect sources that differ only by a trailing slash.
+// Both variants with the same destination can be consolidated, different destinations conflict,
+// and a single variant leaves the other one unredirected unless matchesTriggerPattern considers them one trigger.
+func checkTrailingSlashPairs(rules []EdgeRuleResponse, zoneName string) []CheckIssue {
+	type variant struct {
+		pattern string
+		rule    *EdgeRuleResponse
+	}
+
+	pairs := make(map[string]map[bool][]variant)
+	var order []string
+	for i, rule := range rules {
+		if rule.ActionType != 1 || !rule.Enabled {
+			continue
+		}
+		for _, pattern := range urlTriggerPatterns(rule) {
+			if _, ok := slashVariant(pattern); !ok {
+				continue
+			}
+			base, _, _ := strings.Cut(pattern, "?")
+			key := slashPairKey(pattern)
+			if pairs[key] == nil {
+				pairs[key] = make(map[bool][]variant)
+				order = append(order, key)
 			}
+			withSlash := strings.HasSuffix(base, "/")
+			pairs[key][withSlash] = append(pairs[key][withSlash], variant{pattern: pattern, rule: &rules[i]})
 		}
 	}
 
+	if zoneName == "" {
+		zoneName = "PULL_ZONE_NAME"
+	}
+
+	var issues []CheckIssue
+	for _, key := range order {
+		without, with := pairs[key][false], pairs[key][true]
+
+		if len(without) == 0 || len(with) == 0 {
+			present := append(without, with...)[0]
+			missing, _ := slashVariant(present.pattern)
+			if matchesTriggerPattern(present.pattern, triggerPatternPath(missing)) {
+				continue // the trigger matcher treats both variants as one trigger
+			}
+			suggestion := formatAddRedirectCommand(zoneName, missing, stripPreservedQuery(present.rule.ActionParameter1))
+			if preservesQuery(present.rule.ActionParameter1) {
+				suggestion += " --preserve-query"
+			}
+			issues = append(issues, CheckIssue{
+				Type:     IssueConfiguration,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("Only %s is redirected, %s is not covered", present.pattern, missing),
+				Rule:     present.rule,
+				Details:  map[string]interface{}{"suggestion": suggestion},
+			})
+			continue
+		}
+
+		first, second := without[0], with[0]
+		if first.rule == second.rule {
+			continue // one rule already covers both variants
+		}
+		if first.rule.ActionParameter1 == second.rule.ActionParameter1 {
+			issues = append(issues, CheckIssue{
+				Type:     IssueConfiguration,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("%s and %s redirect to the same destination in separate rules - consolidate them into one rule with both patterns", first.pattern, second.pattern),
+				Rule:     first.rule,
+				Details:  map[string]interface{}{"other_rule": second.rule.Guid},
+			})
+			continue
+		}
+		issues = append(issues, CheckIssue{
+			Type:     IssueConfiguration,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s and %s redirect to different destinations (%s vs %s)", first.pattern, second.pattern, first.rule.ActionParameter1, second.rule.ActionParameter1),
+			Rule:     first.rule,
+			Details:  map[string]interface{}{"other_rule": second.rule.Guid},
+		})
+	}
+
 	return issues
 }
 
-func checkSecurityIssues(rules []EdgeRuleResponse, zoneHostnames []Hostname) []CheckIssue {
+// checkSecurityIssues reports suspicious destinations, redirects to external domains and HTTPS to HTTP downgrades.
+// Without an allowlist every external destination is an info issue; with one, allowed hosts are not reported and all others are warnings.
+// http:// destinations on the zone's hostnames or the canonical host are warnings suggesting the https URL.
+func checkSecurityIssues(rules []EdgeRuleResponse, zoneHostnames []Hostname, allowlist *HostAllowlist, canonical string) []CheckIssue {
 	var issues []CheckIssue
 
 	for i, rule := range rules {
@@ -355,8 +1069,8 @@ func checkSecurityIssues(rules []EdgeRuleResponse, zoneHostnames []Hostname) []C
 			// Check for suspicious patterns
 			if suspicious, reason := isSuspiciousURL(destination); suspicious {
 				issues = append(issues, CheckIssue{
-					Type:     "security",
-					Severity: "warning",
+					Type:     IssueSecurity,
+					Severity: SeverityWarning,
 					Message:  fmt.Sprintf("Suspicious destination URL: %s", reason),
 					Rule:     &rules[i],
 				})
@@ -366,18 +1080,20 @@ func checkSecurityIssues(rules []EdgeRuleResponse, zoneHostnames []Hostname) []C
 			destURL, err := url.Parse(destination)
 			if err == nil && destURL.Host != "" {
 				// This is an absolute URL - check if it's actually external
-				isExternal := true
-				for _, hostname := range zoneHostnames {
-					if strings.EqualFold(destURL.Host, hostname.Value) {
-						isExternal = false
-						break
-					}
-				}
-
-				if isExternal {
+				switch {
+				case isZoneHost(destURL.Host, zoneHostnames), allowlist.allows(destURL.Host):
+				case allowlist != nil:
 					issues = append(issues, CheckIssue{
-						Type:     "security",
-						Severity: "info",
+						Type:     IssueSecurity,
+						Severity: SeverityWarning,
+						Message:  "Redirect to external domain not on the allowlist",
+						Rule:     &rules[i],
+						Details:  map[string]interface{}{"external_host": destURL.Host},
+					})
+				default:
+					issues = append(issues, CheckIssue{
+						Type:     IssueSecurity,
+						Severity: SeverityInfo,
 						Message:  "Open redirect to external domain detected",
 						Rule:     &rules[i],
 						Details:  map[string]interface{}{"external_host": destURL.Host},
@@ -390,11 +1106,19 @@ func checkSecurityIssues(rules []EdgeRuleResponse, zoneHostnames []Hostname) []C
 				source := extractSourceURL(rule)
 				if strings.Contains(strings.ToLower(source), "https://") {
 					issues = append(issues, CheckIssue{
-						Type:     "security",
-						Severity: "error",
+						Type:     IssueSecurity,
+						Severity: SeverityError,
 						Message:  "HTTPS to HTTP downgrade detected - security risk",
 						Rule:     &rules[i],
 					})
+				} else if secure, ok := ownHostHTTPS(destination, zoneHostnames, canonical); ok {
+					issues = append(issues, CheckIssue{
+						Type:     IssueSecurity,
+						Severity: SeverityWarning,
+						Message:  fmt.Sprintf("Insecure http:// destination on an own hostname causes an extra hop, use %s", secure),
+						Rule:     &rules[i],
+						Details:  map[string]interface{}{"suggested": secure},
+					})
 				}
 			}
 		}
@@ -403,129 +1127,612 @@ func checkSecurityIssues(rules []EdgeRuleResponse, zoneHostnames []Hostname) []C
 	return issues
 }
 
-func checkRedirectLoops(redirectMap *RedirectMap) []CheckIssue {
-	var issues []CheckIssue
+// ownHostHTTPS returns the https URL of an http:// destination on one of the zone's hostnames or the canonical host.
+// Relative destinations and other hosts return false.
+func ownHostHTTPS(destination string, zoneHostnames []Hostname, canonical string) (string, bool) {
+	destURL, err := url.Parse(destination)
+	if err != nil || !strings.EqualFold(destURL.Scheme, "http") || destURL.Host == "" {
+		return "", false
+	}
+	own := isZoneHost(destURL.Host, zoneHostnames) || (canonical != "" && canonicalHost(destURL.Host) == canonicalHost(canonical))
+	if !own {
+		return "", false
+	}
+	return "https" + destination[len(destURL.Scheme):], true
+}
+
+// canonicalHost lowercases a host and strips the port and a trailing dot
+func canonicalHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// isZoneHost reports whether a host (optionally with port) is one of the zone's hostnames, including its b-cdn.net system hostname
+func isZoneHost(host string, zoneHostnames []Hostname) bool {
+	host = canonicalHost(host)
+	for _, hostname := range zoneHostnames {
+		if canonicalHost(hostname.Value) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// internalDestinationPath rewrites an absolute destination on one of the zone's own hostnames to path form,
+// because such traffic re-enters the zone and can hit another rule
+func internalDestinationPath(destination string, zoneHostnames []Hostname) (string, bool) {
+	destURL, err := url.Parse(destination)
+	if err != nil || destURL.Host == "" || !isZoneHost(destURL.Host, zoneHostnames) {
+		return destination, false
+	}
+	path := destURL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return path, true
+}
 
+// loopURLKey normalizes a redirect source or destination for loop detection: URLs on the zone's own hostnames
+// become paths regardless of scheme, then case and a trailing slash are ignored
+func loopURLKey(rawURL string, zoneHostnames []Hostname) string {
+	path, _ := internalDestinationPath(rawURL, zoneHostnames)
+	return normalizeURL(path)
+}
+
+// urlScheme returns the lowercase scheme of an absolute http or https URL, "" for a path
+func urlScheme(rawURL string) string {
+	lower := strings.ToLower(rawURL)
+	for _, scheme := range []string{"http", "https"} {
+		if strings.HasPrefix(lower, scheme+"://") {
+			return scheme
+		}
+	}
+	return ""
+}
+
+// normalizedRedirectMap returns the redirect map with sources and destinations normalized by loopURLKey.
+// When several sources normalize to the same key, the rule Bunny evaluates first wins.
+func normalizedRedirectMap(redirectMap *RedirectMap, zoneHostnames []Hostname) *RedirectMap {
+	rm := &RedirectMap{
+		SourceToDestination: make(map[string]string, len(redirectMap.SourceToDestination)),
+		Rules:               make(map[string]*EdgeRuleResponse, len(redirectMap.Rules)),
+	}
 	for source, destination := range redirectMap.SourceToDestination {
-		visited := make(map[string]bool)
-		current := destination
-		chainLength := 0
-
-		// Follow the redirect chain
-		for {
-			chainLength++
-			if chainLength > 10 {
-				issues = append(issues, CheckIssue{
-					Type:     "redirect_chain",
-					Severity: "error",
-					Message:  "Redirect chain too long (>10 hops)",
-					Rule:     redirectMap.Rules[source],
-				})
+		key := loopURLKey(source, zoneHostnames)
+		rule := redirectMap.Rules[source]
+		if existing, ok := rm.Rules[key]; ok && (existing.OrderIndex < rule.OrderIndex || (existing.OrderIndex == rule.OrderIndex && existing.Guid < rule.Guid)) {
+			continue
+		}
+		rm.SourceToDestination[key] = loopURLKey(destination, zoneHostnames)
+		rm.Rules[key] = rule
+	}
+	return rm
+}
+
+// ChainLimits are the redirect chain lengths in hops above which checkRedirectLoops warns and reports an error
+type ChainLimits struct {
+	Warn int
+	Max  int
+}
+
+// defaultChainLimits warns about any chain and fails chains longer than 10 hops
+var defaultChainLimits = ChainLimits{Warn: 1, Max: 10}
+
+// newChainLimits validates the --warn-chain and --max-chain values
+func newChainLimits(warn, maxHops int) (ChainLimits, error) {
+	if maxHops < 1 {
+		return ChainLimits{}, fmt.Errorf("--max-chain must be at least 1, got %d", maxHops)
+	}
+	if warn < 0 {
+		return ChainLimits{}, fmt.Errorf("--warn-chain must not be negative, got %d", warn)
+	}
+	return ChainLimits{Warn: warn, Max: maxHops}, nil
+}
+
+// redirectLoop is a cycle of the redirect map: the sources on it in loop order, starting at the lowest, and the sources leading into it
+type redirectLoop struct {
+	sources []string
+	entries []string
+}
+
+// chain returns the URLs of the loop in order, back to its first source
+func (l *redirectLoop) chain() []string {
+	return append(slices.Clone(l.sources), l.sources[0])
+}
+
+// reportedAt is the source the loop is reported for: its first source, or for a self redirect, which checkSelfRedirects
+// reports, the first source leading into it. "" when nothing is left to report.
+func (l *redirectLoop) reportedAt() string {
+	if len(l.sources) > 1 {
+		return l.sources[0]
+	}
+	if len(l.entries) > 0 {
+		return slices.Min(l.entries)
+	}
+	return ""
+}
+
+// checkRedirectLoops reports loops and chains longer than the limits in one pass over the redirect map.
+// Every source has a single destination, so a walk from a source ends at a URL redirecting no further, at a source an earlier
+// walk resolved, or back on its own path, which closes a loop. Each loop is reported once, listing its sources and the sources leading into it;
+// chains are reported for every source with the URLs of the chain in order.
+// Sources and destinations are compared normalized, so /old, /old/ and https://www.example.com/old on a zone hostname are one URL.
+func checkRedirectLoops(redirectMap *RedirectMap, zoneHostnames []Hostname, limits ChainLimits) []CheckIssue {
+	redirectMap = normalizedRedirectMap(redirectMap, zoneHostnames)
+	graph := redirectMap.SourceToDestination
+
+	sources := make([]string, 0, len(graph))
+	for source := range graph {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	hops := make(map[string]int, len(graph))             // redirects until a URL redirecting no further, -1 for sources ending in a loop
+	loopOf := make(map[string]*redirectLoop, len(graph)) // loop a source is on or leads into
+	onPath := make(map[string]int)                       // position of a source on the current walk
+	for _, start := range sources {
+		var path []string
+		for current := start; ; current = graph[current] {
+			if _, resolved := hops[current]; resolved {
 				break
 			}
-
-			if visited[current] {
-				issues = append(issues, CheckIssue{
-					Type:     "redirect_loop",
-					Severity: "error",
-					Message:  "Infinite redirect loop detected",
-					Rule:     redirectMap.Rules[source],
-					Details:  map[string]interface{}{"loop_url": current},
-				})
+			if _, redirects := graph[current]; !redirects {
 				break
 			}
-
-			visited[current] = true
-
-			// Check if current destination is also a source for another redirect
-			nextDest, exists := redirectMap.SourceToDestination[current]
-			if !exists {
-				if chainLength > 1 {
-					issues = append(issues, CheckIssue{
-						Type:     "redirect_chain",
-						Severity: "warning",
-						Message:  fmt.Sprintf("Redirect chain detected (%d hops)", chainLength),
-						Rule:     redirectMap.Rules[source],
-					})
+			if pos, seen := onPath[current]; seen {
+				loop := &redirectLoop{sources: rotateToMin(path[pos:])}
+				for _, source := range loop.sources {
+					hops[source] = -1
+					loopOf[source] = loop
 				}
+				path = path[:pos]
 				break
 			}
+			onPath[current] = len(path)
+			path = append(path, current)
+		}
 
-			current = nextDest
+		for i := len(path) - 1; i >= 0; i-- {
+			source := path[i]
+			delete(onPath, source)
+			next, resolved := hops[graph[source]]
+			switch {
+			case !resolved:
+				hops[source] = 1
+			case next < 0:
+				hops[source] = -1
+				loopOf[source] = loopOf[graph[source]]
+				loopOf[source].entries = append(loopOf[source].entries, source)
+			default:
+				hops[source] = next + 1
+			}
+		}
+	}
+
+	var issues []CheckIssue
+	for _, source := range sources {
+		if hops[source] < 0 {
+			loop := loopOf[source]
+			if loop.reportedAt() != source {
+				continue
+			}
+			details := map[string]interface{}{"loop_url": loop.sources[0], "chain": loop.chain(), "sources": loop.sources}
+			message := "Infinite redirect loop detected"
+			if len(loop.entries) > 0 {
+				entries := slices.Sorted(slices.Values(loop.entries))
+				details["entry_points"] = entries
+				message += fmt.Sprintf(", %d more sources redirect into it", len(entries))
+			}
+			issues = append(issues, CheckIssue{
+				Type:     IssueRedirectLoop,
+				Severity: SeverityError,
+				Message:  message,
+				Rule:     redirectMap.Rules[source],
+				Details:  details,
+			})
+			continue
+		}
+
+		if hops[source] <= limits.Warn {
+			continue
+		}
+		chain := []string{source}
+		for current := source; len(chain) <= min(hops[source], limits.Max); {
+			current = graph[current]
+			chain = append(chain, current)
+		}
+		if hops[source] > limits.Max {
+			issues = append(issues, CheckIssue{
+				Type:     IssueRedirectChain,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Redirect chain too long (>%d hops)", limits.Max),
+				Rule:     redirectMap.Rules[source],
+				Details:  map[string]interface{}{"chain": chain},
+			})
+		} else {
+			issues = append(issues, CheckIssue{
+				Type:     IssueRedirectChain,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Redirect chain detected (%d hops)", hops[source]),
+				Rule:     redirectMap.Rules[source],
+				Details:  map[string]interface{}{"chain": chain},
+			})
 		}
 	}
 
 	return issues
 }
 
-func checkURLHealth(ctx context.Context, rules []EdgeRuleResponse) []CheckIssue {
-	var issues []CheckIssue
+// rotateToMin returns the loop starting at its lowest source, keeping the loop order
+func rotateToMin(loop []string) []string {
+	lowest := 0
+	for i, source := range loop {
+		if source < loop[lowest] {
+			lowest = i
+		}
+	}
+	return append(slices.Clone(loop[lowest:]), loop[:lowest]...)
+}
 
+// checkSelfRedirects flags enabled redirects whose destination is their own source once both are normalized,
+// every request to the source redirects to itself. A trigger for one scheme redirecting to the other, like an http to https upgrade, is no self redirect.
+func checkSelfRedirects(rules []EdgeRuleResponse, zoneHostnames []Hostname) []CheckIssue {
+	var issues []CheckIssue
 	for i, rule := range rules {
-		if rule.ActionType == 1 && rule.ActionParameter1 != "" {
-			destination := rule.ActionParameter1
-
-			// Skip relative URLs for health checks
-			if !strings.HasPrefix(destination, "http") {
+		if rule.ActionType != 1 || rule.ActionParameter1 == "" || !rule.Enabled {
+			continue
+		}
+		destination := stripPreservedQuery(rule.ActionParameter1)
+		if hasPathPlaceholder(destination) {
+			continue
+		}
+		destinationKey := loopURLKey(destination, zoneHostnames)
+		for _, pattern := range urlTriggerPatterns(rule) {
+			if strings.Contains(pattern, "*") || loopURLKey(pattern, zoneHostnames) != destinationKey {
 				continue
 			}
-
-			// Validate domain first
-			if !isValidDomain(destination) {
-				issues = append(issues, CheckIssue{
-					Type:     "url_health",
-					Severity: "error",
-					Message:  "Invalid destination URL format",
-					Rule:     &rules[i],
-				})
+			if scheme := urlScheme(pattern); scheme != "" && urlScheme(destination) != "" && scheme != urlScheme(destination) {
 				continue
 			}
+			issues = append(issues, CheckIssue{
+				Type:     IssueSelfRedirect,
+				Severity: SeverityCritical,
+				Message:  fmt.Sprintf("Redirect from %s to %s redirects to itself", pattern, rule.ActionParameter1),
+				Rule:     &rules[i],
+				Details:  map[string]interface{}{"source": pattern},
+			})
+			break
+		}
+	}
+	return issues
+}
+
+// healthCheckWorkers bounds the number of concurrent destination probes
+const healthCheckWorkers = 8
+
+// runHealthWorkers calls work for every job on healthCheckWorkers goroutines and returns once all jobs are done
+func runHealthWorkers(jobs int, work func(job int)) {
+	runWorkers(healthCheckWorkers, jobs, work)
+}
+
+// runWorkers calls work for every job on the given number of goroutines and returns once all jobs are done
+func runWorkers(workers, jobs int, work func(job int)) {
+	jobIndexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobIndexes {
+				work(job)
+			}
+		}()
+	}
+	for job := range jobs {
+		jobIndexes <- job
+	}
+	close(jobIndexes)
+	wg.Wait()
+}
 
-			// Perform health check
-			statusCode, hasRedirect, err := performHealthCheck(ctx, destination)
-			if err != nil {
-				issues = append(issues, CheckIssue{
-					Type:     "url_health",
-					Severity: "error",
-					Message:  fmt.Sprintf("URL health check failed: %v", err),
-					Rule:     &rules[i],
-				})
-				continue
+// HealthRun is the outcome of the destination health checks
+type HealthRun struct {
+	Issues  []CheckIssue
+	Skipped int                 // probes not started or abandoned because the context was cancelled
+	Timings []DestinationTiming // response times of the destinations that answered, in rule order
+}
+
+// isHealthCheckCandidate reports whether a rule's destination gets an HTTP health check
+func isHealthCheckCandidate(rule EdgeRuleResponse) bool {
+	// Skip relative URLs for health checks
+	return rule.ActionType == 1 && strings.HasPrefix(rule.ActionParameter1, "http")
+}
+
+// checkDestinationHealth probes the destination of one redirect rule and returns its findings and time to first byte,
+// 0 when no request was made. cancelled reports a probe abandoned because ctx was cancelled.
+func checkDestinationHealth(ctx context.Context, rule *EdgeRuleResponse, resolver *hostResolver, detector *ParkingDetector, prober *healthProber) ([]CheckIssue, time.Duration, bool) {
+	var issues []CheckIssue
+	destination := rule.ActionParameter1
+
+	// Validate domain first
+	if !isValidDomain(destination) {
+		return append(issues, CheckIssue{
+			Type:     IssueURLHealth,
+			Severity: SeverityError,
+			Message:  "Invalid destination URL format",
+			Rule:     rule,
+		}), 0, false
+	}
+
+	// Resolve the host first so dead domains fail fast instead of waiting for the HTTP timeout
+	if destURL, err := url.Parse(destination); err == nil && resolver != nil {
+		if resolutionIssue := classifyResolution(resolver.resolve(ctx, destURL.Hostname()), detector); resolutionIssue != nil {
+			resolutionIssue.Rule = rule
+			issues = append(issues, *resolutionIssue)
+			if resolutionIssue.Severity == SeverityError {
+				return issues, 0, false
 			}
+		}
+	}
+
+	// Perform health check, transient failures are retried
+	response, err := prober.probe(ctx, destination)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, true
+		}
+		return append(issues, CheckIssue{
+			Type:     IssueURLHealth,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("URL health check failed: %v", err),
+			Rule:     rule,
+			Details:  healthDetails(response),
+		}), 0, false
+	}
+
+	// Check for broken URLs
+	if response.Status >= 400 {
+		severity := SeverityError
+		if response.Status >= 500 {
+			severity = SeverityCritical
+		}
+		issues = append(issues, CheckIssue{
+			Type:     IssueURLHealth,
+			Severity: severity,
+			Message:  fmt.Sprintf("Broken destination URL (HTTP %d)", response.Status),
+			Rule:     rule,
+			Details:  healthDetails(response),
+		})
+	}
+
+	// Check for additional redirects
+	if response.HasRedirect {
+		issues = append(issues, CheckIssue{
+			Type:     IssueURLHealth,
+			Severity: SeverityInfo,
+			Message:  "Destination URL itself redirects (creating a redirect chain)",
+			Rule:     rule,
+			Details:  healthDetails(response),
+		})
+	}
+
+	// Check for slow destinations, users wait for them after the redirect
+	if prober.isSlow(response.Duration) {
+		issues = append(issues, CheckIssue{
+			Type:     IssueURLHealth,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Slow destination URL (first byte after %s, threshold %s)", formatResponseTime(response.Duration), prober.slowThreshold),
+			Rule:     rule,
+			Details:  healthDetails(response),
+		})
+	}
+
+	// Check for not-found pages served with HTTP 200
+	if reason, phrase := prober.soft404Match(response); reason != "" {
+		details := healthDetails(response)
+		if phrase != "" {
+			details["phrase"] = phrase
+		}
+		issues = append(issues, CheckIssue{
+			Type:     IssueURLHealth,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Destination looks like a soft 404: %s", reason),
+			Rule:     rule,
+			Details:  details,
+		})
+	}
+
+	return issues, response.Duration, false
+}
 
-			// Check for broken URLs
-			if statusCode >= 400 {
-				severity := "error"
-				if statusCode >= 500 {
-					severity = "critical"
+// streamURLHealth probes destinations concurrently and hands every finding to onIssue as soon as it is recorded,
+// and the progress to onProgress after every destination; either may be nil.
+// Each unique destination is probed once, its findings are attached to every rule redirecting there.
+// Once ctx is cancelled outstanding probes are abandoned and the remaining rules are skipped.
+// The returned issues and timings are in rule order regardless of which probe finished first.
+func streamURLHealth(ctx context.Context, rules []EdgeRuleResponse, resolver *hostResolver, detector *ParkingDetector, prober *healthProber, onIssue func(CheckIssue), onProgress func(HealthProgress)) HealthRun {
+	results := make([][]CheckIssue, len(rules))
+	skipped := make([]bool, len(rules))
+	groups := groupByDestination(rules)
+	timings := make([]DestinationTiming, len(groups))
+
+	var reportMu sync.Mutex
+	progress := HealthProgress{Total: len(groups)}
+	runHealthWorkers(len(groups), func(g int) {
+		group := groups[g]
+		var issues []CheckIssue
+		var duration time.Duration
+		cancelled := ctx.Err() != nil
+		if !cancelled {
+			issues, duration, cancelled = checkDestinationHealth(ctx, &rules[group[0]], resolver, detector, prober)
+		}
+		if duration > 0 {
+			timings[g] = DestinationTiming{URL: rules[group[0]].ActionParameter1, Duration: duration, Rules: len(group)}
+		}
+		for _, i := range group {
+			if cancelled {
+				skipped[i] = true
+				continue
+			}
+			results[i] = sharedDestinationIssues(issues, &rules[i], len(group))
+			if onIssue != nil {
+				reportMu.Lock()
+				for _, issue := range results[i] {
+					onIssue(issue)
 				}
-				issues = append(issues, CheckIssue{
-					Type:     "url_health",
-					Severity: severity,
-					Message:  fmt.Sprintf("Broken destination URL (HTTP %d)", statusCode),
-					Rule:     &rules[i],
-				})
+				reportMu.Unlock()
+			}
+		}
+		if onProgress != nil {
+			reportMu.Lock()
+			progress.Done++
+			if hasIssueAtLeast(issues, SeverityError) {
+				progress.Failed++
 			}
+			onProgress(progress)
+			reportMu.Unlock()
+		}
+	})
 
-			// Check for additional redirects
-			if hasRedirect {
-				issues = append(issues, CheckIssue{
-					Type:     "url_health",
-					Severity: "info",
-					Message:  "Destination URL itself redirects (creating a redirect chain)",
-					Rule:     &rules[i],
-				})
+	var run HealthRun
+	for i := range rules {
+		if skipped[i] {
+			run.Skipped++
+		}
+		run.Issues = append(run.Issues, results[i]...)
+	}
+	for _, timing := range timings {
+		if timing.Duration > 0 {
+			run.Timings = append(run.Timings, timing)
+		}
+	}
+	return run
+}
+
+// groupByDestination returns the indexes of health check candidates grouped by destination, in rule order
+func groupByDestination(rules []EdgeRuleResponse) [][]int {
+	var groups [][]int
+	groupOf := make(map[string]int)
+	for i, rule := range rules {
+		if !isHealthCheckCandidate(rule) {
+			continue
+		}
+		key := destinationKey(rule.ActionParameter1)
+		if g, ok := groupOf[key]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		groupOf[key] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
+
+// destinationKey normalizes a destination URL for deduplication: scheme and host ignore case and the fragment is never sent
+func destinationKey(destination string) string {
+	parsed, err := url.Parse(destination)
+	if err != nil {
+		return destination
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	return parsed.String()
+}
+
+// sharedDestinationIssues copies the findings of a probed destination onto one of the rules redirecting there,
+// noting how many rules share the destination
+func sharedDestinationIssues(issues []CheckIssue, rule *EdgeRuleResponse, shared int) []CheckIssue {
+	if len(issues) == 0 {
+		return nil
+	}
+	copies := make([]CheckIssue, len(issues))
+	for k, issue := range issues {
+		issue.Rule = rule
+		if shared > 1 {
+			issue.Message = fmt.Sprintf("%s (destination shared by %d rules)", issue.Message, shared)
+			details := map[string]interface{}{"shared_by": shared}
+			for key, value := range issue.Details {
+				details[key] = value
 			}
+			issue.Details = details
 		}
+		copies[k] = issue
 	}
+	return copies
+}
 
-	return issues
+func checkURLHealth(ctx context.Context, rules []EdgeRuleResponse, resolver *hostResolver, detector *ParkingDetector) []CheckIssue {
+	return streamURLHealth(ctx, rules, resolver, detector, nil, nil, nil).Issues
+}
+
+// severityRank orders severities from info (0) to critical (3), unknown severities rank lowest.
+// The --fail-on threshold "never" ranks above critical, so no issue reaches it.
+func severityRank(severity string) int {
+	switch severity {
+	case "never":
+		return 4
+	case SeverityCritical:
+		return 3
+	case SeverityError:
+		return 2
+	case SeverityWarning:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// severityAtLeast reports whether a severity reaches the threshold
+func severityAtLeast(severity, threshold string) bool {
+	return severityRank(severity) >= severityRank(threshold)
+}
+
+// hasIssueAtLeast reports whether any issue reaches the threshold
+func hasIssueAtLeast(issues []CheckIssue, threshold string) bool {
+	for _, issue := range issues {
+		if severityAtLeast(issue.Severity, threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// RulesCheckOptions configures which rules checks run and how
+type RulesCheckOptions struct {
+	SkipHealth        bool
+	ParkingPatterns   []string
+	HealthSample      *HealthSample        // nil checks every destination
+	SampleSeed        string               // rotates which rules a sample covers
+	FailFast          bool                 // stop health checks once a finding reaches FailOn
+	FailOn            string               // severity threshold of --fail-on, defaults to "error"
+	SkipReason        string               // why health checks are skipped, defaults to the --skip-health flag
+	Selection         CheckSelection       // issue categories to report, nil reports all
+	HealthRetries     int                  // extra attempts for destinations failing with a network error or 5xx
+	HostConcurrency   int                  // concurrent health checks per destination host, 0 for no limit
+	HostInterval      time.Duration        // minimum spacing of health checks to one destination host
+	SlowThreshold     time.Duration        // destinations answering slower get a warning, 0 disables it
+	Soft404           *Soft404Detector     // nil skips soft 404 detection
+	ChainLimits       ChainLimits          // redirect chain lengths that warn and fail, the zero value uses defaultChainLimits
+	ExternalAllowlist *HostAllowlist       // approved external destinations, nil reports every external destination as info
+	CanonicalHost     string               // host internal redirects should land on, "" skips the canonical host check
+	HealthCache       *HealthCache         // destination results of earlier runs, nil requests every destination
+	OnProgress        func(HealthProgress) // called after every probed destination, nil for no progress
+	VerifySources     bool                 // request exact redirect sources from the origin
+	OriginHost        string               // origin to request sources from, defaults to the pull zone's origin URL
 }
 
 // checkRulesStructured performs all rules validation and returns structured results
-func checkRulesStructured(ctx context.Context, apiKey, zoneID string, skipHealth bool) (CheckResult, error) {
+func checkRulesStructured(ctx context.Context, apiKey, zoneID string, opts RulesCheckOptions) (CheckResult, error) {
 	var result CheckResult
 
+	detector, err := newParkingDetector(opts.ParkingPatterns)
+	if err != nil {
+		return result, err
+	}
+
 	// Get all edge rules
 	rules, err := listEdgeRules(ctx, apiKey, zoneID)
 	if err != nil {
@@ -541,19 +1748,140 @@ func checkRulesStructured(ctx context.Context, apiKey, zoneID string, skipHealth
 		pullZoneDetails = &PullZoneDetails{}
 	}
 
+	chainLimits := opts.ChainLimits
+	if chainLimits == (ChainLimits{}) {
+		chainLimits = defaultChainLimits
+	}
+
 	// Run all checks
 	allIssues = append(allIssues, checkBasicRedirectIssues(rules)...)
 	allIssues = append(allIssues, checkConfigurationIssues(rules)...)
-	allIssues = append(allIssues, checkSecurityIssues(rules, pullZoneDetails.Hostnames)...)
-	allIssues = append(allIssues, checkRedirectLoops(redirectMap)...)
+	allIssues = append(allIssues, checkTrailingSlashPairs(rules, pullZoneDetails.Name)...)
+	allIssues = append(allIssues, checkCanonicalHost(rules, pullZoneDetails.Hostnames, opts.CanonicalHost)...)
+	allIssues = append(allIssues, checkRuleOrdering(rules, pullZoneDetails.Name)...)
+	allIssues = append(allIssues, checkSecurityIssues(rules, pullZoneDetails.Hostnames, opts.ExternalAllowlist, opts.CanonicalHost)...)
+	allIssues = append(allIssues, checkPlaceholderOpenRedirects(rules)...)
+	allIssues = append(allIssues, checkSelfRedirects(rules, pullZoneDetails.Hostnames)...)
+	allIssues = append(allIssues, checkRedirectLoops(redirectMap, pullZoneDetails.Hostnames, chainLimits)...)
+	allIssues = append(allIssues, checkOriginURLs(rules)...)
+
+	ruleCount := fmt.Sprintf("%d rules", len(rules))
+	for _, check := range []string{"basic", "configuration", "trailing_slash", "ordering", "security", "loops", "origin"} {
+		if !opts.Selection.runsCheck(check) {
+			result.Coverage = append(result.Coverage, CheckCoverage{Check: check, Status: "skipped", Reason: notSelectedReason})
+			continue
+		}
+		result.Coverage = append(result.Coverage, CheckCoverage{Check: check, Status: "ran", Reason: ruleCount})
+	}
 
-	if !skipHealth {
-		allIssues = append(allIssues, checkURLHealth(ctx, rules)...)
+	// Other zones of the account only add info issues, so a failed listing narrows the check instead of failing it
+	if opts.Selection.runsCheck("system_hostname") {
+		systemHostCoverage := CheckCoverage{Check: "system_hostname", Status: "ran", Reason: ruleCount}
+		accountZones, err := listPullZones(ctx, apiKey)
+		if err != nil {
+			systemHostCoverage = CheckCoverage{Check: "system_hostname", Status: "partial", Reason: "other zones not listed: " + err.Error()}
+		}
+		allIssues = append(allIssues, checkSystemHostnameDestinations(rules, pullZoneDetails, accountZones, opts.CanonicalHost)...)
+		result.Coverage = append(result.Coverage, systemHostCoverage)
+	} else {
+		result.Coverage = append(result.Coverage, CheckCoverage{Check: "system_hostname", Status: "skipped", Reason: notSelectedReason})
+	}
+	allIssues = opts.Selection.filter(allIssues)
+
+	runsHealth := opts.Selection.runsCheck("url_health")
+	runsOriginHealth := opts.Selection.runsCheck("origin_health") && countOriginRules(rules) > 0
+	if opts.SkipHealth || !runsHealth {
+		reason := opts.SkipReason
+		switch {
+		case !runsHealth:
+			reason = notSelectedReason
+		case reason == "":
+			reason = "--skip-health"
+		}
+		result.Coverage = append(result.Coverage, CheckCoverage{Check: "url_health", Status: "skipped", Reason: reason})
+	} else {
+		healthRules := rules
+		if opts.HealthSample != nil {
+			var coverage SampleCoverage
+			healthRules, coverage = sampleHealthRules(rules, *opts.HealthSample, opts.SampleSeed)
+			allIssues = append(allIssues, CheckIssue{
+				Type:     IssueURLHealth,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("Sampled health check: %s", coverage),
+				Details:  map[string]interface{}{"seed": opts.SampleSeed},
+			})
+		}
+
+		failOn := opts.FailOn
+		if failOn == "" {
+			failOn = "error"
+		}
+		healthCtx, cancelHealth := context.WithCancel(ctx)
+		defer cancelHealth()
+		if opts.FailFast && hasIssueAtLeast(allIssues, failOn) {
+			cancelHealth()
+		}
+
+		prober := newHealthProber(opts.HealthRetries, opts.SlowThreshold, newHostLimiter(opts.HostConcurrency, opts.HostInterval), opts.Soft404)
+		prober.cache = opts.HealthCache
+		run := streamURLHealth(healthCtx, healthRules, newHostResolver(), detector, prober, func(issue CheckIssue) {
+			if opts.FailFast && severityAtLeast(issue.Severity, failOn) {
+				cancelHealth()
+			}
+		}, opts.OnProgress)
+		allIssues = append(allIssues, run.Issues...)
+		result.Slowest = slowestDestinations(run.Timings, slowestListed)
+
+		candidates := 0
+		for _, rule := range healthRules {
+			if isHealthCheckCandidate(rule) {
+				candidates++
+			}
+		}
+		coverage := CheckCoverage{Check: "url_health", Status: "ran", Reason: fmt.Sprintf("%d destinations", candidates)}
+		if opts.HealthSample != nil {
+			coverage.Reason = "sampled " + sampleLabel(*opts.HealthSample)
+		}
+
+		if run.Skipped > 0 {
+			reason := fmt.Sprintf("fail fast after a finding at or above '%s'", failOn)
+			if ctx.Err() != nil {
+				reason = "aborted: " + ctx.Err().Error()
+			}
+			allIssues = append(allIssues, CheckIssue{
+				Type:     IssueURLHealth,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("Skipped %d health checks: %s", run.Skipped, reason),
+			})
+			coverage.Status = "partial"
+			coverage.Reason = fmt.Sprintf("%d of %d destinations, %s", candidates-run.Skipped, candidates, reason)
+		}
+		result.Coverage = append(result.Coverage, coverage)
+	}
+
+	if opts.VerifySources {
+		issues, coverage := runSourceVerification(ctx, rules, pullZoneDetails.OriginUrl, opts)
+		allIssues = append(allIssues, issues...)
+		result.Coverage = append(result.Coverage, coverage)
+	}
+
+	switch {
+	case runsOriginHealth && opts.SkipHealth:
+		reason := opts.SkipReason
+		if reason == "" {
+			reason = "--skip-health"
+		}
+		result.Coverage = append(result.Coverage, CheckCoverage{Check: "origin_health", Status: "skipped", Reason: reason})
+	case runsOriginHealth:
+		allIssues = append(allIssues, checkOriginHealth(ctx, rules)...)
+		result.Coverage = append(result.Coverage, CheckCoverage{Check: "origin_health", Status: "ran", Reason: fmt.Sprintf("%d origins", countOriginRules(rules))})
+	case countOriginRules(rules) > 0 && !opts.Selection.runsCheck("origin_health"):
+		result.Coverage = append(result.Coverage, CheckCoverage{Check: "origin_health", Status: "skipped", Reason: notSelectedReason})
 	}
 
 	// Separate issues from info/successful items
 	for _, issue := range allIssues {
-		if issue.Severity == "critical" || issue.Severity == "error" || issue.Severity == "warning" {
+		if issue.Severity == SeverityCritical || issue.Severity == SeverityError || issue.Severity == SeverityWarning {
 			result.Issues = append(result.Issues, issue)
 		} else {
 			result.Successful = append(result.Successful, issue)
@@ -563,9 +1891,81 @@ func checkRulesStructured(ctx context.Context, apiKey, zoneID string, skipHealth
 	return result, nil
 }
 
-func displayCheckResults(issues []CheckIssue) {
+// runSourceVerification runs --verify-sources unless health checks are skipped or the category is not selected
+func runSourceVerification(ctx context.Context, rules []EdgeRuleResponse, zoneOrigin string, opts RulesCheckOptions) ([]CheckIssue, CheckCoverage) {
+	coverage := CheckCoverage{Check: "source_verification", Status: "skipped"}
+	switch {
+	case !opts.Selection.runsCheck("source_verification"):
+		coverage.Reason = notSelectedReason
+		return nil, coverage
+	case opts.SkipHealth:
+		coverage.Reason = opts.SkipReason
+		if coverage.Reason == "" {
+			coverage.Reason = "--skip-health"
+		}
+		return nil, coverage
+	}
+
+	baseURL, err := originBaseURL(opts.OriginHost, zoneOrigin)
+	if err != nil {
+		coverage.Reason = err.Error()
+		return nil, coverage
+	}
+
+	verification := verifySources(ctx, rules, baseURL)
+	coverage.Status = "ran"
+	coverage.Reason = fmt.Sprintf("%d source paths on %s", verification.Checked, baseURL)
+	if verification.Failed > 0 || verification.Skipped > 0 {
+		total := verification.Checked + verification.Failed + verification.Skipped
+		coverage.Status = "partial"
+		coverage.Reason = fmt.Sprintf("%d of %d source paths on %s", verification.Checked, total, baseURL)
+		if verification.Err != nil {
+			coverage.Reason += ", " + verification.Err.Error()
+		}
+	}
+	return verification.Issues, coverage
+}
+
+// displayRulesDiff prints how the redirects of the target differ from the source
+func displayRulesDiff(diff RulesDiff, fromName, toName string) {
+	fmt.Printf("Comparing redirects of '%s' with '%s'\n", fromName, toName)
+	fmt.Printf("   Only in %s: %d\n", fromName, len(diff.Missing))
+	fmt.Printf("   Only in %s: %d\n", toName, len(diff.Extra))
+	fmt.Printf("   Changed: %d\n", len(diff.Changed))
+	fmt.Printf("   Unchanged: %d\n", diff.Unchanged)
+
+	for _, change := range diff.Missing {
+		fmt.Printf("\n- %s -> %s (only in %s)\n", change.Source, change.From.ActionParameter1, fromName)
+	}
+	for _, change := range diff.Extra {
+		fmt.Printf("\n+ %s -> %s (only in %s)\n", change.Source, change.To.ActionParameter1, toName)
+	}
+	for _, change := range diff.Changed {
+		fmt.Printf("\n~ %s\n", change.Source)
+		fmt.Printf("    %s: %s (%s, %s)\n", fromName, change.From.ActionParameter1, change.From.ActionParameter2, map[bool]string{true: "Enabled", false: "Disabled"}[change.From.Enabled])
+		fmt.Printf("    %s: %s (%s, %s)\n", toName, change.To.ActionParameter1, change.To.ActionParameter2, map[bool]string{true: "Enabled", false: "Disabled"}[change.To.Enabled])
+	}
+}
+
+// displayCheckResults prints how many checks passed, listing them with verbose, then the issues grouped by severity.
+// The summary counts issues only.
+func displayCheckResults(result CheckResult, verbose bool) {
+	if len(result.Successful) > 0 {
+		fmt.Fprintf(output, "%d checks passed\n", len(result.Successful))
+		if verbose {
+			for _, success := range result.Successful {
+				fmt.Fprintf(output, "   OK %s\n", success.Message)
+			}
+		}
+	}
+
+	if len(result.Suppressed) > 0 {
+		fmt.Fprintf(output, "%d known issues suppressed by the baseline\n", len(result.Suppressed))
+	}
+
+	issues := result.Issues
 	if len(issues) == 0 {
-		fmt.Printf("No issues found! All redirect rules appear to be properly configured.\n")
+		fmt.Fprintf(output, "No issues found! All redirect rules appear to be properly configured.\n")
 		return
 	}
 
@@ -577,24 +1977,24 @@ func displayCheckResults(issues []CheckIssue) {
 
 	for _, issue := range issues {
 		switch issue.Severity {
-		case "critical":
+		case SeverityCritical:
 			critical = append(critical, issue)
-		case "error":
+		case SeverityError:
 			errors = append(errors, issue)
-		case "warning":
+		case SeverityWarning:
 			warnings = append(warnings, issue)
-		case "info":
+		case SeverityInfo:
 			info = append(info, issue)
 		}
 	}
 
 	// Display summary
-	fmt.Printf("\nANALYSIS SUMMARY:\n")
-	fmt.Printf("   Critical: %d\n", len(critical))
-	fmt.Printf("   Errors: %d\n", len(errors))
-	fmt.Printf("   Warnings: %d\n", len(warnings))
-	fmt.Printf("   Info: %d\n", len(info))
-	fmt.Println()
+	fmt.Fprintf(output, "\nANALYSIS SUMMARY:\n")
+	fmt.Fprintf(output, "   Critical: %d\n", len(critical))
+	fmt.Fprintf(output, "   Errors: %d\n", len(errors))
+	fmt.Fprintf(output, "   Warnings: %d\n", len(warnings))
+	fmt.Fprintf(output, "   Info: %d\n", len(info))
+	fmt.Fprintln(output)
 
 	// Display issues by severity
 	displayIssueGroup("CRITICAL ISSUES", critical)
@@ -603,39 +2003,83 @@ func displayCheckResults(issues []CheckIssue) {
 	displayIssueGroup("INFORMATION", info)
 }
 
+// displaySuppressed lists the issues the baseline suppressed, one line each
+func displaySuppressed(issues []CheckIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Fprintf(output, "SUPPRESSED BY BASELINE (%d)\n", len(issues))
+	for _, issue := range issues {
+		fmt.Fprintf(output, "   %-8s %s", issue.Severity, issue.Message)
+		if issue.Rule != nil {
+			fmt.Fprintf(output, " (GUID %s)", issue.Rule.Guid)
+		}
+		fmt.Fprintln(output)
+	}
+	fmt.Fprintln(output)
+}
+
+// displayCoverage lists which check categories ran, ran partially or were skipped, and why
+func displayCoverage(coverage []CheckCoverage) {
+	if len(coverage) == 0 {
+		return
+	}
+	fmt.Fprintf(output, "COVERAGE\n")
+	for _, c := range coverage {
+		fmt.Fprintf(output, "   %-15s %-8s %s\n", c.Check, c.Status, c.Reason)
+	}
+	fmt.Fprintln(output)
+}
+
+// displaySlowestDestinations lists the slowest destinations of the health checks
+func displaySlowestDestinations(timings []DestinationTiming) {
+	if len(timings) == 0 {
+		return
+	}
+	fmt.Fprintf(output, "SLOWEST DESTINATIONS\n")
+	for _, timing := range timings {
+		fmt.Fprintf(output, "   %8s  %s", formatResponseTime(timing.Duration), timing.URL)
+		if timing.Rules > 1 {
+			fmt.Fprintf(output, " (%d rules)", timing.Rules)
+		}
+		fmt.Fprintln(output)
+	}
+	fmt.Fprintln(output)
+}
+
 func displayIssueGroup(title string, issues []CheckIssue) {
 	if len(issues) == 0 {
 		return
 	}
 
-	fmt.Printf("%s (%d)\n", title, len(issues))
-	fmt.Println(strings.Repeat("─", 50))
+	fmt.Fprintf(output, "%s (%d)\n", title, len(issues))
+	fmt.Fprintln(output, strings.Repeat("─", 50))
 
 	for i, issue := range issues {
-		fmt.Printf("\n[%d] %s\n", i+1, issue.Message)
+		fmt.Fprintf(output, "\n[%d] %s\n", i+1, issue.Message)
 		if issue.Rule != nil {
-			fmt.Printf("    Rule: %s\n", issue.Rule.Description)
-			fmt.Printf("    GUID: %s\n", issue.Rule.Guid)
-			fmt.Printf("    Status: %s\n", map[bool]string{true: "Enabled", false: "Disabled"}[issue.Rule.Enabled])
+			fmt.Fprintf(output, "    Rule: %s\n", issue.Rule.Description)
+			fmt.Fprintf(output, "    GUID: %s\n", issue.Rule.Guid)
+			fmt.Fprintf(output, "    Status: %s\n", map[bool]string{true: "Enabled", false: "Disabled"}[issue.Rule.Enabled])
 
 			source := extractSourceURL(*issue.Rule)
 			if source != "" {
-				fmt.Printf("    From: %s\n", source)
+				fmt.Fprintf(output, "    From: %s\n", source)
 			}
 			if issue.Rule.ActionParameter1 != "" {
-				fmt.Printf("    To: %s\n", issue.Rule.ActionParameter1)
+				fmt.Fprintf(output, "    To: %s\n", issue.Rule.ActionParameter1)
 			}
 			if issue.Rule.ActionParameter2 != "" {
-				fmt.Printf("    Status Code: %s\n", issue.Rule.ActionParameter2)
+				fmt.Fprintf(output, "    Status Code: %s\n", issue.Rule.ActionParameter2)
 			}
 		}
 
 		// Display additional details
 		if issue.Details != nil {
 			for key, value := range issue.Details {
-				fmt.Printf("    %s: %v\n", key, value)
+				fmt.Fprintf(output, "    %s: %v\n", key, value)
 			}
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(output)
 }
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -394,3 +399,1112 @@ func TestBuildRedirectMap(t *testing.T) {
 		})
 	}
 }
+
+func TestExistingRedirectGuid(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		{
+			Guid:             "redirect-guid",
+			ActionType:       1,
+			ActionParameter1: "https://example.com/new",
+			Triggers:         []Trigger{{PatternMatches: []string{"/old"}}},
+		},
+		{
+			Guid:             "origin-guid",
+			ActionType:       2,
+			ActionParameter1: "https://origin.example.com",
+			Triggers:         []Trigger{{PatternMatches: []string{"/api/*"}}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "existing redirect", source: "/old", want: "redirect-guid"},
+		{name: "no redirect for source", source: "/other", want: ""},
+		{name: "non-redirect rule is not reused", source: "/api/*", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := existingRedirectGuid(rules, tt.source); got != tt.want {
+				t.Errorf("existingRedirectGuid(%q) = %q, want %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesTriggerPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		want    bool
+	}{
+		{name: "exact path", pattern: "/old-page", path: "/old-page", want: true},
+		{name: "leading wildcard", pattern: "*/old-page", path: "/old-page", want: true},
+		{name: "trailing wildcard", pattern: "/blog/*", path: "/blog/2019/post", want: true},
+		{name: "trailing wildcard does not match parent", pattern: "/blog/*", path: "/blogs", want: false},
+		{name: "middle wildcard", pattern: "/a/*/c", path: "/a/b/c", want: true},
+		{name: "case-insensitive", pattern: "/About-Us", path: "/about-us", want: true},
+		{name: "trailing slash ignored", pattern: "/docs/", path: "/docs", want: true},
+		{name: "full URL pattern", pattern: "https://example.com/old", path: "/old", want: true},
+		{name: "wildcard scheme pattern", pattern: "*://example.com/*", path: "/anything", want: true},
+		{name: "no match", pattern: "/old", path: "/new", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesTriggerPattern(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("matchesTriggerPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRedirectPattern(t *testing.T) {
+	tests := []struct {
+		name         string
+		pattern      string
+		allowComplex bool
+		wantWarnings int
+		wantError    bool
+	}{
+		{name: "plain path", pattern: "/old-page"},
+		{name: "subtree wildcard", pattern: "/blog/*"},
+		{name: "host wildcard with subtree wildcard", pattern: "*/blog/*"},
+		{name: "host wildcard only", pattern: "*/old-page"},
+		{name: "wildcard at segment start", pattern: "/assets/*.css"},
+		{name: "wildcard at segment end", pattern: "/blog/post-*"},
+		{name: "trailing slash without wildcard warns", pattern: "/blog/", wantWarnings: 1},
+		{name: "root is not a trailing slash mistake", pattern: "/"},
+		{name: "two wildcards rejected", pattern: "/a/*/b/*", wantError: true},
+		{name: "wildcard inside segment rejected", pattern: "/foo*bar", wantError: true},
+		{name: "two wildcards allowed with flag", pattern: "/a/*/b/*", allowComplex: true},
+		{name: "wildcard inside segment allowed with flag", pattern: "/foo*bar", allowComplex: true},
+		{name: "empty pattern", pattern: " ", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := validateRedirectPattern(tt.pattern, tt.allowComplex)
+			if (err != nil) != tt.wantError {
+				t.Errorf("validateRedirectPattern(%q) error = %v, wantError %v", tt.pattern, err, tt.wantError)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("validateRedirectPattern(%q) warnings = %v, want %d", tt.pattern, warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestWildcardFixedPageCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		wantWarning bool
+	}{
+		{name: "wildcard to fixed page", source: "/blog/*", destination: "https://blog.example.com/index.html", wantWarning: true},
+		{name: "wildcard to directory", source: "/blog/*", destination: "https://blog.example.com/", wantWarning: false},
+		{name: "wildcard with capture", source: "/blog/*", destination: "https://example.com/articles/$1", wantWarning: false},
+		{name: "wildcard with variable", source: "/blog/*", destination: "https://example.com%{Url.Path}", wantWarning: false},
+		{name: "host wildcard is not a path wildcard", source: "*/old-page", destination: "https://example.com/new-page", wantWarning: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []EdgeRuleResponse{{
+				ActionType:       1,
+				ActionParameter1: tt.destination,
+				ActionParameter2: "302",
+				Triggers:         []Trigger{{PatternMatches: []string{tt.source}}},
+			}}
+			found := false
+			for _, issue := range checkConfigurationIssues(rules) {
+				if issue.Severity == "warning" && strings.Contains(issue.Message, "Wildcard source") {
+					found = true
+				}
+			}
+			if found != tt.wantWarning {
+				t.Errorf("wildcard fixed page warning = %v, want %v", found, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestPreservedQuery(t *testing.T) {
+	destination := withPreservedQuery("https://example.com/new")
+	if destination != "https://example.com/new"+queryPreserveSuffix {
+		t.Errorf("withPreservedQuery() = %q", destination)
+	}
+	if withPreservedQuery(destination) != destination {
+		t.Errorf("withPreservedQuery() is not idempotent: %q", withPreservedQuery(destination))
+	}
+	if !preservesQuery(destination) || preservesQuery("https://example.com/new") {
+		t.Errorf("preservesQuery() did not detect the suffix correctly")
+	}
+	if got := stripPreservedQuery(destination); got != "https://example.com/new" {
+		t.Errorf("stripPreservedQuery() = %q", got)
+	}
+}
+
+func TestPreservedQueryWithExistingQueryCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		wantWarning bool
+	}{
+		{name: "preserved query on clean destination", destination: withPreservedQuery("https://example.com/new"), wantWarning: false},
+		{name: "preserved query on destination with query", destination: withPreservedQuery("https://example.com/new?ref=old"), wantWarning: true},
+		{name: "existing query without preservation", destination: "https://example.com/new?ref=old", wantWarning: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []EdgeRuleResponse{{
+				ActionType:       1,
+				ActionParameter1: tt.destination,
+				ActionParameter2: "302",
+				Triggers:         []Trigger{{PatternMatches: []string{"/old"}}},
+			}}
+			found := false
+			for _, issue := range checkConfigurationIssues(rules) {
+				if strings.Contains(issue.Message, "query preservation") {
+					found = true
+				}
+			}
+			if found != tt.wantWarning {
+				t.Errorf("query preservation warning = %v, want %v", found, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestCaseInsensitivePatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "/About-Us", want: []string{"/About-Us", "/about-us"}},
+		{pattern: "/about-us", want: []string{"/about-us"}},
+		{pattern: "*/Blog/*", want: []string{"*/Blog/*", "*/blog/*"}},
+	}
+
+	for _, tt := range tests {
+		got := caseInsensitivePatterns(tt.pattern)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("caseInsensitivePatterns(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestMixedCaseCheckHonorsCaseInsensitiveRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		patterns    []string
+		wantWarning bool
+	}{
+		{name: "mixed case source", patterns: []string{"/About-Us"}, wantWarning: true},
+		{name: "case-insensitive source", patterns: caseInsensitivePatterns("/About-Us"), wantWarning: false},
+		{name: "lowercase source", patterns: []string{"/about-us"}, wantWarning: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []EdgeRuleResponse{{
+				ActionType:       1,
+				ActionParameter1: "https://example.com/about",
+				Triggers:         []Trigger{{PatternMatches: tt.patterns}},
+			}}
+			found := false
+			for _, issue := range checkConfigurationIssues(rules) {
+				if strings.Contains(issue.Message, "Mixed case") {
+					found = true
+				}
+			}
+			if found != tt.wantWarning {
+				t.Errorf("mixed case warning = %v, want %v", found, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestFormatTriggerType(t *testing.T) {
+	tests := []struct {
+		triggerType int
+		want        string
+	}{
+		{triggerType: 0, want: "Url"},
+		{triggerType: 1, want: "RequestHeader"},
+		{triggerType: 4, want: "CountryCode"},
+		{triggerType: 10, want: "CookieValue"},
+		{triggerType: 99, want: "Trigger99"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTriggerType(tt.triggerType); got != tt.want {
+			t.Errorf("formatTriggerType(%d) = %q, want %q", tt.triggerType, got, tt.want)
+		}
+	}
+}
+
+func TestFormatPatternMatchingType(t *testing.T) {
+	tests := []struct {
+		matchingType int
+		want         string
+	}{
+		{matchingType: 0, want: "MatchAny"},
+		{matchingType: 1, want: "MatchAll"},
+		{matchingType: 2, want: "MatchNone"},
+		{matchingType: 7, want: "Match7"},
+	}
+
+	for _, tt := range tests {
+		if got := formatPatternMatchingType(tt.matchingType); got != tt.want {
+			t.Errorf("formatPatternMatchingType(%d) = %q, want %q", tt.matchingType, got, tt.want)
+		}
+	}
+}
+
+func TestFindRuleByGuid(t *testing.T) {
+	rules := []EdgeRuleResponse{{Guid: "abc-1"}, {Guid: "def-2"}}
+
+	if rule := findRuleByGuid(rules, "DEF-2"); rule == nil || rule != &rules[1] {
+		t.Errorf("findRuleByGuid(DEF-2) = %v, want second rule", rule)
+	}
+	if rule := findRuleByGuid(rules, "missing"); rule != nil {
+		t.Errorf("findRuleByGuid(missing) = %v, want nil", rule)
+	}
+}
+
+func TestWinningRedirect(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		{Guid: "disabled", ActionType: 1, Enabled: false, Triggers: []Trigger{{PatternMatches: []string{"/blog/*"}}}},
+		{Guid: "exact", ActionType: 1, Enabled: true, Triggers: []Trigger{{PatternMatches: []string{"/blog/post"}}}},
+		{Guid: "wildcard", ActionType: 1, Enabled: true, Triggers: []Trigger{{PatternMatches: []string{"*/blog/*"}}}},
+		{Guid: "other", ActionType: 1, Enabled: true, Triggers: []Trigger{{PatternMatches: []string{"/about"}}}},
+	}
+
+	matches := findRedirectsForPath(rules, "/Blog/Post/")
+	if len(matches) != 3 {
+		t.Fatalf("findRedirectsForPath() returned %d matches, want 3", len(matches))
+	}
+	if winner := winningRedirect(matches); winner == nil || winner.Guid != "exact" {
+		t.Errorf("winningRedirect() = %v, want the first enabled match", winner)
+	}
+	if winner := winningRedirect(matches[:1]); winner != nil {
+		t.Errorf("winningRedirect() = %v, want nil when every match is disabled", winner)
+	}
+	if matches := findRedirectsForPath(rules, "/contact"); len(matches) != 0 {
+		t.Errorf("findRedirectsForPath(/contact) = %d matches, want 0", len(matches))
+	}
+}
+
+func TestURLTriggerPatterns(t *testing.T) {
+	rule := EdgeRuleResponse{Triggers: []Trigger{
+		{Type: 4, PatternMatches: []string{"DE"}},
+		{Type: 0, PatternMatches: []string{"/a", "/b"}},
+	}}
+	if got := strings.Join(urlTriggerPatterns(rule), ","); got != "/a,/b" {
+		t.Errorf("urlTriggerPatterns() = %q, want /a,/b", got)
+	}
+}
+
+// redirectRule builds an enabled 302 redirect rule for tests
+func redirectRule(guid, source, destination string) EdgeRuleResponse {
+	return EdgeRuleResponse{
+		Guid:             guid,
+		ActionType:       1,
+		ActionParameter1: destination,
+		ActionParameter2: "302",
+		Description:      "redirect " + guid,
+		Enabled:          true,
+		Triggers:         []Trigger{{Type: 0, PatternMatches: []string{source}}},
+	}
+}
+
+func TestDiffRedirectRules(t *testing.T) {
+	disabled := redirectRule("b4", "/disabled", "https://example.com/d")
+	disabled.Enabled = false
+
+	from := []EdgeRuleResponse{
+		redirectRule("a1", "/only-from", "https://example.com/1"),
+		redirectRule("a2", "/Same/", "https://example.com/2"),
+		redirectRule("a3", "/dest", "https://example.com/old"),
+		redirectRule("a4", "/disabled", "https://example.com/d"),
+		{Guid: "a5", ActionType: 5, Triggers: []Trigger{{PatternMatches: []string{"/header"}}}},
+	}
+	to := []EdgeRuleResponse{
+		redirectRule("b1", "/only-to", "https://example.com/x"),
+		redirectRule("b2", "/same", "https://example.com/2"),
+		redirectRule("b3", "/dest", "https://example.com/new"),
+		disabled,
+	}
+
+	diff := diffRedirectRules(from, to)
+
+	if len(diff.Missing) != 1 || diff.Missing[0].Source != "/only-from" || diff.Missing[0].To != nil {
+		t.Errorf("Missing = %+v", diff.Missing)
+	}
+	if len(diff.Extra) != 1 || diff.Extra[0].Source != "/only-to" || diff.Extra[0].From != nil {
+		t.Errorf("Extra = %+v", diff.Extra)
+	}
+	if len(diff.Changed) != 2 || diff.Changed[0].Source != "/dest" || diff.Changed[1].Source != "/disabled" {
+		t.Errorf("Changed = %+v", diff.Changed)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", diff.Unchanged)
+	}
+}
+
+func TestFormatActionType(t *testing.T) {
+	tests := []struct {
+		name       string
+		actionType int
+		want       string
+	}{
+		{name: "force SSL", actionType: 0, want: "ForceSSL"},
+		{name: "redirect", actionType: 1, want: "Redirect"},
+		{name: "origin URL", actionType: 2, want: "OriginUrl"},
+		{name: "block request", actionType: 4, want: "BlockRequest"},
+		{name: "set response header", actionType: 5, want: "SetResponseHeader"},
+		{name: "set request header", actionType: 6, want: "SetRequestHeader"},
+		{name: "origin storage", actionType: 17, want: "OriginStorage"},
+		{name: "unknown action", actionType: 99, want: "Action99"},
+		{name: "negative action", actionType: -1, want: "Action-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatActionType(tt.actionType); got != tt.want {
+				t.Errorf("formatActionType(%d) = %q, want %q", tt.actionType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupRulesByActionType(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		{Guid: "r1", ActionType: 5},
+		{Guid: "r2", ActionType: 1},
+		{Guid: "r3", ActionType: 5},
+		{Guid: "r4", ActionType: 4},
+	}
+
+	actionTypes, groups := groupRulesByActionType(rules)
+	if fmt.Sprint(actionTypes) != "[1 4 5]" {
+		t.Errorf("action types = %v, want [1 4 5]", actionTypes)
+	}
+	if len(groups[5]) != 2 || groups[5][0].Guid != "r1" || groups[5][1].Guid != "r3" {
+		t.Errorf("group 5 = %+v, want r1 and r3 in order", groups[5])
+	}
+}
+
+func TestIsZoneHost(t *testing.T) {
+	hostnames := []Hostname{{Value: "cdn-old.example.com"}, {Value: "site.b-cdn.net"}, {Value: "WWW.Example.com"}}
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "cdn-old.example.com", want: true},
+		{host: "CDN-Old.Example.com", want: true},
+		{host: "cdn-old.example.com:443", want: true},
+		{host: "www.example.com:8080", want: true},
+		{host: "www.example.com.", want: true},
+		{host: "site.b-cdn.net", want: true},
+		{host: "other.b-cdn.net", want: false},
+		{host: "example.com", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isZoneHost(tt.host, hostnames); got != tt.want {
+			t.Errorf("isZoneHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestInternalDestinationPath(t *testing.T) {
+	hostnames := []Hostname{{Value: "www.example.com"}, {Value: "site.b-cdn.net"}}
+
+	tests := []struct {
+		destination  string
+		want         string
+		wantInternal bool
+	}{
+		{destination: "https://www.example.com/new", want: "/new", wantInternal: true},
+		{destination: "https://WWW.EXAMPLE.COM:443/new?x=1", want: "/new", wantInternal: true},
+		{destination: "https://site.b-cdn.net", want: "/", wantInternal: true},
+		{destination: "https://partner.example.org/new", want: "https://partner.example.org/new", wantInternal: false},
+		{destination: "/relative", want: "/relative", wantInternal: false},
+	}
+
+	for _, tt := range tests {
+		got, internal := internalDestinationPath(tt.destination, hostnames)
+		if got != tt.want || internal != tt.wantInternal {
+			t.Errorf("internalDestinationPath(%q) = (%q, %v), want (%q, %v)", tt.destination, got, internal, tt.want, tt.wantInternal)
+		}
+	}
+}
+
+func TestCheckSecurityIssuesHTTPOnOwnHost(t *testing.T) {
+	hostnames := []Hostname{{Value: "example.com"}, {Value: "site.b-cdn.net"}}
+
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		canonical   string
+		want        string // suggested https URL, "" for no warning
+		wantError   bool   // HTTPS to HTTP downgrade error
+	}{
+		{name: "internal http", source: "/old", destination: "http://example.com/new?x=1", want: "https://example.com/new?x=1"},
+		{name: "system hostname", source: "/old", destination: "HTTP://site.b-cdn.net/new", want: "https://site.b-cdn.net/new"},
+		{name: "canonical host", source: "/old", destination: "http://www.example.com/new", canonical: "WWW.example.com", want: "https://www.example.com/new"},
+		{name: "internal https", source: "/old", destination: "https://example.com/new"},
+		{name: "external http", source: "/old", destination: "http://partner.example.org/new"},
+		{name: "relative", source: "/old", destination: "/new"},
+		{name: "downgrade", source: "https://example.com/old", destination: "http://example.com/new", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []EdgeRuleResponse{redirectRule("r1", tt.source, tt.destination)}
+			var suggested string
+			var downgrade bool
+			for _, issue := range checkSecurityIssues(rules, hostnames, nil, tt.canonical) {
+				switch {
+				case issue.Details["suggested"] != nil:
+					if issue.Severity != SeverityWarning {
+						t.Errorf("severity = %s, want warning", issue.Severity)
+					}
+					suggested = issue.Details["suggested"].(string)
+				case strings.Contains(issue.Message, "downgrade"):
+					downgrade = true
+				}
+			}
+			if suggested != tt.want || downgrade != tt.wantError {
+				t.Errorf("suggested = %q, downgrade = %v, want %q, %v", suggested, downgrade, tt.want, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestLoopThroughZoneHostname(t *testing.T) {
+	hostnames := []Hostname{{Value: "www.example.com"}, {Value: "site.b-cdn.net"}}
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/a", "https://WWW.example.com:443/b"),
+		redirectRule("r2", "/b", "https://site.b-cdn.net/a"),
+	}
+
+	redirectMap := buildRedirectMap(rules)
+	if issues := checkRedirectLoops(redirectMap, nil, defaultChainLimits); len(issues) != 0 {
+		t.Fatalf("redirect map without zone hostnames unexpectedly reported %d issues", len(issues))
+	}
+
+	issues := checkRedirectLoops(redirectMap, hostnames, defaultChainLimits)
+	loops := 0
+	for _, issue := range issues {
+		if issue.Type == "redirect_loop" {
+			loops++
+		}
+	}
+	if loops != 1 {
+		t.Errorf("found %d loop issues through zone hostnames, want 1", loops)
+	}
+
+	for _, issue := range checkSecurityIssues(rules, hostnames, nil, "") {
+		if strings.Contains(issue.Message, "external domain") {
+			t.Errorf("destination on a zone hostname flagged as external: %s", issue.Rule.ActionParameter1)
+		}
+	}
+}
+
+func TestFilterRules(t *testing.T) {
+	disabledBlog := redirectRule("r2", "/old-blog", "https://example.com/blog")
+	disabledBlog.Enabled = false
+	disabledBlog.Description = "Legacy"
+	disabledOther := redirectRule("r3", "/about", "https://example.com/about")
+	disabledOther.Enabled = false
+
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/news", "https://example.com/news"),
+		disabledBlog,
+		disabledOther,
+	}
+	rules[0].Description = "Moved BLOG posts"
+
+	tests := []struct {
+		name   string
+		filter RuleFilter
+		want   string
+	}{
+		{name: "no filter", filter: RuleFilter{}, want: "r1,r2,r3"},
+		{name: "disabled only", filter: RuleFilter{Disabled: true}, want: "r2,r3"},
+		{name: "match description case-insensitively", filter: RuleFilter{Match: "blog"}, want: "r1,r2"},
+		{name: "match source path", filter: RuleFilter{Match: "ABOUT"}, want: "r3"},
+		{name: "filters are combined with AND", filter: RuleFilter{Disabled: true, Match: "blog"}, want: "r2"},
+		{name: "nothing matches", filter: RuleFilter{Match: "contact"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var guids []string
+			for _, rule := range filterRules(rules, tt.filter) {
+				guids = append(guids, rule.Guid)
+			}
+			if got := strings.Join(guids, ","); got != tt.want {
+				t.Errorf("filterRules() = %q, want %q", got, tt.want)
+			}
+			if tt.filter.active() != (tt.filter != RuleFilter{}) {
+				t.Errorf("active() = %v for %+v", tt.filter.active(), tt.filter)
+			}
+		})
+	}
+}
+
+func TestSortRules(t *testing.T) {
+	noSource := EdgeRuleResponse{Guid: "none", ActionType: 1, ActionParameter1: "https://example.com/a", Description: "b"}
+
+	rules := []EdgeRuleResponse{
+		redirectRule("blog", "/blog/*", "https://example.com/c"),
+		noSource,
+		redirectRule("about-upper", "/About", "https://example.com/b"),
+		redirectRule("wildcard", "*/docs/*", "https://example.com/A"),
+		redirectRule("about-lower", "/about/", "https://example.com/d"),
+	}
+	rules[0].Description = "Zebra"
+	rules[2].Description = "apple"
+
+	tests := []struct {
+		by   string
+		want string
+	}{
+		{by: "source", want: "wildcard,about-upper,about-lower,blog,none"},
+		{by: "destination", want: "none,wildcard,about-upper,blog,about-lower"},
+		{by: "description", want: "about-upper,none,about-lower,wildcard,blog"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			sorted := append([]EdgeRuleResponse(nil), rules...)
+			sortRules(sorted, tt.by)
+			var guids []string
+			for _, rule := range sorted {
+				guids = append(guids, rule.Guid)
+			}
+			if got := strings.Join(guids, ","); got != tt.want {
+				t.Errorf("sortRules(%s) = %s, want %s", tt.by, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLessRuleSortKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "/a", b: "/b", want: true},
+		{a: "/b", b: "/a", want: false},
+		{a: "*/x", b: "/a", want: true},
+		{a: "", b: "/a", want: false},
+		{a: "/a", b: "", want: true},
+		{a: "", b: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := lessRuleSortKey(tt.a, tt.b); got != tt.want {
+			t.Errorf("lessRuleSortKey(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWriteRulesCSV(t *testing.T) {
+	quoted := redirectRule("g2", "/a", "https://example.com/a?x=1,2")
+	quoted.Description = `Moved "A", then "B"`
+	quoted.Enabled = false
+	quoted.Triggers = []Trigger{{Type: 0, PatternMatches: []string{"/a", "/A"}}, {Type: 4, PatternMatches: []string{"DE"}}}
+
+	rules := []EdgeRuleResponse{redirectRule("g1", "/old", "https://example.com/new"), quoted}
+
+	var out strings.Builder
+	if err := writeRulesCSV(&out, rules); err != nil {
+		t.Fatalf("writeRulesCSV() error = %v", err)
+	}
+
+	want := "guid,from,to,status,enabled,description\n" +
+		"g1,/old,https://example.com/new,302,true,redirect g1\n" +
+		`g2,/a|/A,"https://example.com/a?x=1,2",302,false,"Moved ""A"", then ""B"""` + "\n"
+	if out.String() != want {
+		t.Errorf("writeRulesCSV() =\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV back failed: %v", err)
+	}
+	if len(records) != 3 || records[2][5] != quoted.Description || records[2][1] != "/a|/A" || records[2][2] != quoted.ActionParameter1 {
+		t.Errorf("CSV did not round-trip: %q", records)
+	}
+}
+
+func TestSlashVariant(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+		wantOK bool
+	}{
+		{name: "adds slash", source: "/page", want: "/page/", wantOK: true},
+		{name: "removes slash", source: "/page/", want: "/page", wantOK: true},
+		{name: "root is exempt", source: "/"},
+		{name: "full URL root is exempt", source: "https://example.com/"},
+		{name: "full URL without path is exempt", source: "https://example.com"},
+		{name: "full URL path", source: "https://example.com/page", want: "https://example.com/page/", wantOK: true},
+		{name: "query string stays attached", source: "/page?ref=mail", want: "/page/?ref=mail", wantOK: true},
+		{name: "query string after slash", source: "/page/?ref=mail", want: "/page?ref=mail", wantOK: true},
+		{name: "wildcard is exempt", source: "/blog/*"},
+		{name: "host wildcard is exempt", source: "*/page"},
+		{name: "empty", source: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := slashVariant(tt.source)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("slashVariant(%q) = %q, %v, want %q, %v", tt.source, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckTrailingSlashPairs(t *testing.T) {
+	tests := []struct {
+		name           string
+		rules          []EdgeRuleResponse
+		wantSeverities []string
+		wantSuggestion string
+	}{
+		{
+			name:           "same destination in separate rules",
+			rules:          []EdgeRuleResponse{redirectRule("a", "/page", "https://example.com/new"), redirectRule("b", "/page/", "https://example.com/new")},
+			wantSeverities: []string{"info"},
+		},
+		{
+			name:           "different destinations conflict",
+			rules:          []EdgeRuleResponse{redirectRule("a", "/page", "https://example.com/new"), redirectRule("b", "/Page/", "https://example.com/other")},
+			wantSeverities: []string{"error"},
+		},
+		{
+			name:  "single variant the matcher treats as one trigger",
+			rules: []EdgeRuleResponse{redirectRule("a", "/page/", "https://example.com/new"), redirectRule("b", "https://example.com/about", "https://example.com/team")},
+		},
+		{
+			name:           "single variant with a query is not covered",
+			rules:          []EdgeRuleResponse{redirectRule("a", "/page/?ref=mail", "https://example.com/new")},
+			wantSeverities: []string{"info"},
+			wantSuggestion: "hop rules add --key YOUR_API_KEY --zone 'site' --from '/page?ref=mail' --to 'https://example.com/new'",
+		},
+		{
+			name:           "preserved query is suggested as a flag",
+			rules:          []EdgeRuleResponse{redirectRule("a", "/page?ref=mail", "https://example.com/new?%{Url.Query}")},
+			wantSeverities: []string{"info"},
+			wantSuggestion: "hop rules add --key YOUR_API_KEY --zone 'site' --from '/page/?ref=mail' --to 'https://example.com/new' --preserve-query",
+		},
+		{
+			name: "one rule with both patterns is fine",
+			rules: []EdgeRuleResponse{{Guid: "a", ActionType: 1, ActionParameter1: "https://example.com/new", ActionParameter2: "302", Enabled: true,
+				Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/page", "/page/"}}}}},
+		},
+		{
+			name:  "root and wildcard sources are exempt",
+			rules: []EdgeRuleResponse{redirectRule("a", "/", "https://example.com/"), redirectRule("b", "/blog/*", "https://example.com/blog/")},
+		},
+		{
+			name:           "query strings pair separately",
+			rules:          []EdgeRuleResponse{redirectRule("a", "/page?x=1", "https://example.com/one"), redirectRule("b", "/page/?x=1", "https://example.com/one"), redirectRule("c", "/page/?x=2", "https://example.com/two")},
+			wantSeverities: []string{"info", "info"},
+		},
+		{
+			name: "disabled rules are ignored",
+			rules: []EdgeRuleResponse{redirectRule("a", "/page", "https://example.com/new"),
+				func() EdgeRuleResponse {
+					r := redirectRule("b", "/page/", "https://example.com/other")
+					r.Enabled = false
+					return r
+				}()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := checkTrailingSlashPairs(tt.rules, "site")
+			var severities []string
+			for _, issue := range issues {
+				severities = append(severities, issue.Severity)
+			}
+			if strings.Join(severities, ",") != strings.Join(tt.wantSeverities, ",") {
+				t.Fatalf("severities = %v, want %v (%v)", severities, tt.wantSeverities, issues)
+			}
+			if tt.wantSuggestion != "" {
+				if got := issues[0].Details["suggestion"]; got != tt.wantSuggestion {
+					t.Errorf("suggestion = %v, want %s", got, tt.wantSuggestion)
+				}
+			}
+		})
+	}
+}
+
+func TestSlashPairIsNotReportedAsDuplicate(t *testing.T) {
+	rules := []EdgeRuleResponse{redirectRule("a", "/page", "https://example.com/new"), redirectRule("b", "/page/", "https://example.com/other")}
+	for _, issue := range checkConfigurationIssues(rules) {
+		if strings.HasPrefix(issue.Message, "Duplicate/conflicting") {
+			t.Errorf("unexpected duplicate issue for a trailing-slash pair: %s", issue.Message)
+		}
+	}
+}
+
+func TestCheckBasicRedirectIssuesPointAtTheirRule(t *testing.T) {
+	permanent := redirectRule("permanent", "/a", "/b")
+	permanent.ActionParameter2 = "301"
+	noDestination := redirectRule("no-destination", "/c", "")
+	noStatus := redirectRule("no-status", "/d", "/e")
+	noStatus.ActionParameter2 = ""
+	rules := []EdgeRuleResponse{permanent, redirectRule("fine", "/f", "/g"), noDestination, noStatus}
+
+	issues := checkBasicRedirectIssues(rules)
+	if len(issues) != 3 {
+		t.Fatalf("checkBasicRedirectIssues() returned %d issues, want 3", len(issues))
+	}
+	for i, want := range []string{"permanent", "no-destination", "no-status"} {
+		if issues[i].Rule.Guid != want {
+			t.Errorf("issue %d (%s) points at rule %s, want %s", i, issues[i].Message, issues[i].Rule.Guid, want)
+		}
+	}
+}
+
+func TestCheckRedirectLoopsNormalizesURLs(t *testing.T) {
+	hostnames := []Hostname{{Value: "www.example.com"}}
+	tests := []struct {
+		name  string
+		rules []EdgeRuleResponse
+	}{
+		{
+			name: "absolute URL loop",
+			rules: []EdgeRuleResponse{
+				redirectRule("r1", "/old", "https://www.example.com/new"),
+				redirectRule("r2", "/new", "https://www.example.com/old"),
+			},
+		},
+		{
+			name: "trailing slash loop",
+			rules: []EdgeRuleResponse{
+				redirectRule("r1", "/old", "/new/"),
+				redirectRule("r2", "/new", "/old/"),
+			},
+		},
+		{
+			name: "mixed http and https loop",
+			rules: []EdgeRuleResponse{
+				redirectRule("r1", "https://www.example.com/old", "http://www.example.com/new"),
+				redirectRule("r2", "http://www.example.com/new", "https://WWW.example.com/Old"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loops := 0
+			for _, issue := range checkRedirectLoops(buildRedirectMap(tt.rules), hostnames, defaultChainLimits) {
+				if issue.Type == "redirect_loop" {
+					loops++
+				}
+			}
+			if loops != 1 {
+				t.Errorf("found %d loop issues, want 1", loops)
+			}
+		})
+	}
+}
+
+func TestCheckRedirectLoopsNormalizedChain(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/a", "/b/"),
+		redirectRule("r2", "/b", "/c"),
+	}
+	issues := checkRedirectLoops(buildRedirectMap(rules), nil, defaultChainLimits)
+	if len(issues) != 1 || issues[0].Type != "redirect_chain" || issues[0].Rule.Guid != "r1" {
+		t.Errorf("issues = %+v, want one chain for r1", issues)
+	}
+}
+
+// chainRules returns redirects /p0 -> /p1 -> ... -> /p<hops>, a chain of the given number of hops starting at /p0
+func chainRules(hops int) []EdgeRuleResponse {
+	var rules []EdgeRuleResponse
+	for i := range hops {
+		rules = append(rules, redirectRule(fmt.Sprintf("r%d", i), fmt.Sprintf("/p%d", i), fmt.Sprintf("/p%d", i+1)))
+	}
+	return rules
+}
+
+func TestCheckRedirectLoopsChainLimits(t *testing.T) {
+	limits := ChainLimits{Warn: 2, Max: 4}
+	tests := []struct {
+		name         string
+		hops         int
+		wantSeverity string // of the issue for /p0, "" for none
+	}{
+		{name: "below the warning threshold", hops: 1},
+		{name: "at the warning threshold", hops: 2},
+		{name: "above the warning threshold", hops: 3, wantSeverity: "warning"},
+		{name: "at the maximum", hops: 4, wantSeverity: "warning"},
+		{name: "above the maximum", hops: 5, wantSeverity: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *CheckIssue
+			for _, issue := range checkRedirectLoops(buildRedirectMap(chainRules(tt.hops)), nil, limits) {
+				if issue.Rule.Guid == "r0" {
+					got = &issue
+				}
+			}
+			if tt.wantSeverity == "" {
+				if got != nil {
+					t.Fatalf("issue for /p0 = %+v, want none", *got)
+				}
+				return
+			}
+			if got == nil || got.Type != "redirect_chain" || got.Severity != tt.wantSeverity {
+				t.Fatalf("issue for /p0 = %+v, want a %s redirect_chain", got, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestCheckRedirectLoopsReportsChain(t *testing.T) {
+	issues := checkRedirectLoops(buildRedirectMap(chainRules(3)), nil, defaultChainLimits)
+	for _, issue := range issues {
+		if issue.Rule.Guid != "r0" {
+			continue
+		}
+		want := []string{"/p0", "/p1", "/p2", "/p3"}
+		if got, _ := issue.Details["chain"].([]string); !slices.Equal(got, want) {
+			t.Errorf("chain = %v, want %v", issue.Details["chain"], want)
+		}
+		return
+	}
+	t.Fatalf("issues = %+v, want a chain for r0", issues)
+}
+
+func TestCheckRedirectLoopsTooLongChainStopsAtMax(t *testing.T) {
+	issues := checkRedirectLoops(buildRedirectMap(chainRules(5)), nil, ChainLimits{Warn: 1, Max: 2})
+	for _, issue := range issues {
+		if issue.Rule.Guid != "r0" {
+			continue
+		}
+		if issue.Message != "Redirect chain too long (>2 hops)" {
+			t.Errorf("message = %q", issue.Message)
+		}
+		if got, _ := issue.Details["chain"].([]string); !slices.Equal(got, []string{"/p0", "/p1", "/p2"}) {
+			t.Errorf("chain = %v, want the first two hops", issue.Details["chain"])
+		}
+		return
+	}
+	t.Fatalf("issues = %+v, want a chain for r0", issues)
+}
+
+func TestCheckRedirectLoopsLoopChain(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/a", "/b"),
+		redirectRule("r2", "/b", "/a"),
+	}
+	for _, issue := range checkRedirectLoops(buildRedirectMap(rules), nil, defaultChainLimits) {
+		if issue.Rule.Guid == "r1" {
+			if got, _ := issue.Details["chain"].([]string); !slices.Equal(got, []string{"/a", "/b", "/a"}) {
+				t.Errorf("loop chain = %v", issue.Details["chain"])
+			}
+			return
+		}
+	}
+	t.Fatal("want a loop for r1")
+}
+
+func TestCheckRedirectLoopsSharedLoop(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/x", "/a"),
+		redirectRule("r2", "/y", "/a"),
+		redirectRule("r3", "/z", "/x"),
+		redirectRule("r4", "/c", "/a"),
+		redirectRule("r5", "/a", "/b"),
+		redirectRule("r6", "/b", "/c"),
+		redirectRule("r7", "/w", "/b"),
+	}
+	issues := checkRedirectLoops(buildRedirectMap(rules), nil, defaultChainLimits)
+	if len(issues) != 1 || issues[0].Type != "redirect_loop" || issues[0].Rule.Guid != "r5" {
+		t.Fatalf("issues = %+v, want one loop for /a", issues)
+	}
+	details := issues[0].Details
+	if got, _ := details["chain"].([]string); !slices.Equal(got, []string{"/a", "/b", "/c", "/a"}) {
+		t.Errorf("chain = %v", details["chain"])
+	}
+	if got, _ := details["sources"].([]string); !slices.Equal(got, []string{"/a", "/b", "/c"}) {
+		t.Errorf("sources = %v", details["sources"])
+	}
+	if got, _ := details["entry_points"].([]string); !slices.Equal(got, []string{"/w", "/x", "/y", "/z"}) {
+		t.Errorf("entry_points = %v", details["entry_points"])
+	}
+}
+
+func TestCheckRedirectLoopsIntoSelfRedirect(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("r1", "/self", "/self"),
+		redirectRule("r2", "/old", "/self"),
+	}
+	issues := checkRedirectLoops(buildRedirectMap(rules), nil, defaultChainLimits)
+	if len(issues) != 1 || issues[0].Type != "redirect_loop" || issues[0].Rule.Guid != "r2" {
+		t.Errorf("issues = %+v, want one loop for /old", issues)
+	}
+	if issues := checkRedirectLoops(buildRedirectMap(rules[:1]), nil, defaultChainLimits); len(issues) != 0 {
+		t.Errorf("self redirect reported as loop: %+v", issues)
+	}
+}
+
+func TestNewChainLimits(t *testing.T) {
+	tests := []struct {
+		warn, max int
+		wantErr   bool
+	}{
+		{warn: 1, max: 10},
+		{warn: 0, max: 1},
+		{warn: 2, max: 0, wantErr: true},
+		{warn: -1, max: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		if _, err := newChainLimits(tt.warn, tt.max); (err != nil) != tt.wantErr {
+			t.Errorf("newChainLimits(%d, %d) error = %v, wantErr %v", tt.warn, tt.max, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLoopURLKey(t *testing.T) {
+	hostnames := []Hostname{{Value: "www.example.com"}}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "/Old/", want: "/old"},
+		{url: "https://www.example.com/Old/", want: "/old"},
+		{url: "http://WWW.example.com/old", want: "/old"},
+		{url: "https://www.example.com", want: "/"},
+		{url: "https://other.example.com/Old/", want: "https://other.example.com/old"},
+	}
+	for _, tt := range tests {
+		if got := loopURLKey(tt.url, hostnames); got != tt.want {
+			t.Errorf("loopURLKey(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckSelfRedirects(t *testing.T) {
+	hostnames := []Hostname{{Value: "www.example.com"}}
+	disabled := redirectRule("disabled", "/off", "/off")
+	disabled.Enabled = false
+
+	tests := []struct {
+		name string
+		rule EdgeRuleResponse
+		want bool
+	}{
+		{name: "path only", rule: redirectRule("r", "/pricing", "/pricing"), want: true},
+		{name: "absolute URL on a zone hostname", rule: redirectRule("r", "/pricing", "https://www.example.com/pricing"), want: true},
+		{name: "trailing slash and case", rule: redirectRule("r", "/Pricing", "https://WWW.example.com/pricing/"), want: true},
+		{name: "absolute source", rule: redirectRule("r", "https://www.example.com/pricing", "https://www.example.com/pricing/"), want: true},
+		{name: "absolute source to a path keeps the scheme", rule: redirectRule("r", "http://www.example.com/pricing", "/pricing"), want: true},
+		{name: "http to https upgrade", rule: redirectRule("r", "http://www.example.com/a", "https://www.example.com/a")},
+		{name: "https to http", rule: redirectRule("r", "https://www.example.com/a", "http://www.example.com/a")},
+		{name: "preserved query", rule: redirectRule("r", "/pricing", "/pricing?%{Url.Query}"), want: true},
+		{name: "disabled rule", rule: disabled},
+		{name: "other host", rule: redirectRule("r", "/pricing", "https://shop.example.com/pricing")},
+		{name: "other path", rule: redirectRule("r", "/pricing", "/plans")},
+		{name: "wildcard source", rule: redirectRule("r", "/docs/*", "/docs/*")},
+		{name: "path placeholder", rule: redirectRule("r", "/docs", "/docs%{Url.Path}")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := checkSelfRedirects([]EdgeRuleResponse{tt.rule}, hostnames)
+			if got := len(issues) == 1; got != tt.want {
+				t.Fatalf("checkSelfRedirects() = %+v, want self redirect %v", issues, tt.want)
+			}
+			if tt.want && (issues[0].Type != "self_redirect" || issues[0].Severity != "critical") {
+				t.Errorf("issue = %s/%s, want self_redirect/critical", issues[0].Type, issues[0].Severity)
+			}
+		})
+	}
+}
+
+func TestSelfRedirectIsNotReportedAsLoop(t *testing.T) {
+	rules := []EdgeRuleResponse{redirectRule("r", "/pricing", "/pricing/")}
+	if issues := checkRedirectLoops(buildRedirectMap(rules), nil, defaultChainLimits); len(issues) != 0 {
+		t.Errorf("checkRedirectLoops() = %+v, want the self redirect left to checkSelfRedirects", issues)
+	}
+}
+
+func TestDisplayCheckResults(t *testing.T) {
+	result := CheckResult{
+		Issues: []CheckIssue{
+			{Type: IssueBasic, Severity: SeverityWarning, Message: "301 redirect detected"},
+		},
+		Successful: []CheckIssue{
+			{Type: IssueURLHealth, Severity: SeverityInfo, Message: "Destination https://example.com/a is healthy"},
+			{Type: IssueURLHealth, Severity: SeverityInfo, Message: "Destination https://example.com/b is healthy"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		result  CheckResult
+		verbose bool
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "successes are only counted",
+			result:  result,
+			want:    []string{"2 checks passed", "Warnings: 1", "Info: 0", "301 redirect detected"},
+			notWant: []string{"is healthy", "INFORMATION"},
+		},
+		{
+			name:    "verbose lists successes",
+			result:  result,
+			verbose: true,
+			want:    []string{"2 checks passed", "OK Destination https://example.com/a is healthy", "Info: 0"},
+			notWant: []string{"INFORMATION"},
+		},
+		{
+			name:    "only successes",
+			result:  CheckResult{Successful: result.Successful},
+			want:    []string{"2 checks passed", "No issues found!"},
+			notWant: []string{"ANALYSIS SUMMARY"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			previous := output
+			output = &buf
+			t.Cleanup(func() { output = previous })
+
+			displayCheckResults(tt.result, tt.verbose)
+			got := buf.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output is missing %q:\n%s", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("output contains %q:\n%s", notWant, got)
+				}
+			}
+		})
+	}
+}
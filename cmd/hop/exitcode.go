@@ -0,0 +1,26 @@
+package main
+
+// Exit codes of check, rules check, dns check and cdn check
+const (
+	exitClean    = 0
+	exitIssues   = 1 // an error or critical issue reached --fail-on
+	exitWarnings = 2 // only warnings or info reached --fail-on
+	exitFailure  = 3 // hop itself failed, e.g. an API error or bad flags
+)
+
+// checkExitCodesHelp documents the exit codes in the extended help of the check commands
+const checkExitCodesHelp = "Exit codes: 0 when no issue reaches --fail-on (default error), 1 when an error or critical issue does, " +
+	"2 when only warnings or info do (e.g. with --fail-on warning), 3 when hop itself failed (API error, bad flags)."
+
+// Side effect free functions
+
+// checkExitCode returns the exit code of a check command for its issues
+func checkExitCode(issues []CheckIssue, failOn string) int {
+	if !hasIssueAtLeast(issues, failOn) {
+		return exitClean
+	}
+	if hasIssueAtLeast(issues, SeverityError) {
+		return exitIssues
+	}
+	return exitWarnings
+}
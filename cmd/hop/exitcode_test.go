@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+)
+
+func TestCheckExitCode(t *testing.T) {
+	warning := CheckIssue{Severity: SeverityWarning}
+	errorIssue := CheckIssue{Severity: SeverityError}
+	info := CheckIssue{Severity: SeverityInfo}
+
+	tests := []struct {
+		name   string
+		issues []CheckIssue
+		failOn string
+		want   int
+	}{
+		{name: "no issues", failOn: SeverityError, want: exitClean},
+		{name: "warnings below the threshold", issues: []CheckIssue{warning}, failOn: SeverityError, want: exitClean},
+		{name: "error", issues: []CheckIssue{warning, errorIssue}, failOn: SeverityError, want: exitIssues},
+		{name: "warnings only", issues: []CheckIssue{warning, info}, failOn: SeverityWarning, want: exitWarnings},
+		{name: "info only", issues: []CheckIssue{info}, failOn: SeverityInfo, want: exitWarnings},
+		{name: "error with a lower threshold", issues: []CheckIssue{errorIssue}, failOn: SeverityWarning, want: exitIssues},
+		{name: "never", issues: []CheckIssue{errorIssue}, failOn: "never", want: exitClean},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkExitCode(tt.issues, tt.failOn); got != tt.want {
+				t.Errorf("checkExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+	// a 301 redirect is a warning
+	if err := addEdgeRule(ctx, fakeAPIKey, "1", EdgeRule{ActionType: 1, ActionParameter1: "https://example.com/new", ActionParameter2: "301", Enabled: true,
+		Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/old"}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	previousOutput, previousLog := output, log.Writer()
+	t.Cleanup(func() {
+		output = previousOutput
+		log.SetOutput(previousLog)
+	})
+	log.SetOutput(io.Discard)
+
+	rulesCheck := []string{"rules", "check", "--key", fakeAPIKey, "--zone", "prod", "--skip-health", "--format", "json"}
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{name: "warnings below --fail-on", args: rulesCheck, want: exitClean},
+		{name: "warnings only", args: append(rulesCheck, "--fail-on", "warning"), want: exitWarnings},
+		{name: "unknown zone", args: []string{"rules", "check", "--key", fakeAPIKey, "--zone", "missing", "--skip-health", "--format", "json"}, want: exitFailure},
+		{name: "bad flag value", args: append(rulesCheck, "--only", "nonsense"), want: exitFailure},
+		{name: "unknown flag", args: append(rulesCheck, "--no-such-flag"), want: exitFailure},
+		{name: "dns check without hostnames", args: []string{"dns", "check", "--key", fakeAPIKey, "--zone", "prod"}, want: exitClean},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output = io.Discard
+			if got := run(tt.args); got != tt.want {
+				t.Errorf("run(%v) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+
+	// a redirect without destination is an error
+	if err := addEdgeRule(ctx, fakeAPIKey, "1", EdgeRule{ActionType: 1, ActionParameter2: "302", Enabled: true,
+		Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/broken"}}}}); err != nil {
+		t.Fatal(err)
+	}
+	output = io.Discard
+	if got := run(rulesCheck); got != exitIssues {
+		t.Errorf("run() with an error issue = %d, want %d", got, exitIssues)
+	}
+}
@@ -0,0 +1,474 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const fakeAPIKey = "test-api-key"
+
+// fakeBunny is an in-memory stand-in for the Bunny API and storage endpoints
+type fakeBunny struct {
+	mu           sync.Mutex
+	pullZones    []PullZoneDetails
+	storageZones []StorageZone
+	dnsZones     []DNSZone
+	certificates []string
+	files        map[string]fakeFile
+	nextGuid     int
+	clock        time.Time
+	requests     []string
+	truncations  int // uploads that lose their last byte in transit, to exercise the Checksum header
+	purged       []string
+	purgeStatus  int // status of URL purge requests when set, to exercise failed purges
+	zonePurges   int
+	onUpload     func(r *http.Request) // called for every storage upload before it is stored, e.g. to interrupt a push
+	onList       func(path string)     // called for every storage directory listing before it is answered, e.g. to simulate replication lag
+}
+
+type fakeFile struct {
+	content  []byte
+	modified time.Time
+	header   http.Header // request headers of the upload, nil for files stored with putFile
+}
+
+// newFakeBunny starts a fake server and points apiBaseURL and storageBaseURL at it for the test
+func newFakeBunny(t *testing.T) *fakeBunny {
+	t.Helper()
+
+	fb := &fakeBunny{
+		files: make(map[string]fakeFile),
+		clock: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(fb.serveHTTP))
+
+	oldAPI, oldStorage := apiBaseURL, storageBaseURL
+	apiBaseURL = server.URL + "/api"
+	storageBaseURL = server.URL + "/storage"
+
+	t.Cleanup(func() {
+		server.Close()
+		apiBaseURL, storageBaseURL = oldAPI, oldStorage
+	})
+
+	return fb
+}
+
+// addZone registers a pull zone with a storage zone of the same name
+func (fb *fakeBunny) addZone(id int64, name string, hostnames ...string) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	zone := PullZoneDetails{Id: id, Name: name, OriginUrl: "https://origin.example.com", EdgeRules: []EdgeRuleResponse{}, Hostnames: []Hostname{}}
+	for i, hostname := range hostnames {
+		zone.Hostnames = append(zone.Hostnames, Hostname{Id: int64(i + 1), Value: hostname})
+	}
+	fb.pullZones = append(fb.pullZones, zone)
+	fb.storageZones = append(fb.storageZones, StorageZone{Id: id, Name: name, Password: name + "-password"})
+}
+
+// addDNSZone registers a Bunny DNS zone
+func (fb *fakeBunny) addDNSZone(id int64, domain string, records ...DNSRecord) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	fb.dnsZones = append(fb.dnsZones, DNSZone{Id: id, Domain: domain, Records: append([]DNSRecord{}, records...)})
+}
+
+// edgeRules returns a copy of the edge rules of a pull zone
+func (fb *fakeBunny) edgeRules(zoneID int64) []EdgeRuleResponse {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	for _, zone := range fb.pullZones {
+		if zone.Id == zoneID {
+			return append([]EdgeRuleResponse(nil), zone.EdgeRules...)
+		}
+	}
+	return nil
+}
+
+// putFile stores a file in the stub storage, path is "<storage zone>/<remote path>"
+func (fb *fakeBunny) putFile(path string, content []byte) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	fb.clock = fb.clock.Add(time.Second)
+	fb.files[path] = fakeFile{content: content, modified: fb.clock}
+}
+
+// fileNames returns the sorted paths of all stored files
+func (fb *fakeBunny) fileNames() []string {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	var names []string
+	for name := range fb.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uploadHeader returns a request header of the upload that stored a file, "" when it was not uploaded
+func (fb *fakeBunny) uploadHeader(path, name string) string {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	return fb.files[path].header.Get(name)
+}
+
+// requestLog returns every request received as "METHOD path"
+func (fb *fakeBunny) requestLog() []string {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	return append([]string(nil), fb.requests...)
+}
+
+func (fb *fakeBunny) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fb.mu.Lock()
+	fb.requests = append(fb.requests, r.Method+" "+r.URL.Path)
+	fb.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/"):
+		if r.Header.Get("AccessKey") != fakeAPIKey {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fb.serveAPI(w, r, strings.TrimPrefix(r.URL.Path, "/api"))
+	case strings.HasPrefix(r.URL.Path, "/storage/"):
+		fb.serveStorage(w, r, strings.TrimPrefix(r.URL.Path, "/storage/"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (fb *fakeBunny) serveAPI(w http.ResponseWriter, r *http.Request, path string) {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case r.Method == "GET" && path == "/pullzone":
+		var zones []PullZone
+		for _, zone := range fb.pullZones {
+			zones = append(zones, PullZone{Id: zone.Id, Name: zone.Name})
+		}
+		writeJSON(w, zones)
+	case r.Method == "POST" && path == "/pullzone":
+		var request struct {
+			Name      string `json:"Name"`
+			OriginUrl string `json:"OriginUrl"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id := int64(1)
+		for _, zone := range fb.pullZones {
+			id = max(id, zone.Id+1)
+		}
+		zone := PullZoneDetails{Id: id, Name: request.Name, OriginUrl: request.OriginUrl, EdgeRules: []EdgeRuleResponse{}, Hostnames: []Hostname{}}
+		fb.pullZones = append(fb.pullZones, zone)
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, PullZone{Id: zone.Id, Name: zone.Name})
+	case r.Method == "POST" && path == "/purge":
+		if fb.purgeStatus != 0 {
+			http.Error(w, "purge failed", fb.purgeStatus)
+			return
+		}
+		fb.purged = append(fb.purged, r.URL.Query().Get("url"))
+		w.WriteHeader(http.StatusOK)
+	case r.Method == "POST" && len(parts) == 3 && parts[0] == "pullzone" && parts[2] == "purgeCache":
+		if fb.findZone(parts[1]) == nil {
+			http.NotFound(w, r)
+			return
+		}
+		fb.zonePurges++
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "GET" && path == "/storagezone":
+		writeJSON(w, fb.storageZones)
+	case r.Method == "GET" && path == "/dnszone":
+		writeJSON(w, DNSZoneListResponse{Items: fb.dnsZones, CurrentPage: 1, TotalItems: len(fb.dnsZones)})
+	case r.Method == "POST" && len(parts) == 3 && parts[0] == "pullzone" && parts[2] == "addHostname":
+		zone := fb.findZone(parts[1])
+		if zone == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var request struct {
+			Hostname string `json:"Hostname"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Hostname == "" {
+			http.Error(w, "missing hostname", http.StatusBadRequest)
+			return
+		}
+		zone.Hostnames = append(zone.Hostnames, Hostname{Id: int64(len(zone.Hostnames) + 1), Value: request.Hostname})
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "GET" && path == "/pullzone/loadFreeCertificate":
+		hostname := r.URL.Query().Get("hostname")
+		attached := false
+		for _, zone := range fb.pullZones {
+			if hasHostname(zone.Hostnames, hostname) {
+				attached = true
+			}
+		}
+		if !attached {
+			http.Error(w, "hostname not attached", http.StatusBadRequest)
+			return
+		}
+		fb.certificates = append(fb.certificates, hostname)
+	case r.Method == "PUT" && len(parts) == 3 && parts[0] == "dnszone" && parts[2] == "records":
+		var record DNSRecord
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for i := range fb.dnsZones {
+			if strconv.FormatInt(fb.dnsZones[i].Id, 10) == parts[1] {
+				record.Id = int64(len(fb.dnsZones[i].Records) + 1)
+				fb.dnsZones[i].Records = append(fb.dnsZones[i].Records, record)
+				w.WriteHeader(http.StatusCreated)
+				writeJSON(w, record)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case r.Method == "GET" && len(parts) == 2 && parts[0] == "pullzone":
+		zone := fb.findZone(parts[1])
+		if zone == nil {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, zone)
+	case r.Method == "POST" && len(parts) == 4 && parts[0] == "pullzone" && parts[2] == "edgerules" && parts[3] == "addOrUpdate":
+		zone := fb.findZone(parts[1])
+		if zone == nil {
+			http.NotFound(w, r)
+			return
+		}
+		var rule EdgeRuleResponse
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fb.upsertRule(zone, rule)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "DELETE" && len(parts) == 4 && parts[0] == "pullzone" && parts[2] == "edgerules":
+		zone := fb.findZone(parts[1])
+		if zone == nil {
+			http.NotFound(w, r)
+			return
+		}
+		for i, rule := range zone.EdgeRules {
+			if rule.Guid == parts[3] {
+				zone.EdgeRules = append(zone.EdgeRules[:i], zone.EdgeRules[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (fb *fakeBunny) findZone(id string) *PullZoneDetails {
+	zoneID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for i := range fb.pullZones {
+		if fb.pullZones[i].Id == zoneID {
+			return &fb.pullZones[i]
+		}
+	}
+	return nil
+}
+
+func (fb *fakeBunny) upsertRule(zone *PullZoneDetails, rule EdgeRuleResponse) {
+	if rule.Guid != "" {
+		for i := range zone.EdgeRules {
+			if zone.EdgeRules[i].Guid == rule.Guid {
+				zone.EdgeRules[i] = rule
+				return
+			}
+		}
+	}
+	fb.nextGuid++
+	rule.Guid = fmt.Sprintf("guid-%d", fb.nextGuid)
+	zone.EdgeRules = append(zone.EdgeRules, rule)
+}
+
+func (fb *fakeBunny) serveStorage(w http.ResponseWriter, r *http.Request, path string) {
+	zoneName, _, _ := strings.Cut(path, "/")
+
+	fb.mu.Lock()
+	authorized := false
+	for _, zone := range fb.storageZones {
+		if zone.Name == zoneName && r.Header.Get("AccessKey") == zone.Password {
+			authorized = true
+		}
+	}
+	fb.mu.Unlock()
+
+	if !authorized {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		if !strings.HasSuffix(path, "/") {
+			fb.mu.Lock()
+			file, exists := fb.files[path]
+			fb.mu.Unlock()
+			if !exists {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(file.content)
+			return
+		}
+		fb.mu.Lock()
+		onList := fb.onList
+		fb.mu.Unlock()
+		if onList != nil {
+			onList(path)
+		}
+		writeJSON(w, fb.listDirectory(path))
+	case "PUT":
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fb.mu.Lock()
+		onUpload := fb.onUpload
+		fb.mu.Unlock()
+		if onUpload != nil {
+			onUpload(r)
+		}
+		if r.Context().Err() != nil {
+			return // the client gave up on the upload
+		}
+		fb.mu.Lock()
+		if fb.truncations > 0 && len(content) > 0 {
+			fb.truncations--
+			content = content[:len(content)-1]
+		}
+		fb.mu.Unlock()
+		if checksum := r.Header.Get("Checksum"); checksum != "" {
+			sum := sha256.Sum256(content)
+			if checksum != strings.ToUpper(hex.EncodeToString(sum[:])) {
+				http.Error(w, "Checksum and file hash do not match", http.StatusBadRequest)
+				return
+			}
+		}
+		fb.putFile(path, content)
+		fb.mu.Lock()
+		file := fb.files[path]
+		file.header = r.Header.Clone()
+		fb.files[path] = file
+		fb.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	case "DELETE":
+		fb.mu.Lock()
+		deleted := 0
+		for name := range fb.files {
+			if name == path || (strings.HasSuffix(path, "/") && strings.HasPrefix(name, path)) {
+				delete(fb.files, name)
+				deleted++
+			}
+		}
+		fb.mu.Unlock()
+		if deleted == 0 {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// fakeStorageObject mirrors the storage listing JSON, including LastChanged in Bunny's timestamp format
+type fakeStorageObject struct {
+	ObjectName  string `json:"ObjectName"`
+	IsDirectory bool   `json:"IsDirectory"`
+	Length      int64  `json:"Length"`
+	LastChanged string `json:"LastChanged"`
+	Checksum    string `json:"Checksum"`
+}
+
+func (fb *fakeBunny) listDirectory(dir string) []fakeStorageObject {
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	objects := []fakeStorageObject{}
+	seenDirs := make(map[string]int)
+
+	var names []string
+	for name := range fb.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, dir) {
+			continue
+		}
+		file := fb.files[name]
+		rest := strings.TrimPrefix(name, dir)
+		if child, _, isDir := strings.Cut(rest, "/"); isDir {
+			if i, seen := seenDirs[child]; seen {
+				if file.modified.After(parseFakeTime(objects[i].LastChanged)) {
+					objects[i].LastChanged = file.modified.Format("2006-01-02T15:04:05.999")
+				}
+				continue
+			}
+			seenDirs[child] = len(objects)
+			objects = append(objects, fakeStorageObject{
+				ObjectName:  child,
+				IsDirectory: true,
+				LastChanged: file.modified.Format("2006-01-02T15:04:05.999"),
+			})
+			continue
+		}
+
+		sum := sha256.Sum256(file.content)
+		objects = append(objects, fakeStorageObject{
+			ObjectName:  rest,
+			Length:      int64(len(file.content)),
+			LastChanged: file.modified.Format("2006-01-02T15:04:05.999"),
+			Checksum:    strings.ToUpper(hex.EncodeToString(sum[:])),
+		})
+	}
+
+	return objects
+}
+
+func parseFakeTime(s string) time.Time {
+	t, _ := time.Parse("2006-01-02T15:04:05.999", s)
+	return t
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
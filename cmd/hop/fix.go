@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// RuleFix is a correction rules fix applies to one rule
+type RuleFix struct {
+	Rule   EdgeRuleResponse // the rule as it is now
+	Fixed  EdgeRuleResponse // the rule after the fix, same GUID
+	Issue  string           // the check message the fix resolves
+	Change string
+}
+
+// applyFixes saves every fixed rule under its existing GUID and reports each result, returning the number of failures
+func applyFixes(ctx context.Context, apiKey, zoneID string, fixes []RuleFix, out io.Writer) int {
+	failed := 0
+	for _, fix := range fixes {
+		if err := addEdgeRule(ctx, apiKey, zoneID, restoredRule(fix.Fixed)); err != nil {
+			fmt.Fprintf(out, "FAILED %s: %v\n", describeRule(fix.Rule), err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(out, "Fixed  %s: %s\n", describeRule(fix.Rule), fix.Change)
+	}
+	return failed
+}
+
+// Side effect free functions
+
+// fixBasicIssue returns the corrected rule for an issue of checkBasicRedirectIssues, ok is false for issues it cannot fix safely.
+// It converts 301 redirects to 302 and fills in a missing status code when a destination exists.
+func fixBasicIssue(issue CheckIssue) (EdgeRuleResponse, string, bool) {
+	if issue.Type != IssueBasic || issue.Rule == nil || issue.Rule.ActionType != 1 {
+		return EdgeRuleResponse{}, "", false
+	}
+	fixed := *issue.Rule
+	switch {
+	case fixed.ActionParameter2 == "301":
+		fixed.ActionParameter2 = "302"
+		return fixed, "status 301 -> 302", true
+	case fixed.ActionParameter2 == "" && fixed.ActionParameter1 != "":
+		fixed.ActionParameter2 = "302"
+		return fixed, "missing status -> 302", true
+	}
+	return EdgeRuleResponse{}, "", false
+}
+
+// planFixes returns one fix per rule with a fixable basic issue, and the basic issues left alone
+func planFixes(rules []EdgeRuleResponse) ([]RuleFix, []CheckIssue) {
+	var fixes []RuleFix
+	var unfixable []CheckIssue
+	planned := make(map[string]bool)
+	for _, issue := range checkBasicRedirectIssues(rules) {
+		fixed, change, ok := fixBasicIssue(issue)
+		if !ok {
+			unfixable = append(unfixable, issue)
+			continue
+		}
+		if planned[issue.Rule.Guid] {
+			continue
+		}
+		planned[issue.Rule.Guid] = true
+		fixes = append(fixes, RuleFix{Rule: *issue.Rule, Fixed: fixed, Issue: issue.Message, Change: change})
+	}
+	return fixes, unfixable
+}
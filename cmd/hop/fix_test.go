@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPlanFixes(t *testing.T) {
+	withStatus := func(rule EdgeRuleResponse, status string) EdgeRuleResponse {
+		rule.ActionParameter2 = status
+		return rule
+	}
+
+	tests := []struct {
+		name          string
+		rules         []EdgeRuleResponse
+		wantFixes     map[string]string // GUID to the fixed status code
+		wantUnfixable int
+	}{
+		{
+			name:  "nothing to fix",
+			rules: []EdgeRuleResponse{redirectRule("a", "/a", "https://example.com/a")},
+		},
+		{
+			name:      "301 becomes 302",
+			rules:     []EdgeRuleResponse{withStatus(redirectRule("a", "/a", "https://example.com/a"), "301")},
+			wantFixes: map[string]string{"a": "302"},
+		},
+		{
+			name:      "missing status becomes 302",
+			rules:     []EdgeRuleResponse{withStatus(redirectRule("a", "/a", "https://example.com/a"), "")},
+			wantFixes: map[string]string{"a": "302"},
+		},
+		{
+			name:          "302 without destination is left alone",
+			rules:         []EdgeRuleResponse{redirectRule("a", "/a", "")},
+			wantUnfixable: 1,
+		},
+		{
+			name:          "other status codes are left alone",
+			rules:         []EdgeRuleResponse{withStatus(redirectRule("a", "/a", "https://example.com/a"), "307")},
+			wantUnfixable: 1,
+		},
+		{
+			name: "fixes and unfixable issues side by side",
+			rules: []EdgeRuleResponse{
+				withStatus(redirectRule("a", "/a", "https://example.com/a"), "301"),
+				redirectRule("b", "/b", ""),
+				withStatus(redirectRule("c", "/c", "https://example.com/c"), ""),
+			},
+			wantFixes:     map[string]string{"a": "302", "c": "302"},
+			wantUnfixable: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixes, unfixable := planFixes(tt.rules)
+			if len(fixes) != len(tt.wantFixes) {
+				t.Fatalf("planFixes() returned %d fixes, want %d", len(fixes), len(tt.wantFixes))
+			}
+			for _, fix := range fixes {
+				if fix.Fixed.Guid != fix.Rule.Guid {
+					t.Errorf("fixed rule has GUID %s, want %s", fix.Fixed.Guid, fix.Rule.Guid)
+				}
+				if fix.Fixed.ActionParameter2 != tt.wantFixes[fix.Rule.Guid] {
+					t.Errorf("rule %s fixed to status %q, want %q", fix.Rule.Guid, fix.Fixed.ActionParameter2, tt.wantFixes[fix.Rule.Guid])
+				}
+				if fix.Fixed.ActionParameter1 != fix.Rule.ActionParameter1 {
+					t.Errorf("rule %s destination changed to %q", fix.Rule.Guid, fix.Fixed.ActionParameter1)
+				}
+			}
+			if len(unfixable) != tt.wantUnfixable {
+				t.Errorf("planFixes() left %d issues, want %d", len(unfixable), tt.wantUnfixable)
+			}
+		})
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+
+	for _, status := range []string{"301", "302"} {
+		rule := EdgeRule{ActionType: 1, ActionParameter1: "https://example.com/new", ActionParameter2: status, Enabled: true,
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/page-" + status}}}}
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", rule); err != nil {
+			t.Fatalf("addEdgeRule() error = %v", err)
+		}
+	}
+
+	fixes, _ := planFixes(fb.edgeRules(1))
+	if len(fixes) != 1 {
+		t.Fatalf("planFixes() returned %d fixes, want 1", len(fixes))
+	}
+
+	var out bytes.Buffer
+	if failed := applyFixes(ctx, fakeAPIKey, "1", fixes, &out); failed != 0 {
+		t.Fatalf("applyFixes() failed %d rules: %s", failed, out.String())
+	}
+	rules := fb.edgeRules(1)
+	if len(rules) != 2 {
+		t.Fatalf("zone has %d rules after the fix, want 2", len(rules))
+	}
+	for _, rule := range rules {
+		if rule.ActionParameter2 != "302" {
+			t.Errorf("rule %s has status %s, want 302", rule.Guid, rule.ActionParameter2)
+		}
+	}
+
+	out.Reset()
+	if failed := applyFixes(ctx, fakeAPIKey, "99", fixes, &out); failed != 1 {
+		t.Errorf("applyFixes() to an unknown zone failed %d rules, want 1", failed)
+	}
+	if !strings.Contains(out.String(), "FAILED") {
+		t.Errorf("output %q does not report the failure", out.String())
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogEntry is the part of a Bunny CDN access log line that rules gaps needs
+type LogEntry struct {
+	Status int
+	Time   time.Time
+	URL    string
+}
+
+// LogScan collects the 404 paths of access log lines
+type LogScan struct {
+	Hits      map[string]int // 404 hits per URL path
+	Lines     int
+	Malformed int
+	Truncated bool // stopped at the line limit
+}
+
+// PathHits is a 404 path together with how often it was requested
+type PathHits struct {
+	Path string
+	Hits int
+}
+
+// fetchPullZoneLog opens the access log of a pull zone for one UTC day, returning nil if there is no log for that day
+func fetchPullZoneLog(ctx context.Context, apiKey, zoneID string, day time.Time) (io.ReadCloser, error) {
+	url := fmt.Sprintf("%s/%s/%s.log", logBaseURL, day.UTC().Format("01-02-06"), zoneID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("AccessKey", apiKey)
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %v", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("received nil response")
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		drainAndClose(resp.Body)
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		drainAndClose(resp.Body)
+		return nil, fmt.Errorf("log request failed with status %s: %s", resp.Status, string(body))
+	}
+
+	return resp.Body, nil
+}
+
+// Side effect free functions
+
+// parseLogLine parses a Bunny CDN access log line of the form
+// CacheStatus|StatusCode|TimestampMillis|BytesSent|PullZoneID|RemoteIP|Referer|URL|EdgeLocation|UserAgent|RequestID|CountryCode
+func parseLogLine(line string) (LogEntry, error) {
+	fields := strings.Split(line, "|")
+	if len(fields) < 8 {
+		return LogEntry{}, fmt.Errorf("expected at least 8 fields, got %d", len(fields))
+	}
+
+	status, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("invalid status code '%s'", fields[1])
+	}
+	millis, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return LogEntry{}, fmt.Errorf("invalid timestamp '%s'", fields[2])
+	}
+
+	return LogEntry{Status: status, Time: time.UnixMilli(millis).UTC(), URL: fields[7]}, nil
+}
+
+// logURLPath returns the path of a logged request URL without its query string
+func logURLPath(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Path == "" {
+		return "/"
+	}
+	return parsed.Path
+}
+
+// logDays returns the UTC days whose logs cover everything from since until now
+func logDays(since, now time.Time) []time.Time {
+	var days []time.Time
+	day := since.UTC().Truncate(24 * time.Hour)
+	for !day.After(now.UTC()) {
+		days = append(days, day)
+		day = day.Add(24 * time.Hour)
+	}
+	return days
+}
+
+// read counts the 404 paths of log lines newer than since, stopping once maxLines lines were read in total
+func (s *LogScan) read(r io.Reader, since time.Time, maxLines int) error {
+	if s.Hits == nil {
+		s.Hits = make(map[string]int)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if s.Lines >= maxLines {
+			s.Truncated = true
+			return nil
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		s.Lines++
+
+		entry, err := parseLogLine(line)
+		if err != nil {
+			s.Malformed++
+			continue
+		}
+		if entry.Status != http.StatusNotFound || entry.Time.Before(since) {
+			continue
+		}
+		s.Hits[logURLPath(entry.URL)]++
+	}
+	return scanner.Err()
+}
+
+// rankGaps returns the top 404 paths no enabled redirect covers, most hits first
+func rankGaps(hits map[string]int, rules []EdgeRuleResponse, top int) (gaps []PathHits, uncovered int) {
+	for path, count := range hits {
+		if winningRedirect(findRedirectsForPath(rules, path)) != nil {
+			continue
+		}
+		gaps = append(gaps, PathHits{Path: path, Hits: count})
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].Hits != gaps[j].Hits {
+			return gaps[i].Hits > gaps[j].Hits
+		}
+		return gaps[i].Path < gaps[j].Path
+	})
+
+	uncovered = len(gaps)
+	if top > 0 && len(gaps) > top {
+		gaps = gaps[:top]
+	}
+	return gaps, uncovered
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func logLine(status int, at time.Time, rawURL string) string {
+	return fmt.Sprintf("MISS|%d|%d|1234|42|203.0.113.7|-|%s|FR|Mozilla/5.0|abc123|DE", status, at.UnixMilli(), rawURL)
+}
+
+func TestParseLogLine(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		line    string
+		want    LogEntry
+		wantErr bool
+	}{
+		{name: "bunny log line", line: logLine(404, at, "https://www.example.com/old/page?ref=x"), want: LogEntry{Status: 404, Time: at, URL: "https://www.example.com/old/page?ref=x"}},
+		{name: "user agent with pipes is ignored", line: "HIT|200|1717245000000|1|42|ip|-|https://example.com/|FR|a|b|c|d|DE", want: LogEntry{Status: 200, Time: at, URL: "https://example.com/"}},
+		{name: "too few fields", line: "HIT|200|1717245000000", wantErr: true},
+		{name: "invalid status", line: "HIT|abc|1717245000000|1|42|ip|-|https://example.com/", wantErr: true},
+		{name: "invalid timestamp", line: "HIT|404|yesterday|1|42|ip|-|https://example.com/", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLogLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLogLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (got.Status != tt.want.Status || !got.Time.Equal(tt.want.Time) || got.URL != tt.want.URL) {
+				t.Errorf("parseLogLine() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogURLPath(t *testing.T) {
+	tests := map[string]string{
+		"https://example.com/old/page?ref=x": "/old/page",
+		"https://example.com":                "/",
+		"/relative/path":                     "/relative/path",
+		"%zz":                                "/",
+	}
+	for rawURL, want := range tests {
+		if got := logURLPath(rawURL); got != want {
+			t.Errorf("logURLPath(%q) = %q, want %q", rawURL, got, want)
+		}
+	}
+}
+
+func TestLogDays(t *testing.T) {
+	now := time.Date(2024, 6, 3, 1, 0, 0, 0, time.UTC)
+	days := logDays(now.Add(-26*time.Hour), now)
+	var got []string
+	for _, day := range days {
+		got = append(got, day.Format("01-02-06"))
+	}
+	if strings.Join(got, ",") != "06-01-24,06-02-24,06-03-24" {
+		t.Errorf("logDays() = %v", got)
+	}
+}
+
+func TestLogScanRead(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-time.Hour)
+	lines := strings.Join([]string{
+		logLine(404, now, "https://example.com/missing"),
+		logLine(404, now, "https://example.com/missing?utm=1"),
+		logLine(200, now, "https://example.com/fine"),
+		logLine(404, now.Add(-2*time.Hour), "https://example.com/too-old"),
+		"",
+		"garbage",
+		logLine(404, now, "https://example.com/other"),
+		logLine(404, now, "https://example.com/after-limit"),
+	}, "\n")
+
+	scan := &LogScan{}
+	if err := scan.read(strings.NewReader(lines), since, 6); err != nil {
+		t.Fatalf("read() error = %v", err)
+	}
+
+	if scan.Lines != 6 || scan.Malformed != 1 || !scan.Truncated {
+		t.Errorf("scan = lines %d, malformed %d, truncated %v, want 6, 1, true", scan.Lines, scan.Malformed, scan.Truncated)
+	}
+	want := map[string]int{"/missing": 2, "/other": 1}
+	if len(scan.Hits) != len(want) {
+		t.Fatalf("hits = %v, want %v", scan.Hits, want)
+	}
+	for path, count := range want {
+		if scan.Hits[path] != count {
+			t.Errorf("hits[%s] = %d, want %d", path, scan.Hits[path], count)
+		}
+	}
+
+	// The limit applies across several reads
+	if err := scan.read(strings.NewReader(logLine(404, now, "https://example.com/next-day")), since, 6); err != nil {
+		t.Fatal(err)
+	}
+	if scan.Hits["/next-day"] != 0 {
+		t.Error("read() continued past the line limit")
+	}
+}
+
+func TestRankGaps(t *testing.T) {
+	disabled := redirectRule("d", "/disabled", "https://example.com/")
+	disabled.Enabled = false
+	rules := []EdgeRuleResponse{
+		redirectRule("a", "/old/page", "https://example.com/new"),
+		redirectRule("b", "/blog/*", "https://example.com/articles/"),
+		disabled,
+	}
+	hits := map[string]int{
+		"/old/page/":    50, // covered ignoring the trailing slash
+		"/blog/2019/x":  40, // covered by the wildcard
+		"/disabled":     30, // only a disabled rule
+		"/a-missing":    10,
+		"/b-missing":    10,
+		"/rarely-asked": 1,
+	}
+
+	gaps, uncovered := rankGaps(hits, rules, 3)
+	if uncovered != 4 {
+		t.Errorf("uncovered = %d, want 4", uncovered)
+	}
+	var got []string
+	for _, gap := range gaps {
+		got = append(got, fmt.Sprintf("%s=%d", gap.Path, gap.Hits))
+	}
+	if strings.Join(got, ",") != "/disabled=30,/a-missing=10,/b-missing=10" {
+		t.Errorf("rankGaps() = %v", got)
+	}
+
+	if all, _ := rankGaps(hits, rules, 0); len(all) != 4 {
+		t.Errorf("rankGaps() without a limit returned %d paths, want 4", len(all))
+	}
+}
+
+func TestFetchPullZoneLog(t *testing.T) {
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.Header.Get("AccessKey") != fakeAPIKey {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/06-01-24/42.log" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, logLine(404, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "https://example.com/x"))
+	}))
+	defer server.Close()
+	oldLog := logBaseURL
+	logBaseURL = server.URL
+	t.Cleanup(func() { logBaseURL = oldLog })
+
+	ctx := context.Background()
+	body, err := fetchPullZoneLog(ctx, fakeAPIKey, "42", time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC))
+	if err != nil || body == nil {
+		t.Fatalf("fetchPullZoneLog() = %v, %v", body, err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if !strings.Contains(string(data), "https://example.com/x") {
+		t.Errorf("unexpected log content %q", data)
+	}
+
+	if body, err := fetchPullZoneLog(ctx, fakeAPIKey, "42", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)); body != nil || err != nil {
+		t.Errorf("missing day = %v, %v, want nil, nil", body, err)
+	}
+	if _, err := fetchPullZoneLog(ctx, "wrong", "42", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("fetchPullZoneLog() with a wrong key should fail")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Side effect free functions
+
+// parseHeaderFlag splits a --header value of the form "Name: value" at the first colon, so values may contain colons
+func parseHeaderFlag(header string) (string, string, error) {
+	name, value, found := strings.Cut(header, ":")
+	if !found {
+		return "", "", fmt.Errorf("header '%s' must have the form 'Name: value'", header)
+	}
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(value)
+
+	if err := validateHeaderName(name); err != nil {
+		return "", "", err
+	}
+	if err := validateHeaderValue(value); err != nil {
+		return "", "", err
+	}
+	return name, value, nil
+}
+
+// validateHeaderName checks that a header name is a non-empty HTTP token
+func validateHeaderName(name string) error {
+	if name == "" {
+		return fmt.Errorf("header name must not be empty")
+	}
+	for _, c := range name {
+		if c > 0x7e || !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return fmt.Errorf("header name '%s' contains the invalid character %q", name, c)
+		}
+	}
+	return nil
+}
+
+// validateHeaderValue rejects empty values and control characters, a CR or LF would inject further headers
+func validateHeaderValue(value string) error {
+	if value == "" {
+		return fmt.Errorf("header value must not be empty")
+	}
+	for _, c := range value {
+		if (c < 0x20 && c != '\t') || c == 0x7f {
+			return fmt.Errorf("header value contains the control character %q", c)
+		}
+	}
+	return nil
+}
+
+// headerDescription is the generated description of a response header rule
+func headerDescription(from, name, value string) string {
+	return fmt.Sprintf("Set %s: %s on %s", name, value, from)
+}
+
+// buildHeaderRule builds an edge rule that sets a response header on requests matching from
+func buildHeaderRule(guid, from, name, value, desc string) EdgeRule {
+	if desc == "" {
+		desc = headerDescription(from, name, value)
+	}
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          5,     // SetResponseHeader
+		ActionParameter1:    name,  // Header name
+		ActionParameter2:    value, // Header value
+		TriggerMatchingType: 0,     // MatchAny
+		Description:         desc,
+		Enabled:             true,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      []string{from},
+				PatternMatchingType: 0, // MatchAny
+			},
+		},
+	}
+}
+
+// findHeaderRule returns the rule setting a header on a source pattern, or nil if there is none; header names ignore case
+func findHeaderRule(rules []EdgeRuleResponse, from, name string) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if rule.ActionType == 5 && extractSourceURL(rule) == from && strings.EqualFold(rule.ActionParameter1, name) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
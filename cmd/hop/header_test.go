@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseHeaderFlag(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantName  string
+		wantValue string
+		wantErr   string
+	}{
+		{header: "X-Robots-Tag: noindex", wantName: "X-Robots-Tag", wantValue: "noindex"},
+		{header: "X-Robots-Tag:noindex, nofollow", wantName: "X-Robots-Tag", wantValue: "noindex, nofollow"},
+		{header: "Link: <https://example.com/new>; rel=\"canonical\"", wantName: "Link", wantValue: "<https://example.com/new>; rel=\"canonical\""},
+		{header: "X-Window:  10:00-18:00 ", wantName: "X-Window", wantValue: "10:00-18:00"},
+		{header: "X-Robots-Tag noindex", wantErr: "Name: value"},
+		{header: ": noindex", wantErr: "name must not be empty"},
+		{header: "X Robots: noindex", wantErr: "invalid character"},
+		{header: "X-Robots-Tag:", wantErr: "value must not be empty"},
+		{header: "X-Robots-Tag: noindex\r\nSet-Cookie: a=b", wantErr: "control character"},
+		{header: "X-Robots-Tag: noindex\nSet-Cookie: a=b", wantErr: "control character"},
+		{header: "X-Robots-Tag: a\x00b", wantErr: "control character"},
+		{header: "X-Tab: a\tb", wantName: "X-Tab", wantValue: "a\tb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			name, value, err := parseHeaderFlag(tt.header)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("parseHeaderFlag() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseHeaderFlag() error = %v", err)
+			}
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("parseHeaderFlag() = %q, %q, want %q, %q", name, value, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestBuildHeaderRule(t *testing.T) {
+	rule := buildHeaderRule("", "/legacy/*", "X-Robots-Tag", "noindex", "")
+	if rule.ActionType != 5 || rule.ActionParameter1 != "X-Robots-Tag" || rule.ActionParameter2 != "noindex" {
+		t.Errorf("buildHeaderRule() action = %d %s %s, want SetResponseHeader with name and value", rule.ActionType, rule.ActionParameter1, rule.ActionParameter2)
+	}
+	if len(rule.Triggers) != 1 || rule.Triggers[0].PatternMatches[0] != "/legacy/*" {
+		t.Errorf("buildHeaderRule() triggers = %+v", rule.Triggers)
+	}
+	if rule.Description != "Set X-Robots-Tag: noindex on /legacy/*" {
+		t.Errorf("buildHeaderRule() description = %s", rule.Description)
+	}
+}
+
+func TestFindHeaderRule(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		{Guid: "cache", ActionType: 5, ActionParameter1: "Cache-Control", ActionParameter2: "no-store", Triggers: []Trigger{{PatternMatches: []string{"/legacy/*"}}}},
+		{Guid: "robots", ActionType: 5, ActionParameter1: "X-Robots-Tag", ActionParameter2: "noindex", Triggers: []Trigger{{PatternMatches: []string{"/legacy/*"}}}},
+	}
+	if got := findHeaderRule(rules, "/legacy/*", "x-robots-tag"); got == nil || got.Guid != "robots" {
+		t.Errorf("findHeaderRule() = %+v, want the X-Robots-Tag rule", got)
+	}
+	if got := findHeaderRule(rules, "/other/*", "X-Robots-Tag"); got != nil {
+		t.Errorf("findHeaderRule() = %+v, want nil for another pattern", got)
+	}
+}
+
+func TestDisplayAllRulesShowsActionDetails(t *testing.T) {
+	var buf bytes.Buffer
+	previous := output
+	output = &buf
+	t.Cleanup(func() { output = previous })
+
+	displayAllRules([]EdgeRuleResponse{
+		{Guid: "h", ActionType: 5, ActionParameter1: "X-Robots-Tag", ActionParameter2: "noindex", Enabled: true, Triggers: []Trigger{{PatternMatches: []string{"/legacy/*"}}}},
+		originRule("o", "/api/*", "https://api.example.com"),
+		blockRule("b", "/wp-admin*"),
+	})
+
+	for _, want := range []string{"Header: X-Robots-Tag: noindex", "Origin: https://api.example.com", "Blocked: /wp-admin*"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("displayAllRules() output lacks %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "Parameter 2: noindex") {
+		t.Errorf("header value should only be shown once:\n%s", buf.String())
+	}
+}
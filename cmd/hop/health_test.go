@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStreamURLHealthFailFastCancelsOutstandingProbes(t *testing.T) {
+	var started, cancelled atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		started.Add(1)
+		select {
+		case <-r.Context().Done():
+			cancelled.Add(1)
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	var rules []EdgeRuleResponse
+	for i := range 12 {
+		destination := fmt.Sprintf("%s/slow-%d", server.URL, i)
+		if i == 3 {
+			destination = server.URL + "/broken"
+		}
+		rules = append(rules, redirectRule(fmt.Sprintf("r%d", i), fmt.Sprintf("/old-%d", i), destination))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	begin := time.Now()
+	run := streamURLHealth(ctx, rules, nil, nil, nil, func(issue CheckIssue) {
+		if severityAtLeast(issue.Severity, "error") {
+			cancel()
+		}
+	}, nil)
+	if elapsed := time.Since(begin); elapsed > 3*time.Second {
+		t.Errorf("streamURLHealth() took %v, outstanding probes were not cancelled", elapsed)
+	}
+
+	if len(run.Issues) != 1 || run.Issues[0].Severity != "critical" || run.Issues[0].Rule.Guid != "r3" {
+		t.Fatalf("issues = %+v, want only the critical finding for r3", run.Issues)
+	}
+	if run.Skipped != 11 {
+		t.Errorf("Skipped = %d, want 11", run.Skipped)
+	}
+	if got := started.Load(); got > healthCheckWorkers-1 {
+		t.Errorf("%d slow probes started, want at most %d in flight", got, healthCheckWorkers-1)
+	}
+
+	// The server notices the cancelled requests asynchronously
+	deadline := time.Now().Add(2 * time.Second)
+	for cancelled.Load() != started.Load() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if cancelled.Load() != started.Load() {
+		t.Errorf("%d of %d in-flight probes saw their request cancelled", cancelled.Load(), started.Load())
+	}
+}
+
+func TestStreamURLHealthReportsInRuleOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/first" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	rules := []EdgeRuleResponse{
+		redirectRule("first", "/a", server.URL+"/first"),
+		redirectRule("relative", "/b", "/relative"),
+		redirectRule("second", "/c", server.URL+"/second"),
+	}
+
+	reported := 0
+	run := streamURLHealth(context.Background(), rules, nil, nil, nil, func(CheckIssue) { reported++ }, nil)
+	if len(run.Issues) != 2 || run.Issues[0].Rule.Guid != "first" || run.Issues[1].Rule.Guid != "second" {
+		t.Errorf("issues are not in rule order: %+v", run.Issues)
+	}
+	if reported != 2 || run.Skipped != 0 {
+		t.Errorf("reported = %d, skipped = %d, want 2 and 0", reported, run.Skipped)
+	}
+}
+
+func TestStreamURLHealthProbesSharedDestinationsOnce(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	rules := []EdgeRuleResponse{
+		redirectRule("a", "/a", server.URL+"/category"),
+		redirectRule("b", "/b", server.URL+"/other"),
+		redirectRule("c", "/c", server.URL+"/category#top"),
+		redirectRule("d", "/d", server.URL+"/category"),
+	}
+
+	run := streamURLHealth(context.Background(), rules, nil, nil, nil, nil, nil)
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server got %d requests, want 2 for two unique destinations", got)
+	}
+	if len(run.Issues) != 4 {
+		t.Fatalf("got %d issues, want one per rule: %+v", len(run.Issues), run.Issues)
+	}
+	for i, guid := range []string{"a", "b", "c", "d"} {
+		issue := run.Issues[i]
+		if issue.Rule.Guid != guid {
+			t.Errorf("issue %d points at %s, want %s", i, issue.Rule.Guid, guid)
+		}
+		shared := guid != "b"
+		if got := strings.Contains(issue.Message, "shared by 3 rules"); got != shared {
+			t.Errorf("issue for %s: %q, want shared note %v", guid, issue.Message, shared)
+		}
+		if shared && issue.Details["shared_by"] != 3 {
+			t.Errorf("issue for %s: shared_by = %v, want 3", guid, issue.Details["shared_by"])
+		}
+	}
+}
+
+func TestDestinationKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{a: "https://Example.com/page", b: "https://example.com/page", same: true},
+		{a: "HTTPS://example.com/page", b: "https://example.com/page", same: true},
+		{a: "https://example.com/page#top", b: "https://example.com/page", same: true},
+		{a: "https://example.com/Page", b: "https://example.com/page", same: false},
+		{a: "https://example.com/page/", b: "https://example.com/page", same: false},
+		{a: "https://example.com/page?a=1", b: "https://example.com/page", same: false},
+		{a: "http://example.com/page", b: "https://example.com/page", same: false},
+	}
+	for _, tt := range tests {
+		if got := destinationKey(tt.a) == destinationKey(tt.b); got != tt.same {
+			t.Errorf("destinationKey(%q) == destinationKey(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
+
+func TestSeverityAtLeast(t *testing.T) {
+	tests := []struct {
+		severity, threshold string
+		want                bool
+	}{
+		{severity: "critical", threshold: "error", want: true},
+		{severity: "error", threshold: "error", want: true},
+		{severity: "warning", threshold: "error", want: false},
+		{severity: "warning", threshold: "warning", want: true},
+		{severity: "info", threshold: "warning", want: false},
+		{severity: "info", threshold: "info", want: true},
+		{severity: "critical", threshold: "critical", want: true},
+		{severity: "error", threshold: "critical", want: false},
+		{severity: "critical", threshold: "never", want: false},
+		{severity: "info", threshold: "never", want: false},
+	}
+	for _, tt := range tests {
+		if got := severityAtLeast(tt.severity, tt.threshold); got != tt.want {
+			t.Errorf("severityAtLeast(%q, %q) = %v, want %v", tt.severity, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestHasIssueAtLeast(t *testing.T) {
+	issues := []CheckIssue{{Severity: "info"}, {Severity: "warning"}}
+	tests := []struct {
+		threshold string
+		want      bool
+	}{
+		{threshold: "info", want: true},
+		{threshold: "warning", want: true},
+		{threshold: "error", want: false},
+		{threshold: "critical", want: false},
+		{threshold: "never", want: false},
+	}
+	for _, tt := range tests {
+		if got := hasIssueAtLeast(issues, tt.threshold); got != tt.want {
+			t.Errorf("hasIssueAtLeast(%q) = %v, want %v", tt.threshold, got, tt.want)
+		}
+	}
+	if hasIssueAtLeast(nil, "info") {
+		t.Error("hasIssueAtLeast() without issues = true, want false")
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthCacheFailureTTL caps how long a failed destination stays cached, so a fixed destination is requested again soon
+const healthCacheFailureTTL = 5 * time.Minute
+
+// HealthCache keeps destination health check results on disk, so frequent runs do not request the same destinations again.
+// It is safe for concurrent use by the health check workers; a nil cache never hits and stores nothing.
+type HealthCache struct {
+	path string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]healthCacheEntry
+}
+
+// healthCacheEntry is the cached result of one destination
+type healthCacheEntry struct {
+	Status      int       `json:"status"`
+	HasRedirect bool      `json:"has_redirect"`
+	DurationMS  int64     `json:"duration_ms"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// loadHealthCache reads the cache at path; a missing or corrupt file starts an empty cache
+func loadHealthCache(path string, ttl time.Duration) *HealthCache {
+	cache := &HealthCache{path: path, ttl: ttl, now: time.Now, entries: make(map[string]healthCacheEntry)}
+	// #nosec G304 - the cache path is supplied by the user on purpose
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	var entries map[string]healthCacheEntry
+	if err := json.Unmarshal(data, &entries); err == nil && entries != nil {
+		cache.entries = entries
+	}
+	return cache
+}
+
+// lookup returns the cached response of a destination checked within the TTL
+func (c *HealthCache) lookup(targetURL string) (healthResponse, bool) {
+	if c == nil {
+		return healthResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[healthCacheKey(targetURL)]
+	if !ok || !entry.fresh(c.now(), c.ttl) {
+		return healthResponse{}, false
+	}
+	return healthResponse{
+		Status:      entry.Status,
+		HasRedirect: entry.HasRedirect,
+		Duration:    time.Duration(entry.DurationMS) * time.Millisecond,
+		Cached:      true,
+	}, true
+}
+
+// store records the response of a destination
+func (c *HealthCache) store(targetURL string, response healthResponse) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[healthCacheKey(targetURL)] = healthCacheEntry{
+		Status:      response.Status,
+		HasRedirect: response.HasRedirect,
+		DurationMS:  response.Duration.Milliseconds(),
+		CheckedAt:   c.now().UTC(),
+	}
+}
+
+// save writes the entries within the TTL to a temporary file and renames it over the cache, so readers never see a partial file
+func (c *HealthCache) save() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	entries := make(map[string]healthCacheEntry)
+	now := c.now()
+	for key, entry := range c.entries {
+		if entry.fresh(now, c.ttl) {
+			entries[key] = entry
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding health cache: %v", err)
+	}
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("error creating cache directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating health cache: %v", err)
+	}
+	// the temporary file is gone after a successful rename, removing it then fails harmlessly
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("error writing health cache: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing health cache: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("error replacing health cache: %v", err)
+	}
+	return nil
+}
+
+// Side effect free functions
+
+// healthCacheKey returns the cache key of a destination: the URL with scheme and host lowercased and without fragment.
+// Path and query stay as they are, servers may answer /Page, /page and /page/ differently.
+func healthCacheKey(targetURL string) string {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return targetURL
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+	return parsed.String()
+}
+
+// fresh reports whether an entry checked at CheckedAt is still within ttl at now; failures expire after healthCacheFailureTTL at most
+func (e healthCacheEntry) fresh(now time.Time, ttl time.Duration) bool {
+	if e.Status == 0 || e.Status >= 400 {
+		ttl = min(ttl, healthCacheFailureTTL)
+	}
+	return now.Sub(e.CheckedAt) < ttl
+}
+
+// expandHome replaces a leading ~ in path with home, the shell leaves it alone in --flag=~/path
+func expandHome(path, home string) string {
+	if home == "" {
+		return path
+	}
+	if path == "~" {
+		return home
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		return filepath.Join(home, rest)
+	}
+	return path
+}
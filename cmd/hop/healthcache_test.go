@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthCacheTTL(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := loadHealthCache(filepath.Join(t.TempDir(), "health.json"), time.Hour)
+	cache.now = func() time.Time { return now }
+	cache.store("https://Example.com/page/", healthResponse{Status: 200, HasRedirect: true, Duration: 120 * time.Millisecond})
+	cache.store("https://example.com/broken", healthResponse{Status: 404, Duration: 80 * time.Millisecond})
+
+	tests := []struct {
+		name       string
+		url        string
+		elapsed    time.Duration
+		wantStatus int // 0 for a miss
+	}{
+		{name: "within the TTL", url: "https://example.com/page/", elapsed: 59 * time.Minute, wantStatus: 200},
+		{name: "host case", url: "https://EXAMPLE.com/page/", elapsed: time.Minute, wantStatus: 200},
+		{name: "fragment", url: "https://example.com/page/#top", elapsed: time.Minute, wantStatus: 200},
+		{name: "trailing slash differs", url: "https://example.com/page", elapsed: time.Minute},
+		{name: "path case differs", url: "https://example.com/Page/", elapsed: time.Minute},
+		{name: "expired", url: "https://example.com/page/", elapsed: time.Hour},
+		{name: "unknown URL", url: "https://example.com/other", elapsed: time.Minute},
+		{name: "failure within the failure TTL", url: "https://example.com/broken", elapsed: 4 * time.Minute, wantStatus: 404},
+		{name: "failure after the failure TTL", url: "https://example.com/broken", elapsed: healthCacheFailureTTL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache.now = func() time.Time { return now.Add(tt.elapsed) }
+			response, hit := cache.lookup(tt.url)
+			if hit != (tt.wantStatus != 0) {
+				t.Fatalf("lookup() hit = %v, want %v", hit, tt.wantStatus != 0)
+			}
+			if hit && (response.Status != tt.wantStatus || !response.Cached) {
+				t.Errorf("lookup() = %+v, want status %d from the cache", response, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	tests := []struct {
+		path string
+		home string
+		want string
+	}{
+		{"~/.cache/hop/health.json", "/home/ann", "/home/ann/.cache/hop/health.json"},
+		{"~", "/home/ann", "/home/ann"},
+		{"/tmp/health.json", "/home/ann", "/tmp/health.json"},
+		{"~other/health.json", "/home/ann", "~other/health.json"},
+		{"~/health.json", "", "~/health.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := expandHome(tt.path, tt.home); got != tt.want {
+				t.Errorf("expandHome(%q, %q) = %q, want %q", tt.path, tt.home, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthCacheSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache", "health.json")
+	now := time.Now()
+	cache := loadHealthCache(path, time.Hour)
+	cache.now = func() time.Time { return now }
+	cache.store("https://example.com/fresh", healthResponse{Status: 200})
+	cache.now = func() time.Time { return now.Add(-2 * time.Hour) }
+	cache.store("https://example.com/stale", healthResponse{Status: 200})
+	cache.now = func() time.Time { return now }
+	if err := cache.save(); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	loaded := loadHealthCache(path, time.Hour)
+	if _, hit := loaded.lookup("https://example.com/fresh"); !hit {
+		t.Error("saved entry is missing after loading")
+	}
+	if len(loaded.entries) != 1 {
+		t.Errorf("loaded %d entries, want expired entries dropped on save", len(loaded.entries))
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp")); len(matches) != 0 {
+		t.Errorf("temporary files left behind: %v", matches)
+	}
+}
+
+func TestHealthCacheStartsFreshOnCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "health.json")
+	if err := os.WriteFile(path, []byte(`{"https://example.com": {"status": 2`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cache := loadHealthCache(path, time.Hour)
+	if len(cache.entries) != 0 {
+		t.Fatalf("corrupt cache loaded %d entries", len(cache.entries))
+	}
+	cache.store("https://example.com", healthResponse{Status: 200})
+	if err := cache.save(); err != nil {
+		t.Fatalf("save() over a corrupt cache error = %v", err)
+	}
+	if _, hit := loadHealthCache(path, time.Hour).lookup("https://example.com"); !hit {
+		t.Error("cache was not rewritten")
+	}
+}
+
+func TestCheckDestinationHealthUsesCache(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	rule := redirectRule("r", "/old", server.URL+"/new")
+
+	prober := newHealthProber(0, 0, nil, nil)
+	prober.cache = loadHealthCache(filepath.Join(t.TempDir(), "health.json"), time.Hour)
+	for run := range 2 {
+		issues, _, _ := checkDestinationHealth(context.Background(), &rule, nil, nil, prober)
+		if len(issues) != 1 || issues[0].Message != "Broken destination URL (HTTP 404)" {
+			t.Fatalf("run %d: checkDestinationHealth() = %+v", run, issues)
+		}
+		if cached := issues[0].Details["cached"] == true; cached != (run == 1) {
+			t.Errorf("run %d: cached = %v", run, cached)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("destination requested %d times, want 1", requests.Load())
+	}
+}
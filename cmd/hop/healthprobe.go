@@ -0,0 +1,241 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// healthRetryBaseDelay is the backoff before the first retry of a destination health check
+var healthRetryBaseDelay = 500 * time.Millisecond
+
+// healthProber requests destinations for the health checks, retries transient failures and limits the load per host.
+// A nil prober makes a single attempt without limits and never reports slow destinations.
+type healthProber struct {
+	retries       int              // extra attempts after a network error or a 5xx response
+	baseDelay     time.Duration    // backoff before the first retry, doubled for every further retry
+	limiter       *hostLimiter     // shared by all workers, nil for no limit
+	slowThreshold time.Duration    // destinations answering slower get a warning, 0 disables it
+	soft404       *Soft404Detector // reads destination bodies to find not-found pages, nil skips it
+	cache         *HealthCache     // results of earlier runs, nil requests every destination
+}
+
+// newHealthProber returns a prober retrying transient failures up to retries times through a per-host limiter
+func newHealthProber(retries int, slowThreshold time.Duration, limiter *hostLimiter, soft404 *Soft404Detector) *healthProber {
+	return &healthProber{retries: max(retries, 0), baseDelay: healthRetryBaseDelay, limiter: limiter, slowThreshold: slowThreshold, soft404: soft404}
+}
+
+// healthResponse is the outcome of the last attempt of a destination health check
+type healthResponse struct {
+	Status      int
+	HasRedirect bool
+	Attempts    int
+	Duration    time.Duration // time to first byte, measured on the monotonic clock
+	Body        []byte        // start of the body, only read for soft 404 detection
+	Cached      bool          // taken from the health cache, no request was made
+}
+
+// probe returns the health of a destination from the cache, or requests it and caches responses
+func (p *healthProber) probe(ctx context.Context, targetURL string) (healthResponse, error) {
+	cache := p.healthCache()
+	if response, ok := cache.lookup(targetURL); ok {
+		return response, nil
+	}
+	response, err := p.fetch(ctx, targetURL)
+	if err == nil {
+		cache.store(targetURL, response)
+	}
+	return response, err
+}
+
+// healthCache returns the cache the prober uses; soft 404 detection needs the body, which is not cached
+func (p *healthProber) healthCache() *HealthCache {
+	if p == nil || p.soft404 != nil {
+		return nil
+	}
+	return p.cache
+}
+
+// fetch performs the health check of a destination, retrying network errors and 5xx responses with exponential backoff and jitter.
+// It returns the result of the last attempt and the number of attempts made; retries stop when ctx is done.
+func (p *healthProber) fetch(ctx context.Context, targetURL string) (healthResponse, error) {
+	var limiter *hostLimiter
+	var bodyLimit int64
+	if p != nil {
+		limiter = p.limiter
+		if p.soft404 != nil {
+			bodyLimit = soft404BodyLimit
+		}
+	}
+
+	var response healthResponse
+	for {
+		response.Attempts++
+		release, err := limiter.acquire(ctx, limiterHost(targetURL))
+		if err != nil {
+			return response, err
+		}
+		response.Status, response.HasRedirect, response.Duration, response.Body, err = fetchDestination(ctx, targetURL, bodyLimit)
+		release()
+		if p == nil || response.Attempts > p.retries || !isTransientHealthFailure(response.Status, err) || ctx.Err() != nil {
+			return response, err
+		}
+
+		timer := time.NewTimer(retryDelay(p.baseDelay, response.Attempts))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return response, err
+		case <-timer.C:
+		}
+	}
+}
+
+// isSlow reports whether a destination answering after duration gets a slow destination warning
+func (p *healthProber) isSlow(duration time.Duration) bool {
+	return p != nil && p.slowThreshold > 0 && duration > p.slowThreshold
+}
+
+// soft404Match explains why a response looks like a soft 404 and returns the matched phrase; only 200 responses are judged
+func (p *healthProber) soft404Match(response healthResponse) (string, string) {
+	if p == nil || response.Status != 200 {
+		return "", ""
+	}
+	return p.soft404.match(response.Body)
+}
+
+// hostLimiter bounds the concurrent requests to each host and spaces their start, so many redirects to one site do not trip its WAF
+type hostLimiter struct {
+	maxConcurrent int
+	minInterval   time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostSlot
+}
+
+// hostSlot is the limiter state of one host
+type hostSlot struct {
+	inFlight chan struct{}
+	next     time.Time // earliest start of the next request
+}
+
+// newHostLimiter returns a limiter allowing maxConcurrent requests per host started at least minInterval apart, 0 disables a limit
+func newHostLimiter(maxConcurrent int, minInterval time.Duration) *hostLimiter {
+	return &hostLimiter{maxConcurrent: maxConcurrent, minInterval: minInterval, hosts: make(map[string]*hostSlot)}
+}
+
+// acquire waits until a request to host may start and returns the function that ends it; a nil limiter never waits
+func (l *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	l.mu.Lock()
+	slot, ok := l.hosts[host]
+	if !ok {
+		slot = &hostSlot{}
+		if l.maxConcurrent > 0 {
+			slot.inFlight = make(chan struct{}, l.maxConcurrent)
+		}
+		l.hosts[host] = slot
+	}
+	l.mu.Unlock()
+
+	release := func() {}
+	if slot.inFlight != nil {
+		select {
+		case slot.inFlight <- struct{}{}:
+			release = func() { <-slot.inFlight }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := now
+	if slot.next.After(now) {
+		start = slot.next
+	}
+	slot.next = start.Add(l.minInterval)
+	l.mu.Unlock()
+
+	if wait := start.Sub(now); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return release, nil
+}
+
+// Side effect free functions
+
+// slowestListed is the number of destinations the check summary lists by response time
+const slowestListed = 5
+
+// DestinationTiming is the time to first byte of a probed destination
+type DestinationTiming struct {
+	URL      string
+	Duration time.Duration
+	Rules    int // rules redirecting to the destination
+}
+
+// slowestDestinations returns up to n timings, slowest first; equal durations keep rule order
+func slowestDestinations(timings []DestinationTiming, n int) []DestinationTiming {
+	sorted := slices.Clone(timings)
+	slices.SortStableFunc(sorted, func(a, b DestinationTiming) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// healthDetails returns the issue details of a health check response
+func healthDetails(response healthResponse) map[string]interface{} {
+	details := map[string]interface{}{"attempts": response.Attempts, "response_time_ms": response.Duration.Milliseconds()}
+	if response.Cached {
+		details["cached"] = true
+	}
+	return details
+}
+
+// formatResponseTime renders a response time in milliseconds below one second and in seconds above
+func formatResponseTime(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// limiterHost returns the host a destination's requests count against, ignoring case
+func limiterHost(targetURL string) string {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Host)
+}
+
+// isTransientHealthFailure reports whether a health check result is worth retrying: no response at all, or a server error
+func isTransientHealthFailure(statusCode int, err error) bool {
+	return err != nil || statusCode >= 500
+}
+
+// retryDelay returns the backoff before retry number attempt: the base delay doubled per earlier retry, plus up to the same again as jitter
+func retryDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	delay := base << (attempt - 1)
+	return delay + rand.N(delay)
+}
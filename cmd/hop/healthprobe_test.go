@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer answers status for the first failures requests and 200 afterwards
+func flakyServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(status)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHealthProberRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		retries      int
+		wantStatus   int
+		wantAttempts int
+	}{
+		{name: "recovers after two server errors", failures: 2, status: 503, retries: 2, wantStatus: 200, wantAttempts: 3},
+		{name: "gives up after the last retry", failures: 5, status: 500, retries: 2, wantStatus: 500, wantAttempts: 3},
+		{name: "no retries", failures: 1, status: 502, retries: 0, wantStatus: 502, wantAttempts: 1},
+		{name: "client errors are not retried", failures: 5, status: 404, retries: 2, wantStatus: 404, wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := flakyServer(t, tt.failures, tt.status)
+			prober := &healthProber{retries: tt.retries, baseDelay: time.Millisecond}
+
+			response, err := prober.probe(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("probe() error = %v", err)
+			}
+			if response.Status != tt.wantStatus || response.Attempts != tt.wantAttempts || int(requests.Load()) != tt.wantAttempts {
+				t.Errorf("probe() = status %d after %d attempts (%d requests), want %d after %d", response.Status, response.Attempts, requests.Load(), tt.wantStatus, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestHealthProberRetriesNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target := server.URL
+	server.Close()
+
+	prober := &healthProber{retries: 2, baseDelay: time.Millisecond}
+	response, err := prober.probe(context.Background(), target)
+	if err == nil || response.Attempts != 3 {
+		t.Errorf("probe() = %d attempts, error %v; want 3 attempts and a connection error", response.Attempts, err)
+	}
+}
+
+func TestHealthProberRespectsDeadline(t *testing.T) {
+	server, requests := flakyServer(t, 100, 500)
+	prober := &healthProber{retries: 5, baseDelay: time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	begin := time.Now()
+	response, _ := prober.probe(ctx, server.URL)
+	if elapsed := time.Since(begin); elapsed > 500*time.Millisecond {
+		t.Errorf("probe() took %v, the backoff ignored the deadline", elapsed)
+	}
+	if response.Status != 500 || response.Attempts != 1 || requests.Load() != 1 {
+		t.Errorf("probe() = status %d after %d attempts, want the first 500", response.Status, response.Attempts)
+	}
+}
+
+func TestNilHealthProberMakesOneAttempt(t *testing.T) {
+	server, requests := flakyServer(t, 1, 500)
+	var prober *healthProber
+	if response, _ := prober.probe(context.Background(), server.URL); response.Status != 500 || response.Attempts != 1 || requests.Load() != 1 {
+		t.Errorf("nil prober = status %d after %d attempts, want one 500", response.Status, response.Attempts)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt, want := range map[int]time.Duration{1: base, 2: 2 * base, 3: 4 * base} {
+		for range 20 {
+			if got := retryDelay(base, attempt); got < want || got >= 2*want {
+				t.Fatalf("retryDelay(%v, %d) = %v, want in [%v, %v)", base, attempt, got, want, 2*want)
+			}
+		}
+	}
+	if got := retryDelay(0, 3); got != 0 {
+		t.Errorf("retryDelay(0, 3) = %v, want 0", got)
+	}
+}
+
+func TestCheckDestinationHealthReportsAttempts(t *testing.T) {
+	server, _ := flakyServer(t, 10, 503)
+	rule := redirectRule("r", "/old", server.URL+"/new")
+	prober := &healthProber{retries: 1, baseDelay: time.Millisecond}
+
+	issues, _, cancelled := checkDestinationHealth(context.Background(), &rule, nil, nil, prober)
+	if cancelled || len(issues) != 1 || issues[0].Severity != "critical" {
+		t.Fatalf("checkDestinationHealth() = %+v, want one critical issue", issues)
+	}
+	if issues[0].Details["attempts"] != 2 {
+		t.Errorf("attempts = %v, want 2", issues[0].Details["attempts"])
+	}
+}
+
+func TestHostLimiterSpacesRequestsToOneHost(t *testing.T) {
+	var mu sync.Mutex
+	var starts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	interval := 40 * time.Millisecond
+	prober := newHealthProber(0, 0, newHostLimiter(4, interval), nil)
+	runHealthWorkers(6, func(int) {
+		if _, err := prober.probe(context.Background(), server.URL); err != nil {
+			t.Errorf("probe() error = %v", err)
+		}
+	})
+
+	if len(starts) != 6 {
+		t.Fatalf("got %d requests, want 6", len(starts))
+	}
+	slices.SortFunc(starts, time.Time.Compare)
+	for i := 1; i < len(starts); i++ {
+		// the request arrives a little after the limiter lets it start, allow for that jitter
+		if gap := starts[i].Sub(starts[i-1]); gap < interval-10*time.Millisecond {
+			t.Errorf("requests %d and %d started %v apart, want at least %v", i-1, i, gap, interval)
+		}
+	}
+}
+
+func TestHostLimiterBoundsConcurrencyPerHost(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	slowServer := func() *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			for {
+				old := peak.Load()
+				if current <= old || peak.CompareAndSwap(old, current) {
+					break
+				}
+			}
+			time.Sleep(150 * time.Millisecond)
+			inFlight.Add(-1)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	first, second := slowServer(), slowServer()
+
+	// one request per host at a time: the two hosts still overlap, requests to the same host do not
+	prober := newHealthProber(0, 0, newHostLimiter(1, 0), nil)
+	targets := []string{first.URL, second.URL, first.URL, second.URL}
+	begin := time.Now()
+	runHealthWorkers(len(targets), func(i int) {
+		if _, err := prober.probe(context.Background(), targets[i]); err != nil {
+			t.Errorf("probe() error = %v", err)
+		}
+	})
+	elapsed := time.Since(begin)
+
+	if peak.Load() != 2 {
+		t.Errorf("peak concurrency = %d, want 2 (one per host)", peak.Load())
+	}
+	if elapsed >= 550*time.Millisecond {
+		t.Errorf("four 150ms requests to two hosts took %v, want them to run two at a time", elapsed)
+	}
+}
+
+func TestLimiterHost(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://Example.com/path", want: "example.com"},
+		{url: "http://example.com:8080/", want: "example.com:8080"},
+		{url: "://bad", want: ""},
+	}
+	for _, tt := range tests {
+		if got := limiterHost(tt.url); got != tt.want {
+			t.Errorf("limiterHost(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+// slowServer answers 200 after delay
+func slowServer(t *testing.T, delay time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCheckDestinationHealthReportsSlowDestinations(t *testing.T) {
+	server := slowServer(t, 150*time.Millisecond)
+	rule := redirectRule("r", "/old", server.URL+"/new")
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		wantSlow  bool
+	}{
+		{name: "slower than the threshold", threshold: 50 * time.Millisecond, wantSlow: true},
+		{name: "faster than the threshold", threshold: 2 * time.Second, wantSlow: false},
+		{name: "disabled", threshold: 0, wantSlow: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, duration, cancelled := checkDestinationHealth(context.Background(), &rule, nil, nil, newHealthProber(0, tt.threshold, nil, nil))
+			if cancelled {
+				t.Fatal("checkDestinationHealth() was cancelled")
+			}
+			if duration < 150*time.Millisecond {
+				t.Errorf("duration = %v, want at least the handler's 150ms", duration)
+			}
+			if gotSlow := len(issues) == 1 && issues[0].Severity == "warning"; gotSlow != tt.wantSlow || len(issues) > 1 {
+				t.Fatalf("checkDestinationHealth() = %+v, want slow warning %v", issues, tt.wantSlow)
+			}
+			if tt.wantSlow && issues[0].Details["response_time_ms"].(int64) < 150 {
+				t.Errorf("response_time_ms = %v, want at least 150", issues[0].Details["response_time_ms"])
+			}
+		})
+	}
+}
+
+func TestStreamURLHealthRecordsTimings(t *testing.T) {
+	slow := slowServer(t, 100*time.Millisecond)
+	fast := slowServer(t, 0)
+	rules := []EdgeRuleResponse{
+		redirectRule("a", "/a", fast.URL+"/x"),
+		redirectRule("b", "/b", slow.URL+"/y"),
+		redirectRule("c", "/c", slow.URL+"/y"),
+	}
+
+	run := streamURLHealth(context.Background(), rules, nil, nil, nil, nil, nil)
+	if len(run.Timings) != 2 {
+		t.Fatalf("Timings = %+v, want one per destination", run.Timings)
+	}
+	slowest := slowestDestinations(run.Timings, 1)
+	if len(slowest) != 1 || slowest[0].URL != slow.URL+"/y" || slowest[0].Rules != 2 {
+		t.Errorf("slowestDestinations() = %+v, want the shared slow destination", slowest)
+	}
+}
+
+func TestSlowestDestinations(t *testing.T) {
+	timings := []DestinationTiming{
+		{URL: "a", Duration: 2 * time.Second},
+		{URL: "b", Duration: 5 * time.Second},
+		{URL: "c", Duration: time.Second},
+		{URL: "d", Duration: 5 * time.Second},
+	}
+	tests := []struct {
+		name string
+		n    int
+		want []string
+	}{
+		{name: "top two, ties in rule order", n: 2, want: []string{"b", "d"}},
+		{name: "more than available", n: 5, want: []string{"b", "d", "a", "c"}},
+		{name: "none", n: 0, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, timing := range slowestDestinations(timings, tt.n) {
+				got = append(got, timing.URL)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("slowestDestinations() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if timings[0].URL != "a" {
+		t.Error("slowestDestinations() reordered its input")
+	}
+}
+
+func TestFormatResponseTime(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 250 * time.Millisecond, want: "250ms"},
+		{d: 3200 * time.Millisecond, want: "3.2s"},
+	}
+	for _, tt := range tests {
+		if got := formatResponseTime(tt.d); got != tt.want {
+			t.Errorf("formatResponseTime(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
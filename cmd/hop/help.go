@@ -0,0 +1,414 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/kong"
+)
+
+// CommandExample is a ready-to-run example of a command. Args exclude the leading "hop" so tests can parse them.
+type CommandExample struct {
+	Description string
+	Args        []string
+}
+
+// CommandDoc is the extended help of a command shown by hop help and hop man
+type CommandDoc struct {
+	Long     string
+	Examples []CommandExample
+}
+
+// commandDocs holds the extended help of every command, keyed by command path
+var commandDocs = map[string]CommandDoc{
+	"check": {
+		Long: "Runs every check for a pull zone: redirect rules, destination health, DNS records and SSL. " +
+			"The COVERAGE block lists which checks ran, ran partially or were skipped. " + checkExitCodesHelp,
+		Examples: []CommandExample{
+			{Description: "Check a zone", Args: []string{"check", "--key", "YOUR_API_KEY", "--zone", "mysite"}},
+			{Description: "Check quickly without HTTP health checks", Args: []string{"check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--skip-health"}},
+			{Description: "Fail the CI build on warnings too", Args: []string{"check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--fail-on", "warning"}},
+			{Description: "Leave out the flaky destination health checks", Args: []string{"check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--skip", "url_health"}},
+			{Description: "Show issues as annotations in a GitHub Actions workflow", Args: []string{"check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--format", "github"}},
+			{Description: "Write a shareable HTML report of the audit", Args: []string{"check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--report-html", "audit.html"}},
+		},
+	},
+	"rules add": {
+		Long: "Adds a 302 redirect from a source path to a destination URL. " +
+			"Use --overwrite to update the existing redirect for the same source instead of adding a second one.",
+		Examples: []CommandExample{
+			{Description: "Redirect an old page", Args: []string{"rules", "add", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "/old-page", "--to", "https://example.com/new-page"}},
+			{Description: "Redirect a section and keep the query string", Args: []string{"rules", "add", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "/blog/*", "--to", "https://blog.example.com/", "--preserve-query"}},
+		},
+	},
+	"rules list": {
+		Long: "Lists the 302 redirects of a zone, or every edge rule with --all. Filters combine, and --format csv exports the rules.",
+		Examples: []CommandExample{
+			{Description: "List redirects in evaluation order", Args: []string{"rules", "list", "--key", "YOUR_API_KEY", "--zone", "mysite", "--sort", "order"}},
+			{Description: "Export every edge rule as CSV", Args: []string{"rules", "list", "--key", "YOUR_API_KEY", "--zone", "mysite", "--all", "--format", "csv"}},
+		},
+	},
+	"rules find": {
+		Long: "Shows the redirect rules whose triggers match a path and which one Bunny applies. Exits with code 2 when no redirect matches.",
+		Examples: []CommandExample{
+			{Description: "Find the redirect for a path", Args: []string{"rules", "find", "--key", "YOUR_API_KEY", "--zone", "mysite", "--source", "/docs/intro"}},
+		},
+	},
+	"rules get": {
+		Long: "Shows a single edge rule with all triggers and parameters, or the raw API JSON with --json.",
+		Examples: []CommandExample{
+			{Description: "Show a rule as JSON", Args: []string{"rules", "get", "--key", "YOUR_API_KEY", "--zone", "mysite", "--guid", "RULE_GUID", "--json"}},
+		},
+	},
+	"rules check": {
+		Long: "Checks the redirect rules of a zone for duplicates, loops, chains, shadowed rules, insecure destinations and unhealthy destinations. " +
+			checkExitCodesHelp,
+		Examples: []CommandExample{
+			{Description: "Check the rules", Args: []string{"rules", "check", "--key", "YOUR_API_KEY", "--zone", "mysite"}},
+			{Description: "Health check a 10% sample and stop at the first error", Args: []string{"rules", "check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--health-sample", "10%", "--fail-fast"}},
+			{Description: "Only fail on critical issues, such as destinations answering 5xx", Args: []string{"rules", "check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--fail-on", "critical"}},
+			{Description: "Only look for loops and duplicates in CI", Args: []string{"rules", "check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--only", "redirect_loop,redirect_chain,configuration"}},
+			{Description: "Find redirects whose source page still exists on the origin", Args: []string{"rules", "check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--verify-sources", "--origin-host", "origin.example.com"}},
+			{Description: "Feed the results to a dashboard", Args: []string{"rules", "check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--format", "json"}},
+			{Description: "Export the issues for triage in a spreadsheet", Args: []string{"rules", "check", "--key", "YOUR_API_KEY", "--zone", "mysite", "--report-csv", "issues.csv"}},
+		},
+	},
+	"rules backup": {
+		Long: "Saves every edge rule of a zone, exactly as the API returns it, to a timestamped JSON file for rules restore.",
+		Examples: []CommandExample{
+			{Description: "Back up the rules into a directory", Args: []string{"rules", "backup", "--key", "YOUR_API_KEY", "--zone", "mysite", "--out", "backups"}},
+		},
+	},
+	"rules add-host-redirect": {
+		Long: "Redirects every request for one hostname of the zone to the same path on another hostname of the zone. " +
+			"Running it again updates the hop-managed rule instead of adding a second one.",
+		Examples: []CommandExample{
+			{Description: "Redirect www to the apex domain", Args: []string{"rules", "add-host-redirect", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from-host", "www.example.com", "--to-host", "example.com", "--status", "301"}},
+		},
+	},
+	"rules add-origin": {
+		Long: "Adds an OriginUrl edge rule that fetches requests matching a path pattern from another origin, e.g. an API backend. " +
+			"Running it again for the same pattern updates that rule instead of adding a second one.",
+		Examples: []CommandExample{
+			{Description: "Serve the API from its own backend", Args: []string{"rules", "add-origin", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "/api/*", "--origin", "https://api-backend.example.com"}},
+		},
+	},
+	"rules block": {
+		Long: "Adds a BlockRequest edge rule that denies every request matching a path pattern at the edge. " +
+			"Running it again for the same pattern updates that rule, and redirects whose source overlaps the pattern are warned about.",
+		Examples: []CommandExample{
+			{Description: "Block WordPress login probes", Args: []string{"rules", "block", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "/wp-admin*"}},
+		},
+	},
+	"rules set-header": {
+		Long: "Adds a SetResponseHeader edge rule for requests matching a path pattern. The header is given as 'Name: value', " +
+			"the value may contain colons but no line breaks. Running it again for the same pattern and header name updates that rule.",
+		Examples: []CommandExample{
+			{Description: "Keep legacy pages out of search engines", Args: []string{"rules", "set-header", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "/legacy/*", "--header", "X-Robots-Tag: noindex"}},
+		},
+	},
+	"rules add-slash-policy": {
+		Long: "Adds one wildcard redirect that sends every path without a trailing slash to the same path with one, keeping the query string. " +
+			"Paths with a common file extension are left alone. Refuses to run while per-path redirects without a trailing slash exist. " +
+			"The strip policy cannot be expressed as a single Bunny edge rule and is rejected.",
+		Examples: []CommandExample{
+			{Description: "Show the rule for the add policy", Args: []string{"rules", "add-slash-policy", "--key", "YOUR_API_KEY", "--zone", "mysite", "--policy", "add", "--dry-run"}},
+		},
+	},
+	"rules check-sitemap": {
+		Long: "Downloads a sitemap, following sitemap indexes, and reports every listed URL that an enabled redirect would send elsewhere. " +
+			"Live pages should never redirect, so each one is an error and the command exits with code 1.",
+		Examples: []CommandExample{
+			{Description: "Check the live pages before a migration", Args: []string{"rules", "check-sitemap", "--key", "YOUR_API_KEY", "--zone", "mysite", "--sitemap", "https://example.com/sitemap.xml"}},
+			{Description: "Print the redirected URLs as JSON", Args: []string{"rules", "check-sitemap", "--key", "YOUR_API_KEY", "--zone", "mysite", "--sitemap", "https://example.com/sitemap.xml", "--format", "json"}},
+		},
+	},
+	"rules validate": {
+		Long: "Runs the redirect checks that need no API access on a rules file: basic, configuration, security and loop checks. " +
+			"The file is a rules backup or a JSON list of {\"from\", \"to\", \"status\"} redirects. Exits with code 1 on errors, so CI can lint redirects before they are applied.",
+		Examples: []CommandExample{
+			{Description: "Lint a redirects file in CI", Args: []string{"rules", "validate", "--file", "rules.json"}},
+			{Description: "Treat destinations on the site's own hostnames as internal", Args: []string{"rules", "validate", "--file", "rules.json", "--hostnames", "example.com,www.example.com"}},
+		},
+	},
+	"rules test": {
+		Long: "Simulates a request against the zone's edge rules without sending traffic: the first enabled redirect whose triggers match wins, " +
+			"and the chain is followed while it stays on the zone's hostnames. With --live a real request checks that the first response matches.",
+		Examples: []CommandExample{
+			{Description: "Show what happens to an old URL", Args: []string{"rules", "test", "--key", "YOUR_API_KEY", "--zone", "mysite", "--path", "/products/old-widget"}},
+			{Description: "Compare the simulation with the live site", Args: []string{"rules", "test", "--key", "YOUR_API_KEY", "--zone", "mysite", "--path", "/products/old-widget", "--host", "www.example.com", "--live"}},
+		},
+	},
+	"rules move": {
+		Long: "Moves an edge rule in front of another one. Bunny evaluates rules in order, so a wildcard above a specific redirect hides it.",
+		Examples: []CommandExample{
+			{Description: "Let a specific redirect fire before a wildcard", Args: []string{"rules", "move", "--key", "YOUR_API_KEY", "--zone", "mysite", "--guid", "RULE_GUID", "--before", "WILDCARD_GUID"}},
+		},
+	},
+	"rules park": {
+		Long: "Redirects a parked domain in one go: adds the hostname to the zone, a catch-all redirect, a DNS record on Bunny DNS and a free certificate. " +
+			"Steps already in place are left alone, so the command can be run again until the certificate is issued.",
+		Examples: []CommandExample{
+			{Description: "Park an old brand domain", Args: []string{"rules", "park", "--key", "YOUR_API_KEY", "--zone", "redirects", "--domain", "oldbrand.com", "--to", "https://www.newbrand.com", "--preserve-path", "--dry-run"}},
+		},
+	},
+	"rules fix": {
+		Long: "Fixes the basic redirect issues of rules check that have one safe correction: 301 redirects become 302 and redirects without a status code get 302. " +
+			"Shows the plan and asks before changing anything, then backs up all edge rules and updates each rule under its GUID. Other issues are listed and left alone.",
+		Examples: []CommandExample{
+			{Description: "Review and apply the fixes", Args: []string{"rules", "fix", "--key", "YOUR_API_KEY", "--zone", "mysite"}},
+			{Description: "Apply the fixes without asking, backing up to ./backups", Args: []string{"rules", "fix", "--key", "YOUR_API_KEY", "--zone", "mysite", "--yes", "--out", "backups"}},
+		},
+	},
+	"rules restore": {
+		Long: "Compares a backup with the current rules and, with --apply, re-creates deleted rules and reverts changed ones. Rules added since the backup are left alone.",
+		Examples: []CommandExample{
+			{Description: "Restore a backup", Args: []string{"rules", "restore", "--key", "YOUR_API_KEY", "--zone", "mysite", "--file", "hop-backup-mysite-20260101T000000Z.json", "--apply"}},
+		},
+	},
+	"rules diff": {
+		Long: "Compares the redirects of two zones: sources only in one of them and sources with a different destination, status code or enabled state. " +
+			"Exits with code 1 when the zones differ.",
+		Examples: []CommandExample{
+			{Description: "Detect drift between staging and production", Args: []string{"rules", "diff", "--key", "YOUR_API_KEY", "--zone-a", "staging", "--zone-b", "prod", "--format", "json"}},
+		},
+	},
+	"rules delete": {
+		Long: "Deletes every redirect whose source starts with a prefix or matches a pattern, after confirmation. " +
+			"Stops without deleting anything when more than --max rules match.",
+		Examples: []CommandExample{
+			{Description: "Delete a decommissioned section", Args: []string{"rules", "delete", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from-prefix", "/old-blog/"}},
+			{Description: "Delete by pattern without asking", Args: []string{"rules", "delete", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from-glob", "/old-blog/*", "--yes", "--max", "100"}},
+		},
+	},
+	"rules dedupe": {
+		Long: "Finds redirects for the same source path and, with --apply, deletes the duplicates. Duplicates with different destinations are only resolved with --keep-first.",
+		Examples: []CommandExample{
+			{Description: "Delete duplicates after a backup", Args: []string{"rules", "dedupe", "--key", "YOUR_API_KEY", "--zone", "mysite", "--apply", "--backup"}},
+		},
+	},
+	"rules gaps": {
+		Long: "Reads the access logs and lists the most frequent 404 paths that no redirect covers.",
+		Examples: []CommandExample{
+			{Description: "Find gaps in the last week", Args: []string{"rules", "gaps", "--key", "YOUR_API_KEY", "--zone", "mysite", "--since", "168h"}},
+			{Description: "Read a downloaded log file", Args: []string{"rules", "gaps", "--key", "YOUR_API_KEY", "--zone", "mysite", "--log-file", "access.log"}},
+		},
+	},
+	"rules suggest": {
+		Long: "Lists the known paths under a prefix, from the redirects and an optional sitemap, and whether a redirect handles them.",
+		Examples: []CommandExample{
+			{Description: "Print commands for unredirected paths", Args: []string{"rules", "suggest", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from-prefix", "/docs/", "--sitemap", "sitemap.xml", "--commands"}},
+		},
+	},
+	"cdn push": {
+		Long: "Uploads a local directory to the zone's storage, skipping files whose checksum is unchanged. " +
+			"With --release the files go to releases/<id>/ and the zone switches to them once every upload succeeded.",
+		Examples: []CommandExample{
+			{Description: "Push a build", Args: []string{"cdn", "push", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "./dist"}},
+			{Description: "Preview a push without uploading", Args: []string{"cdn", "push", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "./dist", "--dry-run"}},
+			{Description: "Push without source maps", Args: []string{"cdn", "push", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "./dist", "--exclude", "**/*.map"}},
+			{Description: "Push an atomic release with immutable hashed assets", Args: []string{"cdn", "push", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "./dist", "--release", "v42", "--hashed-assets"}},
+			{Description: "Get notified when a long push ends", Args: []string{"cdn", "push", "--key", "YOUR_API_KEY", "--zone", "mysite", "--from", "./dist", "--notify-desktop", "--on-complete", "echo {status} {summary} >> push.log"}},
+		},
+	},
+	"cdn check": {
+		Long: "Checks that every hostname of the zone has a certificate and serves HTTPS. " + checkExitCodesHelp,
+		Examples: []CommandExample{
+			{Description: "Check SSL", Args: []string{"cdn", "check", "--key", "YOUR_API_KEY", "--zone", "mysite"}},
+		},
+	},
+	"cdn releases list": {
+		Long: "Lists the releases uploaded with cdn push --release and marks the active one.",
+		Examples: []CommandExample{
+			{Description: "List releases", Args: []string{"cdn", "releases", "list", "--key", "YOUR_API_KEY", "--zone", "mysite"}},
+		},
+	},
+	"cdn releases activate": {
+		Long: "Switches the zone to an uploaded release, also to roll back to an earlier one.",
+		Examples: []CommandExample{
+			{Description: "Roll back to an earlier release", Args: []string{"cdn", "releases", "activate", "--key", "YOUR_API_KEY", "--zone", "mysite", "--release", "v41"}},
+		},
+	},
+	"cdn releases prune": {
+		Long: "Deletes old releases from storage. The active release and the most recent inactive ones are kept.",
+		Examples: []CommandExample{
+			{Description: "Show which releases would be deleted", Args: []string{"cdn", "releases", "prune", "--key", "YOUR_API_KEY", "--zone", "mysite", "--keep", "5", "--dry-run"}},
+		},
+	},
+	"zones clone": {
+		Long: "Creates a copy of a pull zone, for example a staging zone, optionally with its redirects and a checklist for staging hostnames.",
+		Examples: []CommandExample{
+			{Description: "Clone production into staging with its redirects", Args: []string{"zones", "clone", "--key", "YOUR_API_KEY", "--from", "prod", "--to", "staging", "--with-rules"}},
+			{Description: "Compare the redirects of both zones", Args: []string{"zones", "clone", "--key", "YOUR_API_KEY", "--from", "prod", "--to", "staging", "--diff"}},
+		},
+	},
+	"dns list": {
+		Long: "Lists the A and CNAME records on Bunny DNS for the hostnames of a zone.",
+		Examples: []CommandExample{
+			{Description: "List DNS records", Args: []string{"dns", "list", "--key", "YOUR_API_KEY", "--zone", "mysite"}},
+		},
+	},
+	"dns check": {
+		Long: "Checks that every hostname of the zone has a DNS record on Bunny DNS. " + checkExitCodesHelp,
+		Examples: []CommandExample{
+			{Description: "Check DNS records", Args: []string{"dns", "check", "--key", "YOUR_API_KEY", "--zone", "mysite"}},
+		},
+	},
+	"help": {
+		Long: "Shows the extended help of a command with examples, or the complete reference with --all.",
+		Examples: []CommandExample{
+			{Description: "Describe a command", Args: []string{"help", "rules", "add"}},
+			{Description: "Print the complete reference", Args: []string{"help", "--all"}},
+		},
+	},
+	"man": {
+		Long: "Prints a man page in troff format.",
+		Examples: []CommandExample{
+			{Description: "Print the man page", Args: []string{"man"}},
+		},
+	},
+}
+
+// findCommandNode returns the command node for a path such as ["rules", "add"], or nil if there is none
+func findCommandNode(app *kong.Application, path []string) *kong.Node {
+	node := app.Node
+	for _, name := range path {
+		var next *kong.Node
+		for _, child := range node.Children {
+			if child.Type == kong.CommandNode && child.Name == name {
+				next = child
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+// commandFlags returns the flags of a command without the inherited global flags and the built-in --help
+func commandFlags(node *kong.Node) []*kong.Flag {
+	var flags []*kong.Flag
+	for _, flag := range node.Flags {
+		if !flag.Hidden && flag.Name != "help" {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
+
+// flagDescription returns a flag's help with its allowed values and whether it is required, the default is part of the flag's placeholder
+func flagDescription(flag *kong.Flag) string {
+	description := flag.Help
+	if flag.Enum != "" {
+		description += fmt.Sprintf(" (one of: %s)", strings.ReplaceAll(flag.Enum, ",", ", "))
+	}
+	if flag.Required {
+		description += " (required)"
+	}
+	return description
+}
+
+// writeCommandHelp prints the extended help of a command: usage, description, flags and examples
+func writeCommandHelp(w io.Writer, node *kong.Node) {
+	path := node.Path()
+	doc := commandDocs[path]
+
+	fmt.Fprintf(w, "Usage: hop %s", path)
+	if summary := node.FlagSummary(true); summary != "" {
+		fmt.Fprintf(w, " %s", summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "\n%s\n", node.Help)
+	if doc.Long != "" {
+		fmt.Fprintf(w, "\n%s\n", doc.Long)
+	}
+
+	if len(node.Children) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		for _, child := range node.Children {
+			fmt.Fprintf(w, "  %-24s %s\n", child.Name, child.Help)
+		}
+	}
+
+	if flags := commandFlags(node); len(flags) > 0 {
+		fmt.Fprintln(w, "\nFlags:")
+		for _, flag := range flags {
+			fmt.Fprintf(w, "  %s\n      %s\n", flag, flagDescription(flag))
+		}
+	}
+
+	if len(doc.Examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, example := range doc.Examples {
+			fmt.Fprintf(w, "  # %s\n  hop %s\n", example.Description, formatExampleArgs(example.Args))
+		}
+	}
+}
+
+// writeReference prints the complete plain-text reference: global flags and the extended help of every command
+func writeReference(w io.Writer, app *kong.Application) {
+	fmt.Fprintf(w, "hop - %s\n", app.Help)
+	fmt.Fprintln(w, "\nGlobal flags:")
+	for _, flag := range commandFlags(app.Node) {
+		fmt.Fprintf(w, "  %s\n      %s\n", flag, flagDescription(flag))
+	}
+	for _, node := range app.Leaves(true) {
+		fmt.Fprintf(w, "\n%s\n", strings.Repeat("=", 71))
+		writeCommandHelp(w, node)
+	}
+}
+
+// writeManPage prints a troff man page covering every command
+func writeManPage(w io.Writer, app *kong.Application) {
+	fmt.Fprintln(w, `.TH HOP 1 "" "hop" "User Commands"`)
+	fmt.Fprintf(w, ".SH NAME\nhop \\- %s\n", manEscape(app.Help))
+	fmt.Fprintln(w, ".SH SYNOPSIS\n.B hop\n[\\fIGLOBAL FLAGS\\fR] \\fICOMMAND\\fR [\\fIFLAGS\\fR]")
+
+	fmt.Fprintln(w, ".SH GLOBAL FLAGS")
+	for _, flag := range commandFlags(app.Node) {
+		fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(flag.String()), manEscape(flagDescription(flag)))
+	}
+
+	fmt.Fprintln(w, ".SH COMMANDS")
+	for _, node := range app.Leaves(true) {
+		doc := commandDocs[node.Path()]
+		fmt.Fprintf(w, ".SS %s\n%s\n", manEscape("hop "+node.Path()), manEscape(node.Help))
+		if doc.Long != "" {
+			fmt.Fprintf(w, ".PP\n%s\n", manEscape(doc.Long))
+		}
+		for _, flag := range commandFlags(node) {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(flag.String()), manEscape(flagDescription(flag)))
+		}
+		for _, example := range doc.Examples {
+			fmt.Fprintf(w, ".PP\n%s:\n.RS\n.nf\nhop %s\n.fi\n.RE\n", manEscape(example.Description), manEscape(formatExampleArgs(example.Args)))
+		}
+	}
+}
+
+// Side effect free functions
+
+// formatExampleArgs joins example arguments into a command line, quoting arguments the shell would change
+func formatExampleArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " *?%$'\"&|;<>()") {
+			quoted[i] = shellQuote(arg)
+		} else {
+			quoted[i] = arg
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// manEscape escapes text for troff: backslashes, hyphens, and control characters at the start of a line
+func manEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	text = strings.ReplaceAll(text, "-", `\-`)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kong"
+)
+
+// newTestParser builds the hop parser on the global CLI, restoring the parsed values when the test ends
+func newTestParser(t *testing.T) *kong.Kong {
+	t.Helper()
+	saved := CLI
+	t.Cleanup(func() { CLI = saved })
+
+	parser, err := kong.New(&CLI, kong.Name("hop"), kong.Exit(func(int) { t.Fatal("parser tried to exit") }))
+	if err != nil {
+		t.Fatalf("kong.New() error = %v", err)
+	}
+	return parser
+}
+
+func TestCommandDocExamplesParse(t *testing.T) {
+	for path, doc := range commandDocs {
+		for _, example := range doc.Examples {
+			t.Run(example.Description, func(t *testing.T) {
+				parser := newTestParser(t)
+				ctx, err := parser.Parse(example.Args)
+				if err != nil {
+					t.Fatalf("hop %s: %v", strings.Join(example.Args, " "), err)
+				}
+				command := strings.TrimSuffix(ctx.Command(), " <command>")
+				if command != path {
+					t.Errorf("example parses as %q, but is documented under %q", command, path)
+				}
+			})
+		}
+	}
+}
+
+func TestEveryCommandIsDocumented(t *testing.T) {
+	parser := newTestParser(t)
+	for _, node := range parser.Model.Leaves(true) {
+		doc, ok := commandDocs[node.Path()]
+		if !ok || doc.Long == "" || len(doc.Examples) == 0 {
+			t.Errorf("command %q has no long description or examples in commandDocs", node.Path())
+		}
+	}
+}
+
+func TestWriteCommandHelp(t *testing.T) {
+	parser := newTestParser(t)
+	node := findCommandNode(parser.Model, []string{"rules", "delete"})
+	if node == nil {
+		t.Fatal("findCommandNode(rules delete) = nil")
+	}
+
+	var out bytes.Buffer
+	writeCommandHelp(&out, node)
+
+	for _, want := range []string{
+		"Usage: hop rules delete --key=STRING --zone=STRING",
+		"--max=50\n      Refuse to delete more than this many rules\n",
+		"--key=STRING\n      Bunny CDN API key (required)\n",
+		"# Delete by pattern without asking\n  hop rules delete --key YOUR_API_KEY --zone mysite --from-glob '/old-blog/*' --yes --max 100\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("help missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if findCommandNode(parser.Model, []string{"rules", "nope"}) != nil {
+		t.Error("findCommandNode(rules nope) should be nil")
+	}
+}
+
+func TestWriteManPage(t *testing.T) {
+	parser := newTestParser(t)
+
+	var out bytes.Buffer
+	writeManPage(&out, parser.Model)
+	page := out.String()
+
+	if !strings.HasPrefix(page, ".TH HOP 1") {
+		t.Errorf("man page does not start with .TH:\n%s", page[:40])
+	}
+	for _, node := range parser.Model.Leaves(true) {
+		if want := ".SS " + manEscape("hop "+node.Path()) + "\n"; !strings.Contains(page, want) {
+			t.Errorf("man page has no section %q", want)
+		}
+	}
+	for _, line := range strings.Split(page, "\n") {
+		if strings.HasPrefix(line, "'") {
+			t.Errorf("line starts with a troff control character: %q", line)
+		}
+	}
+}
+
+func TestManEscape(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "--skip-health", want: `\-\-skip\-health`},
+		{text: `C:\path`, want: `C:\epath`},
+		{text: ".env files", want: `\&.env files`},
+		{text: "plain", want: "plain"},
+	}
+	for _, tt := range tests {
+		if got := manEscape(tt.text); got != tt.want {
+			t.Errorf("manEscape(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFormatExampleArgs(t *testing.T) {
+	got := formatExampleArgs([]string{"rules", "check", "--health-sample", "10%", "--from", "/blog/*"})
+	if want := "rules check --health-sample '10%' --from '/blog/*'"; got != want {
+		t.Errorf("formatExampleArgs() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hostRedirectPrefix marks hop-managed redirects from one hostname of a zone to another
+const hostRedirectPrefix = "hop-host-redirect: "
+
+// Side effect free functions
+
+// hostRedirectDescription is the generated description of a host redirect, recognizable in rules list
+func hostRedirectDescription(fromHost, toHost string) string {
+	return fmt.Sprintf("%s%s -> %s", hostRedirectPrefix, fromHost, toHost)
+}
+
+// buildHostRedirectRule builds a redirect of every request to fromHost to the same path on toHost
+func buildHostRedirectRule(guid, fromHost, toHost, status string) EdgeRule {
+	rule := buildParkRule(guid, fromHost, "https://"+toHost, true)
+	rule.ActionParameter2 = status
+	rule.Description = hostRedirectDescription(fromHost, toHost)
+	return rule
+}
+
+// findHostRedirectRule returns the hop-managed redirect away from a hostname, or nil if there is none
+func findHostRedirectRule(rules []EdgeRuleResponse, fromHost string) *EdgeRuleResponse {
+	prefix := strings.ToLower(hostRedirectPrefix + fromHost + " -> ")
+	for i, rule := range rules {
+		if strings.HasPrefix(strings.ToLower(rule.Description), prefix) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// validateHostRedirect checks that both hostnames are different and served by the zone
+func validateHostRedirect(hostnames []Hostname, fromHost, toHost string) error {
+	if fromHost == toHost {
+		return fmt.Errorf("--from-host and --to-host are both %s", fromHost)
+	}
+	for _, host := range []string{fromHost, toHost} {
+		if !hasHostname(hostnames, host) {
+			return fmt.Errorf("hostname %s is not added to the pull zone", host)
+		}
+	}
+	return nil
+}
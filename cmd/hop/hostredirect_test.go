@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildHostRedirectRule(t *testing.T) {
+	rule := buildHostRedirectRule("", "www.example.com", "example.com", "301")
+
+	if rule.ActionType != 1 || rule.ActionParameter2 != "301" {
+		t.Errorf("rule = action %d status %s, want redirect 301", rule.ActionType, rule.ActionParameter2)
+	}
+	if rule.ActionParameter1 != "https://example.com%{Url.Path}" {
+		t.Errorf("destination = %s, want the path kept on the target host", rule.ActionParameter1)
+	}
+	if got := strings.Join(rule.Triggers[0].PatternMatches, ","); got != "*://www.example.com/*" {
+		t.Errorf("trigger = %s, want every request to www.example.com", got)
+	}
+	if rule.Description != "hop-host-redirect: www.example.com -> example.com" {
+		t.Errorf("description = %s", rule.Description)
+	}
+}
+
+func TestFindHostRedirectRule(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		{Guid: "other", Description: "hop-host-redirect: shop.example.com -> example.com"},
+		{Guid: "www", Description: "hop-host-redirect: WWW.example.com -> old.example.com"},
+	}
+
+	if got := findHostRedirectRule(rules, "www.example.com"); got == nil || got.Guid != "www" {
+		t.Errorf("findHostRedirectRule(www) = %+v, want the rule redirecting www regardless of its target", got)
+	}
+	if got := findHostRedirectRule(rules, "example.com"); got != nil {
+		t.Errorf("findHostRedirectRule(example.com) = %+v, want nil", got)
+	}
+}
+
+func TestValidateHostRedirect(t *testing.T) {
+	hostnames := []Hostname{{Value: "site.b-cdn.net"}, {Value: "www.example.com"}, {Value: "Example.com"}}
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		wantErr string
+	}{
+		{name: "www to apex", from: "www.example.com", to: "example.com"},
+		{name: "apex to www", from: "example.com", to: "www.example.com"},
+		{name: "same host", from: "example.com", to: "example.com", wantErr: "are both"},
+		{name: "unknown from host", from: "shop.example.com", to: "example.com", wantErr: "shop.example.com is not added"},
+		{name: "unknown to host", from: "www.example.com", to: "example.org", wantErr: "example.org is not added"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHostRedirect(hostnames, tt.from, tt.to)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateHostRedirect() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateHostRedirect() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// HostnameReport collects the DNS and SSL findings of a single hostname
+type HostnameReport struct {
+	Hostname string
+	DNS      []CheckIssue
+	SSL      []CheckIssue
+}
+
+// Side effect free functions
+
+// issueHostname returns the hostname recorded in an issue's details
+func issueHostname(issue CheckIssue) string {
+	if hostname, ok := issue.Details["hostname"].(string); ok {
+		return hostname
+	}
+	return ""
+}
+
+// groupByHostname regroups DNS and SSL results per hostname, in the order of the zone's hostnames
+func groupByHostname(hostnames []Hostname, dnsResult, sslResult CheckResult) []HostnameReport {
+	var reports []HostnameReport
+	index := make(map[string]int)
+
+	report := func(hostname string) *HostnameReport {
+		key := strings.ToLower(hostname)
+		if i, exists := index[key]; exists {
+			return &reports[i]
+		}
+		index[key] = len(reports)
+		reports = append(reports, HostnameReport{Hostname: hostname})
+		return &reports[len(reports)-1]
+	}
+
+	for _, hostname := range hostnames {
+		report(hostname.Value)
+	}
+	for _, issue := range append(append([]CheckIssue{}, dnsResult.Successful...), dnsResult.Issues...) {
+		r := report(issueHostname(issue))
+		r.DNS = append(r.DNS, issue)
+	}
+	for _, issue := range append(append([]CheckIssue{}, sslResult.Successful...), sslResult.Issues...) {
+		r := report(issueHostname(issue))
+		r.SSL = append(r.SSL, issue)
+	}
+
+	return reports
+}
+
+// verdict summarizes a hostname as OK, WARN or ERROR from its most severe finding
+func (r HostnameReport) verdict() string {
+	verdict := "OK"
+	for _, issue := range append(append([]CheckIssue{}, r.DNS...), r.SSL...) {
+		switch issue.Severity {
+		case SeverityCritical, SeverityError:
+			return "ERROR"
+		case SeverityWarning:
+			verdict = "WARN"
+		}
+	}
+	return verdict
+}
+
+// renderHostnameReports prints the findings of every hostname on consecutive lines followed by its verdict
+func renderHostnameReports(w io.Writer, reports []HostnameReport) {
+	counts := map[string]int{}
+	for _, report := range reports {
+		_, _ = fmt.Fprintf(w, "%s\n", report.Hostname)
+		if len(report.DNS) == 0 {
+			_, _ = fmt.Fprintf(w, "  DNS: not checked\n")
+		}
+		for _, issue := range report.DNS {
+			_, _ = fmt.Fprintf(w, "  DNS: %s\n", issue.Message)
+		}
+		if len(report.SSL) == 0 {
+			_, _ = fmt.Fprintf(w, "  SSL: not checked\n")
+		}
+		for _, issue := range report.SSL {
+			_, _ = fmt.Fprintf(w, "  SSL: %s\n", issue.Message)
+		}
+		verdict := report.verdict()
+		counts[verdict]++
+		_, _ = fmt.Fprintf(w, "  Verdict: %s\n\n", verdict)
+	}
+	_, _ = fmt.Fprintf(w, "Hostnames: %d OK, %d with warnings, %d with errors\n", counts["OK"], counts["WARN"], counts["ERROR"])
+}
+
+// hostnameCoverage records a per-hostname check as ran, or as aborted when the context ended during it
+func hostnameCoverage(ctx context.Context, check string, hostnames int) CheckCoverage {
+	if err := ctx.Err(); err != nil {
+		return CheckCoverage{Check: check, Status: "partial", Reason: "aborted: " + err.Error()}
+	}
+	return CheckCoverage{Check: check, Status: "ran", Reason: fmt.Sprintf("%d hostnames", hostnames)}
+}
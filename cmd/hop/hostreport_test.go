@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGroupByHostnameGoldenOutput(t *testing.T) {
+	hostnames := []Hostname{{Value: "site.b-cdn.net"}, {Value: "www.example.com"}, {Value: "shop.example.com"}, {Value: "old.example.com"}}
+
+	dnsResult := CheckResult{
+		Issues: []CheckIssue{
+			{Severity: "error", Message: "MISSING old.example.com - No DNS record found", Details: map[string]interface{}{"hostname": "old.example.com"}},
+		},
+		Successful: []CheckIssue{
+			{Severity: "info", Message: "SKIP site.b-cdn.net (Bunny-managed)", Details: map[string]interface{}{"hostname": "site.b-cdn.net"}},
+			{Severity: "info", Message: "OK www.example.com (CNAME -> site.b-cdn.net)", Details: map[string]interface{}{"hostname": "www.example.com"}},
+			{Severity: "info", Message: "OK shop.example.com (CNAME -> site.b-cdn.net)", Details: map[string]interface{}{"hostname": "shop.example.com"}},
+		},
+	}
+	sslResult := CheckResult{
+		Issues: []CheckIssue{
+			{Severity: "warning", Message: "WARN shop.example.com - Force SSL redirect not configured", Details: map[string]interface{}{"hostname": "shop.example.com"}},
+			{Severity: "error", Message: "ERROR old.example.com - HTTPS not working", Details: map[string]interface{}{"hostname": "old.example.com"}},
+		},
+		Successful: []CheckIssue{
+			{Severity: "info", Message: "OK www.example.com", Details: map[string]interface{}{"hostname": "www.example.com"}},
+			{Severity: "info", Message: "OK site.b-cdn.net", Details: map[string]interface{}{"hostname": "site.b-cdn.net"}},
+		},
+	}
+
+	var out bytes.Buffer
+	renderHostnameReports(&out, groupByHostname(hostnames, dnsResult, sslResult))
+
+	golden := `site.b-cdn.net
+  DNS: SKIP site.b-cdn.net (Bunny-managed)
+  SSL: OK site.b-cdn.net
+  Verdict: OK
+
+www.example.com
+  DNS: OK www.example.com (CNAME -> site.b-cdn.net)
+  SSL: OK www.example.com
+  Verdict: OK
+
+shop.example.com
+  DNS: OK shop.example.com (CNAME -> site.b-cdn.net)
+  SSL: WARN shop.example.com - Force SSL redirect not configured
+  Verdict: WARN
+
+old.example.com
+  DNS: MISSING old.example.com - No DNS record found
+  SSL: ERROR old.example.com - HTTPS not working
+  Verdict: ERROR
+
+Hostnames: 2 OK, 1 with warnings, 1 with errors
+`
+	if out.String() != golden {
+		t.Errorf("renderHostnameReports() output mismatch\ngot:\n%s\nwant:\n%s", out.String(), golden)
+	}
+}
+
+func TestGroupByHostnameUnknownAndMissingResults(t *testing.T) {
+	hostnames := []Hostname{{Value: "www.example.com"}}
+	sslResult := CheckResult{Successful: []CheckIssue{
+		{Severity: "info", Message: "OK WWW.example.com", Details: map[string]interface{}{"hostname": "WWW.example.com"}},
+		{Severity: "info", Message: "OK extra.example.com", Details: map[string]interface{}{"hostname": "extra.example.com"}},
+	}}
+
+	var out bytes.Buffer
+	renderHostnameReports(&out, groupByHostname(hostnames, CheckResult{}, sslResult))
+
+	golden := `www.example.com
+  DNS: not checked
+  SSL: OK WWW.example.com
+  Verdict: OK
+
+extra.example.com
+  DNS: not checked
+  SSL: OK extra.example.com
+  Verdict: OK
+
+Hostnames: 2 OK, 0 with warnings, 0 with errors
+`
+	if out.String() != golden {
+		t.Errorf("renderHostnameReports() output mismatch\ngot:\n%s\nwant:\n%s", out.String(), golden)
+	}
+}
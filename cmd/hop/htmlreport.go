@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"time"
+)
+
+// HTMLReport is the data of the --report-html file of check
+type HTMLReport struct {
+	Zone      string
+	Generated string
+	Passed    bool
+	Sections  []HTMLReportSection
+}
+
+// HTMLReportSection is one check of the report with its findings grouped by severity
+type HTMLReportSection struct {
+	Title  string
+	Error  string // set when the check could not run
+	Counts map[string]int
+	Groups []HTMLSeverityGroup
+}
+
+// HTMLSeverityGroup is the table of one severity
+type HTMLSeverityGroup struct {
+	Severity string
+	Issues   []CheckReportIssue
+}
+
+// reportSeverities are the severities of the report tables, most severe first
+var reportSeverities = []string{SeverityCritical, SeverityError, SeverityWarning, SeverityInfo}
+
+// htmlReportTemplate renders a self-contained page, all styles are inline so the file can be mailed
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>hop check: {{.Zone}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+h1 { margin-bottom: 0.2em; }
+.meta { color: #666; margin-top: 0; }
+.result { font-weight: bold; padding: 0.5em 1em; display: inline-block; border-radius: 4px; }
+.passed { background: #e3f6e5; color: #1b6e2a; }
+.failed { background: #fde8e8; color: #9b1c1c; }
+table { border-collapse: collapse; width: 100%; margin: 0.5em 0 1.5em; }
+th, td { border: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; vertical-align: top; font-size: 0.9em; }
+th { background: #f4f4f4; }
+.counts td { text-align: center; }
+.critical h4, .sev-critical { color: #9b1c1c; }
+.error h4, .sev-error { color: #c2410c; }
+.warning h4, .sev-warning { color: #a16207; }
+.info h4, .sev-info { color: #1d4ed8; }
+.details { margin: 0; padding-left: 1.2em; }
+code { font-size: 0.95em; }
+</style>
+</head>
+<body>
+<h1>Check report for {{.Zone}}</h1>
+<p class="meta">Generated {{.Generated}}</p>
+{{if .Passed}}<p class="result passed">All checks passed</p>{{else}}<p class="result failed">Issues found that require attention</p>{{end}}
+
+<h2>Summary</h2>
+<table class="counts">
+<tr><th>Check</th><th>Critical</th><th>Error</th><th>Warning</th><th>Info</th></tr>
+{{range .Sections}}<tr><td>{{.Title}}</td><td>{{index .Counts "critical"}}</td><td>{{index .Counts "error"}}</td><td>{{index .Counts "warning"}}</td><td>{{index .Counts "info"}}</td></tr>
+{{end}}</table>
+{{range .Sections}}
+<h2>{{.Title}}</h2>
+{{if .Error}}<p class="result failed">{{.Error}}</p>{{end}}
+{{range .Groups}}<div class="{{.Severity}}">
+<h4>{{.Severity}} ({{len .Issues}})</h4>
+<table>
+<tr><th>Type</th><th>Message</th><th>Rule GUID</th><th>Source</th><th>Destination</th><th>Details</th></tr>
+{{range .Issues}}<tr class="issue sev-{{.Severity}}"><td>{{.Type}}</td><td>{{.Message}}</td><td><code>{{.Guid}}</code></td><td>{{.Source}}</td><td>{{.Destination}}</td><td>{{if .Details}}<ul class="details">{{range $key, $value := .Details}}<li>{{$key}}: {{$value}}</li>{{end}}</ul>{{end}}</td></tr>
+{{end}}</table>
+</div>
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+// writeHTMLReport renders the report to a file, redacted by redactor when --redact is set
+func writeHTMLReport(path string, report HTMLReport, redactor *Redactor) error {
+	var buf bytes.Buffer
+	if err := renderHTMLReport(&buf, report); err != nil {
+		return err
+	}
+	return os.WriteFile(path, redactor.redactFile(buf.Bytes()), 0600)
+}
+
+// renderHTMLReport executes the report template
+func renderHTMLReport(w io.Writer, report HTMLReport) error {
+	if err := htmlReportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("error rendering HTML report: %v", err)
+	}
+	return nil
+}
+
+// Side effect free functions
+
+// buildHTMLReport assembles the report of a check run
+func buildHTMLReport(zone string, generated time.Time, passed bool, sections ...HTMLReportSection) HTMLReport {
+	return HTMLReport{
+		Zone:      zone,
+		Generated: generated.Format("2006-01-02 15:04 MST"),
+		Passed:    passed,
+		Sections:  sections,
+	}
+}
+
+// htmlReportSection groups the issues and successful checks of one check by severity; empty severities get no table
+func htmlReportSection(title string, result CheckResult) HTMLReportSection {
+	section := HTMLReportSection{Title: title, Counts: make(map[string]int)}
+	all := checkReportIssues(append(append([]CheckIssue{}, result.Issues...), result.Successful...))
+	for _, severity := range reportSeverities {
+		group := HTMLSeverityGroup{Severity: severity}
+		for _, issue := range all {
+			if issue.Severity == severity {
+				group.Issues = append(group.Issues, issue)
+			}
+		}
+		section.Counts[severity] = len(group.Issues)
+		if len(group.Issues) > 0 {
+			section.Groups = append(section.Groups, group)
+		}
+	}
+	return section
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// issueRows returns the text of the cells of every issue row in a rendered report
+func issueRows(t *testing.T, page string) [][]string {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("report is not valid HTML: %v", err)
+	}
+	var rows [][]string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "tr" && strings.Contains(attr(n, "class"), "issue") {
+			var cells []string
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && c.Data == "td" {
+					cells = append(cells, textContent(c))
+				}
+			}
+			rows = append(rows, cells)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return rows
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+func TestRenderHTMLReport(t *testing.T) {
+	rule := redirectRule("guid-1", "/old", "https://example.com/<new>")
+	rules := CheckResult{
+		Issues: []CheckIssue{
+			{Type: IssueURLHealth, Severity: SeverityCritical, Message: "Broken destination URL (HTTP 503)", Rule: &rule, Details: map[string]interface{}{"attempts": 3}},
+			{Type: IssueRedirectChain, Severity: SeverityWarning, Message: "Redirect chain detected (2 hops)", Rule: &rule},
+		},
+		Successful: []CheckIssue{{Type: IssueSecurity, Severity: SeverityInfo, Message: "Open redirect to external domain detected", Rule: &rule}},
+	}
+	dns := CheckResult{Issues: []CheckIssue{{Type: IssueDNSMissingRecord, Severity: SeverityError, Message: "No DNS record for cdn.example.com"}}}
+
+	generated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	report := buildHTMLReport("shop", generated, false, htmlReportSection("Rules", rules), htmlReportSection("DNS", dns), htmlReportSection("SSL", CheckResult{}))
+
+	var buf bytes.Buffer
+	if err := renderHTMLReport(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	rows := issueRows(t, page)
+	if len(rows) != 4 {
+		t.Fatalf("got %d issue rows, want 4: %v", len(rows), rows)
+	}
+	want := []string{"url_health", "Broken destination URL (HTTP 503)", "guid-1", "/old", "https://example.com/<new>", "attempts: 3"}
+	for i, cell := range want {
+		if rows[0][i] != cell {
+			t.Errorf("first row cell %d = %q, want %q", i, rows[0][i], cell)
+		}
+	}
+	if rows[3][0] != "dns_missing_record" {
+		t.Errorf("last row = %v, want the DNS issue", rows[3])
+	}
+
+	for _, forbidden := range []string{"<link", "<script", "src="} {
+		if strings.Contains(page, forbidden) {
+			t.Errorf("report references external assets: found %q", forbidden)
+		}
+	}
+	if !strings.Contains(page, "2026-10-01 12:00 UTC") || !strings.Contains(page, "Issues found that require attention") {
+		t.Error("report lacks the generation time or the overall result")
+	}
+}
+
+func TestHTMLReportSectionCounts(t *testing.T) {
+	result := CheckResult{
+		Issues:     []CheckIssue{{Severity: SeverityError}, {Severity: SeverityError}, {Severity: SeverityWarning}},
+		Successful: []CheckIssue{{Severity: SeverityInfo}},
+	}
+	section := htmlReportSection("Rules", result)
+	wantCounts := map[string]int{SeverityCritical: 0, SeverityError: 2, SeverityWarning: 1, SeverityInfo: 1}
+	for severity, want := range wantCounts {
+		if section.Counts[severity] != want {
+			t.Errorf("count %s = %d, want %d", severity, section.Counts[severity], want)
+		}
+	}
+	if len(section.Groups) != 3 || section.Groups[0].Severity != SeverityError {
+		t.Errorf("groups = %+v, want error, warning and info tables", section.Groups)
+	}
+}
+
+func TestWriteHTMLReportRedacted(t *testing.T) {
+	rule := redirectRule("guid-1", "https://www.acme.com/old", "https://blog.acme.com/new")
+	rules := CheckResult{Issues: []CheckIssue{{Type: IssueURLHealth, Severity: SeverityError, Message: "Destination https://blog.acme.com/new returned 404", Rule: &rule}}}
+	dns := CheckResult{Issues: []CheckIssue{{Type: IssueDNSMissingRecord, Severity: SeverityError, Message: "MISSING www.acme.com - No DNS record found"}}}
+	report := buildHTMLReport("acme-site", time.Now(), false, htmlReportSection("Rules", rules), htmlReportSection("DNS", dns))
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := writeHTMLReport(path, report, newTestRedactor()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := strings.ToLower(string(data))
+	for _, original := range []string{"acme-site", "www.acme.com", "blog.acme.com"} {
+		if strings.Contains(page, original) {
+			t.Errorf("redacted HTML report still contains %q", original)
+		}
+	}
+	if !strings.Contains(page, "host-2.example") {
+		t.Error("redacted HTML report is missing the www alias")
+	}
+}
@@ -2,10 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"regexp"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kong"
@@ -16,49 +22,499 @@ func createDebugContext(baseCtx context.Context) context.Context {
 	return context.WithValue(baseCtx, struct{ key string }{"debug"}, CLI.Debug)
 }
 
+// setupRedaction routes report and log output through a redactor when --redact is set
+func setupRedaction(enabled bool, zoneName string) *Redactor {
+	if !enabled {
+		return nil
+	}
+	redactor := newRedactor()
+	redactor.addZone(zoneName)
+	output = redactingWriter{w: os.Stdout, r: redactor}
+	log.SetOutput(redactingWriter{w: os.Stderr, r: redactor})
+	return redactor
+}
+
+// checkReportWriter returns the writer the report of --format goes to; machine-readable formats silence the human-readable output
+func checkReportWriter(format string) io.Writer {
+	w := output
+	if format != "text" {
+		output = io.Discard
+	}
+	return w
+}
+
+// redactZone registers a pull zone's hostnames and rule destinations with the redactor before anything about it is printed
+func redactZone(ctx context.Context, redactor *Redactor, apiKey, zoneID string) error {
+	if redactor == nil {
+		return nil
+	}
+	details, err := getPullZoneDetails(ctx, apiKey, zoneID)
+	if err != nil {
+		return fmt.Errorf("error getting pull zone details: %v", err)
+	}
+	redactor.addPullZone(details)
+
+	rules, err := listEdgeRules(ctx, apiKey, zoneID)
+	if err != nil {
+		return fmt.Errorf("error listing edge rules: %v", err)
+	}
+	redactor.addRules(rules)
+	return nil
+}
+
+// writeRedactionMap writes the pseudonym mapping when --redact-map is set
+func writeRedactionMap(redactor *Redactor, path string) {
+	if redactor == nil || path == "" {
+		return
+	}
+	var mapping strings.Builder
+	_ = redactor.writeMap(&mapping)
+	if err := os.WriteFile(path, []byte(mapping.String()), 0600); err != nil {
+		log.Fatalf("Error writing redaction map: %v", err)
+	}
+}
+
+// exitNoMatch is the exit code of rules find when no redirect handles the path
+const exitNoMatch = 2
+
+// exit ends the process with code, running the completion hook and printing the --stats footer first; handlers use it instead of os.Exit
+func exit(code int) {
+	runCompletion(code)
+	if CLI.Stats {
+		printRunStats(os.Stderr, runStats, time.Now())
+	}
+	os.Exit(code)
+}
+
+// parseHealthSampleFlag parses the --health-sample value, returning nil when sampling is off
+func parseHealthSampleFlag(value string) (*HealthSample, error) {
+	if value == "" {
+		return nil, nil
+	}
+	sample, err := parseHealthSample(value)
+	if err != nil {
+		return nil, err
+	}
+	return &sample, nil
+}
+
+// allowlistFlag reads the --external-allowlist file, nil when the flag is not set
+func allowlistFlag(path string) (*HostAllowlist, error) {
+	if path == "" {
+		return nil, nil
+	}
+	allowlist, err := readAllowlist(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading external allowlist: %v", err)
+	}
+	return allowlist, nil
+}
+
+// baselineFlag reads the --baseline file, nil when the flag is not set or --write-baseline replaces the file
+func baselineFlag(path string, write bool) (*Baseline, error) {
+	if write && path == "" {
+		return nil, fmt.Errorf("--write-baseline needs --baseline to name the file")
+	}
+	if path == "" || write {
+		return nil, nil
+	}
+	baseline, err := readBaseline(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading baseline: %v", err)
+	}
+	return baseline, nil
+}
+
+// healthCacheFlag loads the cache of --health-cache, nil when the flag is not set or --no-cache bypasses it
+func healthCacheFlag(path string, ttl time.Duration, noCache bool) *HealthCache {
+	if path == "" || noCache {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	return loadHealthCache(expandHome(path, home), ttl)
+}
+
+// checksumCacheFlag loads the checksum cache of cdn push from --cache-file or .hop-cache.json in the --from directory, nil with --no-cache
+func checksumCacheFlag(localDir, cacheFile string, noCache bool) (*ChecksumCache, error) {
+	if noCache {
+		return nil, nil
+	}
+	path, err := checksumCachePath(localDir, cacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --cache-file: %v", err)
+	}
+	cache, err := loadChecksumCache(path)
+	if err != nil {
+		fmt.Printf("WARN: %v\n", err)
+	}
+	return cache, nil
+}
+
+// saveHealthCache writes the health cache back, a failure only costs the next run its cache hits
+func saveHealthCache(cache *HealthCache) {
+	if err := cache.save(); err != nil {
+		fmt.Fprintf(output, "WARN: %v\n", err)
+	}
+}
+
+// soft404DetectorFlag returns the detector of --detect-soft-404, nil when it is off
+func soft404DetectorFlag(enabled bool, phrases []string, minBytes int) *Soft404Detector {
+	if !enabled {
+		return nil
+	}
+	return newSoft404Detector(phrases, minBytes)
+}
+
 var CLI struct {
-	Debug bool `kong:"help='Enable debug output'"`
+	Debug       bool  `kong:"help='Enable debug output'"`
+	Stats       bool  `kong:"help='Print local statistics about requests, transferred bytes and wall time at the end'"`
+	MaxAPICalls int64 `kong:"name='max-api-calls',default='2000',help='Abort once this many requests to Bunny API, storage and logging endpoints were made, 0 for no limit'"`
 
 	Check struct {
-		Key        string `kong:"required,help='Bunny CDN API key'"`
-		Zone       string `kong:"required,help='Pull Zone name'"`
-		SkipHealth bool   `kong:"help='Skip HTTP health checks for faster execution'"`
+		Key               string        `kong:"required,help='Bunny CDN API key'"`
+		Zone              string        `kong:"required,help='Pull Zone name'"`
+		SkipHealth        bool          `kong:"help='Skip HTTP health checks for faster execution'"`
+		ParkingPattern    []string      `kong:"help='Additional parking service IP, CIDR or CNAME suffix (repeatable)'"`
+		HealthSample      string        `kong:"help='Health check only a sample of destinations, as a percentage (10%) or a count'"`
+		HealthRetries     int           `kong:"default='2',help='Retry destinations failing with a network error or 5xx this many times before reporting them'"`
+		HostConcurrency   int           `kong:"default='4',help='Concurrent health checks per destination host, 0 for no limit'"`
+		HostInterval      time.Duration `kong:"default='100ms',help='Minimum spacing of health checks to one destination host'"`
+		SlowThreshold     time.Duration `kong:"default='3s',help='Warn about destinations whose first byte takes longer, 0 to disable'"`
+		DetectSoft404     bool          `kong:"name='detect-soft-404',help='Read destination bodies and warn about HTTP 200 pages that look like not-found pages'"`
+		Soft404Phrase     []string      `kong:"name='soft-404-phrase',help='Additional phrase marking a not-found page for --detect-soft-404 (repeatable)'"`
+		Soft404MinBytes   int           `kong:"name='soft-404-min-bytes',default='512',help='Pages shorter than this count as soft 404s with --detect-soft-404, 0 to disable'"`
+		WarnChain         int           `kong:"default='1',help='Warn about redirect chains longer than this many hops'"`
+		MaxChain          int           `kong:"default='10',help='Report redirect chains longer than this many hops as errors'"`
+		ExternalAllowlist string        `kong:"help='File of approved external destination hosts, one per line (*.example.com for subdomains); others become warnings'"`
+		CanonicalHost     string        `kong:"help='Host internal redirects should land on, e.g. www.example.com; redirects to other hostnames of the zone are warnings'"`
+		HealthCache       string        `kong:"name='health-cache',help='Reuse destination health check results from this file, e.g. ~/.cache/hop/health.json'"`
+		HealthCacheTTL    time.Duration `kong:"name='health-cache-ttl',default='1h',help='How long results in --health-cache stay valid'"`
+		NoCache           bool          `kong:"help='Request every destination even when --health-cache is set'"`
+		Format            string        `kong:"enum='text,json,github',default='text',help='Output format: text, json or github (GitHub Actions annotations)'"`
+		Verbose           bool          `kong:"help='List every passed check instead of only their number'"`
+		Quiet             bool          `kong:"help='Do not print the progress of the destination health checks'"`
+		ReportHTML        string        `kong:"name='report-html',help='Write a self-contained HTML report of all checks to this file'"`
+		FailFast          bool          `kong:"help='Cancel remaining health checks once an error is found'"`
+		FailOn            string        `kong:"enum='critical,error,warning,info,never',default='error',help='Fail when an issue reaches this severity, exit code 1 for errors and 2 for warnings only: critical, error, warning, info or never'"`
+		Only              []string      `kong:"help='Only report these check categories (comma-separated): basic, configuration, ordering, security, self_redirect, redirect_loop, redirect_chain, url_health, source_verification, origin'"`
+		Skip              []string      `kong:"help='Do not run or report these check categories (comma-separated)'"`
+		VerifySources     bool          `kong:"help='Request every exact redirect source from the origin and warn when it still serves the page'"`
+		OriginHost        string        `kong:"help='Origin host or URL for --verify-sources, defaults to the origin URL of the pull zone'"`
+		Redact            bool          `kong:"help='Replace zone names and hostnames with stable pseudonyms in the output'"`
+		RedactMap         string        `kong:"help='Write the pseudonym mapping of --redact to this file'"`
+		OnComplete        string        `kong:"help='Shell command to run when the check ends, {status}, {summary} and {report} are replaced'"`
+		NotifyDesktop     bool          `kong:"help='Show a desktop notification when the check ends, where the platform supports it'"`
 	} `kong:"cmd,help='Run all checks (rules, DNS, SSL) for a pull zone'"`
 
 	Rules struct {
 		Add struct {
-			Key  string `kong:"required,help='Bunny CDN API key'"`
-			Zone string `kong:"required,help='Pull Zone name'"`
-			From string `kong:"required,help='Source URL path to redirect from'"`
-			To   string `kong:"required,help='Destination URL to redirect to'"`
-			Desc string `kong:"help='Edge rule description'"`
+			Key       string `kong:"required,help='Bunny CDN API key'"`
+			Zone      string `kong:"required,help='Pull Zone name'"`
+			From      string `kong:"required,help='Source URL path to redirect from'"`
+			To        string `kong:"required,help='Destination URL to redirect to'"`
+			Desc      string `kong:"help='Edge rule description'"`
+			Overwrite bool   `kong:"help='Update the existing redirect for the same source path instead of adding a new one'"`
+
+			AllowComplexPatterns bool `kong:"help='Allow multiple wildcards or wildcards inside a path segment'"`
+			PreserveQuery        bool `kong:"help='Append the request query string to the destination'"`
+			CaseInsensitive      bool `kong:"help='Also match the lowercase form of the source path'"`
 		} `kong:"cmd,help='Add a new 302 redirect'"`
 
 		List struct {
+			Key      string `kong:"required,help='Bunny CDN API key'"`
+			Zone     string `kong:"required,help='Pull Zone name'"`
+			All      bool   `kong:"help='List every edge rule grouped by action type, not only 302 redirects'"`
+			Disabled bool   `kong:"help='Only list disabled rules'"`
+			Match    string `kong:"help='Only list rules whose description or source path contains this text (case-insensitive)'"`
+			Sort     string `kong:"enum='source,destination,description,order',default='source',help='Sort by source, destination, description or evaluation order'"`
+			Format   string `kong:"enum='text,csv',default='text',help='Output format: text or csv; the CSV is for review in spreadsheets, hop cannot import it again'"`
+
+			Redact    bool   `kong:"help='Replace zone names and hostnames with stable pseudonyms in the output'"`
+			RedactMap string `kong:"help='Write the pseudonym mapping of --redact to this file'"`
+		} `kong:"cmd,help='List all existing 302 redirects'"`
+
+		Find struct {
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			Zone   string `kong:"required,help='Pull Zone name'"`
+			Source string `kong:"required,help='URL path to look up'"`
+		} `kong:"cmd,help='Find the redirect rules that handle a URL path (exit code 2 if none)'"`
+
+		Validate struct {
+			File      string   `kong:"required,help='Rules file: a rules backup or a JSON list of {from, to, status} redirects'"`
+			Hostnames []string `kong:"help='Hostnames of the zone, destinations on them count as internal (comma-separated)'"`
+		} `kong:"cmd,help='Check a rules file offline, without an API key (exit code 1 on errors)'"`
+
+		Test struct {
 			Key  string `kong:"required,help='Bunny CDN API key'"`
 			Zone string `kong:"required,help='Pull Zone name'"`
-		} `kong:"cmd,help='List all existing 302 redirects'"`
+			Path string `kong:"required,help='URL path or full URL to simulate a request for'"`
+			Host string `kong:"help='Hostname a path is requested on (default: the first custom hostname of the zone)'"`
+			Live bool   `kong:"help='Also send a real request and compare it with the simulation'"`
+		} `kong:"cmd,help='Simulate a request against the edge rules and follow the redirect chain (exit code 1 on a loop or a live mismatch)'"`
+
+		Get struct {
+			Key  string `kong:"required,help='Bunny CDN API key'"`
+			Zone string `kong:"required,help='Pull Zone name'"`
+			Guid string `kong:"required,help='GUID of the edge rule to show'"`
+			JSON bool   `kong:"name='json',help='Print the raw rule as JSON'"`
+		} `kong:"cmd,help='Show a single edge rule in full detail'"`
 
 		Check struct {
+			Key               string        `kong:"required,help='Bunny CDN API key'"`
+			Zone              string        `kong:"required,help='Pull Zone name'"`
+			SkipHealth        bool          `kong:"help='Skip HTTP health checks for faster execution'"`
+			ParkingPattern    []string      `kong:"help='Additional parking service IP, CIDR or CNAME suffix (repeatable)'"`
+			HealthSample      string        `kong:"help='Health check only a sample of destinations, as a percentage (10%) or a count'"`
+			HealthRetries     int           `kong:"default='2',help='Retry destinations failing with a network error or 5xx this many times before reporting them'"`
+			HostConcurrency   int           `kong:"default='4',help='Concurrent health checks per destination host, 0 for no limit'"`
+			HostInterval      time.Duration `kong:"default='100ms',help='Minimum spacing of health checks to one destination host'"`
+			SlowThreshold     time.Duration `kong:"default='3s',help='Warn about destinations whose first byte takes longer, 0 to disable'"`
+			DetectSoft404     bool          `kong:"name='detect-soft-404',help='Read destination bodies and warn about HTTP 200 pages that look like not-found pages'"`
+			Soft404Phrase     []string      `kong:"name='soft-404-phrase',help='Additional phrase marking a not-found page for --detect-soft-404 (repeatable)'"`
+			Soft404MinBytes   int           `kong:"name='soft-404-min-bytes',default='512',help='Pages shorter than this count as soft 404s with --detect-soft-404, 0 to disable'"`
+			WarnChain         int           `kong:"default='1',help='Warn about redirect chains longer than this many hops'"`
+			MaxChain          int           `kong:"default='10',help='Report redirect chains longer than this many hops as errors'"`
+			ExternalAllowlist string        `kong:"help='File of approved external destination hosts, one per line (*.example.com for subdomains); others become warnings'"`
+			CanonicalHost     string        `kong:"help='Host internal redirects should land on, e.g. www.example.com; redirects to other hostnames of the zone are warnings'"`
+			HealthCache       string        `kong:"name='health-cache',help='Reuse destination health check results from this file, e.g. ~/.cache/hop/health.json'"`
+			HealthCacheTTL    time.Duration `kong:"name='health-cache-ttl',default='1h',help='How long results in --health-cache stay valid'"`
+			NoCache           bool          `kong:"help='Request every destination even when --health-cache is set'"`
+			Format            string        `kong:"enum='text,json,github',default='text',help='Output format: text, json or github (GitHub Actions annotations)'"`
+			Verbose           bool          `kong:"help='List every passed check instead of only their number'"`
+			Quiet             bool          `kong:"help='Do not print the progress of the destination health checks'"`
+			ReportCSV         string        `kong:"name='report-csv',help='Write one CSV row per issue to this file'"`
+			Baseline          string        `kong:"help='File of accepted issues; matching issues are listed as suppressed and do not fail the check'"`
+			WriteBaseline     bool          `kong:"help='Record the current issues in the --baseline file'"`
+			IncludeOK         bool          `kong:"name='include-ok',help='Also write successful checks to --report-csv'"`
+			FailFast          bool          `kong:"help='Cancel remaining health checks once an error is found'"`
+			FailOn            string        `kong:"enum='critical,error,warning,info,never',default='error',help='Fail when an issue reaches this severity, exit code 1 for errors and 2 for warnings only: critical, error, warning, info or never'"`
+			Only              []string      `kong:"help='Only report these check categories (comma-separated): basic, configuration, ordering, security, self_redirect, redirect_loop, redirect_chain, url_health, source_verification, origin'"`
+			Skip              []string      `kong:"help='Do not run or report these check categories (comma-separated)'"`
+			VerifySources     bool          `kong:"help='Request every exact redirect source from the origin and warn when it still serves the page'"`
+			OriginHost        string        `kong:"help='Origin host or URL for --verify-sources, defaults to the origin URL of the pull zone'"`
+			Redact            bool          `kong:"help='Replace zone names and hostnames with stable pseudonyms in the output'"`
+			RedactMap         string        `kong:"help='Write the pseudonym mapping of --redact to this file'"`
+		} `kong:"cmd,help='Check redirect rules for potential issues'"`
+
+		AddHostRedirect struct {
+			Key      string `kong:"required,help='Bunny CDN API key'"`
+			Zone     string `kong:"required,help='Pull Zone name'"`
+			FromHost string `kong:"required,help='Hostname to redirect away from, e.g. www.example.com'"`
+			ToHost   string `kong:"required,help='Hostname to redirect to, e.g. example.com'"`
+			Status   string `kong:"enum='301,302,307,308',default='302',help='Redirect status code'"`
+		} `kong:"cmd,name='add-host-redirect',help='Redirect every request for one hostname of the zone to the same path on another'"`
+
+		AddOrigin struct {
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			Zone   string `kong:"required,help='Pull Zone name'"`
+			From   string `kong:"required,help='Source URL path pattern to serve from the other origin'"`
+			Origin string `kong:"required,help='Origin URL to fetch matching requests from'"`
+			Desc   string `kong:"help='Edge rule description'"`
+		} `kong:"cmd,name='add-origin',help='Serve requests matching a path pattern from a different origin'"`
+
+		Block struct {
+			Key  string `kong:"required,help='Bunny CDN API key'"`
+			Zone string `kong:"required,help='Pull Zone name'"`
+			From string `kong:"required,help='URL path pattern to block'"`
+			Desc string `kong:"help='Edge rule description'"`
+		} `kong:"cmd,help='Block requests matching a path pattern at the edge'"`
+
+		SetHeader struct {
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			Zone   string `kong:"required,help='Pull Zone name'"`
+			From   string `kong:"required,help='URL path pattern to set the header on'"`
+			Header string `kong:"required,help='Response header as Name: value'"`
+			Desc   string `kong:"help='Edge rule description'"`
+		} `kong:"cmd,name='set-header',help='Set a response header on requests matching a path pattern'"`
+
+		AddSlashPolicy struct {
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			Zone   string `kong:"required,help='Pull Zone name'"`
+			Policy string `kong:"required,enum='add,strip',help='add redirects /path to /path/, strip redirects /path/ to /path'"`
+			Status string `kong:"enum='301,302,307,308',default='301',help='Redirect status code'"`
+			DryRun bool   `kong:"help='Print the rule without creating it'"`
+		} `kong:"cmd,name='add-slash-policy',help='Add one wildcard redirect that enforces a trailing-slash policy'"`
+
+		CheckSitemap struct {
+			Key     string `kong:"required,help='Bunny CDN API key'"`
+			Zone    string `kong:"required,help='Pull Zone name'"`
+			Sitemap string `kong:"required,help='URL of the sitemap or sitemap index'"`
+			Format  string `kong:"enum='text,json',default='text',help='Output format: text or json'"`
+		} `kong:"cmd,name='check-sitemap',help='Report sitemap URLs that a redirect would send elsewhere'"`
+
+		Move struct {
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			Zone   string `kong:"required,help='Pull Zone name'"`
+			Guid   string `kong:"required,help='GUID of the edge rule to move'"`
+			Before string `kong:"required,help='GUID of the edge rule to move it in front of'"`
+		} `kong:"cmd,help='Change the evaluation order of edge rules'"`
+
+		Backup struct {
+			Key  string `kong:"required,help='Bunny CDN API key'"`
+			Zone string `kong:"required,help='Pull Zone name'"`
+			Out  string `kong:"default='.',help='Directory to write the backup file to'"`
+		} `kong:"cmd,help='Save all edge rules of a pull zone to a timestamped JSON file'"`
+
+		Park struct {
+			Key          string `kong:"required,help='Bunny CDN API key'"`
+			Zone         string `kong:"required,help='Pull Zone name of the redirect zone'"`
+			Domain       string `kong:"required,help='Parked domain to redirect, e.g. oldbrand.com'"`
+			To           string `kong:"required,help='Destination URL for every request to the domain'"`
+			PreservePath bool   `kong:"help='Append the request path to the destination'"`
+			DryRun       bool   `kong:"help='Show each step without changing anything'"`
+		} `kong:"cmd,help='Redirect a parked domain: attach the hostname, add a catch-all redirect, DNS record and SSL'"`
+
+		Restore struct {
+			Key   string `kong:"required,help='Bunny CDN API key'"`
+			Zone  string `kong:"required,help='Pull Zone name'"`
+			File  string `kong:"required,help='Backup file written by rules backup'"`
+			Apply bool   `kong:"help='Restore the rules, without it only the plan is shown'"`
+		} `kong:"cmd,help='Restore edge rules from a backup file'"`
+
+		Diff struct {
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			ZoneA  string `kong:"required,help='Pull Zone name of the first zone, e.g. staging'"`
+			ZoneB  string `kong:"required,help='Pull Zone name of the second zone, e.g. prod'"`
+			Format string `kong:"enum='text,json',default='text',help='Output format: text or json'"`
+		} `kong:"cmd,help='Compare the redirects of two pull zones, exits with code 1 when they differ'"`
+
+		Delete struct {
 			Key        string `kong:"required,help='Bunny CDN API key'"`
 			Zone       string `kong:"required,help='Pull Zone name'"`
-			SkipHealth bool   `kong:"help='Skip HTTP health checks for faster execution'"`
-		} `kong:"cmd,help='Check redirect rules for potential issues'"`
+			FromPrefix string `kong:"help='Delete redirects whose source path starts with this prefix, e.g. /old-blog/'"`
+			FromGlob   string `kong:"help='Delete redirects whose source path matches this pattern, e.g. /old-blog/*'"`
+			Yes        bool   `kong:"help='Delete without asking for confirmation'"`
+			Max        int    `kong:"default='50',help='Refuse to delete more than this many rules'"`
+		} `kong:"cmd,help='Delete all redirects matching a source prefix or pattern'"`
+
+		Fix struct {
+			Key  string `kong:"required,help='Bunny CDN API key'"`
+			Zone string `kong:"required,help='Pull Zone name'"`
+			Yes  bool   `kong:"help='Apply the fixes without asking for confirmation'"`
+			Out  string `kong:"default='.',help='Directory for the backup written before any change'"`
+		} `kong:"cmd,help='Fix the basic redirect issues rules check reports: 301 to 302 and missing status codes'"`
+
+		Dedupe struct {
+			Key       string `kong:"required,help='Bunny CDN API key'"`
+			Zone      string `kong:"required,help='Pull Zone name'"`
+			Apply     bool   `kong:"help='Delete the duplicate rules, without it only the plan is shown'"`
+			KeepFirst bool   `kong:"help='Also resolve duplicates with different destinations by keeping the rule currently in effect'"`
+			Backup    bool   `kong:"help='Back up all edge rules before deleting anything'"`
+			Out       string `kong:"default='.',help='Directory for the --backup file'"`
+		} `kong:"cmd,help='Remove duplicate redirects for the same source path'"`
+
+		Gaps struct {
+			Key      string        `kong:"required,help='Bunny CDN API key'"`
+			Zone     string        `kong:"required,help='Pull Zone name'"`
+			Since    time.Duration `kong:"default='24h',help='Only consider requests from this long ago until now'"`
+			LogFile  string        `kong:"help='Read a downloaded access log instead of fetching the pull zone logs'"`
+			Top      int           `kong:"default='20',help='Number of uncovered 404 paths to show'"`
+			MaxLines int           `kong:"default='1000000',help='Stop after reading this many log lines'"`
+		} `kong:"cmd,help='Report frequent 404 paths from the access logs that no redirect covers'"`
+
+		Suggest struct {
+			Key        string `kong:"required,help='Bunny CDN API key'"`
+			Zone       string `kong:"required,help='Pull Zone name'"`
+			FromPrefix string `kong:"required,help='Path prefix to list known paths for'"`
+			Sitemap    string `kong:"help='Local sitemap.xml to include paths from'"`
+			Commands   bool   `kong:"help='Print ready-to-run rules add commands for paths without a redirect'"`
+			JSON       bool   `kong:"name='json',help='Print results as JSON'"`
+		} `kong:"cmd,help='List known paths under a prefix and whether they are redirected'"`
 	} `kong:"cmd,help='Manage redirect rules'"`
 
 	CDN struct {
 		Push struct {
-			Key  string `kong:"required,help='Bunny CDN API key'"`
-			Zone string `kong:"required,help='Pull Zone name'"`
-			From string `kong:"required,help='Local directory path to upload from'"`
+			Key            string        `kong:"required,help='Bunny CDN API key'"`
+			Zone           string        `kong:"required,help='Pull Zone name'"`
+			From           string        `kong:"required,help='Local directory path to upload from'"`
+			To             string        `kong:"help='Remote directory to push into, e.g. /site-a, the comparison and --delete stay inside it (default: storage root)'"`
+			Release        string        `kong:"help='Upload into releases/<id>/ and activate it when the upload succeeds'"`
+			RemoteStats    bool          `kong:"help='Count all remote files and their total size before uploading'"`
+			DryRun         bool          `kong:"help='Show which files would be uploaded or skipped and why, without uploading anything'"`
+			Quiet          bool          `kong:"help='Print neither a line per file nor the progress bar, only failures and the summary'"`
+			SummaryJSON    string        `kong:"name='summary-json',help='Write what happened to every file as JSON to this path, also when uploads fail'"`
+			Delete         bool          `kong:"help='Delete remote files that no longer exist locally, after every upload succeeded'"`
+			MaxDelete      int           `kong:"default='100',help='Refuse --delete of more files than this, or of more than half of the remote files'"`
+			ForceDelete    bool          `kong:"help='Let --delete remove any number of remote files'"`
+			Purge          bool          `kong:"help='Purge the uploaded and deleted files from the CDN cache after a successful push'"`
+			PurgeAll       int           `kong:"help='Purge the whole pull zone instead of single files when more than this many files changed, implies --purge'"`
+			StrictPurge    bool          `kong:"help='Exit 1 when purging the cache fails instead of warning'"`
+			Verify         bool          `kong:"help='List the uploaded files again after the push and fail those whose remote size or checksum differs'"`
+			VerifyAttempts int           `kong:"default='3',help='How often --verify lists a file that does not match yet before failing it, for replication lag'"`
+			VerifyInterval time.Duration `kong:"default='2s',help='Wait between --verify attempts'"`
+			Fast           bool          `kong:"help='Skip files whose size matches and that were not modified since their last upload without hashing them'"`
+			CacheFile      string        `kong:"help='Checksum cache of the local files, by default .hop-cache.json in the --from directory'"`
+			NoCache        bool          `kong:"help='Hash every local file without reading or writing the checksum cache'"`
+			HashWorkers    int           `kong:"help='Local files hashed at the same time, by default one per CPU'"`
+
+			FollowSymlinks        bool `kong:"help='Upload the files and directories symlinks in --from point to, under the path of the link'"`
+			AllowExternalSymlinks bool `kong:"help='With --follow-symlinks, also follow symlinks that point outside --from'"`
+			IncludeHidden         bool `kong:"help='Also upload dotfiles, dot-directories and Thumbs.db, which are skipped by default'"`
+
+			MaxFileSize  string `kong:"help='Fail the files larger than this before uploading anything, e.g. 100MB (default: no limit, 512MB with --strict)'"`
+			SkipOversize bool   `kong:"help='Skip files larger than --max-file-size with a warning instead of failing them'"`
+			Strict       bool   `kong:"help='Apply safe limits: --max-file-size 512MB unless set'"`
+			LimitRate    string `kong:"help='Keep the combined upload speed under this many bytes per second, e.g. 5MB (default: no limit)'"`
+
+			Include []string `kong:"help='Glob of the only files to push, matched against the path below --from (repeatable): images/**, *.css'"`
+			Exclude []string `kong:"help='Glob of files not to push, matched against the path below --from (repeatable): **/*.map, .DS_Store, node_modules/**'"`
+
+			Timeout        time.Duration `kong:"help='Give up on the whole push after this long, e.g. 30m (default: no limit, Ctrl-C stops the push gracefully)'"`
+			PerFileTimeout time.Duration `kong:"default='30s',help='Time every upload gets on top of what its size needs at --min-throughput, 0 with --min-throughput 0 for no limit'"`
+			MinThroughput  string        `kong:"default='256KB',help='Slowest acceptable upload speed per file, e.g. 256KB or 1MB per second'"`
+
+			NoVerifyChecksum bool   `kong:"help='Upload without the SHA256 Checksum header the storage uses to reject truncated transfers'"`
+			ContentTypeMap   string `kong:"help='JSON file of extensions and the Content-Type to upload them with, ahead of the built-in types'"`
+
+			AllowSensitive   bool     `kong:"help='Upload files matching the sensitive file patterns (.env*, *.pem, *.key, ...)'"`
+			SensitivePattern []string `kong:"help='Additional sensitive file pattern that blocks the upload (repeatable)'"`
+
+			HashedAssets        bool   `kong:"help='Maintain an edge rule that serves content-hashed files with an immutable Cache-Control header'"`
+			HashedAssetsPattern string `kong:"help='Regular expression for content-hashed file names (default: 8+ hex characters before the extension)'"`
+
+			OnComplete    string `kong:"help='Shell command to run when the push ends, {status}, {summary} and {report} are replaced'"`
+			NotifyDesktop bool   `kong:"help='Show a desktop notification when the push ends, where the platform supports it'"`
 		} `kong:"cmd,help='Push files from local directory to CDN storage'"`
 
 		Check struct {
-			Key  string `kong:"required,help='Bunny CDN API key'"`
-			Zone string `kong:"required,help='Pull Zone name'"`
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			Zone   string `kong:"required,help='Pull Zone name'"`
+			FailOn string `kong:"enum='critical,error,warning,info,never',default='error',help='Fail when an issue reaches this severity, exit code 1 for errors and 2 for warnings only: critical, error, warning, info or never'"`
 		} `kong:"cmd,help='Check SSL configuration for all pull zone hostnames'"`
+
+		Releases struct {
+			List struct {
+				Key  string `kong:"required,help='Bunny CDN API key'"`
+				Zone string `kong:"required,help='Pull Zone name'"`
+			} `kong:"cmd,help='List releases in the storage zone'"`
+
+			Activate struct {
+				Key     string `kong:"required,help='Bunny CDN API key'"`
+				Zone    string `kong:"required,help='Pull Zone name'"`
+				Release string `kong:"required,help='Release ID to activate'"`
+			} `kong:"cmd,help='Switch the pull zone to an uploaded release'"`
+
+			Prune struct {
+				Key    string `kong:"required,help='Bunny CDN API key'"`
+				Zone   string `kong:"required,help='Pull Zone name'"`
+				Keep   int    `kong:"default='3',help='Number of most recent inactive releases to keep'"`
+				DryRun bool   `kong:"help='Show which releases would be deleted without deleting them'"`
+			} `kong:"cmd,help='Delete old releases from the storage zone'"`
+		} `kong:"cmd,help='Manage atomic releases'"`
 	} `kong:"cmd,help='Manage CDN content'"`
 
+	Zones struct {
+		Clone struct {
+			Key                 string `kong:"required,help='Bunny CDN API key'"`
+			From                string `kong:"required,help='Pull Zone name to clone'"`
+			To                  string `kong:"required,help='Pull Zone name of the clone, created if missing'"`
+			WithRules           bool   `kong:"help='Copy redirect rules to the clone'"`
+			WithHostnamesReport bool   `kong:"help='Print the DNS and SSL steps to give each hostname a staging counterpart'"`
+			Diff                bool   `kong:"help='Compare the redirects of both zones instead of cloning'"`
+			DryRun              bool   `kong:"help='Show what would change without changing anything'"`
+		} `kong:"cmd,help='Clone a pull zone, e.g. into a staging zone'"`
+	} `kong:"cmd,help='Manage pull zones'"`
+
 	DNS struct {
 		List struct {
 			Key  string `kong:"required,help='Bunny CDN API key'"`
@@ -66,115 +522,1499 @@ var CLI struct {
 		} `kong:"cmd,help='List DNS A and CNAME records for a pull zone'"`
 
 		Check struct {
-			Key  string `kong:"required,help='Bunny CDN API key'"`
-			Zone string `kong:"required,help='Pull Zone name'"`
+			Key    string `kong:"required,help='Bunny CDN API key'"`
+			Zone   string `kong:"required,help='Pull Zone name'"`
+			FailOn string `kong:"enum='critical,error,warning,info,never',default='error',help='Fail when an issue reaches this severity, exit code 1 for errors and 2 for warnings only: critical, error, warning, info or never'"`
 		} `kong:"cmd,help='Check DNS records exist for pull zone hostnames'"`
 	} `kong:"cmd,help='Manage DNS records'"`
+
+	Help struct {
+		Command []string `kong:"arg,optional,help='Command to describe, e.g. rules add'"`
+		All     bool     `kong:"help='Print the complete reference of every command'"`
+	} `kong:"cmd,help='Show extended help with examples for a command'"`
+
+	Man struct{} `kong:"cmd,help='Print a man page in troff format'"`
 }
 
 func main() {
-	ctx := kong.Parse(&CLI,
+	installRunStats(runStats)
+	exit(run(os.Args[1:]))
+}
+
+// run parses args into CLI and runs the command, returning the exit code.
+// The check commands return their errors, which end the run with exitFailure here; bad flags do the same for every command.
+func run(args []string) int {
+	parser, err := kong.New(&CLI,
 		kong.Name("hop"),
 		kong.Description("A Go command-line tool to manage 302 redirects in Bunny CDN pull zones."),
-		kong.UsageOnError(),
 		kong.ConfigureHelp(kong.HelpOptions{
 			Compact: true,
 		}))
+	if err != nil {
+		log.Print(err)
+		return exitFailure
+	}
+	ctx, err := parser.Parse(args)
+	if err != nil {
+		var parseErr *kong.ParseError
+		if errors.As(err, &parseErr) {
+			_ = parseErr.Context.PrintUsage(false)
+			fmt.Fprintln(parser.Stdout)
+		}
+		parser.Errorf("%s", err)
+		return exitFailure
+	}
+	runStats.maxBunnyCalls = CLI.MaxAPICalls
 
+	code := exitClean
 	switch ctx.Command() {
 	case "check":
-		handleGeneralCheck()
+		code, err = handleGeneralCheck()
 	case "rules add":
 		handleAdd()
 	case "rules list":
 		handleList()
+	case "rules find":
+		handleFind()
+	case "rules validate":
+		handleValidate()
+	case "rules test":
+		handleRulesTest()
+	case "rules get":
+		handleGet()
 	case "rules check":
-		handleCheck()
+		code, err = handleCheck()
+	case "rules backup":
+		handleBackup()
+	case "rules add-host-redirect":
+		handleAddHostRedirect()
+	case "rules add-origin":
+		handleAddOrigin()
+	case "rules block":
+		handleBlock()
+	case "rules set-header":
+		handleSetHeader()
+	case "rules add-slash-policy":
+		handleAddSlashPolicy()
+	case "rules check-sitemap":
+		handleCheckSitemap()
+	case "rules move":
+		handleMove()
+	case "rules park":
+		handlePark()
+	case "rules restore":
+		handleRestore()
+	case "rules diff":
+		handleRulesDiff()
+	case "rules delete":
+		handleDelete()
+	case "rules fix":
+		handleFix()
+	case "rules dedupe":
+		handleDedupe()
+	case "rules gaps":
+		handleGaps()
+	case "rules suggest":
+		handleSuggest()
 	case "cdn push":
-		handleCDNPush()
+		code, err = handleCDNPush()
 	case "cdn check":
-		handleCDNCheck()
+		code, err = handleCDNCheck()
+	case "cdn releases list":
+		handleReleasesList()
+	case "cdn releases activate":
+		handleReleasesActivate()
+	case "cdn releases prune":
+		handleReleasesPrune()
+	case "zones clone":
+		handleZonesClone()
 	case "dns list":
 		handleDNSList()
 	case "dns check":
-		handleDNSCheck()
+		code, err = handleDNSCheck()
+	case "help", "help <command>":
+		handleHelp(ctx.Model)
+	case "man":
+		writeManPage(os.Stdout, ctx.Model)
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", ctx.Command())
 		_ = ctx.PrintUsage(true)
-		os.Exit(1)
+		return exitFailure
 	}
+
+	if err != nil {
+		log.Print(err)
+		completion.finish(completionFailed, err.Error())
+		return exitFailure
+	}
+	return code
 }
 
-func handleCDNPush() {
-	baseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+func handleAddHostRedirect() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
 	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.AddHostRedirect
+	fromHost, toHost := canonicalHost(opts.FromHost), canonicalHost(opts.ToHost)
 
-	// Verify local directory exists
-	localDir := CLI.CDN.Push.From
-	if _, err := os.Stat(localDir); os.IsNotExist(err) {
-		log.Fatalf("Local directory '%s' does not exist", localDir)
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
 	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
 
-	// Look up pull zone by name
-	pullZoneID, err := findPullZoneByName(ctx, CLI.CDN.Push.Key, CLI.CDN.Push.Zone)
+	details, err := getPullZoneDetails(ctx, opts.Key, zoneID)
 	if err != nil {
-		log.Fatalf("Error finding pull zone '%s': %v", CLI.CDN.Push.Zone, err)
+		log.Fatalf("Error getting pull zone details: %v", err)
+	}
+	if err := validateHostRedirect(details.Hostnames, fromHost, toHost); err != nil {
+		log.Fatalf("Error: %v", err)
 	}
-	fmt.Printf("Found pull zone '%s' with ID: %d\n", CLI.CDN.Push.Zone, pullZoneID)
 
-	// Find associated storage zone
-	storageZone, err := getStorageZoneByPullZone(ctx, CLI.CDN.Push.Key, pullZoneID)
+	for _, result := range checkDNSRecordsForHostnames(ctx, opts.Key, []Hostname{{Value: fromHost}}) {
+		if !result.HasRecord {
+			fmt.Printf("WARN: no DNS record found for %s, the redirect only works once it resolves to the pull zone\n", fromHost)
+		}
+	}
+
+	rule := buildHostRedirectRule("", fromHost, toHost, opts.Status)
+	if existing := findHostRedirectRule(details.EdgeRules, fromHost); existing != nil {
+		if parkRuleUpToDate(*existing, rule) && existing.Description == rule.Description {
+			fmt.Printf("Redirect %s -> %s already exists (%s)\n", fromHost, toHost, existing.Guid)
+			return
+		}
+		rule.Guid = existing.Guid
+	}
+
+	if err := addEdgeRule(ctx, opts.Key, zoneID, rule); err != nil {
+		log.Fatalf("Error adding host redirect: %v", err)
+	}
+	if rule.Guid != "" {
+		fmt.Printf("Updated redirect %s -> %s (%s)\n", fromHost, toHost, rule.Guid)
+		return
+	}
+	fmt.Printf("Added %s redirect %s -> %s, the request path is kept\n", opts.Status, fromHost, toHost)
+}
+
+func handleAddOrigin() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.AddOrigin
+
+	warnings, err := validateRedirectPattern(opts.From, true)
+	for _, warning := range warnings {
+		fmt.Printf("WARN: %s\n", warning)
+	}
 	if err != nil {
-		log.Fatalf("Error finding storage zone: %v", err)
+		log.Fatalf("Invalid source pattern: %v", err)
+	}
+	if err := validateOriginURL(opts.Origin); err != nil {
+		log.Fatal(err)
 	}
-	fmt.Printf("Found storage zone: %s\n", storageZone.Name)
 
-	// Upload directory contents
-	fmt.Printf("Uploading files from '%s' to storage zone '%s'...\n", localDir, storageZone.Name)
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
 
-	results := uploadDirectoryOptimized(ctx, storageZone, localDir, "")
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
 
-	// Summary
-	successful := 0
-	skipped := 0
-	failed := 0
-	for _, result := range results {
-		if result.Success {
-			if result.Skipped {
-				skipped++
-			} else {
-				successful++
-			}
-		} else {
-			failed++
+	// Update the origin override for the same pattern instead of adding a second one
+	guid := ""
+	if existing := findOriginRule(rules, opts.From); existing != nil {
+		guid = existing.Guid
+	}
+
+	if err := addEdgeRule(ctx, opts.Key, zoneID, buildOriginRule(guid, opts.From, opts.Origin, opts.Desc)); err != nil {
+		log.Fatalf("Error adding edge rule: %v", err)
+	}
+	if guid != "" {
+		fmt.Printf("Updated origin override %s: %s -> %s\n", guid, opts.From, opts.Origin)
+	} else {
+		fmt.Printf("Added origin override: %s -> %s\n", opts.From, opts.Origin)
+	}
+}
+
+func handleBlock() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Block
+
+	warnings, err := validateRedirectPattern(opts.From, true)
+	for _, warning := range warnings {
+		fmt.Printf("WARN: %s\n", warning)
+	}
+	if err != nil {
+		log.Fatalf("Invalid pattern: %v", err)
+	}
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	// Update the block rule for the same pattern instead of adding a second one
+	guid := ""
+	if existing := findBlockRule(rules, opts.From); existing != nil {
+		guid = existing.Guid
+	}
+
+	if err := addEdgeRule(ctx, opts.Key, zoneID, buildBlockRule(guid, opts.From, opts.Desc)); err != nil {
+		log.Fatalf("Error adding edge rule: %v", err)
+	}
+	if guid != "" {
+		fmt.Printf("Updated block rule %s: %s\n", guid, opts.From)
+	} else {
+		fmt.Printf("Blocked requests to %s\n", opts.From)
+	}
+
+	for i := range rules {
+		if rules[i].ActionType != 1 || !rules[i].Enabled {
+			continue
+		}
+		if _, source, found := overlappingPatterns([]string{opts.From}, urlTriggerPatterns(rules[i])); found {
+			fmt.Printf("WARN: the redirect from %s (%s) overlaps the blocked pattern, which one applies depends on rule order\n", source, rules[i].Guid)
 		}
 	}
+}
 
-	uploadedWord := "file"
-	if successful != 1 {
-		uploadedWord = "files"
+func handleSetHeader() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.SetHeader
+
+	name, value, err := parseHeaderFlag(opts.Header)
+	if err != nil {
+		log.Fatalf("Invalid --header: %v", err)
 	}
-	skippedWord := "file"
-	if skipped != 1 {
-		skippedWord = "files"
+	warnings, err := validateRedirectPattern(opts.From, true)
+	for _, warning := range warnings {
+		fmt.Printf("WARN: %s\n", warning)
 	}
-	failedWord := "file"
-	if failed != 1 {
-		failedWord = "files"
+	if err != nil {
+		log.Fatalf("Invalid pattern: %v", err)
+	}
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	// Update the rule setting the same header on the same pattern instead of adding a second one
+	guid := ""
+	if existing := findHeaderRule(rules, opts.From, name); existing != nil {
+		guid = existing.Guid
+	}
+
+	if err := addEdgeRule(ctx, opts.Key, zoneID, buildHeaderRule(guid, opts.From, name, value, opts.Desc)); err != nil {
+		log.Fatalf("Error adding edge rule: %v", err)
+	}
+	if guid != "" {
+		fmt.Printf("Updated header rule %s: %s: %s on %s\n", guid, name, value, opts.From)
+	} else {
+		fmt.Printf("Set %s: %s on %s\n", name, value, opts.From)
+	}
+}
+
+func handleAddSlashPolicy() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.AddSlashPolicy
+
+	rule, err := buildSlashPolicyRule("", opts.Policy, opts.Status)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	if conflicts := slashPolicyConflicts(rules, opts.Policy); len(conflicts) > 0 {
+		fmt.Printf("\nThese redirects compete with the '%s' policy for the same requests:\n", opts.Policy)
+		for _, conflict := range conflicts {
+			fmt.Printf("   %s\n", describeRule(conflict))
+		}
+		log.Fatalf("Refusing to add the slash policy, change or delete the %d conflicting redirects first", len(conflicts))
+	}
+
+	existing := findSlashPolicyRule(rules)
+	if existing != nil {
+		rule.Guid = existing.Guid
+	}
+
+	output, err := json.MarshalIndent(rule, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding JSON: %v", err)
+	}
+	fmt.Printf("\nSlash policy rule:\n%s\n", output)
+
+	if opts.DryRun {
+		fmt.Println("\nDry run: the rule was not created")
+		return
+	}
+	if err := addEdgeRule(ctx, opts.Key, zoneID, rule); err != nil {
+		log.Fatalf("Error adding slash policy: %v", err)
+	}
+	if existing != nil {
+		fmt.Printf("\nUpdated slash policy rule %s\n", existing.Guid)
+		return
+	}
+	fmt.Println("\nAdded slash policy rule")
+}
+
+func handleMove() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Move
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	reordered, err := moveRuleBefore(rules, opts.Guid, opts.Before)
+	if err != nil {
+		log.Fatalf("Error moving rule: %v", err)
+	}
+
+	moved := 0
+	err = applyRuleOrder(ctx, opts.Key, zoneID, reordered, func(rule EdgeRuleResponse, index int) {
+		fmt.Printf("Order %d -> %d: %s\n", rule.OrderIndex, index, describeRule(rule))
+		moved++
+	})
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if moved == 0 {
+		fmt.Printf("Rule %s is already in front of %s\n", opts.Guid, opts.Before)
+		return
+	}
+	fmt.Printf("Moved rule %s in front of %s (%d rules renumbered)\n", opts.Guid, opts.Before, moved)
+}
+
+func handleHelp(app *kong.Application) {
+	if CLI.Help.All {
+		writeReference(os.Stdout, app)
+		return
+	}
+	node := findCommandNode(app, CLI.Help.Command)
+	if node == nil {
+		log.Fatalf("Unknown command '%s', run hop help --all for every command", strings.Join(CLI.Help.Command, " "))
+	}
+	if node.Type == kong.ApplicationNode {
+		writeReference(os.Stdout, app)
+		return
+	}
+	writeCommandHelp(os.Stdout, node)
+}
+
+func handleBackup() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Backup
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+
+	path, err := backupEdgeRules(ctx, opts.Key, fmt.Sprintf("%d", id), opts.Zone, opts.Out, time.Now())
+	if err != nil {
+		log.Fatalf("Error backing up edge rules: %v", err)
+	}
+	fmt.Printf("Backup written to %s\n", path)
+}
+
+func handlePark() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Park
+
+	domain := canonicalHost(opts.Domain)
+	if domain == "" || strings.ContainsAny(domain, "/:*") {
+		log.Fatalf("Invalid domain '%s'", opts.Domain)
+	}
+	if !isValidDomain(opts.To) {
+		log.Fatalf("Invalid destination URL '%s'", opts.To)
+	}
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+	if opts.DryRun {
+		fmt.Println("Dry run: nothing will be changed")
+	}
+
+	err = parkDomain(ctx, opts.Key, zoneID, ParkOptions{Domain: domain, To: opts.To, PreservePath: opts.PreservePath, DryRun: opts.DryRun}, func(step ParkStep) {
+		fmt.Printf("%-9s %-8s %s\n", strings.ToUpper(step.Status), step.Name, step.Detail)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func handleRulesDiff() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Diff
+
+	pullZones, err := listPullZones(ctx, opts.Key)
+	if err != nil {
+		log.Fatalf("Error listing pull zones: %v", err)
+	}
+
+	zoneRules := func(name string) []EdgeRuleResponse {
+		id, found := pullZoneIDByName(pullZones, name)
+		if !found {
+			log.Fatalf("Pull zone '%s' not found", name)
+		}
+		rules, err := listEdgeRules(ctx, opts.Key, fmt.Sprintf("%d", id))
+		if err != nil {
+			log.Fatalf("Error listing edge rules of '%s': %v", name, err)
+		}
+		return rules
+	}
+
+	diff := sortRulesDiff(diffRedirectRules(zoneRules(opts.ZoneA), zoneRules(opts.ZoneB)))
+
+	if opts.Format == "json" {
+		output, err := json.MarshalIndent(buildRulesDiffReport(diff, opts.ZoneA, opts.ZoneB), "", "  ")
+		if err != nil {
+			log.Fatalf("Error encoding JSON: %v", err)
+		}
+		fmt.Println(string(output))
+	} else {
+		displayRulesDiff(diff, opts.ZoneA, opts.ZoneB)
+	}
+
+	if diff.hasDifferences() {
+		exit(1)
+	}
+}
+
+func handleCheckSitemap() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.CheckSitemap
+
+	urls, err := fetchSitemapURLs(ctx, opts.Sitemap)
+	if err != nil {
+		log.Fatalf("Error reading sitemap: %v", err)
+	}
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	rules, err := listEdgeRules(ctx, opts.Key, fmt.Sprintf("%d", id))
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	issues := checkSitemapURLs(urls, rules)
+
+	if opts.Format == "json" {
+		output, err := json.MarshalIndent(buildSitemapCheckReport(opts.Sitemap, len(urls), issues), "", "  ")
+		if err != nil {
+			log.Fatalf("Error encoding JSON: %v", err)
+		}
+		fmt.Println(string(output))
+	} else {
+		fmt.Fprintf(output, "Checked %d sitemap URLs against %d edge rules of '%s'\n", len(urls), len(rules), opts.Zone)
+		displayCheckResults(CheckResult{Issues: issues}, false)
+	}
+
+	if len(issues) > 0 {
+		exit(1)
+	}
+}
+
+func handleRestore() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Restore
+
+	backup, err := readBackupFile(opts.File)
+	if err != nil {
+		log.Fatalf("Error reading backup: %v", err)
+	}
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+
+	current, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	plan := planRestore(backup, current)
+	printRules := func(title string, rules []EdgeRuleResponse, marker string) {
+		if len(rules) == 0 {
+			return
+		}
+		fmt.Printf("\n%s (%d):\n", title, len(rules))
+		for _, rule := range rules {
+			fmt.Printf("  %s %s\n", marker, describeRule(rule))
+		}
+	}
+	printRules("Rules to recreate", plan.Recreate, "+")
+	printRules("Rules to update", plan.Update, "~")
+	printRules("Rules not in the backup, left untouched", plan.Extra, "?")
+	fmt.Printf("\nRestore: %d to recreate, %d to update, %d unchanged, %d not in the backup\n",
+		len(plan.Recreate), len(plan.Update), plan.Unchanged, len(plan.Extra))
+
+	toRestore := append(append([]EdgeRuleResponse{}, plan.Recreate...), plan.Update...)
+	if len(toRestore) == 0 {
+		fmt.Println("Nothing to restore.")
+		return
+	}
+	if !opts.Apply {
+		fmt.Println("Dry run: nothing was changed, re-run with --apply to restore the rules")
+		return
+	}
+
+	var failed []string
+	restored := 0
+	for _, rule := range toRestore {
+		if err := addEdgeRule(ctx, opts.Key, zoneID, restoredRule(rule)); err != nil {
+			fmt.Printf("FAILED %s: %v\n", describeRule(rule), err)
+			failed = append(failed, rule.Guid)
+			continue
+		}
+		fmt.Printf("Restored %s\n", describeRule(rule))
+		restored++
+	}
+
+	fmt.Printf("\nRestore finished: %d restored, %d failed\n", restored, len(failed))
+	if len(failed) > 0 {
+		fmt.Printf("Failed rules: %s\n", strings.Join(failed, ", "))
+		exit(1)
+	}
+}
+
+func handleDelete() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Delete
+
+	if (opts.FromPrefix == "") == (opts.FromGlob == "") {
+		log.Fatalf("Specify exactly one of --from-prefix or --from-glob")
+	}
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	selected := selectRulesToDelete(rules, opts.FromPrefix, opts.FromGlob)
+	if len(selected) == 0 {
+		fmt.Println("No matching redirects found.")
+		return
+	}
+
+	fmt.Printf("\nMatching redirects (%d):\n", len(selected))
+	for _, rule := range selected {
+		fmt.Printf("   %s\n", describeRule(rule))
+	}
+
+	if len(selected) > opts.Max {
+		log.Fatalf("%d redirects match, more than --max %d; narrow the pattern or raise --max", len(selected), opts.Max)
+	}
+
+	if !opts.Yes && !confirm(os.Stdin, os.Stdout, fmt.Sprintf("\nDelete %d redirects from '%s'?", len(selected), opts.Zone)) {
+		fmt.Println("Aborted, nothing was deleted.")
+		return
+	}
+
+	failed := 0
+	for _, rule := range selected {
+		if err := deleteEdgeRule(ctx, opts.Key, zoneID, rule.Guid); err != nil {
+			fmt.Printf("FAILED  %s: %v\n", describeRule(rule), err)
+			failed++
+			continue
+		}
+		fmt.Printf("Deleted %s\n", describeRule(rule))
+	}
+
+	fmt.Printf("\nDeleted %d of %d redirects\n", len(selected)-failed, len(selected))
+	if failed > 0 {
+		exit(1)
+	}
+}
+
+func handleFix() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Fix
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	fixes, unfixable := planFixes(rules)
+	if len(unfixable) > 0 {
+		fmt.Printf("\nNot fixable, left untouched (%d):\n", len(unfixable))
+		for _, issue := range unfixable {
+			fmt.Printf("   %s: %s\n", describeRule(*issue.Rule), issue.Message)
+		}
+	}
+	if len(fixes) == 0 {
+		fmt.Println("\nNothing to fix.")
+		return
+	}
+
+	fmt.Printf("\nPlanned fixes (%d):\n", len(fixes))
+	for _, fix := range fixes {
+		fmt.Printf("   ~ %s: %s\n", describeRule(fix.Rule), fix.Change)
+	}
+
+	if !opts.Yes && !confirm(os.Stdin, os.Stdout, fmt.Sprintf("\nApply %d fixes to '%s'?", len(fixes), opts.Zone)) {
+		fmt.Println("Aborted, nothing was changed.")
+		return
+	}
+
+	path, err := backupEdgeRules(ctx, opts.Key, zoneID, opts.Zone, opts.Out, time.Now())
+	if err != nil {
+		log.Fatalf("Error backing up edge rules: %v", err)
+	}
+	fmt.Printf("Backup written to %s\n", path)
+
+	failed := applyFixes(ctx, opts.Key, zoneID, fixes, os.Stdout)
+	fmt.Printf("\nFixed %d of %d rules\n", len(fixes)-failed, len(fixes))
+	if failed > 0 {
+		exit(1)
+	}
+}
+
+func handleDedupe() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Dedupe
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	plan := planDedupe(rules, opts.KeepFirst)
+	if len(plan) == 0 {
+		fmt.Println("No duplicate redirects found.")
+		return
+	}
+
+	toDelete, conflicts := 0, 0
+	for _, group := range plan {
+		fmt.Printf("\n%s\n", group.Source)
+		if group.Keep == nil {
+			conflicts++
+			fmt.Println("   CONFLICT: duplicates redirect to different destinations, skipped (use --keep-first to keep the rule in effect)")
+			continue
+		}
+		if group.Conflict {
+			fmt.Println("   CONFLICT: duplicates redirect to different destinations, keeping the rule in effect")
+		}
+		fmt.Printf("   KEEP   %s -> %s (%s)\n", group.Keep.Guid, group.Keep.ActionParameter1, map[bool]string{true: "enabled", false: "disabled"}[group.Keep.Enabled])
+		for _, rule := range group.Remove {
+			fmt.Printf("   DELETE %s -> %s (%s)\n", rule.Guid, rule.ActionParameter1, map[bool]string{true: "enabled", false: "disabled"}[rule.Enabled])
+			toDelete++
+		}
+	}
+
+	fmt.Printf("\nDuplicates: %d groups, %d rules to delete, %d conflicts skipped\n", len(plan), toDelete, conflicts)
+	if !opts.Apply {
+		if toDelete > 0 {
+			fmt.Println("Dry run: nothing was deleted, re-run with --apply to delete the rules marked DELETE")
+		}
+		return
+	}
+
+	if opts.Backup && toDelete > 0 {
+		path, err := backupEdgeRules(ctx, opts.Key, zoneID, opts.Zone, opts.Out, time.Now())
+		if err != nil {
+			log.Fatalf("Error backing up edge rules: %v", err)
+		}
+		fmt.Printf("Backup written to %s\n", path)
+	}
+
+	for _, group := range plan {
+		for _, rule := range group.Remove {
+			if err := deleteEdgeRule(ctx, opts.Key, zoneID, rule.Guid); err != nil {
+				log.Fatalf("Error deleting rule %s: %v", rule.Guid, err)
+			}
+			fmt.Printf("Deleted rule %s (%s)\n", rule.Guid, group.Source)
+		}
+	}
+}
+
+func handleGaps() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Gaps
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+	fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	now := time.Now()
+	since := now.Add(-opts.Since)
+	scan := &LogScan{}
+
+	if opts.LogFile != "" {
+		file, err := os.Open(opts.LogFile)
+		if err != nil {
+			log.Fatalf("Error opening log file: %v", err)
+		}
+		defer file.Close()
+		if err := scan.read(file, since, opts.MaxLines); err != nil {
+			log.Fatalf("Error reading log file: %v", err)
+		}
+	} else {
+		for _, day := range logDays(since, now) {
+			if scan.Truncated {
+				break
+			}
+			body, err := fetchPullZoneLog(ctx, opts.Key, zoneID, day)
+			if err != nil {
+				log.Fatalf("Error fetching logs for %s: %v", day.Format("2006-01-02"), err)
+			}
+			if body == nil {
+				fmt.Printf("No logs for %s\n", day.Format("2006-01-02"))
+				continue
+			}
+			err = scan.read(body, since, opts.MaxLines)
+			drainAndClose(body)
+			if err != nil {
+				log.Fatalf("Error reading logs for %s: %v", day.Format("2006-01-02"), err)
+			}
+		}
+	}
+
+	gaps, uncovered := rankGaps(scan.Hits, rules, opts.Top)
+
+	fmt.Printf("Read %s log lines since %s", formatCount(scan.Lines), since.UTC().Format(time.RFC3339))
+	if scan.Malformed > 0 {
+		fmt.Printf(", %s malformed lines skipped", formatCount(scan.Malformed))
+	}
+	fmt.Println()
+	if scan.Truncated {
+		fmt.Printf("WARN: stopped after %s lines (--max-lines), results are incomplete\n", formatCount(opts.MaxLines))
+	}
+	fmt.Printf("404 paths: %d distinct, %d not covered by a redirect\n", len(scan.Hits), uncovered)
+
+	if len(gaps) == 0 {
+		fmt.Println("\nEvery 404 path is covered by a redirect.")
+		return
+	}
+
+	fmt.Printf("\nTop %d uncovered 404 paths:\n", len(gaps))
+	for _, gap := range gaps {
+		fmt.Printf("%8d  %s\n", gap.Hits, gap.Path)
+	}
+}
+
+func handleZonesClone() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Zones.Clone
+
+	pullZones, err := listPullZones(ctx, opts.Key)
+	if err != nil {
+		log.Fatalf("Error listing pull zones: %v", err)
+	}
+
+	fromID, found := pullZoneIDByName(pullZones, opts.From)
+	if !found {
+		log.Fatalf("Pull zone '%s' not found", opts.From)
+	}
+	fromZone, err := getPullZoneDetails(ctx, opts.Key, fmt.Sprintf("%d", fromID))
+	if err != nil {
+		log.Fatalf("Error getting pull zone '%s': %v", opts.From, err)
+	}
+
+	toID, toExists := pullZoneIDByName(pullZones, opts.To)
+	toRules := []EdgeRuleResponse{}
+	if toExists {
+		toZone, err := getPullZoneDetails(ctx, opts.Key, fmt.Sprintf("%d", toID))
+		if err != nil {
+			log.Fatalf("Error getting pull zone '%s': %v", opts.To, err)
+		}
+		toRules = toZone.EdgeRules
+	}
+
+	diff := diffRedirectRules(fromZone.EdgeRules, toRules)
+
+	if opts.Diff {
+		if !toExists {
+			log.Fatalf("Pull zone '%s' does not exist, nothing to compare", opts.To)
+		}
+		displayRulesDiff(diff, opts.From, opts.To)
+		return
+	}
+
+	switch {
+	case toExists:
+		fmt.Printf("Pull zone '%s' already exists with ID: %d\n", opts.To, toID)
+	case opts.DryRun:
+		fmt.Printf("Would create pull zone '%s' with origin %s\n", opts.To, fromZone.OriginUrl)
+	default:
+		if fromZone.OriginUrl == "" {
+			log.Fatalf("Pull zone '%s' has no origin URL to clone", opts.From)
+		}
+		toID, err = createPullZone(ctx, opts.Key, opts.To, fromZone.OriginUrl)
+		if err != nil {
+			log.Fatalf("Error creating pull zone '%s': %v", opts.To, err)
+		}
+		fmt.Printf("Created pull zone '%s' with ID: %d\n", opts.To, toID)
+	}
+
+	if opts.WithRules {
+		plan := planRuleClone(diff, opts.From)
+		for _, rule := range plan {
+			action, done := "create", "Created"
+			if rule.Guid != "" {
+				action, done = "update", "Updated"
+			}
+			source := strings.Join(rule.Triggers[0].PatternMatches, ", ")
+			if opts.DryRun {
+				fmt.Printf("Would %s redirect %s -> %s\n", action, source, rule.ActionParameter1)
+				continue
+			}
+			if err := addEdgeRule(ctx, opts.Key, fmt.Sprintf("%d", toID), rule); err != nil {
+				log.Fatalf("Error copying redirect %s: %v", source, err)
+			}
+			fmt.Printf("%s redirect %s -> %s\n", done, source, rule.ActionParameter1)
+		}
+		fmt.Printf("\nRedirects: %d to copy, %d already up to date, %d only in '%s' (left untouched)\n",
+			len(plan), diff.Unchanged, len(diff.Extra), opts.To)
+	}
+
+	if opts.WithHostnamesReport {
+		items := hostnameChecklist(fromZone.Hostnames, opts.To)
+		fmt.Printf("\nHOSTNAME CHECKLIST (production hostnames are not attached to '%s')\n", opts.To)
+		fmt.Println(strings.Repeat("-", 40))
+		fmt.Printf("%s.b-cdn.net works without any DNS or SSL setup\n", opts.To)
+		for _, item := range items {
+			fmt.Printf("\n%s -> %s\n", item.Hostname, item.Staging)
+			for i, step := range item.Steps {
+				fmt.Printf("   %d. %s\n", i+1, step)
+			}
+		}
+	}
+}
+
+// handleCDNPush runs cdn push and returns its exit code, and the error when hop itself failed.
+// The first Ctrl-C or SIGTERM stops the push gracefully, a second one exits immediately.
+func handleCDNPush() (int, error) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	ctx, stop := watchInterrupts(context.Background(), signals, func() { os.Exit(exitInterrupted) })
+	defer stop()
+
+	// A push has no deadline unless --timeout sets one
+	if CLI.CDN.Push.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, CLI.CDN.Push.Timeout)
+		defer cancel()
+	}
+	return runPush(ctx)
+}
+
+// runPush pushes --from as configured in CLI.CDN.Push until ctx is cancelled and returns the exit code.
+// A push cancelled by ctx reports the files it did not attempt and returns exitInterrupted, or 1 when --timeout ended it.
+// Bad flags and API errors before or after the uploads return exitFailure with the error, run reports it.
+func runPush(baseCtx context.Context) (int, error) {
+	ctx := createDebugContext(baseCtx)
+	completion = newCompletionHook("hop cdn push", CLI.CDN.Push.OnComplete, CLI.CDN.Push.NotifyDesktop)
+
+	// Verify local directory exists
+	localDir := CLI.CDN.Push.From
+	if _, err := os.Stat(localDir); os.IsNotExist(err) {
+		return exitFailure, fmt.Errorf("local directory '%s' does not exist", localDir)
+	}
+
+	filter, err := newPathFilter(CLI.CDN.Push.Include, CLI.CDN.Push.Exclude)
+	if err != nil {
+		return exitFailure, err
+	}
+	if CLI.CDN.Push.AllowExternalSymlinks && !CLI.CDN.Push.FollowSymlinks {
+		return exitFailure, errors.New("--allow-external-symlinks requires --follow-symlinks")
+	}
+	walk := LocalWalkOptions{
+		FollowSymlinks:        CLI.CDN.Push.FollowSymlinks,
+		AllowExternalSymlinks: CLI.CDN.Push.AllowExternalSymlinks,
+		IncludeHidden:         CLI.CDN.Push.IncludeHidden,
+	}
+	maxFileSize, err := resolveMaxFileSize(CLI.CDN.Push.MaxFileSize, CLI.CDN.Push.Strict)
+	if err != nil {
+		return exitFailure, fmt.Errorf("invalid --max-file-size: %v", err)
+	}
+	if CLI.CDN.Push.SkipOversize && maxFileSize == 0 {
+		return exitFailure, errors.New("--skip-oversize requires --max-file-size or --strict")
+	}
+	var limitRate int64
+	if CLI.CDN.Push.LimitRate != "" {
+		if limitRate, err = parseByteSize(CLI.CDN.Push.LimitRate); err != nil {
+			return exitFailure, fmt.Errorf("invalid --limit-rate: %v", err)
+		}
+	}
+	minThroughput, err := parseByteSize(CLI.CDN.Push.MinThroughput)
+	if err != nil {
+		return exitFailure, fmt.Errorf("invalid --min-throughput: %v", err)
+	}
+	var contentTypes map[string]string
+	if CLI.CDN.Push.ContentTypeMap != "" {
+		contentTypes, err = readContentTypeMap(CLI.CDN.Push.ContentTypeMap)
+		if err != nil {
+			return exitFailure, fmt.Errorf("error reading content type map: %v", err)
+		}
+	}
+
+	// Refuse to publish secrets before any bytes are transferred
+	if !CLI.CDN.Push.AllowSensitive {
+		sensitive, err := scanSensitiveFiles(localDir, CLI.CDN.Push.SensitivePattern, filter, walk)
+		if err != nil {
+			return exitFailure, fmt.Errorf("error scanning '%s': %v", localDir, err)
+		}
+		if len(sensitive) > 0 {
+			fmt.Printf("ERROR: Refusing to upload %d sensitive files:\n", len(sensitive))
+			for _, relPath := range sensitive {
+				fmt.Printf("  %s\n", relPath)
+			}
+			fmt.Println("Remove them from the upload directory or pass --allow-sensitive")
+			completion.finish(completionFailed, fmt.Sprintf("refused to upload %d sensitive files", len(sensitive)))
+			return 1, nil
+		}
+	}
+
+	var hashedAssetsPattern *regexp.Regexp
+	if CLI.CDN.Push.HashedAssets {
+		expr := CLI.CDN.Push.HashedAssetsPattern
+		if expr == "" {
+			expr = defaultHashedAssetsPattern
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return exitFailure, fmt.Errorf("invalid --hashed-assets-pattern: %v", err)
+		}
+		hashedAssetsPattern = pattern
+	}
+
+	remoteDir, err := normalizeRemoteDir(CLI.CDN.Push.To)
+	if err != nil {
+		return exitFailure, fmt.Errorf("invalid --to: %v", err)
+	}
+	if CLI.CDN.Push.Release != "" {
+		if remoteDir != "" {
+			return exitFailure, fmt.Errorf("--to cannot be combined with --release, releases are always uploaded to %s/<id>/", releasesDir)
+		}
+		if err := validateReleaseID(CLI.CDN.Push.Release); err != nil {
+			return exitFailure, err
+		}
+		remoteDir = releaseRemoteDir(CLI.CDN.Push.Release)
+	}
+
+	if CLI.CDN.Push.HashWorkers < 0 {
+		return exitFailure, errors.New("--hash-workers must not be negative")
+	}
+	checksumCache, err := checksumCacheFlag(localDir, CLI.CDN.Push.CacheFile, CLI.CDN.Push.NoCache)
+	if err != nil {
+		return exitFailure, err
+	}
+
+	// Look up pull zone by name
+	pullZoneID, err := findPullZoneByName(ctx, CLI.CDN.Push.Key, CLI.CDN.Push.Zone)
+	if err != nil {
+		return exitFailure, fmt.Errorf("error finding pull zone '%s': %v", CLI.CDN.Push.Zone, err)
+	}
+	fmt.Printf("Found pull zone '%s' with ID: %d\n", CLI.CDN.Push.Zone, pullZoneID)
+
+	// Find associated storage zone
+	storageZone, err := getStorageZoneByPullZone(ctx, CLI.CDN.Push.Key, pullZoneID)
+	if err != nil {
+		return exitFailure, fmt.Errorf("error finding storage zone: %v", err)
+	}
+	fmt.Printf("Found storage zone: %s\n", storageZone.Name)
+
+	if err := printRemoteSummary(ctx, storageZone, remoteDir, CLI.CDN.Push.RemoteStats); err != nil {
+		return exitFailure, fmt.Errorf("error inspecting storage zone '%s': %v", storageZone.Name, err)
+	}
+
+	// Upload directory contents
+	if CLI.CDN.Push.DryRun {
+		fmt.Println("Dry run, no files are uploaded")
+	}
+	if remoteDir != "" {
+		fmt.Printf("Uploading files from '%s' to '%s/' in storage zone '%s'...\n", localDir, remoteDir, storageZone.Name)
+	} else {
+		fmt.Printf("Uploading files from '%s' to storage zone '%s'...\n", localDir, storageZone.Name)
+	}
+
+	startedAt := time.Now()
+	push := uploadDirectoryOptimized(ctx, storageZone, localDir, remoteDir, PushOptions{
+		DryRun:      CLI.CDN.Push.DryRun,
+		Delete:      CLI.CDN.Push.Delete,
+		MaxDelete:   CLI.CDN.Push.MaxDelete,
+		ForceDelete: CLI.CDN.Push.ForceDelete,
+		Filter:      filter,
+		Walk:        walk,
+
+		NoVerifyChecksum: CLI.CDN.Push.NoVerifyChecksum,
+		PerFileTimeout:   CLI.CDN.Push.PerFileTimeout,
+		MinThroughput:    minThroughput,
+		ContentTypes:     contentTypes,
+		Progress:         isTerminal(os.Stdout),
+		Quiet:            CLI.CDN.Push.Quiet,
+		Verify:           CLI.CDN.Push.Verify,
+		VerifyAttempts:   CLI.CDN.Push.VerifyAttempts,
+		VerifyInterval:   CLI.CDN.Push.VerifyInterval,
+		Fast:             CLI.CDN.Push.Fast,
+		Cache:            checksumCache,
+		HashWorkers:      CLI.CDN.Push.HashWorkers,
+		MaxFileSize:      maxFileSize,
+		SkipOversize:     CLI.CDN.Push.SkipOversize,
+		LimitRate:        limitRate,
+	})
+	if err := checksumCache.save(); err != nil {
+		fmt.Printf("WARN: %v\n", err)
+	}
+	results := push.Files
+	if CLI.CDN.Push.SummaryJSON != "" {
+		summary := buildPushSummary(push)
+		summary.Timestamp = startedAt.UTC()
+		summary.Zone = CLI.CDN.Push.Zone
+		summary.StorageZone = storageZone.Name
+		summary.RemoteDir = remoteDir
+		summary.Release = CLI.CDN.Push.Release
+		summary.DryRun = CLI.CDN.Push.DryRun
+		if err := writePushSummary(CLI.CDN.Push.SummaryJSON, summary); err != nil {
+			fmt.Printf("WARN: could not write push summary: %v\n", err)
+		} else {
+			completion.setReport(CLI.CDN.Push.SummaryJSON)
+		}
+	}
+	interrupted := errors.Is(ctx.Err(), context.Canceled)
+	if CLI.CDN.Push.DryRun {
+		for _, result := range results {
+			if !result.Success && !result.Interrupted {
+				log.Printf("%s: %v", result.Path, result.Error)
+				completion.finish(completionFailed, fmt.Sprintf("%s: %v", result.Path, result.Error))
+				return 1, nil
+			}
+		}
+		if interrupted {
+			return exitInterrupted, nil
+		}
+		return exitClean, nil
+	}
+
+	// Summary
+	successful := 0
+	skipped := 0
+	failed := 0
+	notAttempted := 0
+	deleted := 0
+	deleteFailed := 0
+	for _, result := range results {
+		if result.Deleted {
+			if result.Success {
+				deleted++
+			} else {
+				deleteFailed++
+			}
+			continue
+		}
+		if result.Success {
+			if result.Skipped {
+				skipped++
+			} else {
+				successful++
+			}
+		} else if result.Interrupted {
+			notAttempted++
+		} else {
+			failed++
+		}
+	}
+
+	uploadedWord := "file"
+	if successful != 1 {
+		uploadedWord = "files"
+	}
+	skippedWord := "file"
+	if skipped != 1 {
+		skippedWord = "files"
+	}
+	failedWord := "file"
+	if failed != 1 {
+		failedWord = "files"
+	}
+	fmt.Printf("\nUpload complete: %d %s uploaded, %d %s skipped, %d %s failed",
+		successful, uploadedWord, skipped, skippedWord, failed, failedWord)
+	if notAttempted > 0 {
+		fmt.Printf(", %d %s", notAttempted, notAttemptedReason(ctx.Err()))
+	}
+	if filtered := push.Scan.filtered(); filtered != "" {
+		fmt.Printf(", %s", filtered)
+	}
+	fmt.Println()
+	if CLI.CDN.Push.Delete {
+		fmt.Printf("Delete complete: %d deleted, %d failed\n", deleted, deleteFailed)
+	}
+	completion.finish(pushCompletion(successful, skipped, failed+deleteFailed+notAttempted))
+
+	if failed+deleteFailed > 0 {
+		if deleteFailed > 0 {
+			fmt.Println("\nFailed uploads and deletions:")
+		} else {
+			fmt.Println("\nFailed uploads:")
+		}
+		for _, result := range results {
+			if !result.Success && !result.Interrupted {
+				fmt.Printf("  %s: %v\n", result.Path, result.Error)
+			}
+		}
+	}
+	if failed+deleteFailed+notAttempted > 0 || interrupted {
+		if CLI.CDN.Push.Release != "" {
+			reason := "some uploads failed"
+			if notAttempted > 0 || interrupted {
+				reason = "the push did not finish"
+			}
+			fmt.Printf("\nRelease '%s' was not activated because %s\n", CLI.CDN.Push.Release, reason)
+		}
+		if interrupted {
+			return exitInterrupted, nil
+		}
+		return 1, nil
+	}
+
+	if CLI.CDN.Push.Release != "" {
+		if leftOut := releaseLeftOut(push.Scan, results); leftOut != "" {
+			fmt.Fprintf(output, "\nRelease '%s' was uploaded but not activated because the push left out local files (%s)\n", CLI.CDN.Push.Release, leftOut)
+			fmt.Fprintf(output, "Activate it with 'hop cdn releases activate --zone %s --release %s' if the site does not need them\n", CLI.CDN.Push.Zone, CLI.CDN.Push.Release)
+			completion.finish(completionFailed, fmt.Sprintf("release '%s' not activated, files were left out", CLI.CDN.Push.Release))
+			return 1, nil
+		}
+	}
+
+	if hashedAssetsPattern != nil {
+		pushed, base := pushedAssetPaths(results, remoteDir, CLI.CDN.Push.Release != "")
+		hashed := selectHashedAssets(pushed, hashedAssetsPattern)
+		patterns := compressAssetPatterns(hashed, pushed, base)
+		changed, err := syncHashedAssetsRule(ctx, CLI.CDN.Push.Key, fmt.Sprintf("%d", pullZoneID), patterns)
+		if err != nil {
+			return exitFailure, fmt.Errorf("error updating hashed assets rule: %v", err)
+		}
+		switch {
+		case len(hashed) == 0:
+			fmt.Println("No hashed assets found, hashed assets rule left unchanged")
+		case changed:
+			fmt.Printf("Updated hashed assets rule: %d files covered by %d URL patterns\n", len(hashed), len(patterns))
+		default:
+			fmt.Printf("Hashed assets rule is up to date (%d files, %d URL patterns)\n", len(hashed), len(patterns))
+		}
+	}
+
+	if CLI.CDN.Push.Release != "" {
+		err := activateRelease(ctx, CLI.CDN.Push.Key, fmt.Sprintf("%d", pullZoneID), CLI.CDN.Push.Release)
+		if err != nil {
+			return exitFailure, fmt.Errorf("error activating release '%s': %v", CLI.CDN.Push.Release, err)
+		}
+		fmt.Printf("Activated release '%s'\n", CLI.CDN.Push.Release)
+	}
+
+	if CLI.CDN.Push.Purge || CLI.CDN.Push.PurgeAll > 0 {
+		if err := purgeAfterPush(ctx, fmt.Sprintf("%d", pullZoneID), results, remoteDir, successful+deleted); err != nil {
+			log.Print(err)
+			completion.finish(completionFailed, err.Error())
+			return 1, nil
+		}
+	}
+	return exitClean, nil
+}
+
+// purgeAfterPush purges the changed files of a push from the CDN cache.
+// Failures are warnings unless --strict-purge is set, then they are returned and fail the push.
+func purgeAfterPush(ctx context.Context, zoneID string, results []FileUploadStatus, remoteDir string, changedFiles int) error {
+	if changedFiles == 0 {
+		fmt.Println("No changed files, nothing to purge")
+		return nil
+	}
+	zone, err := getPullZoneDetails(ctx, CLI.CDN.Push.Key, zoneID)
+	if err != nil {
+		if CLI.CDN.Push.StrictPurge {
+			return fmt.Errorf("error purging the cache: %v", err)
+		}
+		fmt.Printf("WARN: cache not purged: %v\n", err)
+		return nil
+	}
+
+	urls := changedFileURLs(cdnHostname(zone), results, remoteDir, CLI.CDN.Push.Release != "")
+	outcome := purgeChangedFiles(ctx, CLI.CDN.Push.Key, zoneID, urls, changedFiles, CLI.CDN.Push.PurgeAll)
+	for _, err := range outcome.Errors {
+		fmt.Printf("WARN: %v\n", err)
+	}
+	switch {
+	case outcome.WholeZone && len(outcome.Errors) == 0:
+		fmt.Printf("Purged the whole pull zone cache, %d files changed\n", changedFiles)
+	case !outcome.WholeZone:
+		fmt.Printf("Purged %d of %d URLs from the cache\n", outcome.URLs-len(outcome.Errors), outcome.URLs)
+	}
+	if len(outcome.Errors) > 0 && CLI.CDN.Push.StrictPurge {
+		return errors.New("cache purge failed and --strict-purge is set")
+	}
+	return nil
+}
+
+// setupReleaseCommand resolves the pull zone ID and storage zone shared by the release commands
+func setupReleaseCommand(ctx context.Context, apiKey, zoneName string) (string, *StorageZone, error) {
+	pullZoneID, err := findPullZoneByName(ctx, apiKey, zoneName)
+	if err != nil {
+		return "", nil, fmt.Errorf("error finding pull zone '%s': %v", zoneName, err)
+	}
+	fmt.Printf("Found pull zone '%s' with ID: %d\n", zoneName, pullZoneID)
+
+	storageZone, err := getStorageZoneByPullZone(ctx, apiKey, pullZoneID)
+	if err != nil {
+		return "", nil, fmt.Errorf("error finding storage zone: %v", err)
+	}
+
+	return fmt.Sprintf("%d", pullZoneID), storageZone, nil
+}
+
+func handleReleasesList() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+
+	zoneID, storageZone, err := setupReleaseCommand(ctx, CLI.CDN.Releases.List.Key, CLI.CDN.Releases.List.Zone)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	releases, err := listReleases(ctx, storageZone)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rules, err := listEdgeRules(ctx, CLI.CDN.Releases.List.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+	active := activeReleaseID(rules)
+
+	if len(releases) == 0 {
+		fmt.Println("No releases found in this storage zone.")
+		return
+	}
+
+	sortReleasesNewestFirst(releases)
+
+	releaseWord := "release"
+	if len(releases) != 1 {
+		releaseWord = "releases"
+	}
+	fmt.Printf("\nFound %d %s:\n", len(releases), releaseWord)
+	for _, release := range releases {
+		marker := " "
+		if release.Name == active {
+			marker = "*"
+		}
+		fmt.Printf("%s %s (%s)\n", marker, release.Name, release.LastModified.Format("2006-01-02 15:04:05"))
+	}
+	if active == "" {
+		fmt.Println("\nNo release is active in this pull zone.")
+	}
+}
+
+func handleReleasesActivate() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+
+	releaseID := CLI.CDN.Releases.Activate.Release
+	if err := validateReleaseID(releaseID); err != nil {
+		log.Fatal(err)
+	}
+
+	zoneID, storageZone, err := setupReleaseCommand(ctx, CLI.CDN.Releases.Activate.Key, CLI.CDN.Releases.Activate.Zone)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	releases, err := listReleases(ctx, storageZone)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	found := false
+	for _, release := range releases {
+		if release.Name == releaseID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		log.Fatalf("Release '%s' does not exist in storage zone '%s'", releaseID, storageZone.Name)
+	}
+
+	if err := activateRelease(ctx, CLI.CDN.Releases.Activate.Key, zoneID, releaseID); err != nil {
+		log.Fatalf("Error activating release '%s': %v", releaseID, err)
+	}
+	fmt.Printf("Activated release '%s'\n", releaseID)
+}
+
+func handleReleasesPrune() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+
+	if CLI.CDN.Releases.Prune.Keep < 0 {
+		log.Fatalf("--keep must not be negative")
+	}
+
+	zoneID, storageZone, err := setupReleaseCommand(ctx, CLI.CDN.Releases.Prune.Key, CLI.CDN.Releases.Prune.Zone)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	releases, err := listReleases(ctx, storageZone)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rules, err := listEdgeRules(ctx, CLI.CDN.Releases.Prune.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	toPrune := selectReleasesToPrune(releases, activeReleaseID(rules), CLI.CDN.Releases.Prune.Keep)
+	if len(toPrune) == 0 {
+		fmt.Println("No releases to prune.")
+		return
 	}
-	fmt.Printf("\nUpload complete: %d %s uploaded, %d %s skipped, %d %s failed\n",
-		successful, uploadedWord, skipped, skippedWord, failed, failedWord)
 
-	if failed > 0 {
-		fmt.Println("\nFailed uploads:")
-		for _, result := range results {
-			if !result.Success {
-				fmt.Printf("  %s: %v\n", result.Path, result.Error)
-			}
+	failed := 0
+	for _, releaseID := range toPrune {
+		if CLI.CDN.Releases.Prune.DryRun {
+			fmt.Printf("Would delete release: %s\n", releaseID)
+			continue
+		}
+		if err := deleteRemotePath(ctx, storageZone, releaseRemoteDir(releaseID)+"/"); err != nil {
+			fmt.Printf("ERROR %s: %v\n", releaseID, err)
+			failed++
+			continue
 		}
-		os.Exit(1)
+		fmt.Printf("Deleted release: %s\n", releaseID)
+	}
+
+	if failed > 0 {
+		exit(1)
 	}
 }
 
@@ -184,6 +2024,15 @@ func handleAdd() {
 
 	ctx := createDebugContext(baseCtx)
 
+	// Validate the source pattern before touching the API
+	warnings, err := validateRedirectPattern(CLI.Rules.Add.From, CLI.Rules.Add.AllowComplexPatterns)
+	for _, warning := range warnings {
+		fmt.Printf("WARN: %s\n", warning)
+	}
+	if err != nil {
+		log.Fatalf("Invalid source pattern: %v", err)
+	}
+
 	// Look up pull zone by name
 	id, err := findPullZoneByName(ctx, CLI.Rules.Add.Key, CLI.Rules.Add.Zone)
 	if err != nil {
@@ -198,29 +2047,59 @@ func handleAdd() {
 		desc = fmt.Sprintf("302 redirect from %s to %s", CLI.Rules.Add.From, CLI.Rules.Add.To)
 	}
 
+	destination := CLI.Rules.Add.To
+	if CLI.Rules.Add.PreserveQuery {
+		if strings.Contains(destination, "?") {
+			fmt.Printf("WARN: destination '%s' already contains '?', preserving the query string produces malformed URLs\n", destination)
+		}
+		destination = withPreservedQuery(destination)
+	}
+
+	patterns := []string{CLI.Rules.Add.From}
+	if CLI.Rules.Add.CaseInsensitive {
+		patterns = caseInsensitivePatterns(CLI.Rules.Add.From)
+		fmt.Println("WARN: Bunny URL triggers are case-sensitive, matching the source as given and in lowercase only")
+	}
+
 	// Create the edge rule for 302 redirect using the Redirect action
 	rule := EdgeRule{
-		ActionType:          1,                // Redirect
-		ActionParameter1:    CLI.Rules.Add.To, // Destination URL
-		ActionParameter2:    "302",            // Status code
-		TriggerMatchingType: 0,                // MatchAny
+		ActionType:          1,           // Redirect
+		ActionParameter1:    destination, // Destination URL
+		ActionParameter2:    "302",       // Status code
+		TriggerMatchingType: 0,           // MatchAny
 		Description:         desc,
 		Enabled:             true,
 		Triggers: []Trigger{
 			{
 				Type:                0, // Url trigger
-				PatternMatches:      []string{CLI.Rules.Add.From},
+				PatternMatches:      patterns,
 				PatternMatchingType: 0, // MatchAny
 			},
 		},
 	}
 
+	// Reuse the GUID of an existing redirect so Bunny updates it in place
+	if CLI.Rules.Add.Overwrite {
+		rules, err := listEdgeRules(ctx, CLI.Rules.Add.Key, zoneID)
+		if err != nil {
+			log.Fatalf("Error listing edge rules: %v", err)
+		}
+		rule.Guid = existingRedirectGuid(rules, CLI.Rules.Add.From)
+	}
+
 	err = addEdgeRule(ctx, CLI.Rules.Add.Key, zoneID, rule)
 	if err != nil {
 		log.Fatalf("Error adding edge rule: %v", err)
 	}
 
-	fmt.Printf("Successfully added 302 redirect from %s to %s\n", CLI.Rules.Add.From, CLI.Rules.Add.To)
+	switch {
+	case !CLI.Rules.Add.Overwrite:
+		fmt.Printf("Successfully added 302 redirect from %s to %s\n", CLI.Rules.Add.From, CLI.Rules.Add.To)
+	case rule.Guid != "":
+		fmt.Printf("Updated existing rule %s: %s -> %s\n", rule.Guid, CLI.Rules.Add.From, CLI.Rules.Add.To)
+	default:
+		fmt.Printf("Created new rule: %s -> %s\n", CLI.Rules.Add.From, CLI.Rules.Add.To)
+	}
 }
 
 func handleList() {
@@ -228,6 +2107,8 @@ func handleList() {
 	defer cancel()
 
 	ctx := createDebugContext(baseCtx)
+	redactor := setupRedaction(CLI.Rules.List.Redact, CLI.Rules.List.Zone)
+	defer writeRedactionMap(redactor, CLI.Rules.List.RedactMap)
 
 	// Look up pull zone by name
 	id, err := findPullZoneByName(ctx, CLI.Rules.List.Key, CLI.Rules.List.Zone)
@@ -235,7 +2116,13 @@ func handleList() {
 		log.Fatalf("Error finding pull zone '%s': %v", CLI.Rules.List.Zone, err)
 	}
 	zoneID := fmt.Sprintf("%d", id)
-	fmt.Printf("Found pull zone '%s' with ID: %s\n", CLI.Rules.List.Zone, zoneID)
+	if err := redactZone(ctx, redactor, CLI.Rules.List.Key, zoneID); err != nil {
+		log.Fatal(err)
+	}
+	csvOutput := CLI.Rules.List.Format == "csv"
+	if !csvOutput {
+		fmt.Fprintf(output, "Found pull zone '%s' with ID: %s\n", CLI.Rules.List.Zone, zoneID)
+	}
 
 	// Get all edge rules
 	rules, err := listEdgeRules(ctx, CLI.Rules.List.Key, zoneID)
@@ -243,63 +2130,515 @@ func handleList() {
 		log.Fatalf("Error listing edge rules: %v", err)
 	}
 
+	filter := RuleFilter{Disabled: CLI.Rules.List.Disabled, Match: CLI.Rules.List.Match}
+
+	if CLI.Rules.List.All {
+		filtered := filterRules(rules, filter)
+		sortRules(filtered, CLI.Rules.List.Sort)
+		if csvOutput {
+			if err := writeRulesCSV(output, filtered); err != nil {
+				log.Fatalf("Error writing CSV: %v", err)
+			}
+			return
+		}
+		if filter.active() {
+			fmt.Fprintf(output, "\nShowing %d of %d edge rules\n", len(filtered), len(rules))
+		}
+		displayAllRules(filtered)
+		return
+	}
+
 	// Filter and display 302 redirects
-	redirects := []EdgeRuleResponse{}
+	allRedirects := []EdgeRuleResponse{}
 	for _, rule := range rules {
 		if rule.ActionType == 1 && rule.ActionParameter2 == "302" {
-			redirects = append(redirects, rule)
+			allRedirects = append(allRedirects, rule)
 		}
 	}
+	redirects := filterRules(allRedirects, filter)
+	sortRules(redirects, CLI.Rules.List.Sort)
+
+	if csvOutput {
+		if err := writeRulesCSV(output, redirects); err != nil {
+			log.Fatalf("Error writing CSV: %v", err)
+		}
+		return
+	}
 
 	if len(redirects) == 0 {
-		fmt.Println("No 302 redirects found in this pull zone.")
+		if filter.active() && len(allRedirects) > 0 {
+			fmt.Fprintf(output, "No 302 redirects match the filter (showing 0 of %d redirects).\n", len(allRedirects))
+			return
+		}
+		fmt.Fprintln(output, "No 302 redirects found in this pull zone.")
 		return
 	}
 
 	redirectWord := "redirect"
-	if len(redirects) != 1 {
+	if len(allRedirects) != 1 {
 		redirectWord = "redirects"
 	}
-	fmt.Printf("\nFound %d 302 %s:\n", len(redirects), redirectWord)
-	fmt.Println("=" + strings.Repeat("=", 70))
+	if filter.active() {
+		fmt.Fprintf(output, "\nShowing %d of %d 302 %s:\n", len(redirects), len(allRedirects), redirectWord)
+	} else {
+		fmt.Fprintf(output, "\nFound %d 302 %s:\n", len(redirects), redirectWord)
+	}
+	fmt.Fprintln(output, "="+strings.Repeat("=", 70))
 
 	for i, redirect := range redirects {
-		fmt.Printf("\n%d. %s\n", i+1, redirect.Description)
-		fmt.Printf("   Status: %s\n", map[bool]string{true: "Enabled", false: "Disabled"}[redirect.Enabled])
+		fmt.Fprintf(output, "\n%d. %s\n", i+1, redirect.Description)
+		fmt.Fprintf(output, "   Status: %s\n", map[bool]string{true: "Enabled", false: "Disabled"}[redirect.Enabled])
 
 		// Extract source URL from triggers
 		if len(redirect.Triggers) > 0 && len(redirect.Triggers[0].PatternMatches) > 0 {
-			fmt.Printf("   From: %s\n", redirect.Triggers[0].PatternMatches[0])
+			fmt.Fprintf(output, "   From: %s\n", redirect.Triggers[0].PatternMatches[0])
+		}
+
+		fmt.Fprintf(output, "   To: %s\n", stripPreservedQuery(redirect.ActionParameter1))
+		if preservesQuery(redirect.ActionParameter1) {
+			fmt.Fprintf(output, "   Query string: preserved\n")
+		}
+		fmt.Fprintf(output, "   Order: %d\n", redirect.OrderIndex)
+		fmt.Fprintf(output, "   GUID: %s\n", redirect.Guid)
+	}
+}
+
+func handleFind() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+
+	// Look up pull zone by name
+	id, err := findPullZoneByName(ctx, CLI.Rules.Find.Key, CLI.Rules.Find.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", CLI.Rules.Find.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+
+	rules, err := listEdgeRules(ctx, CLI.Rules.Find.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	source := CLI.Rules.Find.Source
+	matches := findRedirectsForPath(rules, source)
+	if len(matches) == 0 {
+		fmt.Printf("No redirect configured for %s\n", source)
+		exit(exitNoMatch)
+	}
+
+	winner := winningRedirect(matches)
+	ruleWord := "rule"
+	if len(matches) != 1 {
+		ruleWord = "rules"
+	}
+	fmt.Printf("%d redirect %s match %s (in evaluation order):\n", len(matches), ruleWord, source)
+
+	for i, rule := range matches {
+		fmt.Printf("\n%d. %s\n", i+1, rule.Description)
+		fmt.Printf("   Status: %s\n", map[bool]string{true: "Enabled", false: "Disabled"}[rule.Enabled])
+		fmt.Printf("   From: %s\n", strings.Join(urlTriggerPatterns(*rule), ", "))
+		fmt.Printf("   To: %s\n", rule.ActionParameter1)
+		fmt.Printf("   GUID: %s\n", rule.Guid)
+		if rule == winner && len(matches) > 1 {
+			fmt.Printf("   Wins: first enabled match, the later rules are never applied\n")
+		}
+	}
+
+	if winner == nil {
+		fmt.Printf("\nAll matching rules are disabled, no redirect is applied for %s\n", source)
+		exit(exitNoMatch)
+	}
+}
+
+func handleValidate() {
+	opts := CLI.Rules.Validate
+
+	rules, err := readRulesFile(opts.File)
+	if err != nil {
+		log.Fatalf("Error reading rules file: %v", err)
+	}
+	fmt.Fprintf(output, "Validating %d rules from '%s'\n", len(rules), opts.File)
+
+	issues := validateRules(rules, opts.Hostnames)
+	displayCheckResults(CheckResult{Issues: issues}, false)
+
+	for _, issue := range issues {
+		if issue.Severity == SeverityError || issue.Severity == SeverityCritical {
+			exit(1)
+		}
+	}
+}
+
+func handleRulesTest() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Test
+
+	id, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zone, err := getPullZoneDetails(ctx, opts.Key, fmt.Sprintf("%d", id))
+	if err != nil {
+		log.Fatalf("Error getting pull zone details: %v", err)
+	}
+
+	host := opts.Host
+	if host == "" {
+		host = firstCustomHostname(zone.Hostnames)
+	}
+	if host == "" {
+		host = cdnHostname(zone)
+	}
+	startURL := simulationStartURL(opts.Path, host)
+
+	sim, err := simulateRequest(zone.EdgeRules, zone.Hostnames, startURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Simulating GET %s against %d edge rules of '%s'\n", startURL, len(zone.EdgeRules), opts.Zone)
+	displaySimulation(os.Stdout, sim)
+
+	failed := sim.End == simulationLoop || sim.End == simulationTooLong
+	if opts.Live {
+		probe, err := probeRedirect(ctx, startURL)
+		if err != nil {
+			log.Fatalf("Error requesting %s: %v", startURL, err)
+		}
+		fmt.Printf("\nLIVE\n")
+		fmt.Printf("   GET %s: %d", startURL, probe.Status)
+		if probe.Location != "" {
+			fmt.Printf(" Location: %s", probe.Location)
+		}
+		fmt.Println()
+		mismatches := compareLiveProbe(sim, probe)
+		for _, mismatch := range mismatches {
+			fmt.Printf("   MISMATCH: %s\n", mismatch)
+		}
+		if len(mismatches) == 0 {
+			fmt.Printf("   OK: the first response matches the simulation\n")
+		}
+		failed = failed || len(mismatches) > 0
+
+		if last := sim.Hops[len(sim.Hops)-1]; sim.End == simulationExternal {
+			statusCode, hasRedirect, _, err := performHealthCheck(ctx, last.Location)
+			switch {
+			case err != nil:
+				fmt.Printf("   WARN: destination %s is unreachable: %v\n", last.Location, err)
+			case hasRedirect:
+				fmt.Printf("   Destination %s still redirects after 3 hops (status %d)\n", last.Location, statusCode)
+			default:
+				fmt.Printf("   Destination %s answers %d\n", last.Location, statusCode)
+			}
+		}
+	}
+
+	if failed {
+		exit(1)
+	}
+}
+
+func handleGet() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+
+	// Look up pull zone by name
+	id, err := findPullZoneByName(ctx, CLI.Rules.Get.Key, CLI.Rules.Get.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", CLI.Rules.Get.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", id)
+
+	rules, err := listEdgeRules(ctx, CLI.Rules.Get.Key, zoneID)
+	if err != nil {
+		log.Fatalf("Error listing edge rules: %v", err)
+	}
+
+	rule := findRuleByGuid(rules, CLI.Rules.Get.Guid)
+	if rule == nil {
+		log.Fatalf("No edge rule with GUID '%s' in pull zone '%s'", CLI.Rules.Get.Guid, CLI.Rules.Get.Zone)
+	}
+
+	if CLI.Rules.Get.JSON {
+		output, err := json.MarshalIndent(rule, "", "  ")
+		if err != nil {
+			log.Fatalf("Error encoding JSON: %v", err)
+		}
+		fmt.Println(string(output))
+		return
+	}
+
+	fmt.Printf("GUID: %s\n", rule.Guid)
+	fmt.Printf("Description: %s\n", rule.Description)
+	fmt.Printf("Status: %s\n", map[bool]string{true: "Enabled", false: "Disabled"}[rule.Enabled])
+	fmt.Printf("Order: %d\n", rule.OrderIndex)
+	fmt.Printf("Action: %s (%d)\n", formatActionType(rule.ActionType), rule.ActionType)
+	fmt.Printf("Action parameter 1: %s\n", rule.ActionParameter1)
+	fmt.Printf("Action parameter 2: %s\n", rule.ActionParameter2)
+	fmt.Printf("Trigger matching: %s\n", formatPatternMatchingType(rule.TriggerMatchingType))
+	fmt.Printf("Triggers: %d\n", len(rule.Triggers))
+	for i, trigger := range rule.Triggers {
+		fmt.Printf("\n%d. %s (%s)\n", i+1, formatTriggerType(trigger.Type), formatPatternMatchingType(trigger.PatternMatchingType))
+		if trigger.Parameter1 != "" {
+			fmt.Printf("   Parameter: %s\n", trigger.Parameter1)
 		}
+		for _, pattern := range trigger.PatternMatches {
+			fmt.Printf("   Pattern: %s\n", pattern)
+		}
+	}
+}
+
+// displayAllRules prints every edge rule grouped by action type
+func displayAllRules(rules []EdgeRuleResponse) {
+	if len(rules) == 0 {
+		fmt.Fprintln(output, "No edge rules found in this pull zone.")
+		return
+	}
 
-		fmt.Printf("   To: %s\n", redirect.ActionParameter1)
-		fmt.Printf("   GUID: %s\n", redirect.Guid)
+	ruleWord := "rule"
+	if len(rules) != 1 {
+		ruleWord = "rules"
+	}
+	fmt.Fprintf(output, "\nFound %d edge %s:\n", len(rules), ruleWord)
+
+	actionTypes, groups := groupRulesByActionType(rules)
+	for _, actionType := range actionTypes {
+		group := groups[actionType]
+		fmt.Fprintln(output, "\n"+strings.Repeat("=", 71))
+		fmt.Fprintf(output, "%s (%d)\n", formatActionType(actionType), len(group))
+		fmt.Fprintln(output, strings.Repeat("=", 71))
+
+		for i, rule := range group {
+			fmt.Fprintf(output, "\n%d. %s\n", i+1, rule.Description)
+			fmt.Fprintf(output, "   Status: %s\n", map[bool]string{true: "Enabled", false: "Disabled"}[rule.Enabled])
+			for _, trigger := range rule.Triggers {
+				fmt.Fprintf(output, "   Trigger: %s %s\n", formatTriggerType(trigger.Type), strings.Join(trigger.PatternMatches, ", "))
+			}
+			switch {
+			case rule.ActionType == 2: // OriginUrl
+				fmt.Fprintf(output, "   Origin: %s\n", rule.ActionParameter1)
+			case rule.ActionType == 4: // BlockRequest
+				fmt.Fprintf(output, "   Blocked: %s\n", strings.Join(urlTriggerPatterns(rule), ", "))
+			case rule.ActionType == 5: // SetResponseHeader
+				fmt.Fprintf(output, "   Header: %s: %s\n", rule.ActionParameter1, rule.ActionParameter2)
+			case rule.ActionParameter1 != "":
+				fmt.Fprintf(output, "   Parameter 1: %s\n", rule.ActionParameter1)
+			}
+			if rule.ActionParameter2 != "" && rule.ActionType != 5 {
+				fmt.Fprintf(output, "   Parameter 2: %s\n", rule.ActionParameter2)
+			}
+			fmt.Fprintf(output, "   Order: %d\n", rule.OrderIndex)
+			fmt.Fprintf(output, "   GUID: %s\n", rule.Guid)
+		}
 	}
 }
 
-func handleCheck() {
+func handleCheck() (int, error) {
 	baseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
 	ctx := createDebugContext(baseCtx)
+	selection, err := parseCheckSelection(CLI.Rules.Check.Only, CLI.Rules.Check.Skip)
+	if err != nil {
+		return exitFailure, err
+	}
+	baseline, err := baselineFlag(CLI.Rules.Check.Baseline, CLI.Rules.Check.WriteBaseline)
+	if err != nil {
+		return exitFailure, err
+	}
+	sample, err := parseHealthSampleFlag(CLI.Rules.Check.HealthSample)
+	if err != nil {
+		return exitFailure, err
+	}
+	chainLimits, err := newChainLimits(CLI.Rules.Check.WarnChain, CLI.Rules.Check.MaxChain)
+	if err != nil {
+		return exitFailure, err
+	}
+	allowlist, err := allowlistFlag(CLI.Rules.Check.ExternalAllowlist)
+	if err != nil {
+		return exitFailure, err
+	}
+	redactor := setupRedaction(CLI.Rules.Check.Redact, CLI.Rules.Check.Zone)
+	defer writeRedactionMap(redactor, CLI.Rules.Check.RedactMap)
+	reportOut := checkReportWriter(CLI.Rules.Check.Format)
 
 	// Look up pull zone by name
 	id, err := findPullZoneByName(ctx, CLI.Rules.Check.Key, CLI.Rules.Check.Zone)
 	if err != nil {
-		log.Fatalf("Error finding pull zone '%s': %v", CLI.Rules.Check.Zone, err)
+		return exitFailure, fmt.Errorf("error finding pull zone '%s': %v", CLI.Rules.Check.Zone, err)
 	}
 	zoneID := fmt.Sprintf("%d", id)
-	fmt.Printf("Found pull zone '%s' with ID: %s\n", CLI.Rules.Check.Zone, zoneID)
+	if err := redactZone(ctx, redactor, CLI.Rules.Check.Key, zoneID); err != nil {
+		return exitFailure, err
+	}
+	fmt.Fprintf(output, "Found pull zone '%s' with ID: %s\n", CLI.Rules.Check.Zone, zoneID)
 
 	// Check rules using structured function
-	result, err := checkRulesStructured(ctx, CLI.Rules.Check.Key, zoneID, CLI.Rules.Check.SkipHealth)
+	opts := RulesCheckOptions{
+		SkipHealth:        CLI.Rules.Check.SkipHealth,
+		ParkingPatterns:   CLI.Rules.Check.ParkingPattern,
+		HealthSample:      sample,
+		HealthRetries:     CLI.Rules.Check.HealthRetries,
+		HostConcurrency:   CLI.Rules.Check.HostConcurrency,
+		HostInterval:      CLI.Rules.Check.HostInterval,
+		SlowThreshold:     CLI.Rules.Check.SlowThreshold,
+		Soft404:           soft404DetectorFlag(CLI.Rules.Check.DetectSoft404, CLI.Rules.Check.Soft404Phrase, CLI.Rules.Check.Soft404MinBytes),
+		ChainLimits:       chainLimits,
+		ExternalAllowlist: allowlist,
+		CanonicalHost:     CLI.Rules.Check.CanonicalHost,
+		HealthCache:       healthCacheFlag(CLI.Rules.Check.HealthCache, CLI.Rules.Check.HealthCacheTTL, CLI.Rules.Check.NoCache),
+		OnProgress:        healthProgressFlag(CLI.Rules.Check.Quiet),
+		SampleSeed:        time.Now().UTC().Format("2006-01-02"),
+		FailFast:          CLI.Rules.Check.FailFast,
+		FailOn:            CLI.Rules.Check.FailOn,
+		Selection:         selection,
+		VerifySources:     CLI.Rules.Check.VerifySources,
+		OriginHost:        CLI.Rules.Check.OriginHost,
+	}
+	result, err := checkRulesStructured(ctx, CLI.Rules.Check.Key, zoneID, opts)
+	if err != nil {
+		return exitFailure, fmt.Errorf("error checking rules: %v", err)
+	}
+	saveHealthCache(opts.HealthCache)
+
+	if CLI.Rules.Check.WriteBaseline {
+		if err := writeBaseline(CLI.Rules.Check.Baseline, result.Issues); err != nil {
+			return exitFailure, fmt.Errorf("error writing baseline: %v", err)
+		}
+		fmt.Fprintf(output, "Baseline of %d issues written to %s\n", len(result.Issues), CLI.Rules.Check.Baseline)
+		written := buildBaseline(result.Issues)
+		baseline = &written
+	}
+	result = applyBaseline(result, baseline)
+
+	if CLI.Rules.Check.ReportCSV != "" {
+		if err := writeIssuesCSVFile(CLI.Rules.Check.ReportCSV, result, CLI.Rules.Check.IncludeOK, redactor); err != nil {
+			return exitFailure, fmt.Errorf("error writing CSV report: %v", err)
+		}
+		completion.setReport(CLI.Rules.Check.ReportCSV)
+		fmt.Fprintf(output, "CSV report written to %s\n", CLI.Rules.Check.ReportCSV)
+	}
+
+	switch CLI.Rules.Check.Format {
+	case "json":
+		if err := writeJSONReport(reportOut, buildCheckReport(CLI.Rules.Check.Zone, result, opts.FailOn)); err != nil {
+			return exitFailure, err
+		}
+	case "github":
+		if err := writeGitHubAnnotations(reportOut, result); err != nil {
+			return exitFailure, err
+		}
+	default:
+		displayCheckResults(result, CLI.Rules.Check.Verbose)
+		displaySuppressed(result.Suppressed)
+		displayCoverage(result.Coverage)
+		displaySlowestDestinations(result.Slowest)
+	}
+
+	return checkExitCode(result.Issues, opts.FailOn), nil
+}
+
+func handleSuggest() {
+	baseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	ctx := createDebugContext(baseCtx)
+	opts := CLI.Rules.Suggest
+
+	// Read the sitemap first so a bad file fails before any API call
+	var sitemapPaths []string
+	if opts.Sitemap != "" {
+		// #nosec G304 - the sitemap path is supplied by the user on purpose
+		data, err := os.ReadFile(opts.Sitemap)
+		if err != nil {
+			log.Fatalf("Error reading sitemap: %v", err)
+		}
+		sitemap, err := parseSitemap(data)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(sitemap.Sitemaps) > 0 && !opts.JSON {
+			fmt.Printf("Note: '%s' is a sitemap index, its %d child sitemaps are not followed\n", opts.Sitemap, len(sitemap.Sitemaps))
+		}
+		for _, loc := range sitemap.URLs {
+			if sitemapPath := sitemapURLPath(loc); sitemapPath != "" {
+				sitemapPaths = append(sitemapPaths, sitemapPath)
+			}
+		}
+	}
+
+	pullZoneID, err := findPullZoneByName(ctx, opts.Key, opts.Zone)
+	if err != nil {
+		log.Fatalf("Error finding pull zone '%s': %v", opts.Zone, err)
+	}
+	zoneID := fmt.Sprintf("%d", pullZoneID)
+	if !opts.JSON {
+		fmt.Printf("Found pull zone '%s' with ID: %s\n", opts.Zone, zoneID)
+	}
+
+	storageZone, err := getStorageZoneByPullZone(ctx, opts.Key, pullZoneID)
+	if err != nil {
+		log.Fatalf("Error finding storage zone: %v", err)
+	}
+
+	remoteFiles, err := listRemoteFilesRecursive(ctx, storageZone, remoteDirForPrefix(normalizePathPrefix(opts.FromPrefix)))
+	if err != nil {
+		log.Fatalf("Error listing remote files: %v", err)
+	}
+	storagePaths := make([]string, 0, len(remoteFiles))
+	for _, file := range remoteFiles {
+		storagePaths = append(storagePaths, file.Path)
+	}
+
+	rules, err := listEdgeRules(ctx, opts.Key, zoneID)
 	if err != nil {
-		log.Fatalf("Error checking rules: %v", err)
+		log.Fatalf("Error listing edge rules: %v", err)
 	}
 
-	// Display results using the existing display function (it expects all issues)
-	allIssues := append(result.Issues, result.Successful...)
-	displayCheckResults(allIssues)
+	suggestions := buildPathSuggestions(storagePaths, sitemapPaths, opts.FromPrefix, rules)
+
+	if opts.JSON {
+		output, err := json.MarshalIndent(suggestions, "", "  ")
+		if err != nil {
+			log.Fatalf("Error encoding JSON: %v", err)
+		}
+		fmt.Println(string(output))
+		return
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Printf("No known paths found under %s\n", normalizePathPrefix(opts.FromPrefix))
+		return
+	}
+
+	pathWord := "path"
+	if len(suggestions) != 1 {
+		pathWord = "paths"
+	}
+	fmt.Printf("\nFound %d %s under %s:\n", len(suggestions), pathWord, normalizePathPrefix(opts.FromPrefix))
+
+	var missing []string
+	for _, suggestion := range suggestions {
+		if suggestion.Redirected {
+			fmt.Printf("REDIRECTED %s -> %s\n", suggestion.Path, suggestion.Destination)
+		} else {
+			fmt.Printf("MISSING    %s (%s)\n", suggestion.Path, strings.Join(suggestion.Origins, ", "))
+			missing = append(missing, suggestion.Path)
+		}
+	}
+
+	if opts.Commands && len(missing) > 0 {
+		fmt.Println()
+		for _, missingPath := range missing {
+			fmt.Println(formatAddCommand(opts.Zone, missingPath))
+		}
+	}
 }
 
 // setupDNSCommand handles the common setup for DNS commands
@@ -372,7 +2711,7 @@ func handleDNSList() {
 	}
 }
 
-func handleCDNCheck() {
+func handleCDNCheck() (int, error) {
 	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -381,14 +2720,14 @@ func handleCDNCheck() {
 	// Look up pull zone by name
 	pullZoneID, err := findPullZoneByName(ctx, CLI.CDN.Check.Key, CLI.CDN.Check.Zone)
 	if err != nil {
-		log.Fatalf("Error finding pull zone '%s': %v", CLI.CDN.Check.Zone, err)
+		return exitFailure, fmt.Errorf("error finding pull zone '%s': %v", CLI.CDN.Check.Zone, err)
 	}
 	fmt.Printf("Found pull zone '%s' with ID: %d\n", CLI.CDN.Check.Zone, pullZoneID)
 
 	// Get pull zone details to check SSL configuration
 	pullZoneDetails, err := getPullZoneDetails(ctx, CLI.CDN.Check.Key, fmt.Sprintf("%d", pullZoneID))
 	if err != nil {
-		log.Fatalf("Error getting pull zone details: %v", err)
+		return exitFailure, fmt.Errorf("error getting pull zone details: %v", err)
 	}
 
 	// Check SSL configuration using structured function
@@ -402,20 +2741,10 @@ func handleCDNCheck() {
 		fmt.Println(issue.Message)
 	}
 
-	// Summary and exit code
-	errorCount := 0
-	for _, issue := range result.Issues {
-		if issue.Severity == "error" {
-			errorCount++
-		}
-	}
-
-	if errorCount > 0 {
-		os.Exit(1)
-	}
+	return checkExitCode(result.Issues, CLI.CDN.Check.FailOn), nil
 }
 
-func handleDNSCheck() {
+func handleDNSCheck() (int, error) {
 	baseCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -424,11 +2753,11 @@ func handleDNSCheck() {
 	// Setup DNS command (shared logic)
 	pullZoneDetails, err := setupDNSCommand(ctx, CLI.DNS.Check.Key, CLI.DNS.Check.Zone)
 	if err != nil {
-		log.Fatal(err)
+		return exitFailure, err
 	}
 
 	if len(pullZoneDetails.Hostnames) == 0 {
-		return
+		return exitClean, nil
 	}
 
 	// Check DNS records using structured function
@@ -442,124 +2771,168 @@ func handleDNSCheck() {
 		fmt.Println(issue.Message)
 	}
 
-	// Summary and exit code
-	errorCount := 0
-	for _, issue := range result.Issues {
-		if issue.Severity == "error" {
-			errorCount++
-		}
-	}
-
-	if errorCount > 0 {
-		os.Exit(1)
-	}
+	return checkExitCode(result.Issues, CLI.DNS.Check.FailOn), nil
 }
 
-func handleGeneralCheck() {
+func handleGeneralCheck() (int, error) {
 	baseCtx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer cancel()
 
+	start := time.Now()
 	ctx := createDebugContext(baseCtx)
+	completion = newCompletionHook("hop check", CLI.Check.OnComplete, CLI.Check.NotifyDesktop)
+	selection, err := parseCheckSelection(CLI.Check.Only, CLI.Check.Skip)
+	if err != nil {
+		return exitFailure, err
+	}
+	sample, err := parseHealthSampleFlag(CLI.Check.HealthSample)
+	if err != nil {
+		return exitFailure, err
+	}
+	chainLimits, err := newChainLimits(CLI.Check.WarnChain, CLI.Check.MaxChain)
+	if err != nil {
+		return exitFailure, err
+	}
+	allowlist, err := allowlistFlag(CLI.Check.ExternalAllowlist)
+	if err != nil {
+		return exitFailure, err
+	}
+	redactor := setupRedaction(CLI.Check.Redact, CLI.Check.Zone)
+	defer writeRedactionMap(redactor, CLI.Check.RedactMap)
+	reportOut := checkReportWriter(CLI.Check.Format)
 
-	fmt.Printf("Running comprehensive checks for pull zone '%s'...\n", CLI.Check.Zone)
-	fmt.Println("=" + strings.Repeat("=", 60))
+	fmt.Fprintf(output, "Running comprehensive checks for pull zone '%s'...\n", CLI.Check.Zone)
+	fmt.Fprintln(output, "="+strings.Repeat("=", 60))
 
 	// Look up pull zone by name (shared by all checks)
 	pullZoneID, err := findPullZoneByName(ctx, CLI.Check.Key, CLI.Check.Zone)
 	if err != nil {
-		log.Fatalf("Error finding pull zone '%s': %v", CLI.Check.Zone, err)
+		return exitFailure, fmt.Errorf("error finding pull zone '%s': %v", CLI.Check.Zone, err)
 	}
 	zoneID := fmt.Sprintf("%d", pullZoneID)
-	fmt.Printf("Found pull zone '%s' with ID: %s\n", CLI.Check.Zone, zoneID)
+	if err := redactZone(ctx, redactor, CLI.Check.Key, zoneID); err != nil {
+		return exitFailure, err
+	}
+	fmt.Fprintf(output, "Found pull zone '%s' with ID: %s\n", CLI.Check.Zone, zoneID)
 
 	// Get pull zone details (needed for DNS and SSL checks)
 	pullZoneDetails, err := getPullZoneDetails(ctx, CLI.Check.Key, zoneID)
 	if err != nil {
-		log.Fatalf("Error getting pull zone details: %v", err)
+		return exitFailure, fmt.Errorf("error getting pull zone details: %v", err)
 	}
 
 	hasErrors := false
+	var coverage []CheckCoverage
 
-	// 1. Rules Check
-	fmt.Printf("\nRULES CHECK\n")
-	fmt.Println(strings.Repeat("-", 40))
+	// 1. Hostnames: DNS and SSL results grouped per hostname
+	fmt.Fprintf(output, "\nHOSTNAMES\n")
+	fmt.Fprintln(output, strings.Repeat("-", 40))
 
-	rulesResult, err := checkRulesStructured(ctx, CLI.Check.Key, zoneID, CLI.Check.SkipHealth)
-	if err != nil {
-		fmt.Printf("ERROR: Failed to check rules: %v\n", err)
-		hasErrors = true
+	dnsResult := checkDNSRecordsStructured(ctx, CLI.Check.Key, pullZoneDetails.Hostnames)
+	sslResult := checkSSLConfiguration(ctx, pullZoneDetails.Hostnames)
+	coverage = append(coverage, dnsResult.Coverage...)
+	coverage = append(coverage, sslResult.Coverage...)
+
+	if len(pullZoneDetails.Hostnames) == 0 {
+		fmt.Fprintln(output, "No hostnames found for this pull zone.")
 	} else {
-		// Display rules results using existing display function
-		allIssues := append(rulesResult.Issues, rulesResult.Successful...)
-		displayCheckResults(allIssues)
-
-		// Check for errors in rules
-		for _, issue := range rulesResult.Issues {
-			if issue.Severity == "error" || issue.Severity == "critical" {
-				hasErrors = true
-				break
-			}
-		}
+		renderHostnameReports(output, groupByHostname(pullZoneDetails.Hostnames, dnsResult, sslResult))
+		hasErrors = hasIssueAtLeast(dnsResult.Issues, CLI.Check.FailOn) || hasIssueAtLeast(sslResult.Issues, CLI.Check.FailOn)
 	}
 
-	// 2. DNS Check
-	fmt.Printf("\nDNS CHECK\n")
-	fmt.Println(strings.Repeat("-", 40))
-
-	if len(pullZoneDetails.Hostnames) == 0 {
-		fmt.Println("No hostnames found for this pull zone.")
+	// 2. Rules Check
+	fmt.Fprintf(output, "\nRULES CHECK\n")
+	fmt.Fprintln(output, strings.Repeat("-", 40))
+
+	rulesOpts := RulesCheckOptions{
+		SkipHealth:        CLI.Check.SkipHealth,
+		ParkingPatterns:   CLI.Check.ParkingPattern,
+		HealthSample:      sample,
+		HealthRetries:     CLI.Check.HealthRetries,
+		HostConcurrency:   CLI.Check.HostConcurrency,
+		HostInterval:      CLI.Check.HostInterval,
+		SlowThreshold:     CLI.Check.SlowThreshold,
+		Soft404:           soft404DetectorFlag(CLI.Check.DetectSoft404, CLI.Check.Soft404Phrase, CLI.Check.Soft404MinBytes),
+		ChainLimits:       chainLimits,
+		ExternalAllowlist: allowlist,
+		CanonicalHost:     CLI.Check.CanonicalHost,
+		HealthCache:       healthCacheFlag(CLI.Check.HealthCache, CLI.Check.HealthCacheTTL, CLI.Check.NoCache),
+		OnProgress:        healthProgressFlag(CLI.Check.Quiet),
+		SampleSeed:        time.Now().UTC().Format("2006-01-02"),
+		FailFast:          CLI.Check.FailFast,
+		FailOn:            CLI.Check.FailOn,
+		Selection:         selection,
+		VerifySources:     CLI.Check.VerifySources,
+		OriginHost:        CLI.Check.OriginHost,
+	}
+	if CLI.Check.FailFast && hasErrors && !rulesOpts.SkipHealth {
+		fmt.Fprintln(output, "Fail fast: skipping destination health checks after hostname errors")
+		rulesOpts.SkipHealth = true
+		rulesOpts.SkipReason = "fail fast after hostname errors"
+	}
+	var slowest []DestinationTiming
+	rulesResult, rulesErr := checkRulesStructured(ctx, CLI.Check.Key, zoneID, rulesOpts)
+	saveHealthCache(rulesOpts.HealthCache)
+	if rulesErr != nil {
+		fmt.Fprintf(output, "ERROR: Failed to check rules: %v\n", rulesErr)
+		hasErrors = true
+		coverage = append(coverage, CheckCoverage{Check: "rules", Status: "skipped", Reason: rulesErr.Error()})
 	} else {
-		dnsResult := checkDNSRecordsStructured(ctx, CLI.Check.Key, pullZoneDetails.Hostnames)
+		coverage = append(coverage, rulesResult.Coverage...)
+		slowest = rulesResult.Slowest
 
-		// Display DNS results
-		for _, success := range dnsResult.Successful {
-			fmt.Println(success.Message)
-		}
-		for _, issue := range dnsResult.Issues {
-			fmt.Println(issue.Message)
-			if issue.Severity == "error" {
-				hasErrors = true
-			}
-		}
+		displayCheckResults(rulesResult, CLI.Check.Verbose)
 
-		// Show summary if no issues
-		if len(dnsResult.Issues) == 0 {
-			fmt.Printf("No DNS issues found! All hostname records are properly configured.\n")
+		if hasIssueAtLeast(rulesResult.Issues, CLI.Check.FailOn) {
+			hasErrors = true
 		}
 	}
 
-	// 3. SSL Check
-	fmt.Printf("\nSSL CHECK\n")
-	fmt.Println(strings.Repeat("-", 40))
-
-	if len(pullZoneDetails.Hostnames) == 0 {
-		fmt.Println("No hostnames found for this pull zone.")
-	} else {
-		sslResult := checkSSLConfiguration(ctx, pullZoneDetails.Hostnames)
-
-		// Display SSL results
-		for _, success := range sslResult.Successful {
-			fmt.Println(success.Message)
+	if CLI.Check.ReportHTML != "" {
+		rulesSection := htmlReportSection("Rules", rulesResult)
+		if rulesErr != nil {
+			rulesSection.Error = fmt.Sprintf("Failed to check rules: %v", rulesErr)
 		}
-		for _, issue := range sslResult.Issues {
-			fmt.Println(issue.Message)
-			if issue.Severity == "error" {
-				hasErrors = true
-			}
+		report := buildHTMLReport(CLI.Check.Zone, time.Now(), !hasErrors, rulesSection, htmlReportSection("DNS", dnsResult), htmlReportSection("SSL", sslResult))
+		if err := writeHTMLReport(CLI.Check.ReportHTML, report, redactor); err != nil {
+			return exitFailure, fmt.Errorf("error writing HTML report: %v", err)
 		}
+		completion.setReport(CLI.Check.ReportHTML)
+		fmt.Fprintf(output, "HTML report written to %s\n", CLI.Check.ReportHTML)
+	}
 
-		// Show summary if no issues
-		if len(sslResult.Issues) == 0 {
-			fmt.Printf("No SSL issues found! All hostnames have SSL properly configured.\n")
+	summary := buildCheckSummary(!hasErrors, time.Since(start),
+		summarizeSection("Rules", rulesResult, rulesErr), summarizeSection("DNS", dnsResult, nil), summarizeSection("SSL", sslResult, nil))
+	merged := mergeCheckResults(dnsResult, sslResult, rulesResult)
+	merged.Coverage = coverage
+	switch CLI.Check.Format {
+	case "json":
+		report := buildCheckReport(CLI.Check.Zone, merged, CLI.Check.FailOn)
+		report.Passed = !hasErrors
+		report.Summary = &summary
+		if err := writeJSONReport(reportOut, report); err != nil {
+			return exitFailure, err
+		}
+	case "github":
+		if err := writeGitHubAnnotations(reportOut, merged); err != nil {
+			return exitFailure, err
 		}
 	}
 
 	// Summary
-	fmt.Printf("\n%s\n", strings.Repeat("=", 60))
+	fmt.Fprintf(output, "\n%s\n", strings.Repeat("=", 60))
+	displayCoverage(coverage)
+	displaySlowestDestinations(slowest)
+	displayCheckSummary(summary)
 	if hasErrors {
-		fmt.Printf("OVERALL RESULT: Issues found that require attention\n")
-		os.Exit(1)
+		fmt.Fprintf(output, "OVERALL RESULT: Issues found that require attention\n")
+		completion.finish(completionFailed, "issues found that require attention")
 	} else {
-		fmt.Printf("OVERALL RESULT: All checks passed successfully\n")
+		fmt.Fprintf(output, "OVERALL RESULT: All checks passed successfully\n")
+		completion.finish(completionSuccess, "all checks passed")
+	}
+	if rulesErr != nil {
+		return exitFailure, nil
 	}
+	return checkExitCode(merged.Issues, CLI.Check.FailOn), nil
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+)
+
+// Side effect free functions
+
+// contradictoryPatterns returns two URL patterns that no single request path matches, found is false when every pair can match together.
+// Patterns are compared like patternsOverlap does: by path, ignoring case and trailing slashes.
+func contradictoryPatterns(patterns []string) (string, string, bool) {
+	for i := range patterns {
+		for j := i + 1; j < len(patterns); j++ {
+			if !patternsOverlap(patterns[i], patterns[j]) {
+				return patterns[i], patterns[j], true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// unreachableMatchAll explains why an enabled rule's MatchAll triggers can never all apply to one request path, or returns "".
+// It looks at URL triggers only: patterns of a MatchAll trigger must all match, and with a MatchAll rule every MatchAny URL trigger needs one matching pattern.
+func unreachableMatchAll(rule EdgeRuleResponse) string {
+	var urlTriggers [][]string
+	for _, trigger := range rule.Triggers {
+		if trigger.Type != 0 { // Url trigger
+			continue
+		}
+		switch trigger.PatternMatchingType {
+		case 1: // MatchAll
+			if a, b, found := contradictoryPatterns(trigger.PatternMatches); found {
+				return fmt.Sprintf("its URL trigger requires the path to match both %s and %s", a, b)
+			}
+		case 0: // MatchAny
+			if len(trigger.PatternMatches) > 0 {
+				urlTriggers = append(urlTriggers, trigger.PatternMatches)
+			}
+		}
+	}
+
+	if rule.TriggerMatchingType != 1 { // MatchAll
+		return ""
+	}
+	for i := range urlTriggers {
+		for j := i + 1; j < len(urlTriggers); j++ {
+			if _, _, found := overlappingPatterns(urlTriggers[i], urlTriggers[j]); !found {
+				return fmt.Sprintf("all its triggers must match, but no path matches one of %v and one of %v", urlTriggers[i], urlTriggers[j])
+			}
+		}
+	}
+	return ""
+}
+
+// checkMatchAllContradictions reports enabled rules whose MatchAll URL patterns contradict each other, such a rule silently never fires
+func checkMatchAllContradictions(rules []EdgeRuleResponse) []CheckIssue {
+	var issues []CheckIssue
+	for i, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		if reason := unreachableMatchAll(rule); reason != "" {
+			issues = append(issues, CheckIssue{
+				Type:     IssueConfiguration,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Rule can never fire: %s", reason),
+				Rule:     &rules[i],
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContradictoryPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     bool
+	}{
+		{name: "single pattern", patterns: []string{"/a/*"}},
+		{name: "disjoint prefixes", patterns: []string{"/a/*", "/b/*"}, want: true},
+		{name: "different exact paths", patterns: []string{"/pricing", "/plans"}, want: true},
+		{name: "same exact path", patterns: []string{"/pricing", "/pricing"}},
+		{name: "exact path inside wildcard", patterns: []string{"/docs/*", "/docs/changelog"}},
+		{name: "exact path outside wildcard", patterns: []string{"/docs/*", "/blog/post"}, want: true},
+		{name: "prefix and suffix wildcards", patterns: []string{"/shop/*", "*.html"}},
+		{name: "nested prefixes", patterns: []string{"/shop/*", "/shop/sale/*"}},
+		{name: "case and trailing slash", patterns: []string{"/Pricing/", "/pricing"}},
+		{name: "third pattern contradicts", patterns: []string{"/a/*", "*.html", "/b/x.html"}, want: true},
+		{name: "host scoped patterns compare by path", patterns: []string{"*://www.example.com/a/*", "/a/page"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b, found := contradictoryPatterns(tt.patterns)
+			if found != tt.want {
+				t.Errorf("contradictoryPatterns(%v) = %s, %s, %v; want %v", tt.patterns, a, b, found, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnreachableMatchAll(t *testing.T) {
+	urlTrigger := func(matching int, patterns ...string) Trigger {
+		return Trigger{Type: 0, PatternMatches: patterns, PatternMatchingType: matching}
+	}
+	slashPolicy, err := buildSlashPolicyRule("", "add", "301")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		ruleMatching int
+		triggers     []Trigger
+		want         string
+	}{
+		{name: "MatchAny patterns", triggers: []Trigger{urlTrigger(0, "/a/*", "/b/*")}},
+		{name: "MatchAll patterns disjoint", triggers: []Trigger{urlTrigger(1, "/a/*", "/b/*")}, want: "both /a/* and /b/*"},
+		{name: "MatchAll patterns compatible", triggers: []Trigger{urlTrigger(1, "/a/*", "*.pdf")}},
+		{name: "MatchAny rule with disjoint triggers", triggers: []Trigger{urlTrigger(0, "/a/*"), urlTrigger(0, "/b/*")}},
+		{name: "MatchAll rule with disjoint triggers", ruleMatching: 1, triggers: []Trigger{urlTrigger(0, "/a/*"), urlTrigger(0, "/b/*", "/c/*")}, want: "all its triggers must match"},
+		{name: "MatchAll rule with one compatible alternative", ruleMatching: 1, triggers: []Trigger{urlTrigger(0, "/a/*"), urlTrigger(0, "/b/*", "/a/x")}},
+		{name: "MatchNone triggers are ignored", ruleMatching: 1, triggers: []Trigger{urlTrigger(0, "/a/*"), urlTrigger(2, "/b/*")}},
+		{name: "other trigger types are ignored", ruleMatching: 1, triggers: []Trigger{urlTrigger(0, "/a/*"), {Type: 3, PatternMatches: []string{"pdf"}}}},
+		{name: "slash policy rule", ruleMatching: 1, triggers: slashPolicy.Triggers},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := EdgeRuleResponse{ActionType: 1, Enabled: true, TriggerMatchingType: tt.ruleMatching, Triggers: tt.triggers}
+			got := unreachableMatchAll(rule)
+			if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+				t.Errorf("unreachableMatchAll() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMatchAllContradictions(t *testing.T) {
+	contradiction := EdgeRuleResponse{Guid: "never", ActionType: 4, Enabled: true, Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/a/*", "/b/*"}, PatternMatchingType: 1}}}
+	disabled := contradiction
+	disabled.Guid = "disabled"
+	disabled.Enabled = false
+
+	issues := checkMatchAllContradictions([]EdgeRuleResponse{redirectRule("ok", "/x", "/y"), contradiction, disabled})
+	if len(issues) != 1 || issues[0].Rule.Guid != "never" || issues[0].Severity != "error" || issues[0].Type != "configuration" {
+		t.Errorf("checkMatchAllContradictions() = %+v, want one configuration error for never", issues)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Side effect free functions
+
+// hostPlaceholder returns the placeholder of a redirect destination that decides the host the visitor is sent to, or "" when the host is fixed.
+// Placeholders take three forms, all matched by placeholderPattern: $1 for a wildcard capture, {{name}} and Bunny variables like %{Url.Path}.
+// A placeholder decides the host when it sits in the authority of an absolute or scheme-relative URL (https://%{Url.Path},
+// https://example.com$1, //$1), or when it starts a relative destination, where the request can supply "https://evil.com".
+// Hostname variables such as %{Request.Hostname} only hold the zone's own hostnames and are never reported;
+// path variables such as %{Url.Path} always start with "/", so they only decide the host when nothing precedes them in the authority.
+func hostPlaceholder(destination string) string {
+	authority, absolute := destinationAuthority(destination)
+	if !absolute {
+		loc := placeholderPattern.FindStringIndex(destination)
+		if loc == nil || loc[0] != 0 {
+			return ""
+		}
+		placeholder := destination[:loc[1]]
+		if isHostnameVariable(placeholder) || isPathVariable(placeholder) {
+			return ""
+		}
+		return placeholder
+	}
+
+	for _, loc := range placeholderPattern.FindAllStringIndex(authority, -1) {
+		placeholder := authority[loc[0]:loc[1]]
+		if isHostnameVariable(placeholder) || (isPathVariable(placeholder) && loc[0] > 0) {
+			continue
+		}
+		return placeholder
+	}
+	return ""
+}
+
+// isHostnameVariable reports whether a placeholder is a Bunny variable holding the requested hostname, like %{Request.Hostname}
+func isHostnameVariable(placeholder string) bool {
+	lower := strings.ToLower(placeholder)
+	return strings.HasPrefix(lower, "%{") && strings.HasSuffix(lower, ".hostname}")
+}
+
+// isPathVariable reports whether a placeholder is a Bunny variable that always starts with "/", like %{Url.Path}
+func isPathVariable(placeholder string) bool {
+	lower := strings.ToLower(placeholder)
+	return lower == "%{url.path}" || lower == "%{url.directory}"
+}
+
+// destinationAuthority returns the host part of an absolute or scheme-relative destination, absolute is false for relative destinations.
+// The authority ends at the first '/', '?' or '#' after the scheme.
+func destinationAuthority(destination string) (string, bool) {
+	rest, found := strings.CutPrefix(destination, "//")
+	if !found {
+		var scheme string
+		scheme, rest, found = strings.Cut(destination, "://")
+		if !found || scheme == "" || strings.ContainsAny(scheme, "/?#") {
+			return "", false
+		}
+	}
+	if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest, true
+}
+
+// checkPlaceholderOpenRedirects reports enabled redirects with a wildcard source whose destination host comes from the request.
+// Anyone can then craft a link on the zone's domain that sends visitors to a site of their choice.
+func checkPlaceholderOpenRedirects(rules []EdgeRuleResponse) []CheckIssue {
+	var issues []CheckIssue
+	for i, rule := range rules {
+		if rule.ActionType != 1 || !rule.Enabled || rule.ActionParameter1 == "" {
+			continue
+		}
+		placeholder := hostPlaceholder(rule.ActionParameter1)
+		if placeholder == "" {
+			continue
+		}
+		for _, pattern := range urlTriggerPatterns(rule) {
+			if !hasPathWildcard(pattern) {
+				continue
+			}
+			issues = append(issues, CheckIssue{
+				Type:     IssueSecurity,
+				Severity: SeverityCritical,
+				Message:  fmt.Sprintf("Open redirect: %s lets the request path of %s choose the destination host", placeholder, pattern),
+				Rule:     &rules[i],
+				Details:  map[string]interface{}{"placeholder": placeholder, "source": pattern},
+			})
+			break
+		}
+	}
+	return issues
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestHostPlaceholder(t *testing.T) {
+	tests := []struct {
+		destination string
+		want        string
+	}{
+		{destination: "https://%{Url.Path}", want: "%{Url.Path}"},
+		{destination: "https://$1", want: "$1"},
+		{destination: "https://{{path}}/landing", want: "{{path}}"},
+		{destination: "https://example.com$1", want: "$1"},
+		{destination: "//%{Url.Query}", want: "%{Url.Query}"},
+		{destination: "$1", want: "$1"},
+		{destination: "%{Url.Query}/x", want: "%{Url.Query}"},
+		{destination: "https://example.com/$1", want: ""},
+		{destination: "https://example.com%{Url.Path}", want: ""},
+		{destination: "https://%{Request.Hostname}/new$1", want: ""},
+		{destination: "https://example.com/search?q=%{Url.Path}", want: ""},
+		{destination: "/blog/$1", want: ""},
+		{destination: "%{Url.Path}?x=1", want: ""},
+		{destination: "https://example.com/new", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := hostPlaceholder(tt.destination); got != tt.want {
+			t.Errorf("hostPlaceholder(%q) = %q, want %q", tt.destination, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPlaceholderOpenRedirects(t *testing.T) {
+	disabled := redirectRule("r4", "/go/*", "https://$1")
+	disabled.Enabled = false
+
+	tests := []struct {
+		name string
+		rule EdgeRuleResponse
+		want bool
+	}{
+		{name: "wildcard source", rule: redirectRule("r1", "/out/*", "https://%{Url.Path}"), want: true},
+		{name: "exact source", rule: redirectRule("r2", "/out", "https://%{Url.Path}"), want: false},
+		{name: "fixed host", rule: redirectRule("r3", "/out/*", "https://example.com/$1"), want: false},
+		{name: "disabled", rule: disabled, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := checkPlaceholderOpenRedirects([]EdgeRuleResponse{tt.rule})
+			if got := len(issues) == 1; got != tt.want {
+				t.Fatalf("reported %d issues, want open redirect %v", len(issues), tt.want)
+			}
+			if tt.want && (issues[0].Severity != SeverityCritical || issues[0].Type != IssueSecurity) {
+				t.Errorf("issue = %s %s, want critical security", issues[0].Severity, issues[0].Type)
+			}
+		})
+	}
+}
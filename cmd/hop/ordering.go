@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// applyRuleOrder saves the new position of every rule whose OrderIndex changed, reporting each saved rule
+func applyRuleOrder(ctx context.Context, apiKey, zoneID string, rules []EdgeRuleResponse, report func(EdgeRuleResponse, int)) error {
+	for _, update := range planRuleOrder(rules) {
+		if err := addEdgeRule(ctx, apiKey, zoneID, update.rule); err != nil {
+			return fmt.Errorf("error moving rule %s: %v", update.rule.Guid, err)
+		}
+		report(update.previous, *update.rule.OrderIndex)
+	}
+	return nil
+}
+
+// Side effect free functions
+
+// sortRulesByOrder stably sorts rules into Bunny's evaluation order
+func sortRulesByOrder(rules []EdgeRuleResponse) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].OrderIndex < rules[j].OrderIndex
+	})
+}
+
+// moveRuleBefore returns the rules in evaluation order with the rule guid moved directly in front of the rule before
+func moveRuleBefore(rules []EdgeRuleResponse, guid, before string) ([]EdgeRuleResponse, error) {
+	if guid == before {
+		return nil, fmt.Errorf("cannot move rule %s before itself", guid)
+	}
+
+	var moved *EdgeRuleResponse
+	rest := make([]EdgeRuleResponse, 0, len(rules))
+	for i := range rules {
+		if rules[i].Guid == guid {
+			moved = &rules[i]
+			continue
+		}
+		rest = append(rest, rules[i])
+	}
+	if moved == nil {
+		return nil, fmt.Errorf("rule %s not found", guid)
+	}
+
+	for i := range rest {
+		if rest[i].Guid == before {
+			reordered := append([]EdgeRuleResponse{}, rest[:i]...)
+			reordered = append(reordered, *moved)
+			return append(reordered, rest[i:]...), nil
+		}
+	}
+	return nil, fmt.Errorf("rule %s not found", before)
+}
+
+// ruleOrderUpdate is a rule to save with a new OrderIndex
+type ruleOrderUpdate struct {
+	previous EdgeRuleResponse
+	rule     EdgeRule
+}
+
+// planRuleOrder numbers rules by their position and returns the rules whose OrderIndex has to change
+func planRuleOrder(rules []EdgeRuleResponse) []ruleOrderUpdate {
+	var updates []ruleOrderUpdate
+	for i, rule := range rules {
+		if rule.OrderIndex == i {
+			continue
+		}
+		index := i
+		update := restoredRule(rule)
+		update.OrderIndex = &index
+		updates = append(updates, ruleOrderUpdate{previous: rule, rule: update})
+	}
+	return updates
+}
+
+// checkRuleOrdering flags enabled redirects that never fire because an earlier wildcard redirect already matches their source.
+// A different destination is an error, the specific redirect silently sends visitors elsewhere. Rules must be in evaluation order.
+func checkRuleOrdering(rules []EdgeRuleResponse, zoneName string) []CheckIssue {
+	if zoneName == "" {
+		zoneName = "PULL_ZONE_NAME"
+	}
+
+	var issues []CheckIssue
+	for i := range rules {
+		rule := &rules[i]
+		if rule.ActionType != 1 || !rule.Enabled {
+			continue
+		}
+		for _, pattern := range urlTriggerPatterns(*rule) {
+			if strings.Contains(pattern, "*") {
+				continue
+			}
+			shadow, wildcard := shadowingWildcard(rules[:i], pattern)
+			if shadow == nil {
+				continue
+			}
+			severity, consequence := "error", "redirects to "+shadow.ActionParameter1+" instead"
+			if normalizeURL(shadow.ActionParameter1) == normalizeURL(rule.ActionParameter1) {
+				severity, consequence = "info", "has the same destination, the specific rule is redundant"
+			}
+			issues = append(issues, CheckIssue{
+				Type:     IssueOrdering,
+				Severity: severity,
+				Message:  fmt.Sprintf("%s never fires: the earlier wildcard %s (%s) matches it first and %s", pattern, wildcard, shadow.Guid, consequence),
+				Rule:     rule,
+				Details: map[string]interface{}{
+					"shadowed_guid":    rule.Guid,
+					"wildcard_guid":    shadow.Guid,
+					"wildcard_pattern": wildcard,
+					"suggestion":       formatMoveCommand(zoneName, rule.Guid, shadow.Guid),
+				},
+			})
+			break
+		}
+	}
+	return issues
+}
+
+// shadowingWildcard returns the first enabled wildcard redirect and pattern among earlier rules that matches path on every host path applies to.
+// A wildcard scoped to one host never shadows a pattern for other or all hosts.
+func shadowingWildcard(earlier []EdgeRuleResponse, path string) (*EdgeRuleResponse, string) {
+	host := triggerPatternHost(path)
+	for i := range earlier {
+		rule := &earlier[i]
+		if rule.ActionType != 1 || !rule.Enabled {
+			continue
+		}
+		for _, pattern := range urlTriggerPatterns(*rule) {
+			if strings.Contains(pattern, "*") && hostScopeCovers(triggerPatternHost(pattern), host) && matchesTriggerPattern(pattern, triggerPatternPath(path)) {
+				return rule, pattern
+			}
+		}
+	}
+	return nil, ""
+}
+
+// hostScopeCovers reports whether a wildcard trigger for wildcardHost matches requests to every host a trigger for host matches, "" is any host
+func hostScopeCovers(wildcardHost, host string) bool {
+	if wildcardHost == "" || wildcardHost == "*" {
+		return true
+	}
+	return host != "" && wildcardMatch(wildcardHost, host)
+}
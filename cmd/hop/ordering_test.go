@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func orderedRules(guids ...string) []EdgeRuleResponse {
+	var rules []EdgeRuleResponse
+	for i, guid := range guids {
+		rule := redirectRule(guid, "/"+guid, "https://example.com/"+guid)
+		rule.OrderIndex = i
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func ruleGuids(rules []EdgeRuleResponse) string {
+	var guids []string
+	for _, rule := range rules {
+		guids = append(guids, rule.Guid)
+	}
+	return strings.Join(guids, ",")
+}
+
+func TestMoveRuleBefore(t *testing.T) {
+	tests := []struct {
+		name    string
+		guid    string
+		before  string
+		want    string
+		wantErr string
+	}{
+		{name: "move up", guid: "c", before: "a", want: "c,a,b,d"},
+		{name: "move down", guid: "a", before: "d", want: "b,c,a,d"},
+		{name: "already in place", guid: "b", before: "c", want: "a,b,c,d"},
+		{name: "unknown rule", guid: "x", before: "a", wantErr: "rule x not found"},
+		{name: "unknown target", guid: "a", before: "x", wantErr: "rule x not found"},
+		{name: "itself", guid: "a", before: "a", wantErr: "before itself"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := moveRuleBefore(orderedRules("a", "b", "c", "d"), tt.guid, tt.before)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("moveRuleBefore() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("moveRuleBefore() error = %v", err)
+			}
+			if ruleGuids(got) != tt.want {
+				t.Errorf("moveRuleBefore() = %s, want %s", ruleGuids(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanRuleOrder(t *testing.T) {
+	reordered, err := moveRuleBefore(orderedRules("a", "b", "c", "d"), "c", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates := planRuleOrder(reordered)
+	var got []string
+	for _, update := range updates {
+		got = append(got, update.rule.Guid+"="+string(rune('0'+*update.rule.OrderIndex)))
+	}
+	if strings.Join(got, ",") != "c=1,b=2" {
+		t.Errorf("planRuleOrder() = %v, want only c=1 and b=2 renumbered", got)
+	}
+
+	if updates := planRuleOrder(orderedRules("a", "b")); len(updates) != 0 {
+		t.Errorf("planRuleOrder() of numbered rules = %d updates, want 0", len(updates))
+	}
+}
+
+func TestSortRulesByOrder(t *testing.T) {
+	rules := orderedRules("a", "b", "c")
+	rules[0].OrderIndex = 5
+	sortRulesByOrder(rules)
+	if ruleGuids(rules) != "b,c,a" {
+		t.Errorf("sortRulesByOrder() = %s, want b,c,a", ruleGuids(rules))
+	}
+}
+
+func TestCheckRuleOrdering(t *testing.T) {
+	disabledWildcard := redirectRule("off", "/old/*", "https://example.com/off")
+	disabledWildcard.Enabled = false
+
+	tests := []struct {
+		name         string
+		rules        []EdgeRuleResponse
+		wantSeverity string
+	}{
+		{
+			name: "specific after wildcard",
+			rules: []EdgeRuleResponse{
+				redirectRule("w", "/docs/*", "https://docs.example.com/"),
+				redirectRule("s", "/docs/changelog", "https://example.com/changelog"),
+			},
+			wantSeverity: "error",
+		},
+		{
+			name: "same destination is redundant",
+			rules: []EdgeRuleResponse{
+				redirectRule("w", "/docs/*", "https://docs.example.com/"),
+				redirectRule("s", "/docs/changelog", "https://docs.example.com"),
+			},
+			wantSeverity: "info",
+		},
+		{
+			name: "specific before wildcard",
+			rules: []EdgeRuleResponse{
+				redirectRule("s", "/docs/changelog", "https://example.com/changelog"),
+				redirectRule("w", "/docs/*", "https://docs.example.com/"),
+			},
+		},
+		{
+			name: "disabled wildcard does not shadow",
+			rules: []EdgeRuleResponse{
+				disabledWildcard,
+				redirectRule("s", "/old/page", "https://example.com/page"),
+			},
+		},
+		{
+			name: "host scoped wildcard does not shadow other hosts",
+			rules: []EdgeRuleResponse{
+				redirectRule("w", "*://www.example.com/shop/*", "https://shop.example.com/"),
+				redirectRule("s", "/shop/sale", "https://example.com/sale"),
+			},
+		},
+		{
+			name: "host scoped wildcard for another host",
+			rules: []EdgeRuleResponse{
+				redirectRule("w", "*://www.example.com/shop/*", "https://shop.example.com/"),
+				redirectRule("s", "https://blog.example.com/shop/sale", "https://example.com/sale"),
+			},
+		},
+		{
+			name: "host scoped wildcard on the same host",
+			rules: []EdgeRuleResponse{
+				redirectRule("w", "*://www.example.com/shop/*", "https://shop.example.com/"),
+				redirectRule("s", "https://WWW.example.com/shop/sale", "https://example.com/sale"),
+			},
+			wantSeverity: "error",
+		},
+		{
+			name: "any host wildcard",
+			rules: []EdgeRuleResponse{
+				redirectRule("w", "*://*/shop/*", "https://shop.example.com/"),
+				redirectRule("s", "https://www.example.com/shop/sale", "https://example.com/sale"),
+			},
+			wantSeverity: "error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := checkRuleOrdering(tt.rules, "site")
+			if tt.wantSeverity == "" {
+				if len(issues) != 0 {
+					t.Errorf("checkRuleOrdering() = %+v, want no issues", issues)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("checkRuleOrdering() = %d issues, want 1", len(issues))
+			}
+			issue := issues[0]
+			if issue.Severity != tt.wantSeverity || issue.Rule.Guid != "s" {
+				t.Errorf("issue = %s for %s, want %s for s", issue.Severity, issue.Rule.Guid, tt.wantSeverity)
+			}
+			if want := "hop rules move --key YOUR_API_KEY --zone 'site' --guid 's' --before 'w'"; issue.Details["suggestion"] != want {
+				t.Errorf("suggestion = %v, want %s", issue.Details["suggestion"], want)
+			}
+			if issue.Details["shadowed_guid"] != "s" || issue.Details["wildcard_guid"] != "w" || issue.Details["wildcard_pattern"] != extractSourceURL(tt.rules[0]) {
+				t.Errorf("details = %v, want both GUIDs and the wildcard pattern", issue.Details)
+			}
+		})
+	}
+}
+
+func TestApplyRuleOrderAgainstFake(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+
+	ctx := context.Background()
+	for i, rule := range orderedRules("a", "b", "c") {
+		index := i
+		create := restoredRule(rule)
+		create.Guid = ""
+		create.OrderIndex = &index
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", create); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules, err := listEdgeRules(ctx, fakeAPIKey, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reordered, err := moveRuleBefore(rules, rules[2].Guid, rules[0].Guid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reported := 0
+	if err := applyRuleOrder(ctx, fakeAPIKey, "1", reordered, func(EdgeRuleResponse, int) { reported++ }); err != nil {
+		t.Fatalf("applyRuleOrder() error = %v", err)
+	}
+	if reported != 3 {
+		t.Errorf("reported %d moved rules, want 3", reported)
+	}
+
+	rules, err = listEdgeRules(ctx, fakeAPIKey, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sources []string
+	for _, rule := range rules {
+		sources = append(sources, extractSourceURL(rule))
+	}
+	if got := strings.Join(sources, ","); got != "/c,/a,/b" {
+		t.Errorf("order after move = %s, want /c,/a,/b", got)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// checkOriginHealth reports origin overrides whose origin does not answer; any HTTP response counts as reachable
+func checkOriginHealth(ctx context.Context, rules []EdgeRuleResponse) []CheckIssue {
+	var issues []CheckIssue
+	for i, rule := range rules {
+		if rule.ActionType != 2 || !rule.Enabled || validateOriginURL(rule.ActionParameter1) != nil {
+			continue
+		}
+		statusCode, _, _, err := performHealthCheck(ctx, rule.ActionParameter1)
+		if err != nil {
+			issues = append(issues, CheckIssue{
+				Type:     IssueOrigin,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Origin %s is unreachable: %v", rule.ActionParameter1, err),
+				Rule:     &rules[i],
+				Details:  map[string]interface{}{"origin": rule.ActionParameter1},
+			})
+			continue
+		}
+		issues = append(issues, CheckIssue{
+			Type:     IssueOrigin,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("Origin %s is reachable (status %d)", rule.ActionParameter1, statusCode),
+			Rule:     &rules[i],
+			Details:  map[string]interface{}{"origin": rule.ActionParameter1, "status_code": statusCode},
+		})
+	}
+	return issues
+}
+
+// Side effect free functions
+
+// originDescription is the generated description of an origin override
+func originDescription(from, origin string) string {
+	return fmt.Sprintf("Origin override from %s to %s", from, origin)
+}
+
+// buildOriginRule builds an edge rule that serves requests matching from out of another origin
+func buildOriginRule(guid, from, origin, desc string) EdgeRule {
+	if desc == "" {
+		desc = originDescription(from, origin)
+	}
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          2,      // OriginUrl
+		ActionParameter1:    origin, // Origin URL
+		TriggerMatchingType: 0,      // MatchAny
+		Description:         desc,
+		Enabled:             true,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      []string{from},
+				PatternMatchingType: 0, // MatchAny
+			},
+		},
+	}
+}
+
+// findOriginRule returns the origin override for a source pattern, or nil if there is none
+func findOriginRule(rules []EdgeRuleResponse, from string) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if rule.ActionType == 2 && extractSourceURL(rule) == from {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// validateOriginURL checks that an origin is an absolute http or https URL with a host
+func validateOriginURL(origin string) error {
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return fmt.Errorf("origin '%s' is not a valid URL: %v", origin, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("origin '%s' must start with http:// or https://", origin)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("origin '%s' has no host", origin)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("origin '%s' must not contain a query string or fragment", origin)
+	}
+	if strings.Contains(origin, " ") {
+		return fmt.Errorf("origin '%s' contains a space", origin)
+	}
+	return nil
+}
+
+// countOriginRules returns the number of origin overrides
+func countOriginRules(rules []EdgeRuleResponse) int {
+	count := 0
+	for _, rule := range rules {
+		if rule.ActionType == 2 {
+			count++
+		}
+	}
+	return count
+}
+
+// checkOriginURLs reports origin overrides whose origin URL is malformed.
+// A hop release rule from before release rules carried an absolute origin is a warning to activate the release again.
+func checkOriginURLs(rules []EdgeRuleResponse) []CheckIssue {
+	var issues []CheckIssue
+	for i, rule := range rules {
+		if rule.ActionType != 2 {
+			continue
+		}
+		err := validateOriginURL(rule.ActionParameter1)
+		if err != nil && strings.HasPrefix(rule.Description, releaseRulePrefix) && strings.HasPrefix(rule.ActionParameter1, "/") {
+			releaseID := strings.TrimPrefix(rule.Description, releaseRulePrefix)
+			issues = append(issues, CheckIssue{
+				Type:     IssueOrigin,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Release rule uses the path '%s' as origin, run 'hop cdn releases activate --release %s' again to point it at an absolute origin URL", rule.ActionParameter1, releaseID),
+				Rule:     &rules[i],
+				Details:  map[string]interface{}{"origin": rule.ActionParameter1, "release": releaseID},
+			})
+			continue
+		}
+		if err != nil {
+			issues = append(issues, CheckIssue{
+				Type:     IssueOrigin,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Malformed origin override: %v", err),
+				Rule:     &rules[i],
+				Details:  map[string]interface{}{"origin": rule.ActionParameter1},
+			})
+		}
+	}
+	return issues
+}
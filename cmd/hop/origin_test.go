@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func originRule(guid, from, origin string) EdgeRuleResponse {
+	return EdgeRuleResponse{
+		Guid:             guid,
+		ActionType:       2,
+		ActionParameter1: origin,
+		Enabled:          true,
+		Triggers:         []Trigger{{Type: 0, PatternMatches: []string{from}}},
+	}
+}
+
+func TestBuildOriginRule(t *testing.T) {
+	rule := buildOriginRule("", "/api/*", "https://api-backend.example.com", "")
+	if rule.ActionType != 2 || rule.ActionParameter1 != "https://api-backend.example.com" || rule.ActionParameter2 != "" {
+		t.Errorf("buildOriginRule() action = %d %s %s, want an OriginUrl action with the origin", rule.ActionType, rule.ActionParameter1, rule.ActionParameter2)
+	}
+	if len(rule.Triggers) != 1 || rule.Triggers[0].PatternMatches[0] != "/api/*" || rule.Triggers[0].Type != 0 {
+		t.Errorf("buildOriginRule() triggers = %+v, want one URL trigger for /api/*", rule.Triggers)
+	}
+	if rule.Description != "Origin override from /api/* to https://api-backend.example.com" {
+		t.Errorf("buildOriginRule() description = %s", rule.Description)
+	}
+	if custom := buildOriginRule("g", "/api/*", "https://a.example.com", "API"); custom.Description != "API" || custom.Guid != "g" {
+		t.Errorf("buildOriginRule() = %+v, want the given GUID and description", custom)
+	}
+}
+
+func TestValidateOriginURL(t *testing.T) {
+	tests := []struct {
+		origin  string
+		wantErr string
+	}{
+		{origin: "https://api-backend.example.com"},
+		{origin: "http://10.0.0.5:8080/base"},
+		{origin: "api-backend.example.com", wantErr: "http://"},
+		{origin: "ftp://files.example.com", wantErr: "http://"},
+		{origin: "https://", wantErr: "no host"},
+		{origin: "https://api.example.com/?x=1", wantErr: "query string"},
+		{origin: "https://api.example.com/a b", wantErr: "space"},
+	}
+	for _, tt := range tests {
+		err := validateOriginURL(tt.origin)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("validateOriginURL(%s) error = %v, want nil", tt.origin, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("validateOriginURL(%s) error = %v, want it to mention %q", tt.origin, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFindOriginRule(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		redirectRule("redirect", "/api/*", "https://example.com/"),
+		originRule("origin", "/api/*", "https://api.example.com"),
+	}
+	if got := findOriginRule(rules, "/api/*"); got == nil || got.Guid != "origin" {
+		t.Errorf("findOriginRule() = %+v, want the origin override, not the redirect", got)
+	}
+	if got := findOriginRule(rules, "/other/*"); got != nil {
+		t.Errorf("findOriginRule() = %+v, want nil", got)
+	}
+}
+
+func TestCheckOriginURLs(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		originRule("good", "/api/*", "https://api.example.com"),
+		originRule("bad", "/legacy/*", "legacy.example.com"),
+		redirectRule("redirect", "/old", "not-a-url"),
+	}
+	issues := checkOriginURLs(rules)
+	if len(issues) != 1 || issues[0].Rule.Guid != "bad" || issues[0].Severity != "error" {
+		t.Errorf("checkOriginURLs() = %+v, want one error for the malformed origin", issues)
+	}
+}
+
+func TestCheckOriginURLsReleaseRule(t *testing.T) {
+	origin, err := releaseOriginURL("https://origin.example.com", "r1")
+	if err != nil {
+		t.Fatalf("releaseOriginURL() error = %v", err)
+	}
+	toResponse := func(rule EdgeRule) EdgeRuleResponse {
+		return EdgeRuleResponse{Guid: rule.Guid, ActionType: rule.ActionType, ActionParameter1: rule.ActionParameter1,
+			Triggers: rule.Triggers, TriggerMatchingType: rule.TriggerMatchingType, Description: rule.Description, Enabled: rule.Enabled}
+	}
+	legacy := toResponse(buildReleaseRule("legacy", "r0", "/releases/r0"))
+
+	tests := []struct {
+		name         string
+		rule         EdgeRuleResponse
+		wantSeverity string
+	}{
+		{name: "release rule", rule: toResponse(buildReleaseRule("current", "r1", origin))},
+		{name: "path-only release rule", rule: legacy, wantSeverity: SeverityWarning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := checkOriginURLs([]EdgeRuleResponse{tt.rule})
+			if tt.wantSeverity == "" {
+				if len(issues) != 0 {
+					t.Errorf("checkOriginURLs() = %+v, want no issues", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Severity != tt.wantSeverity {
+				t.Errorf("checkOriginURLs() = %+v, want one %s", issues, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestCheckOriginHealth(t *testing.T) {
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	disabled := originRule("disabled", "/old/*", down.URL)
+	disabled.Enabled = false
+	rules := []EdgeRuleResponse{
+		originRule("up", "/api/*", up.URL),
+		originRule("down", "/legacy/*", down.URL),
+		disabled,
+	}
+
+	issues := checkOriginHealth(context.Background(), rules)
+	severities := map[string]string{}
+	for _, issue := range issues {
+		severities[issue.Rule.Guid] = issue.Severity
+	}
+	if severities["up"] != "info" || severities["down"] != "error" || severities["disabled"] != "" {
+		t.Errorf("checkOriginHealth() severities = %v, want up info (any response counts), down error, disabled unchecked", severities)
+	}
+}
+
+func TestRulesCheckSkipsOriginRulesInRedirectChecks(t *testing.T) {
+	origin := httptest.NewServer(http.NotFoundHandler())
+	defer origin.Close()
+
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+	if err := addEdgeRule(ctx, fakeAPIKey, "1", buildOriginRule("", "/api/*", origin.URL, "")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := checkRulesStructured(ctx, fakeAPIKey, "1", RulesCheckOptions{})
+	if err != nil {
+		t.Fatalf("checkRulesStructured() error = %v", err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("checkRulesStructured() issues = %+v, want none for a reachable origin override", result.Issues)
+	}
+	if !strings.Contains(formatCoverage(result.Coverage), "origin_health ran 1 origins") {
+		t.Errorf("coverage =\n%s\nwant origin_health to run", formatCoverage(result.Coverage))
+	}
+
+	skipped, err := checkRulesStructured(ctx, fakeAPIKey, "1", RulesCheckOptions{SkipHealth: true})
+	if err != nil {
+		t.Fatalf("checkRulesStructured() error = %v", err)
+	}
+	if !strings.Contains(formatCoverage(skipped.Coverage), "origin_health skipped --skip-health") {
+		t.Errorf("coverage =\n%s\nwant origin_health skipped", formatCoverage(skipped.Coverage))
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// parkRulePrefix marks the hop-managed catch-all redirect of a parked domain
+const parkRulePrefix = "hop-park: "
+
+// ParkOptions describes a parked domain and where its traffic goes
+type ParkOptions struct {
+	Domain       string
+	To           string
+	PreservePath bool
+	DryRun       bool
+}
+
+// ParkStep is the outcome of one step of parking a domain
+type ParkStep struct {
+	Name   string // hostname, rule, dns or ssl
+	Status string // done, exists, would, skipped or failed
+	Detail string
+}
+
+// Side effect free functions
+
+// parkDestination returns the redirect destination, keeping the request path when asked to
+func parkDestination(to string, preservePath bool) string {
+	if !preservePath {
+		return to
+	}
+	return strings.TrimSuffix(to, "/") + "%{Url.Path}"
+}
+
+// buildParkRule builds the catch-all redirect for every request to a parked domain
+func buildParkRule(guid, domain, to string, preservePath bool) EdgeRule {
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          1, // Redirect
+		ActionParameter1:    parkDestination(to, preservePath),
+		ActionParameter2:    "302",
+		TriggerMatchingType: 0, // MatchAny
+		Description:         parkRulePrefix + domain,
+		Enabled:             true,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      []string{"*://" + domain + "/*"},
+				PatternMatchingType: 0, // MatchAny
+			},
+		},
+	}
+}
+
+// findParkRule returns the hop-managed redirect of a parked domain, or nil if there is none
+func findParkRule(rules []EdgeRuleResponse, domain string) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if strings.EqualFold(rule.Description, parkRulePrefix+domain) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// parkRuleUpToDate reports whether an existing park rule already does what the wanted rule does
+func parkRuleUpToDate(existing EdgeRuleResponse, wanted EdgeRule) bool {
+	return existing.Enabled &&
+		existing.ActionType == wanted.ActionType &&
+		existing.ActionParameter1 == wanted.ActionParameter1 &&
+		existing.ActionParameter2 == wanted.ActionParameter2 &&
+		len(existing.Triggers) == 1 &&
+		strings.Join(existing.Triggers[0].PatternMatches, ",") == strings.Join(wanted.Triggers[0].PatternMatches, ",")
+}
+
+// hasHostname reports whether a pull zone serves a hostname, ignoring case
+func hasHostname(hostnames []Hostname, hostname string) bool {
+	for _, h := range hostnames {
+		if strings.EqualFold(h.Value, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// cdnHostname returns the zone's own b-cdn.net hostname that custom hostnames point their DNS at
+func cdnHostname(details *PullZoneDetails) string {
+	for _, h := range details.Hostnames {
+		if strings.HasSuffix(strings.ToLower(h.Value), ".b-cdn.net") {
+			return h.Value
+		}
+	}
+	return details.Name + ".b-cdn.net"
+}
+
+// findDNSZoneForHost returns the most specific Bunny DNS zone a hostname belongs to and the record name within it,
+// "" for the zone apex
+func findDNSZoneForHost(zones []DNSZone, hostname string) (*DNSZone, string) {
+	host := strings.ToLower(hostname)
+	var best *DNSZone
+	for i, zone := range zones {
+		domain := strings.ToLower(zone.Domain)
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		if best == nil || len(domain) > len(best.Domain) {
+			best = &zones[i]
+		}
+	}
+	if best == nil {
+		return nil, ""
+	}
+	return best, strings.TrimSuffix(strings.TrimSuffix(host, strings.ToLower(best.Domain)), ".")
+}
+
+// parkDNSRecord returns the record that points name in a DNS zone at the pull zone and describes it.
+// A CNAME cannot live at the zone apex next to SOA and NS, so the apex gets a pull zone record, which Bunny DNS flattens.
+func parkDNSRecord(details *PullZoneDetails, domain, name string) (DNSRecord, string) {
+	if name == "" {
+		record := DNSRecord{Type: 7, Name: name, Value: details.Name, PullZoneId: details.Id, TTL: 300} // PZ
+		return record, fmt.Sprintf("pull zone record %s -> %s", domain, details.Name)
+	}
+	record := DNSRecord{Type: 2, Name: name, Value: cdnHostname(details), TTL: 300} // CNAME
+	return record, fmt.Sprintf("CNAME %s -> %s", domain, record.Value)
+}
+
+// hasAddressRecord reports whether a DNS zone has an A, CNAME or pull zone record with the given name
+func hasAddressRecord(zone *DNSZone, name string) bool {
+	for _, record := range zone.Records {
+		if isTargetRecordType(record.Type) && strings.EqualFold(record.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Side effect functions (HTTP calls)
+
+// parkDomain attaches a parked domain to a redirect zone step by step: hostname, catch-all redirect, DNS record
+// and SSL certificate. Every step reports its outcome, steps that are already in place are left alone, so
+// running it again after a failure finishes the remaining steps.
+func parkDomain(ctx context.Context, apiKey, zoneID string, opts ParkOptions, report func(ParkStep)) error {
+	details, err := getPullZoneDetails(ctx, apiKey, zoneID)
+	if err != nil {
+		return fmt.Errorf("error getting pull zone details: %v", err)
+	}
+
+	failed := false
+	step := func(name, status, detail string) {
+		if status == "failed" {
+			failed = true
+		}
+		report(ParkStep{Name: name, Status: status, Detail: detail})
+	}
+
+	// 1. Hostname
+	switch {
+	case hasHostname(details.Hostnames, opts.Domain):
+		step("hostname", "exists", opts.Domain+" is attached to the zone")
+	case opts.DryRun:
+		step("hostname", "would", "attach "+opts.Domain)
+	default:
+		if err := addPullZoneHostname(ctx, apiKey, zoneID, opts.Domain); err != nil {
+			step("hostname", "failed", err.Error())
+			return fmt.Errorf("could not attach %s, later steps need it", opts.Domain)
+		}
+		step("hostname", "done", "attached "+opts.Domain)
+	}
+
+	// 2. Catch-all redirect scoped to the domain
+	existing := findParkRule(details.EdgeRules, opts.Domain)
+	guid := ""
+	if existing != nil {
+		guid = existing.Guid
+	}
+	rule := buildParkRule(guid, opts.Domain, opts.To, opts.PreservePath)
+	summary := fmt.Sprintf("%s -> %s", rule.Triggers[0].PatternMatches[0], rule.ActionParameter1)
+	switch {
+	case existing != nil && parkRuleUpToDate(*existing, rule):
+		step("rule", "exists", summary)
+	case opts.DryRun:
+		step("rule", "would", map[bool]string{true: "update ", false: "create "}[existing != nil]+summary)
+	default:
+		if err := addEdgeRule(ctx, apiKey, zoneID, rule); err != nil {
+			step("rule", "failed", err.Error())
+		} else {
+			step("rule", "done", summary)
+		}
+	}
+
+	// 3. DNS record, only when the domain is on Bunny DNS
+	dnsZones, err := getAllDNSZones(ctx, apiKey)
+	if err != nil {
+		step("dns", "failed", err.Error())
+	} else if dnsZone, name := findDNSZoneForHost(dnsZones, opts.Domain); dnsZone == nil {
+		step("dns", "skipped", opts.Domain+" is not on Bunny DNS, point it at "+cdnHostname(details)+" manually")
+	} else if hasAddressRecord(dnsZone, name) {
+		step("dns", "exists", "record for "+opts.Domain+" in "+dnsZone.Domain)
+	} else {
+		record, detail := parkDNSRecord(details, opts.Domain, name)
+		detail += " in " + dnsZone.Domain
+		switch {
+		case opts.DryRun:
+			step("dns", "would", "create "+detail)
+		default:
+			if err := addDNSRecord(ctx, apiKey, dnsZone.Id, record); err != nil {
+				step("dns", "failed", err.Error())
+			} else {
+				step("dns", "done", detail)
+			}
+		}
+	}
+
+	// 4. SSL certificate, Bunny validates it over HTTP so it needs working DNS
+	if opts.DryRun {
+		step("ssl", "would", "request a free certificate for "+opts.Domain)
+	} else if err := loadFreeCertificate(ctx, apiKey, opts.Domain); err != nil {
+		step("ssl", "failed", fmt.Sprintf("%v (DNS may not have propagated yet, run the command again later)", err))
+	} else {
+		step("ssl", "done", "certificate requested for "+opts.Domain)
+	}
+
+	if failed {
+		return fmt.Errorf("parking %s did not complete", opts.Domain)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFindDNSZoneForHost(t *testing.T) {
+	zones := []DNSZone{{Id: 1, Domain: "example.com"}, {Id: 2, Domain: "shop.example.com"}, {Id: 3, Domain: "oldbrand.com"}}
+
+	tests := []struct {
+		host     string
+		wantZone int64
+		wantName string
+	}{
+		{host: "oldbrand.com", wantZone: 3, wantName: ""},
+		{host: "www.OldBrand.com", wantZone: 3, wantName: "www"},
+		{host: "eu.shop.example.com", wantZone: 2, wantName: "eu"},
+		{host: "a.b.example.com", wantZone: 1, wantName: "a.b"},
+		{host: "notexample.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			zone, name := findDNSZoneForHost(zones, tt.host)
+			var zoneID int64
+			if zone != nil {
+				zoneID = zone.Id
+			}
+			if zoneID != tt.wantZone || name != tt.wantName {
+				t.Errorf("findDNSZoneForHost(%s) = %d, %q, want %d, %q", tt.host, zoneID, name, tt.wantZone, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestParkDNSRecord(t *testing.T) {
+	details := &PullZoneDetails{Id: 7, Name: "redirects"}
+	tests := []struct {
+		name       string
+		recordName string
+		wantType   int
+		wantValue  string
+	}{
+		{name: "apex gets a pull zone record", recordName: "", wantType: 7, wantValue: "redirects"},
+		{name: "subdomain gets a CNAME", recordName: "www", wantType: 2, wantValue: "redirects.b-cdn.net"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record, _ := parkDNSRecord(details, "oldbrand.com", tt.recordName)
+			if record.Type != tt.wantType || record.Value != tt.wantValue || record.Name != tt.recordName {
+				t.Errorf("parkDNSRecord() = %+v, want type %d -> %s", record, tt.wantType, tt.wantValue)
+			}
+			if tt.wantType == 7 && record.PullZoneId != details.Id {
+				t.Errorf("pull zone record points at zone %d, want %d", record.PullZoneId, details.Id)
+			}
+		})
+	}
+}
+
+func TestParkDestination(t *testing.T) {
+	if got := parkDestination("https://www.newbrand.com/", false); got != "https://www.newbrand.com/" {
+		t.Errorf("parkDestination() = %s", got)
+	}
+	if got := parkDestination("https://www.newbrand.com/", true); got != "https://www.newbrand.com%{Url.Path}" {
+		t.Errorf("parkDestination() with path = %s", got)
+	}
+}
+
+func TestParkDomain(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "redirects", "redirects.b-cdn.net")
+	fb.addDNSZone(7, "oldbrand.com")
+	ctx := context.Background()
+
+	park := func(opts ParkOptions) (string, error) {
+		t.Helper()
+		var steps []string
+		err := parkDomain(ctx, fakeAPIKey, "1", opts, func(step ParkStep) {
+			steps = append(steps, step.Name+" "+step.Status)
+		})
+		return strings.Join(steps, ", "), err
+	}
+	opts := ParkOptions{Domain: "oldbrand.com", To: "https://www.newbrand.com", PreservePath: true}
+
+	// Dry run changes nothing
+	dryRun := opts
+	dryRun.DryRun = true
+	steps, err := park(dryRun)
+	if err != nil || steps != "hostname would, rule would, dns would, ssl would" {
+		t.Fatalf("dry run = %s, %v", steps, err)
+	}
+	for _, request := range fb.requestLog() {
+		if !strings.HasPrefix(request, "GET ") {
+			t.Errorf("dry run sent %s", request)
+		}
+	}
+
+	steps, err = park(opts)
+	if err != nil || steps != "hostname done, rule done, dns done, ssl done" {
+		t.Fatalf("park = %s, %v", steps, err)
+	}
+
+	rules := fb.edgeRules(1)
+	if len(rules) != 1 || rules[0].ActionParameter1 != "https://www.newbrand.com%{Url.Path}" || rules[0].Triggers[0].PatternMatches[0] != "*://oldbrand.com/*" {
+		t.Errorf("park rule = %+v", rules)
+	}
+	if records := fb.dnsZones[0].Records; len(records) != 1 || records[0].Type != 7 || records[0].PullZoneId != 1 || records[0].Name != "" {
+		t.Errorf("DNS records = %+v", fb.dnsZones[0].Records)
+	}
+	if fmt.Sprint(fb.certificates) != "[oldbrand.com]" {
+		t.Errorf("certificates = %v", fb.certificates)
+	}
+
+	// Running again only re-requests the certificate
+	steps, err = park(opts)
+	if err != nil || steps != "hostname exists, rule exists, dns exists, ssl done" {
+		t.Fatalf("second park = %s, %v", steps, err)
+	}
+	if len(fb.edgeRules(1)) != 1 {
+		t.Errorf("second park duplicated the rule")
+	}
+
+	// A changed destination updates the same rule
+	opts.To = "https://www.newerbrand.com"
+	if steps, err = park(opts); err != nil || !strings.Contains(steps, "rule done") {
+		t.Fatalf("park with a new destination = %s, %v", steps, err)
+	}
+	if rules := fb.edgeRules(1); len(rules) != 1 || !strings.HasPrefix(rules[0].ActionParameter1, "https://www.newerbrand.com") {
+		t.Errorf("rule after destination change = %+v", rules)
+	}
+
+	// Domains outside Bunny DNS skip the DNS step
+	steps, err = park(ParkOptions{Domain: "elsewhere.net", To: "https://www.newbrand.com"})
+	if err != nil || steps != "hostname done, rule done, dns skipped, ssl done" {
+		t.Errorf("park outside Bunny DNS = %s, %v", steps, err)
+	}
+}
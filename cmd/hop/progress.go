@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// healthProgressEvery is how many destinations pass between progress lines when stderr is not a terminal
+const healthProgressEvery = 25
+
+// HealthProgress is the state of a destination health check run
+type HealthProgress struct {
+	Done   int // destinations probed or skipped
+	Total  int
+	Failed int // destinations with an error or critical finding
+}
+
+// healthProgressFlag returns the progress reporter of rules check and check on stderr, nil with --quiet
+func healthProgressFlag(quiet bool) func(HealthProgress) {
+	if quiet {
+		return nil
+	}
+	return newProgressReporter(os.Stderr, isTerminal(os.Stderr), healthProgressEvery)
+}
+
+// newProgressReporter returns a reporter that rewrites a single line on a terminal, and otherwise prints a line every n destinations and at the end
+func newProgressReporter(w io.Writer, terminal bool, every int) func(HealthProgress) {
+	return func(progress HealthProgress) {
+		done := progress.Done == progress.Total
+		switch {
+		case terminal:
+			fmt.Fprintf(w, "\r%s", formatHealthProgress(progress))
+			if done {
+				fmt.Fprintln(w)
+			}
+		case done || (every > 0 && progress.Done%every == 0):
+			fmt.Fprintln(w, formatHealthProgress(progress))
+		}
+	}
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Side effect free functions
+
+// formatHealthProgress renders progress like "health checks: 123/300 (4 failed)"
+func formatHealthProgress(progress HealthProgress) string {
+	return fmt.Sprintf("health checks: %d/%d (%d failed)", progress.Done, progress.Total, progress.Failed)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProgressReporter(t *testing.T) {
+	steps := []HealthProgress{{Done: 1, Total: 3}, {Done: 2, Total: 3, Failed: 1}, {Done: 3, Total: 3, Failed: 1}}
+
+	tests := []struct {
+		name     string
+		terminal bool
+		every    int
+		want     string
+	}{
+		{
+			name:     "terminal rewrites one line",
+			terminal: true,
+			want:     "\rhealth checks: 1/3 (0 failed)\rhealth checks: 2/3 (1 failed)\rhealth checks: 3/3 (1 failed)\n",
+		},
+		{
+			name:  "pipe prints every n and the end",
+			every: 2,
+			want:  "health checks: 2/3 (1 failed)\nhealth checks: 3/3 (1 failed)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			report := newProgressReporter(&buf, tt.terminal, tt.every)
+			for _, step := range steps {
+				report(step)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("progress output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamURLHealthReportsProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/gone") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	rules := []EdgeRuleResponse{
+		redirectRule("a", "/a", server.URL+"/ok"),
+		redirectRule("b", "/b", server.URL+"/gone"),
+		redirectRule("c", "/c", server.URL+"/ok"),
+		redirectRule("d", "/d", server.URL+"/gone-too"),
+	}
+
+	var steps []HealthProgress
+	streamURLHealth(context.Background(), rules, nil, nil, nil, nil, func(progress HealthProgress) {
+		steps = append(steps, progress)
+	})
+	if len(steps) != 3 {
+		t.Fatalf("got %d progress updates, want one per unique destination", len(steps))
+	}
+	for i, step := range steps {
+		if step.Done != i+1 || step.Total != 3 {
+			t.Errorf("update %d = %+v, want %d/3", i, step, i+1)
+		}
+	}
+	if last := steps[len(steps)-1]; last.Failed != 2 {
+		t.Errorf("failed = %d, want 2", last.Failed)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// purgeWorkers is how many purge requests run at the same time, the purge API takes one URL per request
+const purgeWorkers = 4
+
+// PurgeOutcome is the result of purging the cache after a push
+type PurgeOutcome struct {
+	URLs      int     // URLs purged or attempted, 0 when the whole zone was purged
+	WholeZone bool    // the zone was purged instead of single URLs
+	Errors    []error // failed purge requests
+}
+
+// purgeChangedFiles purges the changed files of a push from the CDN cache, or the whole pull zone when more than purgeAllAbove files changed.
+// purgeAllAbove 0 always purges single URLs.
+func purgeChangedFiles(ctx context.Context, apiKey, zoneID string, urls []string, changedFiles, purgeAllAbove int) PurgeOutcome {
+	if purgeAllAbove > 0 && changedFiles > purgeAllAbove {
+		var outcome PurgeOutcome
+		outcome.WholeZone = true
+		if err := purgePullZoneCache(ctx, apiKey, zoneID); err != nil {
+			outcome.Errors = append(outcome.Errors, fmt.Errorf("purging the pull zone: %v", err))
+		}
+		return outcome
+	}
+
+	errs := make([]error, len(urls))
+	runWorkers(purgeWorkers, len(urls), func(job int) {
+		if err := purgeURL(ctx, apiKey, urls[job]); err != nil {
+			errs[job] = fmt.Errorf("purging %s: %v", urls[job], err)
+		}
+	})
+	outcome := PurgeOutcome{URLs: len(urls)}
+	for _, err := range errs {
+		if err != nil {
+			outcome.Errors = append(outcome.Errors, err)
+		}
+	}
+	return outcome
+}
+
+// Side effect free functions
+
+// changedFileURLs returns the sorted public URLs of the uploaded and deleted files of a push on hostname.
+// Files of a release are served from the release root, other files under their storage path; an index.html is purged with its directory URL too.
+func changedFileURLs(hostname string, results []FileUploadStatus, remoteDir string, release bool) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(urlPath string) {
+		u := (&url.URL{Scheme: "https", Host: hostname, Path: urlPath}).String()
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	for _, result := range results {
+		if !result.Success || result.Skipped || result.Planned {
+			continue
+		}
+		storagePath := result.RelPath
+		if !release {
+			storagePath = remoteFilePath(remoteDir, result.RelPath)
+		}
+		urlPath := "/" + strings.TrimPrefix(storagePath, "/")
+		add(urlPath)
+		if path.Base(urlPath) == "index.html" {
+			add(storagePathToURLPath(urlPath))
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestPurgeChangedFiles(t *testing.T) {
+	urls := []string{"https://site.b-cdn.net/a.html", "https://site.b-cdn.net/docs/a%20b.html"}
+
+	t.Run("single urls", func(t *testing.T) {
+		fb := newFakeBunny(t)
+		fb.addZone(1, "site")
+		outcome := purgeChangedFiles(context.Background(), fakeAPIKey, "1", urls, 2, 0)
+		if outcome.WholeZone || outcome.URLs != 2 || len(outcome.Errors) != 0 {
+			t.Fatalf("outcome %+v, want 2 URLs without errors", outcome)
+		}
+		purged := append([]string(nil), fb.purged...)
+		sort.Strings(purged)
+		want := []string{"https://site.b-cdn.net/a.html", "https://site.b-cdn.net/docs/a%20b.html"}
+		if !reflect.DeepEqual(purged, want) {
+			t.Errorf("purged %v, want %v", purged, want)
+		}
+	})
+
+	t.Run("whole zone above threshold", func(t *testing.T) {
+		fb := newFakeBunny(t)
+		fb.addZone(1, "site")
+		outcome := purgeChangedFiles(context.Background(), fakeAPIKey, "1", urls, 3, 2)
+		if !outcome.WholeZone || len(outcome.Errors) != 0 {
+			t.Fatalf("outcome %+v, want a whole zone purge", outcome)
+		}
+		if fb.zonePurges != 1 || len(fb.purged) != 0 {
+			t.Errorf("%d zone purges and %d URL purges, want 1 and 0", fb.zonePurges, len(fb.purged))
+		}
+	})
+
+	t.Run("at threshold purges urls", func(t *testing.T) {
+		fb := newFakeBunny(t)
+		fb.addZone(1, "site")
+		outcome := purgeChangedFiles(context.Background(), fakeAPIKey, "1", urls, 2, 2)
+		if outcome.WholeZone || fb.zonePurges != 0 || len(fb.purged) != 2 {
+			t.Errorf("outcome %+v with %d zone purges, want single URLs", outcome, fb.zonePurges)
+		}
+	})
+
+	t.Run("failures are collected", func(t *testing.T) {
+		fb := newFakeBunny(t)
+		fb.addZone(1, "site")
+		fb.purgeStatus = http.StatusInternalServerError
+		outcome := purgeChangedFiles(context.Background(), fakeAPIKey, "1", urls, 2, 0)
+		if len(outcome.Errors) != 2 {
+			t.Fatalf("got %d errors, want 2", len(outcome.Errors))
+		}
+		if !strings.Contains(outcome.Errors[0].Error(), "purging https://site.b-cdn.net/a.html") {
+			t.Errorf("error %q does not name the URL", outcome.Errors[0])
+		}
+	})
+
+	t.Run("unknown zone", func(t *testing.T) {
+		newFakeBunny(t)
+		outcome := purgeChangedFiles(context.Background(), fakeAPIKey, "9", urls, 3, 1)
+		if !outcome.WholeZone || len(outcome.Errors) != 1 {
+			t.Errorf("outcome %+v, want one whole zone error", outcome)
+		}
+	})
+}
+
+func TestChangedFileURLs(t *testing.T) {
+	results := []FileUploadStatus{
+		{RelPath: "index.html", Success: true},
+		{RelPath: "docs/index.html", Success: true},
+		{RelPath: "css/site.css", Success: true},
+		{RelPath: "old.html", Success: true, Deleted: true},
+		{RelPath: "same.js", Success: true, Skipped: true},
+		{RelPath: "broken.js", Success: false},
+		{RelPath: "planned.js", Success: true, Planned: true},
+	}
+
+	tests := []struct {
+		name      string
+		remoteDir string
+		release   bool
+		want      []string
+	}{
+		{"root", "", false, []string{
+			"https://site.b-cdn.net/",
+			"https://site.b-cdn.net/css/site.css",
+			"https://site.b-cdn.net/docs/",
+			"https://site.b-cdn.net/docs/index.html",
+			"https://site.b-cdn.net/index.html",
+			"https://site.b-cdn.net/old.html",
+		}},
+		{"to prefix", "blog", false, []string{
+			"https://site.b-cdn.net/blog/",
+			"https://site.b-cdn.net/blog/css/site.css",
+			"https://site.b-cdn.net/blog/docs/",
+			"https://site.b-cdn.net/blog/docs/index.html",
+			"https://site.b-cdn.net/blog/index.html",
+			"https://site.b-cdn.net/blog/old.html",
+		}},
+		{"release serves from the root", "releases/v2", true, []string{
+			"https://site.b-cdn.net/",
+			"https://site.b-cdn.net/css/site.css",
+			"https://site.b-cdn.net/docs/",
+			"https://site.b-cdn.net/docs/index.html",
+			"https://site.b-cdn.net/index.html",
+			"https://site.b-cdn.net/old.html",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := changedFileURLs("site.b-cdn.net", results, tt.remoteDir, tt.release)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedFileURLs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := changedFileURLs("site.b-cdn.net", []FileUploadStatus{{RelPath: "a b.html", Success: true}}, "", false); got[0] != "https://site.b-cdn.net/a%20b.html" {
+		t.Errorf("got %v, want an escaped path", got)
+	}
+}
+
+func TestPushStrictPurgeFailureReturnsExitCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		want   int
+	}{
+		{name: "warning", want: 0},
+		{name: "strict", strict: true, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBunny(t)
+			fb.addZone(1, "site")
+			fb.purgeStatus = http.StatusInternalServerError
+			localDir := t.TempDir()
+			writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+
+			args := []string{"cdn", "push", "--key", fakeAPIKey, "--zone", "site", "--from", localDir, "--purge"}
+			if tt.strict {
+				args = append(args, "--strict-purge")
+			}
+			if code := run(args); code != tt.want {
+				t.Errorf("exit code = %d, want %d", code, tt.want)
+			}
+			if got := fb.fileNames(); len(got) != 1 {
+				t.Errorf("stored files = %v, want the upload kept", got)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,15 +14,23 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type FileUploadStatus struct {
-	Path    string
-	Success bool
-	Error   error
-	Skipped bool
-	Reason  string
+	Path     string
+	RelPath  string
+	Size     int64
+	Checksum string // SHA256 of the local file, "" for deletions
+	Success  bool
+	Error    error
+	Skipped  bool
+	Planned  bool // dry run: the file would be uploaded, or deleted with Deleted
+	Deleted  bool // a remote file removed by --delete, RelPath is relative to the remote directory
+	Reason   string
+
+	Interrupted bool // the push was interrupted or timed out before the file was uploaded
 }
 
 type RemoteFileInfo struct {
@@ -36,8 +45,11 @@ type RemoteFileInfo struct {
 type LocalFileInfo struct {
 	Path     string
 	Size     int64
-	Checksum string
+	ModTime  time.Time
+	Checksum string // SHA256, computed by withChecksum once a comparison or upload needs it
 	RelPath  string
+
+	hashed bool // withChecksum ran, Checksum stays "" when hashing failed
 }
 
 func calculateFileChecksum(filePath string) (string, error) {
@@ -57,7 +69,7 @@ func calculateFileChecksum(filePath string) (string, error) {
 }
 
 func listRemoteFiles(ctx context.Context, storageZone *StorageZone, remotePath string) ([]RemoteFileInfo, error) {
-	url := fmt.Sprintf("https://storage.bunnycdn.com/%s/%s", storageZone.Name, strings.TrimPrefix(remotePath, "/"))
+	url := fmt.Sprintf("%s/%s/%s", storageBaseURL, storageZone.Name, strings.TrimPrefix(remotePath, "/"))
 	if !strings.HasSuffix(url, "/") {
 		url += "/"
 	}
@@ -77,7 +89,7 @@ func listRemoteFiles(ctx context.Context, storageZone *StorageZone, remotePath s
 	if resp == nil {
 		return nil, fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode == http.StatusNotFound {
 		// Directory doesn't exist, return empty list
@@ -110,6 +122,14 @@ func listRemoteFiles(ctx context.Context, storageZone *StorageZone, remotePath s
 	return remoteFiles, nil
 }
 
+// uploadReason explains why a local file that also exists remotely is uploaded again
+func uploadReason(localFile LocalFileInfo, remoteFile RemoteFileInfo) string {
+	if localFile.Size != remoteFile.Size {
+		return "changed, size mismatch"
+	}
+	return "changed, checksum mismatch"
+}
+
 func shouldSkipUpload(localFile LocalFileInfo, remoteFile RemoteFileInfo) (bool, string) {
 	// Compare size first (quick check)
 	if localFile.Size != remoteFile.Size {
@@ -132,7 +152,22 @@ func shouldSkipUpload(localFile LocalFileInfo, remoteFile RemoteFileInfo) (bool,
 	return false, ""
 }
 
-func uploadFileToStorage(ctx context.Context, storageZone *StorageZone, localPath, remotePath string) error {
+// errChecksumMismatch marks an upload the storage rejected because the received content does not match the Checksum header
+var errChecksumMismatch = errors.New("checksum mismatch")
+
+// checksumRetries is how often an upload rejected for a checksum mismatch is repeated
+const checksumRetries = 2
+
+// uploadOptions are the per-file settings of uploadFileToStorage, the zero value uploads without checksum or progress
+type uploadOptions struct {
+	Checksum     string            // SHA256 the storage verifies the received content against, "" for no check
+	ContentTypes map[string]string // Content-Type overrides by lowercase extension
+	OnProgress   func(int64)       // called with the number of body bytes sent, nil for none
+	Limiter      *rateLimiter      // shared limit of the bytes all uploads send per second, nil for none
+}
+
+// uploadFileToStorage uploads a local file, with a checksum the storage verifies the received content and rejects a truncated transfer
+func uploadFileToStorage(ctx context.Context, storageZone *StorageZone, localPath, remotePath string, upload uploadOptions) error {
 	// Read the file
 	// #nosec G304 - localPath comes from filepath.Walk which validates the path
 	fileContent, err := os.ReadFile(localPath)
@@ -141,19 +176,31 @@ func uploadFileToStorage(ctx context.Context, storageZone *StorageZone, localPat
 	}
 
 	// Construct the storage URL
-	url := fmt.Sprintf("https://storage.bunnycdn.com/%s/%s", storageZone.Name, strings.TrimPrefix(remotePath, "/"))
+	url := fmt.Sprintf("%s/%s/%s", storageBaseURL, storageZone.Name, strings.TrimPrefix(remotePath, "/"))
 
 	// Create PUT request
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(fileContent))
+	var body io.Reader = bytes.NewReader(fileContent)
+	if upload.Limiter != nil {
+		body = &rateLimitedReader{ctx: ctx, r: body, limiter: upload.Limiter}
+	}
+	if upload.OnProgress != nil {
+		body = &progressReader{r: body, onProgress: upload.OnProgress}
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, body)
 	if err != nil {
 		return fmt.Errorf("error creating request: %v", err)
 	}
+	req.ContentLength = int64(len(fileContent))
 
 	// Set headers
 	req.Header.Set("AccessKey", storageZone.Password)
-	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Type", contentTypeFor(localPath, fileContent, upload.ContentTypes))
+	if upload.Checksum != "" {
+		req.Header.Set("Checksum", strings.ToUpper(upload.Checksum))
+	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	// No client timeout, large files take as long as ctx allows
+	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("error uploading file: %v", err)
@@ -161,164 +208,372 @@ func uploadFileToStorage(ctx context.Context, storageZone *StorageZone, localPat
 	if resp == nil {
 		return fmt.Errorf("received nil response")
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		if upload.Checksum != "" && resp.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(string(body)), "checksum") {
+			return fmt.Errorf("upload failed with status %s: %w: %s", resp.Status, errChecksumMismatch, string(body))
+		}
 		return fmt.Errorf("upload failed with status %s: %s", resp.Status, string(body))
 	}
 
 	return nil
 }
 
-// buildLocalFileMap builds a complete map of local files with checksums
-func buildLocalFileMap(localDir string) (map[string]LocalFileInfo, error) {
-	localFileMap := make(map[string]LocalFileInfo)
+// walkRemoteFiles calls fn for every file below a remote directory, with paths relative to the storage root.
+// It is the one recursive listing of storage, it stops at the first listing or callback error and when ctx is cancelled.
+func walkRemoteFiles(ctx context.Context, storageZone *StorageZone, remoteDir string, fn func(RemoteFileInfo) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	err := filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
+	entries, err := listRemoteFiles(ctx, storageZone, remoteDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDirectory {
+			subDir := strings.Trim(strings.TrimSuffix(remoteDir, "/")+"/"+entry.Name, "/")
+			if err := walkRemoteFiles(ctx, storageZone, subDir, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		entry.Path = strings.TrimPrefix(entry.Path, "/")
+		if err := fn(entry); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
+// listRemoteFilesRecursive lists every file below a remote directory, with paths relative to the storage root
+func listRemoteFilesRecursive(ctx context.Context, storageZone *StorageZone, remoteDir string) ([]RemoteFileInfo, error) {
+	var files []RemoteFileInfo
+	err := walkRemoteFiles(ctx, storageZone, remoteDir, func(file RemoteFileInfo) error {
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// storageZoneEndpoint returns the storage API endpoint a zone's files are read from and written to
+func storageZoneEndpoint(storageZone *StorageZone) string {
+	return fmt.Sprintf("%s/%s/", storageBaseURL, storageZone.Name)
+}
+
+// summarizeRemoteFiles returns the number of files and their total size
+func summarizeRemoteFiles(files []RemoteFileInfo) (int, int64) {
+	count := 0
+	var total int64
+	for _, file := range files {
+		if file.IsDirectory {
+			continue
 		}
+		count++
+		total += file.Size
+	}
+	return count, total
+}
 
-		// Calculate relative path
-		relPath, err := filepath.Rel(localDir, path)
-		if err != nil {
-			return err
+// formatByteSize formats a byte count using binary units
+func formatByteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// printRemoteSummary prints the target storage zone and what it already contains before a push
+func printRemoteSummary(ctx context.Context, storageZone *StorageZone, remoteDir string, fullStats bool) error {
+	fmt.Printf("Storage zone: %s (endpoint %s)\n", storageZone.Name, storageZoneEndpoint(storageZone))
+
+	target := "/" + remoteDir
+	entries, err := listRemoteFiles(ctx, storageZone, remoteDir)
+	if err != nil {
+		return fmt.Errorf("error listing '%s': %v", target, err)
+	}
+	dirs := 0
+	for _, entry := range entries {
+		if entry.IsDirectory {
+			dirs++
 		}
+	}
+	fmt.Printf("Remote '%s' has %d entries (%d files, %d directories)\n", target, len(entries), len(entries)-dirs, dirs)
 
-		// Calculate checksum
-		checksum, err := calculateFileChecksum(path)
-		if err != nil {
-			fmt.Printf("⚠ Warning: Could not calculate checksum for %s: %v\n", relPath, err)
-			checksum = ""
+	if !fullStats {
+		return nil
+	}
+	files, err := listRemoteFilesRecursive(ctx, storageZone, remoteDir)
+	if err != nil {
+		return fmt.Errorf("error gathering remote stats for '%s': %v", target, err)
+	}
+	count, total := summarizeRemoteFiles(files)
+	fmt.Printf("Remote '%s' contains %d files totalling %s\n", target, count, formatByteSize(total))
+	return nil
+}
+
+// deleteRemotePath deletes a file or, when the path ends with a slash, a whole directory from storage
+func deleteRemotePath(ctx context.Context, storageZone *StorageZone, remotePath string) error {
+	url := fmt.Sprintf("%s/%s/%s", storageBaseURL, storageZone.Name, strings.TrimPrefix(remotePath, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("AccessKey", storageZone.Password)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error deleting %s: %v", remotePath, err)
+	}
+	if resp == nil {
+		return fmt.Errorf("received nil response")
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("delete failed with status %s: %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// listLocalRelPaths returns the paths of all files below a local directory, relative to it, walking it like the push does
+func listLocalRelPaths(localDir string, walk LocalWalkOptions) ([]string, error) {
+	var relPaths []string
+	_, err := walkLocalFiles(localDir, walk, func(path, relPath string, info os.FileInfo) error {
+		relPaths = append(relPaths, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return relPaths, nil
+}
+
+// LocalScanStats counts the local files buildLocalFileMap left out
+type LocalScanStats struct {
+	NotIncluded int      // matched none of the --include globs
+	Excluded    int      // matched an --exclude glob
+	Symlinks    []string // symlinks that were not followed, relative paths
+	Hidden      []string // hidden files and directories that were skipped, relative paths
+}
+
+// filtered describes the files the filter left out, "" when it left out none
+func (s LocalScanStats) filtered() string {
+	var parts []string
+	if s.NotIncluded > 0 {
+		parts = append(parts, fmt.Sprintf("%d not matching --include", s.NotIncluded))
+	}
+	if s.Excluded > 0 {
+		parts = append(parts, fmt.Sprintf("%d excluded", s.Excluded))
+	}
+	if len(s.Symlinks) > 0 {
+		parts = append(parts, fmt.Sprintf("%d symlinks skipped", len(s.Symlinks)))
+	}
+	if len(s.Hidden) > 0 {
+		parts = append(parts, fmt.Sprintf("%d hidden skipped", len(s.Hidden)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildLocalFileMap builds a complete map of local files with size and modification time, files the filter leaves out are counted.
+// No file is hashed here, skipChecker and the uploaders compute checksums only for the files they need them for.
+func buildLocalFileMap(localDir string, filter PathFilter, walk LocalWalkOptions) (map[string]LocalFileInfo, LocalScanStats, error) {
+	localFileMap := make(map[string]LocalFileInfo)
+	var stats LocalScanStats
+
+	skipped, err := walkLocalFiles(localDir, walk, func(path, relPath string, info os.FileInfo) error {
+		// hop's own state files are never uploaded
+		if isHopStateFile(relPath) {
+			return nil
+		}
+		if !filter.includes(relPath) {
+			stats.NotIncluded++
+			return nil
+		}
+		if filter.excludes(relPath) {
+			stats.Excluded++
+			return nil
 		}
 
 		localFileMap[strings.ReplaceAll(relPath, "\\", "/")] = LocalFileInfo{
-			Path:     path,
-			Size:     info.Size(),
-			Checksum: checksum,
-			RelPath:  strings.ReplaceAll(relPath, "\\", "/"),
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+			RelPath: strings.ReplaceAll(relPath, "\\", "/"),
 		}
 
 		return nil
 	})
+	// symlinks the filter leaves out are not worth a warning
+	stats.Symlinks = filter.selectPaths(skipped.Symlinks)
+	stats.Hidden = skipped.Hidden
 
-	return localFileMap, err
+	return localFileMap, stats, err
 }
 
-// remoteFileStreamer streams remote files to the skip checker
+// remoteFileStreamer streams remote files to the skip checker, with paths relative to remoteDir
 func remoteFileStreamer(ctx context.Context, storageZone *StorageZone, remoteDir string, remoteFiles chan<- RemoteFileInfo) {
 	defer close(remoteFiles)
 
 	fmt.Println("Streaming remote file list...")
 
-	var streamFiles func(string) error
-	streamFiles = func(currentPath string) error {
+	err := walkRemoteFiles(ctx, storageZone, remoteDir, func(file RemoteFileInfo) error {
+		file.Path = relativeRemotePath(file.Path, remoteDir)
 		select {
+		case remoteFiles <- file:
+			return nil
 		case <-ctx.Done():
 			return ctx.Err()
-		default:
-		}
-
-		files, err := listRemoteFiles(ctx, storageZone, currentPath)
-		if err != nil {
-			fmt.Printf("⚠ Warning: Could not list remote files in %s: %v\n", currentPath, err)
-			return nil // Continue with other directories
 		}
+	})
+	if err != nil && ctx.Err() == nil {
+		fmt.Printf("WARN: Could not list all remote files, unlisted files are uploaded again: %v\n", err)
+	}
+}
 
-		for _, file := range files {
-			if file.IsDirectory {
-				// Recursively stream subdirectories
-				subPath := filepath.Join(currentPath, file.Name)
-				subPath = strings.ReplaceAll(subPath, "\\", "/")
-				if err := streamFiles(subPath); err != nil {
-					return err
-				}
-			} else {
-				// Stream the file
-				relPath := file.Name
-				if currentPath != "" && currentPath != "/" {
-					relPath = filepath.Join(strings.TrimPrefix(currentPath, remoteDir), file.Name)
-					relPath = strings.ReplaceAll(relPath, "\\", "/")
-				}
-				file.Path = relPath
-
-				remoteFiles <- file
-			}
+// normalizeRemoteDir turns a --to prefix like /site-a/ or site-a\docs into a storage directory like site-a/docs, "" is the storage root.
+// It rejects ".." segments, which would leave the prefix.
+func normalizeRemoteDir(to string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(strings.ReplaceAll(to, "\\", "/"), "/") {
+		switch strings.TrimSpace(segment) {
+		case "", ".":
+		case "..":
+			return "", fmt.Errorf("invalid remote directory '%s': '..' is not allowed", to)
+		default:
+			segments = append(segments, segment)
 		}
-		return nil
 	}
+	return strings.Join(segments, "/"), nil
+}
 
-	if err := streamFiles(remoteDir); err != nil {
-		fmt.Printf("⚠ Warning: Error streaming remote files: %v\n", err)
+// relativeRemotePath strips remoteDir from a path relative to the storage root
+func relativeRemotePath(path, remoteDir string) string {
+	prefix := strings.Trim(remoteDir, "/")
+	if prefix == "" {
+		return path
 	}
+	return strings.TrimPrefix(path, prefix+"/")
 }
 
-// skipChecker processes streamed remote files and manages local file states
-func skipChecker(localStates map[string]*LocalFileState, remoteFiles <-chan RemoteFileInfo, uploadTasks chan<- FileUploadTask, remoteDir string, results chan<- FileUploadStatus) {
+// remoteComparison is what skipChecker learned about the remote files
+type remoteComparison struct {
+	total      int              // remote files streamed
+	remoteOnly []RemoteFileInfo // remote files without a local file, paths relative to remoteDir
+}
+
+// skipChecker processes streamed remote files and manages local file states, it returns the remote files without a local counterpart.
+// Local files with a remote copy are compared, and hashed when needed, by a pool of opts.HashWorkers goroutines.
+// Once ctx is cancelled no more files are compared or handed to the uploaders.
+func skipChecker(ctx context.Context, localStates map[string]*LocalFileState, remoteFiles <-chan RemoteFileInfo, uploadTasks chan<- FileUploadTask, remoteDir string, results chan<- FileUploadStatus, opts PushOptions) remoteComparison {
 	defer close(uploadTasks)
 
 	remoteCount := 0
 	remoteOnlyCount := 0
+	var remoteOnly []RemoteFileInfo
+
+	// Compare files on the hash workers, each local state is handed to exactly one of them
+	pending := make(chan pendingComparison, 100)
+	var comparerWG sync.WaitGroup
+	for range hashWorkerCount(opts.HashWorkers) {
+		comparerWG.Add(1)
+		go func() {
+			defer comparerWG.Done()
+			for comparison := range pending {
+				if ctx.Err() != nil {
+					continue
+				}
+				compareLocalState(comparison.local, comparison.remote, uploadTasks, remoteDir, results, opts)
+			}
+		}()
+	}
 
 	// Process streamed remote files
 	for remoteFile := range remoteFiles {
 		remoteCount++
 
-		// Look up corresponding local file
+		// Look up corresponding local file, both paths are relative to remoteDir with '/' separators
 		localState, exists := localStates[remoteFile.Path]
 		if !exists {
-			// Remote file doesn't exist locally - ignore it
+			// Remote file doesn't exist locally - ignore it unless --delete prunes it
 			remoteOnlyCount++
+			remoteOnly = append(remoteOnly, remoteFile)
 			continue
 		}
 
 		// Mark as checked
 		localState.Checked = true
-
-		// Check if we should skip this file
-		if skip, reason := shouldSkipUpload(localState.File, remoteFile); skip {
-			localState.Skip = true
-			localState.Reason = reason
-
-			results <- FileUploadStatus{
-				Path:    localState.File.Path,
-				Success: true,
-				Skipped: true,
-				Reason:  reason,
-			}
-		} else {
-			// Need to upload this file
-			remotePath := filepath.Join(remoteDir, localState.File.RelPath)
-			remotePath = strings.ReplaceAll(remotePath, "\\", "/")
-
-			uploadTasks <- FileUploadTask{
-				LocalFile:  localState.File,
-				RemotePath: remotePath,
-			}
-		}
+		pending <- pendingComparison{local: localState, remote: remoteFile}
 	}
+	close(pending)
+	comparerWG.Wait()
 
-	fmt.Printf("Processed %d remote files for comparison (%d remote-only files ignored)\n", remoteCount, remoteOnlyCount)
+	opts.progress.println("Processed %d remote files for comparison (%d remote-only files ignored)", remoteCount, remoteOnlyCount)
 
 	// Process any unchecked local files (they are new files)
 	for _, localState := range localStates {
+		if ctx.Err() != nil {
+			break
+		}
 		if !localState.Checked && !localState.Skip {
 			// This is a new local file - needs uploading
-			remotePath := filepath.Join(remoteDir, localState.File.RelPath)
-			remotePath = strings.ReplaceAll(remotePath, "\\", "/")
-
 			uploadTasks <- FileUploadTask{
 				LocalFile:  localState.File,
-				RemotePath: remotePath,
+				RemotePath: remoteFilePath(remoteDir, localState.File.RelPath),
+				Reason:     "new",
 			}
 		}
 	}
+	return remoteComparison{total: remoteCount, remoteOnly: remoteOnly}
+}
+
+// pendingComparison is a local file with a remote copy, waiting for a hash worker
+type pendingComparison struct {
+	local  *LocalFileState
+	remote RemoteFileInfo
+}
+
+// compareLocalState decides whether a local file is skipped or uploaded and reports a skip as a result or hands the upload to the uploaders
+func compareLocalState(localState *LocalFileState, remoteFile RemoteFileInfo, uploadTasks chan<- FileUploadTask, remoteDir string, results chan<- FileUploadStatus, opts PushOptions) {
+	file, skip, reason := compareWithRemote(localState.File, remoteFile, opts)
+	localState.File = file
+	if !skip {
+		// Need to upload this file
+		uploadTasks <- FileUploadTask{
+			LocalFile:  localState.File,
+			RemotePath: remoteFilePath(remoteDir, localState.File.RelPath),
+			Reason:     reason,
+		}
+		return
+	}
+	localState.Skip = true
+	localState.Reason = reason
+	results <- FileUploadStatus{
+		Path:     localState.File.Path,
+		RelPath:  localState.File.RelPath,
+		Size:     localState.File.Size,
+		Checksum: localState.File.Checksum,
+		Success:  true,
+		Skipped:  true,
+		Reason:   reason,
+	}
 }
 
 // FileProcessTask represents a file that needs processing
@@ -332,6 +587,7 @@ type FileProcessTask struct {
 type FileUploadTask struct {
 	LocalFile  LocalFileInfo
 	RemotePath string
+	Reason     string // why the file is uploaded: new or changed
 }
 
 // LocalFileState tracks the state of local files during processing
@@ -342,27 +598,100 @@ type LocalFileState struct {
 	Reason  string
 }
 
-func uploadDirectoryOptimized(ctx context.Context, storageZone *StorageZone, localDir, remoteDir string) []FileUploadStatus {
+// PushOptions configures uploadDirectoryOptimized, the zero value uploads every new or changed file
+type PushOptions struct {
+	DryRun           bool // compare local and remote files and report what would be uploaded, without uploading
+	Delete           bool // delete remote files that do not exist locally once every upload succeeded
+	MaxDelete        int  // refuse to delete more files than this unless ForceDelete is set
+	ForceDelete      bool // delete however many files --delete selects
+	Filter           PathFilter
+	Walk             LocalWalkOptions
+	NoVerifyChecksum bool // upload without the Checksum header the storage verifies the content with
+	// PerFileTimeout and MinThroughput bound every upload to PerFileTimeout plus its size at MinThroughput bytes per second, zero for no bound
+	PerFileTimeout time.Duration
+	MinThroughput  int64
+	// ContentTypes maps lowercase extensions to the Content-Type uploads of them get, ahead of the built-in types
+	ContentTypes map[string]string
+	Progress     bool // show a progress bar instead of a line per uploaded or skipped file, for terminals
+	Quiet        bool // print neither per-file lines nor a progress bar, failures are still printed
+	Verify       bool // list the uploaded files again and fail those whose remote size or checksum differs
+	// VerifyAttempts and VerifyInterval are how often and how far apart Verify lists a file that does not match yet,
+	// 0 for defaultVerifyAttempts and defaultVerifyInterval
+	VerifyAttempts int
+	VerifyInterval time.Duration
+	Fast           bool           // skip files whose size matches and that were not modified since their upload, without hashing them
+	Cache          *ChecksumCache // reuses and records the checksums of local files, nil for none
+	HashWorkers    int            // goroutines comparing and hashing local files with a remote copy, 0 for GOMAXPROCS
+	// InterruptGrace is how long running uploads may finish once ctx is cancelled, 0 for pushInterruptGrace
+	InterruptGrace time.Duration
+	MaxFileSize    int64 // local files larger than this many bytes fail, or are skipped with SkipOversize; 0 for no limit
+	SkipOversize   bool
+
+	LimitRate int64 // bytes per second all uploads together may send, 0 for no limit
+
+	progress *pushProgress // the running progress bar, nil without one
+	limiter  *rateLimiter  // the token bucket of LimitRate, nil without one
+}
+
+// PushResult is the outcome of uploadDirectoryOptimized
+type PushResult struct {
+	Files []FileUploadStatus
+	Scan  LocalScanStats
+}
+
+func uploadDirectoryOptimized(ctx context.Context, storageZone *StorageZone, localDir, remoteDir string, opts PushOptions) PushResult {
 	fmt.Println("Starting streaming concurrent file upload...")
 
-	// Build complete local file list with checksums first
-	fmt.Println("Building local file list with checksums...")
-	localFileMap, err := buildLocalFileMap(localDir)
+	// Build complete local file list first, checksums follow per file when needed
+	fmt.Println("Building local file list...")
+	localFileMap, scan, err := buildLocalFileMap(localDir, opts.Filter, opts.Walk)
 	if err != nil {
-		return []FileUploadStatus{{
+		return PushResult{Files: []FileUploadStatus{{
 			Path:    localDir,
 			Success: false,
 			Error:   fmt.Errorf("failed to build local file list: %v", err),
-		}}
+		}}}
 	}
 
-	fmt.Printf("Found %d local files\n", len(localFileMap))
+	if filtered := scan.filtered(); filtered != "" {
+		fmt.Printf("Found %d local files (%s)\n", len(localFileMap), filtered)
+	} else {
+		fmt.Printf("Found %d local files\n", len(localFileMap))
+	}
+	if len(scan.Symlinks) > 0 {
+		fmt.Println(formatSkippedSymlinks(scan.Symlinks, 10))
+	}
+	if len(scan.Hidden) > 0 && !opts.Cache.hiddenNoticeShown() {
+		// skipping hidden files is new, so the first push that skips some says so loudly
+		fmt.Println(formatHiddenNotice(scan.Hidden, 10))
+		opts.Cache.markHiddenNoticeShown()
+	}
+	if len(opts.Filter.Include) > 0 && len(localFileMap) == 0 {
+		fmt.Printf("WARN: no local file matches --include %s\n", strings.Join(opts.Filter.Include, ", "))
+	}
+
+	// Files above --max-file-size are reported before anything is uploaded and never compared or uploaded
+	var allResults []FileUploadStatus
+	oversize := oversizeFiles(localFileMap, opts.MaxFileSize)
+	if len(oversize) > 0 {
+		fmt.Printf("%d files are larger than --max-file-size %s:\n", len(oversize), formatByteSize(opts.MaxFileSize))
+	}
+	for _, relPath := range oversize {
+		result := oversizeResult(localFileMap[relPath], opts.MaxFileSize, opts.SkipOversize)
+		if result.Skipped {
+			fmt.Printf("⚠ Skipping: %s (%s)\n", relPath, formatByteSize(result.Size))
+		} else {
+			fmt.Printf("✗ Too large: %s (%s)\n", relPath, formatByteSize(result.Size))
+		}
+		allResults = append(allResults, result)
+		delete(localFileMap, relPath)
+	}
 
 	// Initialize local file states
 	localStates := make(map[string]*LocalFileState)
 	for relPath, localFile := range localFileMap {
 		localStates[relPath] = &LocalFileState{
-			File:    localFile,
+			File:    opts.Cache.lookup(localFile),
 			Checked: false,
 			Skip:    false,
 			Reason:  "",
@@ -374,21 +703,48 @@ func uploadDirectoryOptimized(ctx context.Context, storageZone *StorageZone, loc
 	uploadTasks := make(chan FileUploadTask, 10)
 	results := make(chan FileUploadStatus, 100)
 
+	if opts.Progress && !opts.Quiet && !opts.DryRun {
+		var totalBytes int64
+		for _, localFile := range localFileMap {
+			totalBytes += localFile.Size
+		}
+		opts.progress = newPushProgress(os.Stdout, len(localFileMap), totalBytes)
+	}
+
+	// The uploaders share --limit-rate
+	const numWorkers = 8
+	opts.limiter = newRateLimiter(opts.LimitRate)
+	opts.MinThroughput = limitedMinThroughput(opts.MinThroughput, opts.LimitRate, numWorkers)
+
+	// Running uploads outlive an interruption by the grace period, files not started by then are not attempted
+	grace := opts.InterruptGrace
+	if grace <= 0 {
+		grace = pushInterruptGrace
+	}
+	uploadCtx, cancelUploads := graceContext(ctx, grace)
+	defer cancelUploads()
+
 	// Start remote file streamer
 	go remoteFileStreamer(ctx, storageZone, remoteDir, remoteFiles)
 
-	// Start skip checker that processes streamed remote files
-	go skipChecker(localStates, remoteFiles, uploadTasks, remoteDir, results)
+	// Start skip checker that processes streamed remote files; it hands over the remote-only files when done
+	comparisons := make(chan remoteComparison, 1)
+	go func() {
+		comparisons <- skipChecker(ctx, localStates, remoteFiles, uploadTasks, remoteDir, results, opts)
+	}()
 
-	// Start 8 parallel uploader goroutines
-	const numWorkers = 8
+	// Start 8 parallel uploader goroutines, a dry run reports the tasks instead
+	upload := uploader
+	if opts.DryRun {
+		upload = planReporter
+	}
 	var uploaderWG sync.WaitGroup
 	uploaderWG.Add(numWorkers)
 
 	for range numWorkers {
 		go func() {
 			defer uploaderWG.Done()
-			uploader(ctx, storageZone, uploadTasks, results)
+			upload(ctx, uploadCtx, storageZone, opts, uploadTasks, results)
 		}()
 	}
 
@@ -399,10 +755,18 @@ func uploadDirectoryOptimized(ctx context.Context, storageZone *StorageZone, loc
 	}()
 
 	// Collect results
-	var allResults []FileUploadStatus
 	skipped := 0
 	uploaded := 0
 	failed := 0
+	for _, result := range allResults {
+		if result.Success {
+			skipped++
+		} else {
+			failed++
+		}
+	}
+	planned := 0
+	var plannedBytes int64
 
 	// We need to know when processing is done
 	done := make(chan bool, 1)
@@ -411,16 +775,34 @@ func uploadDirectoryOptimized(ctx context.Context, storageZone *StorageZone, loc
 		for result := range results {
 			allResults = append(allResults, result)
 
+			switch {
+			case result.Planned:
+				fmt.Printf("would upload (%s): %s\n", result.Reason, result.RelPath)
+				planned++
+				plannedBytes += result.Size
+				continue
+			case opts.DryRun && result.Skipped:
+				fmt.Printf("skip (%s): %s\n", result.Reason, result.RelPath)
+				skipped++
+				continue
+			}
+
+			opts.progress.fileDone(result)
+			perFileLines := opts.progress == nil && !opts.Quiet
 			if result.Success {
 				if result.Skipped {
-					fmt.Printf("⏭ Skipped: %s (%s)\n", filepath.Base(result.Path), result.Reason)
+					if perFileLines {
+						fmt.Printf("⏭ Skipped: %s (%s)\n", filepath.Base(result.Path), result.Reason)
+					}
 					skipped++
 				} else {
-					fmt.Printf("✓ Uploaded: %s\n", filepath.Base(result.Path))
+					if perFileLines {
+						fmt.Printf("✓ Uploaded: %s\n", filepath.Base(result.Path))
+					}
 					uploaded++
 				}
 			} else {
-				fmt.Printf("✗ Failed: %s (%v)\n", filepath.Base(result.Path), result.Error)
+				opts.progress.println("✗ Failed: %s (%v)", filepath.Base(result.Path), result.Error)
 				failed++
 			}
 		}
@@ -428,6 +810,28 @@ func uploadDirectoryOptimized(ctx context.Context, storageZone *StorageZone, loc
 	}()
 
 	<-done // Wait for everything to complete
+	opts.progress.stop()
+
+	// An interrupted push reports the local files it neither skipped nor uploaded
+	var notAttempted []FileUploadStatus
+	if ctx.Err() != nil {
+		notAttempted = notAttemptedResults(localStates, allResults, ctx.Err())
+		allResults = append(allResults, notAttempted...)
+	}
+
+	if opts.Verify && !opts.DryRun && ctx.Err() == nil {
+		attempts, interval := opts.VerifyAttempts, opts.VerifyInterval
+		if attempts == 0 {
+			attempts = defaultVerifyAttempts
+		}
+		if interval == 0 {
+			interval = defaultVerifyInterval
+		}
+		verified, mismatches := verifyUploads(ctx, storageZone, remoteDir, allResults, attempts, interval)
+		fmt.Printf("Verified %d uploaded files, %d mismatches\n", verified, mismatches)
+		uploaded -= mismatches
+		failed += mismatches
+	}
 
 	uploadedWord := "file"
 	if uploaded != 1 {
@@ -441,28 +845,116 @@ func uploadDirectoryOptimized(ctx context.Context, storageZone *StorageZone, loc
 	if failed != 1 {
 		failedWord = "files"
 	}
-	fmt.Printf("\n%d %s uploaded, %d %s skipped, %d %s failed\n",
-		uploaded, uploadedWord, skipped, skippedWord, failed, failedWord)
-	return allResults
+	if opts.DryRun {
+		plannedWord := "file"
+		if planned != 1 {
+			plannedWord = "files"
+		}
+		fmt.Printf("\nDry run: %d %s would be uploaded (%s), %d %s skipped",
+			planned, plannedWord, formatByteSize(plannedBytes), skipped, skippedWord)
+		if filtered := scan.filtered(); filtered != "" {
+			fmt.Printf(", %s", filtered)
+		}
+		fmt.Println()
+	} else {
+		fmt.Printf("\n%d %s uploaded, %d %s skipped, %d %s failed",
+			uploaded, uploadedWord, skipped, skippedWord, failed, failedWord)
+		if len(notAttempted) > 0 {
+			fmt.Printf(", %d %s", len(notAttempted), notAttemptedReason(ctx.Err()))
+		}
+		if filtered := scan.filtered(); filtered != "" {
+			fmt.Printf(", %s", filtered)
+		}
+		fmt.Println()
+	}
+	if ratio := opts.Cache.summary(); ratio != "" {
+		fmt.Println(ratio)
+	}
+
+	if opts.Delete && ctx.Err() != nil {
+		fmt.Println("Not deleting remote files, the push did not finish")
+	} else if opts.Delete {
+		comparison := <-comparisons
+		comparison.remoteOnly = withoutTrees(withoutPaths(comparison.remoteOnly, oversize), scan.Symlinks)
+		allResults = append(allResults, pruneRemoteFiles(ctx, storageZone, remoteDir, comparison, failed, opts)...)
+	}
+	return PushResult{Files: allResults, Scan: scan}
 }
 
-// uploader handles the actual file uploads
-func uploader(ctx context.Context, storageZone *StorageZone, uploadTasks <-chan FileUploadTask, results chan<- FileUploadStatus) {
-	for {
-		select {
-		case task, ok := <-uploadTasks:
-			if !ok {
-				return
-			}
-			err := uploadFileToStorage(ctx, storageZone, task.LocalFile.Path, task.RemotePath)
+// uploader handles the actual file uploads until uploadTasks is closed.
+// Once ctx is cancelled it starts no more uploads, the running one continues until uploadCtx ends.
+func uploader(ctx, uploadCtx context.Context, storageZone *StorageZone, opts PushOptions, uploadTasks <-chan FileUploadTask, results chan<- FileUploadStatus) {
+	for task := range uploadTasks {
+		if ctx.Err() != nil {
+			continue
+		}
+		if !opts.NoVerifyChecksum || opts.Verify {
+			task.LocalFile = withChecksum(task.LocalFile, opts)
+		}
+		err := uploadVerified(uploadCtx, storageZone, task, opts)
+
+		results <- FileUploadStatus{
+			Path:     task.LocalFile.Path,
+			RelPath:  task.LocalFile.RelPath,
+			Size:     task.LocalFile.Size,
+			Checksum: task.LocalFile.Checksum,
+			Success:  err == nil,
+			Error:    err,
+		}
+	}
+}
 
-			results <- FileUploadStatus{
-				Path:    task.LocalFile.Path,
-				Success: err == nil,
-				Error:   err,
+// uploadVerified uploads the file of a task with its checksum, repeating uploads the storage rejected for a checksum mismatch
+func uploadVerified(ctx context.Context, storageZone *StorageZone, task FileUploadTask, opts PushOptions) error {
+	upload := uploadOptions{Checksum: task.LocalFile.Checksum, ContentTypes: opts.ContentTypes, Limiter: opts.limiter}
+	if opts.NoVerifyChecksum {
+		upload.Checksum = ""
+	}
+	timeout := uploadTimeout(task.LocalFile.Size, opts.PerFileTimeout, opts.MinThroughput)
+	for attempt := 0; ; attempt++ {
+		var sent atomic.Int64
+		if opts.progress != nil {
+			upload.OnProgress = func(n int64) {
+				sent.Add(n)
+				opts.progress.addBytes(n)
 			}
-		case <-ctx.Done():
-			return
+		}
+		err := uploadWithTimeout(ctx, storageZone, task, timeout, upload)
+		if err != nil {
+			opts.progress.addBytes(-sent.Load())
+		}
+		if !errors.Is(err, errChecksumMismatch) || attempt == checksumRetries || ctx.Err() != nil {
+			return err
+		}
+		opts.progress.println("⚠ Checksum mismatch: %s, retrying", task.LocalFile.RelPath)
+	}
+}
+
+// uploadWithTimeout makes one upload attempt that is cancelled after timeout, 0 for none
+func uploadWithTimeout(ctx context.Context, storageZone *StorageZone, task FileUploadTask, timeout time.Duration, upload uploadOptions) error {
+	if timeout <= 0 {
+		return uploadFileToStorage(ctx, storageZone, task.LocalFile.Path, task.RemotePath, upload)
+	}
+	fileCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err := uploadFileToStorage(fileCtx, storageZone, task.LocalFile.Path, task.RemotePath, upload)
+	if err != nil && ctx.Err() == nil && errors.Is(fileCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("upload of %s timed out after %s", formatByteSize(task.LocalFile.Size), timeout.Round(time.Second))
+	}
+	return err
+}
+
+// planReporter reports every upload task as planned without uploading it, it replaces the uploaders in a dry run
+func planReporter(ctx, uploadCtx context.Context, storageZone *StorageZone, opts PushOptions, uploadTasks <-chan FileUploadTask, results chan<- FileUploadStatus) {
+	for task := range uploadTasks {
+		results <- FileUploadStatus{
+			Path:     task.LocalFile.Path,
+			RelPath:  task.LocalFile.RelPath,
+			Size:     task.LocalFile.Size,
+			Checksum: task.LocalFile.Checksum,
+			Success:  true,
+			Planned:  true,
+			Reason:   task.Reason,
 		}
 	}
 }
@@ -1,6 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -117,3 +125,230 @@ func TestShouldSkipUpload(t *testing.T) {
 		})
 	}
 }
+
+func TestSummarizeRemoteFiles(t *testing.T) {
+	files := []RemoteFileInfo{
+		{Name: "index.html", Size: 100},
+		{Name: "assets", IsDirectory: true, Size: 4096},
+		{Name: "app.js", Size: 2048},
+	}
+	count, total := summarizeRemoteFiles(files)
+	if count != 2 || total != 2148 {
+		t.Errorf("summarizeRemoteFiles() = (%d, %d), want (2, 2148)", count, total)
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{size: 0, want: "0 B"},
+		{size: 1023, want: "1023 B"},
+		{size: 1024, want: "1.0 KiB"},
+		{size: 1536, want: "1.5 KiB"},
+		{size: 5 * 1024 * 1024, want: "5.0 MiB"},
+		{size: 3 * 1024 * 1024 * 1024, want: "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatByteSize(tt.size); got != tt.want {
+			t.Errorf("formatByteSize(%d) = %q, want %q", tt.size, got, tt.want)
+		}
+	}
+}
+
+func newWalkFixture(t *testing.T) *StorageZone {
+	t.Helper()
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+	fb.putFile("site/index.html", []byte("home"))
+	fb.putFile("site/releases/r1/index.html", []byte("r1"))
+	fb.putFile("site/releases/r1/css/site.css", []byte("body {}"))
+	return &StorageZone{Name: "site", Password: "site-password"}
+}
+
+func TestWalkRemoteFiles(t *testing.T) {
+	storageZone := newWalkFixture(t)
+
+	var paths []string
+	err := walkRemoteFiles(context.Background(), storageZone, "releases", func(file RemoteFileInfo) error {
+		paths = append(paths, file.Path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walkRemoteFiles() error = %v", err)
+	}
+	sort.Strings(paths)
+	if got := strings.Join(paths, ","); got != "releases/r1/css/site.css,releases/r1/index.html" {
+		t.Errorf("walked paths = %s", got)
+	}
+}
+
+func TestWalkRemoteFilesStops(t *testing.T) {
+	storageZone := newWalkFixture(t)
+	stop := errors.New("stop")
+
+	calls := 0
+	err := walkRemoteFiles(context.Background(), storageZone, "", func(RemoteFileInfo) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("callback error: err = %v after %d calls, want stop after 1", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = walkRemoteFiles(ctx, storageZone, "", func(RemoteFileInfo) error {
+		t.Error("callback called after cancellation")
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled context: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRemoteFileStreamerRelativePaths(t *testing.T) {
+	storageZone := newWalkFixture(t)
+
+	remoteFiles := make(chan RemoteFileInfo, 10)
+	remoteFileStreamer(context.Background(), storageZone, "releases/r1", remoteFiles)
+
+	var paths []string
+	for file := range remoteFiles {
+		paths = append(paths, file.Path)
+	}
+	sort.Strings(paths)
+	if got := strings.Join(paths, ","); got != "css/site.css,index.html" {
+		t.Errorf("streamed paths = %s, want paths relative to the remote directory", got)
+	}
+}
+
+func TestPushDryRun(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	fb.putFile("site/index.html", []byte("home"))
+	fb.putFile("site/app.js", []byte("old"))
+	fb.putFile("site/logo.txt", []byte("aaaa"))
+
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, "app.js"), "new code")
+	writeTestFile(t, filepath.Join(localDir, "logo.txt"), "bbbb")
+	writeTestFile(t, filepath.Join(localDir, "css", "site.css"), "body {}")
+
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	got := map[string]string{}
+	for _, result := range uploadDirectoryOptimized(context.Background(), storageZone, localDir, "", PushOptions{DryRun: true}).Files {
+		switch {
+		case result.Planned:
+			got[result.RelPath] = "upload " + result.Reason
+		case result.Skipped:
+			got[result.RelPath] = "skip " + result.Reason
+		default:
+			got[result.RelPath] = fmt.Sprintf("unexpected %+v", result)
+		}
+	}
+	want := map[string]string{
+		"index.html":   "skip checksum match",
+		"app.js":       "upload changed, size mismatch",
+		"logo.txt":     "upload changed, checksum mismatch",
+		"css/site.css": "upload new",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("dry run plan = %v, want %v", got, want)
+	}
+
+	if code := run([]string{"cdn", "push", "--key", fakeAPIKey, "--zone", "site", "--from", localDir, "--dry-run"}); code != exitClean {
+		t.Errorf("dry run exit code = %d, want %d", code, exitClean)
+	}
+	for _, request := range fb.requestLog() {
+		if !strings.HasPrefix(request, "GET ") {
+			t.Errorf("dry run sent %s", request)
+		}
+	}
+}
+
+func TestNormalizeRemoteDir(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"/", "", false},
+		{"/site-a", "site-a", false},
+		{"site-a/", "site-a", false},
+		{"//site-a//docs/", "site-a/docs", false},
+		{`\site-a\docs`, "site-a/docs", false},
+		{"./site-a", "site-a", false},
+		{"/site-a/../site-b", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := normalizeRemoteDir(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeRemoteDir(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeRemoteDir(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushToPrefix(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	fb.putFile("site/site-a/index.html", []byte("home"))
+	fb.putFile("site/site-a/old.html", []byte("old"))
+	fb.putFile("site/site-b/index.html", []byte("other site"))
+	fb.putFile("site/site-b/old.html", []byte("other site"))
+
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, "css", "site.css"), "body {}")
+
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	results := uploadDirectoryOptimized(context.Background(), storageZone, localDir, "site-a", PushOptions{Delete: true, ForceDelete: true}).Files
+	for _, result := range results {
+		if result.RelPath == "index.html" && !result.Skipped {
+			t.Errorf("index.html under the prefix was not matched to its remote copy: %+v", result)
+		}
+	}
+
+	want := []string{"site/site-a/css/site.css", "site/site-a/index.html", "site/site-b/index.html", "site/site-b/old.html"}
+	if got := fb.fileNames(); !slices.Equal(got, want) {
+		t.Errorf("files after push --to site-a = %v, want %v", got, want)
+	}
+}
+
+func TestPushSetupErrorsReturnExitFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing local directory", args: []string{"--zone", "site", "--from", "/does/not/exist"}},
+		{name: "skip oversize without a limit", args: []string{"--zone", "site", "--skip-oversize"}},
+		{name: "invalid --to", args: []string{"--zone", "site", "--to", "../up"}},
+		{name: "unknown pull zone", args: []string{"--zone", "missing"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fb := newFakeBunny(t)
+			fb.addZone(1, "site")
+			localDir := t.TempDir()
+			writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+
+			args := append([]string{"cdn", "push", "--key", fakeAPIKey, "--from", localDir}, tt.args...)
+			if code := run(args); code != exitFailure {
+				t.Errorf("exit code = %d, want %d", code, exitFailure)
+			}
+			if files := fb.fileNames(); len(files) != 0 {
+				t.Errorf("stored files = %v, want none", files)
+			}
+		})
+	}
+}
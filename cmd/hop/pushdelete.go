@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// defaultMaxDelete is the number of remote files --delete removes without --force-delete
+const defaultMaxDelete = 100
+
+// pruneRemoteFiles deletes the remote files without a local counterpart after the uploads, in a dry run it only lists them.
+// Nothing is deleted when an upload failed or the delete guard refuses, the refusal is returned as a failed result.
+func pruneRemoteFiles(ctx context.Context, storageZone *StorageZone, remoteDir string, comparison remoteComparison, failedUploads int, opts PushOptions) []FileUploadStatus {
+	paths := deletePlan(comparison.remoteOnly, remoteDir, opts.Filter, opts.Walk.IncludeHidden)
+	if len(paths) == 0 {
+		fmt.Println("No remote files to delete")
+		return nil
+	}
+
+	remoteTotal := comparison.total - (len(comparison.remoteOnly) - len(paths))
+	if err := checkDeleteGuard(len(paths), remoteTotal, opts.MaxDelete, opts.ForceDelete); err != nil {
+		if opts.DryRun {
+			fmt.Printf("WARN: --delete would refuse: %v\n", err)
+			return nil
+		}
+		fmt.Printf("✗ Not deleting remote files: %v\n", err)
+		return []FileUploadStatus{{Path: "/" + strings.Trim(remoteDir, "/"), Deleted: true, Error: err}}
+	}
+
+	var results []FileUploadStatus
+	if opts.DryRun {
+		for _, relPath := range paths {
+			fmt.Printf("would delete: %s\n", relPath)
+			results = append(results, FileUploadStatus{Path: remoteFilePath(remoteDir, relPath), RelPath: relPath, Success: true, Planned: true, Deleted: true})
+		}
+		fmt.Printf("Dry run: %d remote files would be deleted\n", len(paths))
+		return results
+	}
+	if failedUploads > 0 {
+		fmt.Printf("Not deleting %d remote files because some uploads failed\n", len(paths))
+		return nil
+	}
+
+	deleted, failed := 0, 0
+	for _, relPath := range paths {
+		remotePath := remoteFilePath(remoteDir, relPath)
+		err := deleteRemotePath(ctx, storageZone, remotePath)
+		if err != nil {
+			fmt.Printf("✗ Failed to delete: %s (%v)\n", relPath, err)
+			failed++
+		} else {
+			fmt.Printf("✓ Deleted: %s\n", relPath)
+			deleted++
+		}
+		results = append(results, FileUploadStatus{Path: remotePath, RelPath: relPath, Success: err == nil, Error: err, Deleted: true})
+	}
+	fmt.Printf("%d remote files deleted, %d deletions failed\n", deleted, failed)
+	return results
+}
+
+// Side effect free functions
+
+// deletePlan returns the sorted paths, relative to remoteDir, that --delete removes.
+// A push to the storage root never prunes releases/, which holds the uploaded releases; files outside the filter and,
+// without includeHidden, hidden files stay on the remote.
+func deletePlan(remoteOnly []RemoteFileInfo, remoteDir string, filter PathFilter, includeHidden bool) []string {
+	var paths []string
+	for _, file := range remoteOnly {
+		if strings.Trim(remoteDir, "/") == "" && strings.HasPrefix(file.Path, releasesDir+"/") {
+			continue
+		}
+		if !filter.keeps(file.Path) || (!includeHidden && hasHiddenSegment(file.Path)) {
+			continue
+		}
+		paths = append(paths, file.Path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// checkDeleteGuard refuses to delete more than maxDelete files or more than half of the remote files unless forced,
+// an empty or wrong local directory would otherwise wipe the zone
+func checkDeleteGuard(deletes, remoteTotal, maxDelete int, force bool) error {
+	switch {
+	case force:
+		return nil
+	case deletes > maxDelete:
+		return fmt.Errorf("refusing to delete %d remote files, more than --max-delete %d (pass --force-delete to delete them anyway)", deletes, maxDelete)
+	case deletes*2 > remoteTotal:
+		return fmt.Errorf("refusing to delete %d of %d remote files, more than half of them (pass --force-delete to delete them anyway)", deletes, remoteTotal)
+	}
+	return nil
+}
+
+// remoteFilePath returns the path of a file relative to remoteDir as a path relative to the storage root
+func remoteFilePath(remoteDir, relPath string) string {
+	return strings.TrimPrefix(path.Join(strings.Trim(remoteDir, "/"), relPath), "/")
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDeletePlan(t *testing.T) {
+	remoteOnly := []RemoteFileInfo{{Path: "old.html"}, {Path: "releases/r1/index.html"}, {Path: "css/old.css"}}
+
+	if got := deletePlan(remoteOnly, "", PathFilter{}, false); !slices.Equal(got, []string{"css/old.css", "old.html"}) {
+		t.Errorf("deletePlan() at the root = %v, want releases/ left alone", got)
+	}
+	if got := deletePlan(remoteOnly, "site-a", PathFilter{}, false); !slices.Equal(got, []string{"css/old.css", "old.html", "releases/r1/index.html"}) {
+		t.Errorf("deletePlan() in a subdirectory = %v", got)
+	}
+	if got := deletePlan(remoteOnly, "site-a", PathFilter{Exclude: []string{"css/**"}}, false); !slices.Equal(got, []string{"old.html", "releases/r1/index.html"}) {
+		t.Errorf("deletePlan() with --exclude = %v, want excluded files left alone", got)
+	}
+	if got := deletePlan(remoteOnly, "site-a", PathFilter{Include: []string{"*.html"}}, false); !slices.Equal(got, []string{"old.html", "releases/r1/index.html"}) {
+		t.Errorf("deletePlan() with --include = %v, want only included files pruned", got)
+	}
+
+	hidden := []RemoteFileInfo{{Path: "old.html"}, {Path: ".htaccess"}, {Path: ".git/config"}, {Path: "docs/.nojekyll"}, {Path: ".well-known/old.txt"}}
+	if got := deletePlan(hidden, "", PathFilter{}, false); !slices.Equal(got, []string{".well-known/old.txt", "old.html"}) {
+		t.Errorf("deletePlan() = %v, want hidden files left alone", got)
+	}
+	if got := deletePlan(hidden, "", PathFilter{}, true); !slices.Equal(got, []string{".git/config", ".htaccess", ".well-known/old.txt", "docs/.nojekyll", "old.html"}) {
+		t.Errorf("deletePlan() with --include-hidden = %v, want hidden files pruned", got)
+	}
+}
+
+func TestCheckDeleteGuard(t *testing.T) {
+	tests := []struct {
+		name                            string
+		deletes, remoteTotal, maxDelete int
+		force                           bool
+		wantErr                         string
+	}{
+		{name: "few deletes", deletes: 2, remoteTotal: 10, maxDelete: 100},
+		{name: "exactly half", deletes: 5, remoteTotal: 10, maxDelete: 100},
+		{name: "more than half", deletes: 6, remoteTotal: 10, maxDelete: 100, wantErr: "more than half"},
+		{name: "more than --max-delete", deletes: 101, remoteTotal: 1000, maxDelete: 100, wantErr: "--max-delete 100"},
+		{name: "forced", deletes: 10, remoteTotal: 10, maxDelete: 1, force: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDeleteGuard(tt.deletes, tt.remoteTotal, tt.maxDelete, tt.force)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkDeleteGuard() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkDeleteGuard() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPushDelete(t *testing.T) {
+	newFixture := func(t *testing.T) (*fakeBunny, string) {
+		fb := newFakeBunny(t)
+		fb.addZone(1, "site")
+		for _, name := range []string{"index.html", "a.html", "b.html", "gone.html", "releases/r1/index.html"} {
+			fb.putFile("site/"+name, []byte(name))
+		}
+		localDir := t.TempDir()
+		for _, name := range []string{"index.html", "a.html", "b.html"} {
+			writeTestFile(t, filepath.Join(localDir, name), name)
+		}
+		return fb, localDir
+	}
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	ctx := context.Background()
+
+	t.Run("dry run", func(t *testing.T) {
+		fb, localDir := newFixture(t)
+		results := uploadDirectoryOptimized(ctx, storageZone, localDir, "", PushOptions{DryRun: true, Delete: true, MaxDelete: defaultMaxDelete}).Files
+		var planned []string
+		for _, result := range results {
+			if result.Deleted && result.Planned {
+				planned = append(planned, result.RelPath)
+			}
+		}
+		if !slices.Equal(planned, []string{"gone.html"}) {
+			t.Errorf("planned deletes = %v, want gone.html", planned)
+		}
+		if len(fb.fileNames()) != 5 {
+			t.Errorf("dry run changed storage: %v", fb.fileNames())
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		fb, localDir := newFixture(t)
+		uploadDirectoryOptimized(ctx, storageZone, localDir, "", PushOptions{Delete: true, MaxDelete: defaultMaxDelete})
+		want := []string{"site/a.html", "site/b.html", "site/index.html", "site/releases/r1/index.html"}
+		if got := fb.fileNames(); !slices.Equal(got, want) {
+			t.Errorf("files after --delete = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("guard", func(t *testing.T) {
+		fb, _ := newFixture(t)
+		results := uploadDirectoryOptimized(ctx, storageZone, t.TempDir(), "", PushOptions{Delete: true, MaxDelete: defaultMaxDelete}).Files
+		if len(results) != 1 || results[0].Success || !results[0].Deleted {
+			t.Errorf("results = %+v, want one refused delete", results)
+		}
+		if len(fb.fileNames()) != 5 {
+			t.Errorf("refused delete changed storage: %v", fb.fileNames())
+		}
+	})
+}
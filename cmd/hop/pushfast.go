@@ -0,0 +1,55 @@
+package main
+
+import "runtime"
+
+// hashWorkerCount returns how many goroutines hash local files: workers, or GOMAXPROCS when it is 0
+func hashWorkerCount(workers int) int {
+	if workers > 0 {
+		return workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// withChecksum returns the file with its SHA256 computed and recorded in the checksum cache, a file that was hashed before or came from the cache is returned as is.
+// A file that cannot be read keeps an empty checksum, so it is compared by size and uploaded without the Checksum header.
+func withChecksum(file LocalFileInfo, opts PushOptions) LocalFileInfo {
+	if file.hashed {
+		return file
+	}
+	checksum, err := calculateFileChecksum(file.Path)
+	if err != nil {
+		opts.progress.println("⚠ Warning: Could not calculate checksum for %s: %v", file.RelPath, err)
+	}
+	file.Checksum = checksum
+	file.hashed = true
+	opts.Cache.store(file)
+	return file
+}
+
+// compareWithRemote decides whether a local file that exists remotely is skipped, and why it is skipped or uploaded.
+// A size mismatch uploads the file unhashed; with --fast a file of the same size that was not modified since its upload is skipped unhashed,
+// every other file is hashed and compared by checksum. It returns the local file with its checksum once computed.
+func compareWithRemote(localFile LocalFileInfo, remoteFile RemoteFileInfo, opts PushOptions) (LocalFileInfo, bool, string) {
+	if localFile.Size != remoteFile.Size {
+		return localFile, false, uploadReason(localFile, remoteFile)
+	}
+	if opts.Fast && unchangedSinceUpload(localFile, remoteFile) {
+		return localFile, true, "size and mtime match"
+	}
+	localFile = withChecksum(localFile, opts)
+	if skip, reason := shouldSkipUpload(localFile, remoteFile); skip {
+		return localFile, true, reason
+	}
+	return localFile, false, uploadReason(localFile, remoteFile)
+}
+
+// Side effect free functions
+
+// unchangedSinceUpload reports whether a local file was last modified no later than its remote copy was uploaded.
+// It is inconclusive, false, when either time is unknown; LastChanged is the upload time in UTC, not the local modification time.
+func unchangedSinceUpload(localFile LocalFileInfo, remoteFile RemoteFileInfo) bool {
+	if localFile.ModTime.IsZero() || remoteFile.LastModified.IsZero() {
+		return false
+	}
+	return !localFile.ModTime.After(remoteFile.LastModified.Time)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnchangedSinceUpload(t *testing.T) {
+	uploadedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		modTime time.Time
+		remote  time.Time
+		want    bool
+	}{
+		{"modified before upload", uploadedAt.Add(-time.Hour), uploadedAt, true},
+		{"modified at upload", uploadedAt, uploadedAt, true},
+		{"modified after upload", uploadedAt.Add(time.Second), uploadedAt, false},
+		{"unknown upload time", uploadedAt.Add(-time.Hour), time.Time{}, false},
+		{"unknown modification time", time.Time{}, uploadedAt, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := LocalFileInfo{Size: 4, ModTime: tt.modTime}
+			remote := RemoteFileInfo{Size: 4, LastModified: BunnyTime{tt.remote}}
+			if got := unchangedSinceUpload(local, remote); got != tt.want {
+				t.Errorf("unchangedSinceUpload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareWithRemote(t *testing.T) {
+	localDir := t.TempDir()
+	path := filepath.Join(localDir, "a.html")
+	writeTestFile(t, path, "home")
+	checksum, err := calculateFileChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploadedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := LocalFileInfo{Path: path, RelPath: "a.html", Size: 4, ModTime: uploadedAt.Add(-time.Hour)}
+	touched := LocalFileInfo{Path: path, RelPath: "a.html", Size: 4, ModTime: uploadedAt.Add(time.Hour)}
+
+	tests := []struct {
+		name       string
+		local      LocalFileInfo
+		remote     RemoteFileInfo
+		fast       bool
+		wantSkip   bool
+		wantReason string
+		wantHashed bool
+	}{
+		{"size mismatch is not hashed", old, RemoteFileInfo{Size: 5, Checksum: checksum}, false, false, "changed, size mismatch", false},
+		{"checksum match", old, RemoteFileInfo{Size: 4, Checksum: checksum, LastModified: BunnyTime{uploadedAt}}, false, true, "checksum match", true},
+		{"checksum mismatch", old, RemoteFileInfo{Size: 4, Checksum: "FFFF", LastModified: BunnyTime{uploadedAt}}, false, false, "changed, checksum mismatch", true},
+		{"fast skips unmodified file unhashed", old, RemoteFileInfo{Size: 4, Checksum: "FFFF", LastModified: BunnyTime{uploadedAt}}, true, true, "size and mtime match", false},
+		{"fast hashes modified file", touched, RemoteFileInfo{Size: 4, Checksum: checksum, LastModified: BunnyTime{uploadedAt}}, true, true, "checksum match", true},
+		{"fast hashes without upload time", old, RemoteFileInfo{Size: 4, Checksum: "FFFF"}, true, false, "changed, checksum mismatch", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, skip, reason := compareWithRemote(tt.local, tt.remote, PushOptions{Fast: tt.fast})
+			if skip != tt.wantSkip || reason != tt.wantReason {
+				t.Errorf("compareWithRemote() = %v, %q, want %v, %q", skip, reason, tt.wantSkip, tt.wantReason)
+			}
+			if hashed := file.Checksum != ""; hashed != tt.wantHashed {
+				t.Errorf("file hashed = %v, want %v", hashed, tt.wantHashed)
+			}
+		})
+	}
+}
+
+func TestPushFast(t *testing.T) {
+	newFixture := func(t *testing.T) (*fakeBunny, string) {
+		fb := newFakeBunny(t)
+		fb.addZone(1, "site")
+		fb.putFile("site/same.html", []byte("same"))
+		fb.putFile("site/edited.html", []byte("old1")) // same size as the local edit
+
+		localDir := t.TempDir()
+		writeTestFile(t, filepath.Join(localDir, "same.html"), "same")
+		writeTestFile(t, filepath.Join(localDir, "edited.html"), "new1")
+		return fb, localDir
+	}
+	setModTime := func(t *testing.T, path string, modTime time.Time) {
+		t.Helper()
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	push := func(localDir string, fast bool) map[string]FileUploadStatus {
+		storageZone := &StorageZone{Name: "site", Password: "site-password"}
+		byPath := make(map[string]FileUploadStatus)
+		for _, result := range uploadDirectoryOptimized(context.Background(), storageZone, localDir, "", PushOptions{Fast: fast}).Files {
+			byPath[result.RelPath] = result
+		}
+		return byPath
+	}
+
+	t.Run("touched but identical file is skipped by checksum", func(t *testing.T) {
+		fb, localDir := newFixture(t)
+		setModTime(t, filepath.Join(localDir, "same.html"), fb.clock.Add(time.Hour))
+		results := push(localDir, false)
+		if got := results["same.html"]; !got.Skipped || got.Reason != "checksum match" {
+			t.Errorf("same.html = %+v, want skipped by checksum", got)
+		}
+		if got := results["edited.html"]; got.Skipped || !got.Success {
+			t.Errorf("edited.html = %+v, want uploaded", got)
+		}
+	})
+
+	t.Run("fast uploads a file modified after its upload", func(t *testing.T) {
+		fb, localDir := newFixture(t)
+		setModTime(t, filepath.Join(localDir, "same.html"), fb.clock.Add(-time.Hour))
+		setModTime(t, filepath.Join(localDir, "edited.html"), fb.clock.Add(time.Hour))
+		results := push(localDir, true)
+		if got := results["same.html"]; !got.Skipped || got.Reason != "size and mtime match" || got.Checksum != "" {
+			t.Errorf("same.html = %+v, want skipped unhashed", got)
+		}
+		if got := results["edited.html"]; got.Skipped || !got.Success || got.Checksum == "" {
+			t.Errorf("edited.html = %+v, want uploaded with its checksum", got)
+		}
+		if got := string(fb.files["site/edited.html"].content); got != "new1" {
+			t.Errorf("remote edited.html = %q, want the local edit", got)
+		}
+	})
+}
+
+func TestSkipCheckerHashWorkers(t *testing.T) {
+	// A tree of files where every third remote copy differs, every fifth has another size and every seventh is missing
+	localDir := t.TempDir()
+	const fileCount = 300
+	var remote []RemoteFileInfo
+	for i := range fileCount {
+		relPath := fmt.Sprintf("dir%d/file%d.txt", i%10, i)
+		content := fmt.Sprintf("content of file %d %s", i, string(make([]byte, 4096)))
+		writeTestFile(t, filepath.Join(localDir, filepath.FromSlash(relPath)), content)
+		checksum, err := calculateFileChecksum(filepath.Join(localDir, filepath.FromSlash(relPath)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		remoteFile := RemoteFileInfo{Path: relPath, Size: int64(len(content)), Checksum: checksum}
+		switch {
+		case i%7 == 0:
+			continue
+		case i%5 == 0:
+			remoteFile.Size++
+		case i%3 == 0:
+			remoteFile.Checksum = "FFFF"
+		}
+		remote = append(remote, remoteFile)
+	}
+	localFiles, _, err := buildLocalFileMap(localDir, PathFilter{}, LocalWalkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// compare runs skipChecker with the given number of hash workers and returns every file's outcome and checksum
+	compare := func(workers int) (map[string]string, time.Duration) {
+		localStates := make(map[string]*LocalFileState)
+		for relPath, file := range localFiles {
+			localStates[relPath] = &LocalFileState{File: file}
+		}
+		remoteFiles := make(chan RemoteFileInfo, len(remote))
+		for _, file := range remote {
+			remoteFiles <- file
+		}
+		close(remoteFiles)
+		uploadTasks := make(chan FileUploadTask, fileCount)
+		results := make(chan FileUploadStatus, fileCount)
+
+		start := time.Now()
+		skipChecker(context.Background(), localStates, remoteFiles, uploadTasks, "", results, PushOptions{HashWorkers: workers})
+		elapsed := time.Since(start)
+		close(results)
+
+		outcomes := make(map[string]string)
+		for task := range uploadTasks {
+			outcomes[task.LocalFile.RelPath] = "upload (" + task.Reason + ") " + task.LocalFile.Checksum
+		}
+		for result := range results {
+			outcomes[result.RelPath] = "skip (" + result.Reason + ") " + result.Checksum
+		}
+		return outcomes, elapsed
+	}
+
+	serial, serialTime := compare(1)
+	pooled, pooledTime := compare(8)
+	t.Logf("1 hash worker: %s, 8 hash workers: %s", serialTime, pooledTime)
+	if len(serial) != fileCount {
+		t.Fatalf("serial comparison decided %d files, want %d", len(serial), fileCount)
+	}
+	if !reflect.DeepEqual(serial, pooled) {
+		for relPath, outcome := range serial {
+			if pooled[relPath] != outcome {
+				t.Errorf("%s: serial %q, pooled %q", relPath, outcome, pooled[relPath])
+			}
+		}
+	}
+}
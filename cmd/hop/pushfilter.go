@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathFilter selects the local files a push considers by their path relative to --from
+type PathFilter struct {
+	Include []string // globs of the only files pushed, empty for all files
+	Exclude []string // globs of files never hashed or uploaded
+}
+
+// Side effect free functions
+
+// newPathFilter returns the filter of the --include and --exclude globs, rejecting malformed patterns
+func newPathFilter(include, exclude []string) (PathFilter, error) {
+	if err := validateGlobs("--include", include); err != nil {
+		return PathFilter{}, err
+	}
+	if err := validateGlobs("--exclude", exclude); err != nil {
+		return PathFilter{}, err
+	}
+	return PathFilter{Include: include, Exclude: exclude}, nil
+}
+
+// validateGlobs rejects patterns path.Match cannot parse, naming the flag they came from
+func validateGlobs(flag string, patterns []string) error {
+	for _, pattern := range patterns {
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid %s pattern '%s': %v", flag, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// includes reports whether a relative path matches one of the --include globs, every path does without them
+func (f PathFilter) includes(relPath string) bool {
+	return len(f.Include) == 0 || matchesAnyGlob(f.Include, relPath)
+}
+
+// excludes reports whether a relative path matches one of the --exclude globs
+func (f PathFilter) excludes(relPath string) bool {
+	return matchesAnyGlob(f.Exclude, relPath)
+}
+
+// keeps reports whether a push considers a relative path: included and not excluded
+func (f PathFilter) keeps(relPath string) bool {
+	return f.includes(relPath) && !f.excludes(relPath)
+}
+
+// selectPaths returns the relative paths the filter keeps
+func (f PathFilter) selectPaths(relPaths []string) []string {
+	var selected []string
+	for _, relPath := range relPaths {
+		if f.keeps(relPath) {
+			selected = append(selected, relPath)
+		}
+	}
+	return selected
+}
+
+// matchesAnyGlob reports whether a relative path matches one of the globs
+func matchesAnyGlob(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if matchPathGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathGlob reports whether a relative path, separated by '/' or '\', matches a doublestar glob.
+// A "**" segment matches any number of path segments, other segments match like path.Match.
+// Patterns without a slash match the file name at any depth, so ".DS_Store" and "*.map" need no "**/".
+func matchPathGlob(pattern, relPath string) bool {
+	relPath = strings.ReplaceAll(relPath, "\\", "/")
+	if !strings.Contains(pattern, "/") {
+		if pattern == "**" {
+			return true
+		}
+		matched, _ := path.Match(pattern, path.Base(relPath))
+		return matched
+	}
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/"))
+}
+
+// matchGlobSegments matches path segments against pattern segments, trying every split for "**"
+func matchGlobSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchGlobSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		relPath string
+		want    bool
+	}{
+		{"**/*.map", "app.js.map", true},
+		{"**/*.map", "assets/js/app.js.map", true},
+		{"**/*.map", `assets\js\app.js.map`, true},
+		{"**/*.map", "assets/js/app.js", false},
+		{"*.map", "assets/js/app.js.map", true},
+		{".DS_Store", ".DS_Store", true},
+		{".DS_Store", "images/.DS_Store", true},
+		{".DS_Store", `images\.DS_Store`, true},
+		{".DS_Store", "images/DS_Store", false},
+		{"node_modules/**", "node_modules/lib/index.js", true},
+		{"node_modules/**", `node_modules\lib\index.js`, true},
+		{"node_modules/**", "src/node_modules/lib/index.js", false},
+		{"**/node_modules/**", "src/node_modules/lib/index.js", true},
+		{"drafts/*.html", "drafts/post.html", true},
+		{"drafts/*.html", "drafts/2024/post.html", false},
+		{"drafts/*.html", `drafts\post.html`, true},
+		{"assets/**/*.psd", "assets/logo.psd", true},
+		{"assets/**/*.psd", "assets/brand/raw/logo.psd", true},
+		{"**", "any/file.txt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.relPath, func(t *testing.T) {
+			if got := matchPathGlob(tt.pattern, tt.relPath); got != tt.want {
+				t.Errorf("matchPathGlob(%q, %q) = %v, want %v", tt.pattern, tt.relPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPathFilterRejectsMalformedPattern(t *testing.T) {
+	if _, err := newPathFilter(nil, []string{"assets/[a-/*.js"}); err == nil {
+		t.Error("newPathFilter() accepted a malformed --exclude pattern")
+	}
+	if _, err := newPathFilter([]string{"[images/**"}, nil); err == nil || !strings.Contains(err.Error(), "--include") {
+		t.Errorf("newPathFilter() error = %v, want a malformed --include pattern", err)
+	}
+	if _, err := newPathFilter([]string{"images/**"}, []string{"**/*.map", ".DS_Store"}); err != nil {
+		t.Errorf("newPathFilter() error = %v", err)
+	}
+}
+
+func TestBuildLocalFileMapExcludes(t *testing.T) {
+	localDir := t.TempDir()
+	for _, name := range []string{"index.html", "app.js", "app.js.map", ".DS_Store", "node_modules/lib/index.js"} {
+		writeTestFile(t, filepath.Join(localDir, filepath.FromSlash(name)), name)
+	}
+
+	filter, err := newPathFilter(nil, []string{"**/*.map", ".DS_Store", "node_modules/**"})
+	if err != nil {
+		t.Fatalf("newPathFilter() error = %v", err)
+	}
+	localFiles, stats, err := buildLocalFileMap(localDir, filter, LocalWalkOptions{IncludeHidden: true})
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+	if stats.Excluded != 3 {
+		t.Errorf("Excluded = %d, want 3", stats.Excluded)
+	}
+	if len(localFiles) != 2 {
+		t.Errorf("local files = %v, want index.html and app.js", localFiles)
+	}
+	if _, exists := localFiles["app.js.map"]; exists {
+		t.Error("buildLocalFileMap() kept an excluded file")
+	}
+}
+
+func TestPathFilterInclude(t *testing.T) {
+	filter := PathFilter{Include: []string{"images/**", "*.css"}, Exclude: []string{"**/*.psd"}}
+	tests := []struct {
+		relPath string
+		want    bool
+	}{
+		{"images/logo.png", true},
+		{`images\icons\home.svg`, true},
+		{"images/raw/logo.psd", false},
+		{"css/site.css", true},
+		{"index.html", false},
+		{"js/app.js", false},
+	}
+
+	for _, tt := range tests {
+		if got := filter.keeps(tt.relPath); got != tt.want {
+			t.Errorf("keeps(%q) = %v, want %v", tt.relPath, got, tt.want)
+		}
+	}
+	if !(PathFilter{}).keeps("index.html") {
+		t.Error("an empty filter left out a file")
+	}
+}
+
+func TestBuildLocalFileMapIncludes(t *testing.T) {
+	localDir := t.TempDir()
+	for _, name := range []string{"index.html", "images/logo.png", "images/logo.png.map", "css/site.css"} {
+		writeTestFile(t, filepath.Join(localDir, filepath.FromSlash(name)), name)
+	}
+
+	localFiles, stats, err := buildLocalFileMap(localDir, PathFilter{Include: []string{"images/**"}, Exclude: []string{"*.map"}}, LocalWalkOptions{})
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+	if stats.NotIncluded != 2 || stats.Excluded != 1 {
+		t.Errorf("stats = %+v, want 2 not included and 1 excluded", stats)
+	}
+	if got := stats.filtered(); got != "2 not matching --include, 1 excluded" {
+		t.Errorf("filtered() = %q", got)
+	}
+	if got := slices.Collect(maps.Keys(localFiles)); !slices.Equal(got, []string{"images/logo.png"}) {
+		t.Errorf("local files = %v, want only images/logo.png", got)
+	}
+}
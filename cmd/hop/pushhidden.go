@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hiddenNames are file and directory names a push skips besides dotfiles, without --include-hidden
+var hiddenNames = []string{".git", ".svn", ".hg", "Thumbs.db"}
+
+// visibleDotNames are dot-directories websites serve on purpose, they are uploaded like any other directory
+var visibleDotNames = []string{".well-known"}
+
+// Side effect free functions
+
+// hasHiddenSegment reports whether a push skips relPath by default because it or one of its directories is hidden
+func hasHiddenSegment(relPath string) bool {
+	for _, segment := range strings.Split(relPath, "/") {
+		if isHiddenName(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHiddenName reports whether a push skips a file or directory of this name by default: dotfiles, VCS directories and Thumbs.db
+func isHiddenName(name string) bool {
+	for _, visible := range visibleDotNames {
+		if name == visible {
+			return false
+		}
+	}
+	for _, hidden := range hiddenNames {
+		if strings.EqualFold(name, hidden) {
+			return true
+		}
+	}
+	return strings.HasPrefix(name, ".")
+}
+
+// formatHiddenNotice renders the notice about hidden entries a push skipped, listing at most limit of them
+func formatHiddenNotice(hidden []string, limit int) string {
+	shown := hidden
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+	list := strings.Join(shown, ", ")
+	if len(hidden) > limit {
+		list += fmt.Sprintf(" and %d more", len(hidden)-limit)
+	}
+	return fmt.Sprintf("NOTICE: skipped %d hidden files and directories: %s\n"+
+		"        Dotfiles, .git, .svn, .hg and Thumbs.db are no longer uploaded by default, pass --include-hidden to upload them",
+		len(hidden), list)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIsHiddenName(t *testing.T) {
+	tests := map[string]bool{
+		".git":         true,
+		".DS_Store":    true,
+		".index.swp":   true,
+		".env":         true,
+		"Thumbs.db":    true,
+		"thumbs.db":    true,
+		".well-known":  false,
+		"index.html":   false,
+		"svn":          false,
+		"file.git":     false,
+		"..hidden.txt": true,
+	}
+	for name, want := range tests {
+		if got := isHiddenName(name); got != want {
+			t.Errorf("isHiddenName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestBuildLocalFileMapSkipsHidden(t *testing.T) {
+	localDir := t.TempDir()
+	for _, name := range []string{"index.html", ".DS_Store", ".git/config", ".git/objects/ab/cdef", "img/Thumbs.db", "docs/.index.html.swp", ".well-known/security.txt", checksumCacheName} {
+		writeTestFile(t, filepath.Join(localDir, filepath.FromSlash(name)), name)
+	}
+
+	localFiles, stats, err := buildLocalFileMap(localDir, PathFilter{}, LocalWalkOptions{})
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+	var relPaths []string
+	for relPath := range localFiles {
+		relPaths = append(relPaths, relPath)
+	}
+	slices.Sort(relPaths)
+	if want := []string{".well-known/security.txt", "index.html"}; !slices.Equal(relPaths, want) {
+		t.Errorf("local files = %v, want %v", relPaths, want)
+	}
+	if want := []string{".DS_Store", ".git", "docs/.index.html.swp", "img/Thumbs.db"}; !slices.Equal(stats.Hidden, want) {
+		t.Errorf("hidden = %v, want %v", stats.Hidden, want)
+	}
+	if got := stats.filtered(); got != "4 hidden skipped" {
+		t.Errorf("filtered() = %q", got)
+	}
+
+	localFiles, stats, err = buildLocalFileMap(localDir, PathFilter{}, LocalWalkOptions{IncludeHidden: true})
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+	if len(localFiles) != 7 || len(stats.Hidden) != 0 {
+		t.Errorf("with --include-hidden got %d files and %v hidden, want 7 and none", len(localFiles), stats.Hidden)
+	}
+}
+
+func TestHiddenNoticeShownOnce(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, ".git", "HEAD"), "ref: refs/heads/main")
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	cachePath := filepath.Join(localDir, checksumCacheName)
+
+	cache, _ := loadChecksumCache(cachePath)
+	if cache.hiddenNoticeShown() {
+		t.Fatal("a new cache has the hidden notice shown")
+	}
+	push := uploadDirectoryOptimized(context.Background(), storageZone, localDir, "", PushOptions{Cache: cache})
+	if !slices.Equal(push.Scan.Hidden, []string{".git"}) {
+		t.Errorf("hidden = %v, want .git", push.Scan.Hidden)
+	}
+	if !cache.hiddenNoticeShown() {
+		t.Error("the push did not record the hidden notice")
+	}
+	if err := cache.save(); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := loadChecksumCache(cachePath); !reloaded.hiddenNoticeShown() {
+		t.Error("the hidden notice is shown again after reloading the cache")
+	}
+	if slices.Contains(fb.fileNames(), "site/.git/HEAD") {
+		t.Error(".git was uploaded")
+	}
+}
+
+func TestFormatHiddenNotice(t *testing.T) {
+	got := formatHiddenNotice([]string{".git", ".DS_Store", "Thumbs.db"}, 2)
+	if !strings.HasPrefix(got, "NOTICE: skipped 3 hidden files and directories: .git, .DS_Store and 1 more\n") || !strings.Contains(got, "--include-hidden") {
+		t.Errorf("formatHiddenNotice() = %q", got)
+	}
+}
+
+func TestSensitiveScanSkipsHidden(t *testing.T) {
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, ".env"), "SECRET=1")
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+
+	for _, includeHidden := range []bool{false, true} {
+		sensitive, err := scanSensitiveFiles(localDir, nil, PathFilter{}, LocalWalkOptions{IncludeHidden: includeHidden})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := includeHidden; (len(sensitive) == 1) != want {
+			t.Errorf("with --include-hidden %v sensitive files = %v", includeHidden, sensitive)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// exitInterrupted is the exit code of cdn push when Ctrl-C or SIGTERM stopped it, like a shell reports SIGINT
+const exitInterrupted = 130
+
+// pushInterruptGrace is how long running uploads may finish after a push was interrupted before they are aborted
+const pushInterruptGrace = 5 * time.Second
+
+// watchInterrupts returns a context that is cancelled by the first signal on signals; a second signal calls forceExit.
+// The returned stop function ends the watch, signals arriving after it are ignored.
+func watchInterrupts(parent context.Context, signals <-chan os.Signal, forceExit func()) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stopped := make(chan struct{})
+	// receive waits for a signal and reports false once the watch was stopped, even when a signal is pending too
+	receive := func() bool {
+		select {
+		case <-signals:
+		case <-stopped:
+			return false
+		}
+		select {
+		case <-stopped:
+			return false
+		default:
+			return true
+		}
+	}
+	go func() {
+		if !receive() {
+			return
+		}
+		fmt.Fprintln(os.Stderr, "\nInterrupted, waiting for running uploads to finish, press Ctrl-C again to quit immediately")
+		cancel()
+		if receive() {
+			forceExit()
+		}
+	}()
+	return ctx, func() {
+		close(stopped)
+		cancel()
+	}
+}
+
+// graceContext returns a context for work that may continue for grace after ctx ends, then it is cancelled too.
+// It keeps the values of ctx but not its deadline.
+func graceContext(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	graceCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		time.AfterFunc(grace, cancel)
+	})
+	return graceCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Side effect free functions
+
+// notAttemptedReason explains why the files left over by an interrupted push were not uploaded, err is the error of its context
+func notAttemptedReason(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "not attempted (timed out)"
+	}
+	return "not attempted (interrupted)"
+}
+
+// notAttemptedResults returns a sorted result for every local file without one in results, after the push was stopped by err
+func notAttemptedResults(localStates map[string]*LocalFileState, results []FileUploadStatus, err error) []FileUploadStatus {
+	reported := make(map[string]bool, len(results))
+	for _, result := range results {
+		if !result.Deleted {
+			reported[result.RelPath] = true
+		}
+	}
+	reason := notAttemptedReason(err)
+	var remaining []FileUploadStatus
+	for relPath, state := range localStates {
+		if reported[relPath] {
+			continue
+		}
+		remaining = append(remaining, FileUploadStatus{
+			Path:        state.File.Path,
+			RelPath:     relPath,
+			Size:        state.File.Size,
+			Checksum:    state.File.Checksum,
+			Error:       errors.New(reason),
+			Reason:      reason,
+			Interrupted: true,
+		})
+	}
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].RelPath < remaining[j].RelPath
+	})
+	return remaining
+}
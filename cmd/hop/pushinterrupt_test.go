@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWatchInterrupts(t *testing.T) {
+	signals := make(chan os.Signal, 2)
+	forced := make(chan struct{})
+	ctx, stop := watchInterrupts(context.Background(), signals, func() { close(forced) })
+	defer stop()
+
+	signals <- os.Interrupt
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("first signal did not cancel the context")
+	}
+	select {
+	case <-forced:
+		t.Fatal("first signal forced an exit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	signals <- syscall.SIGTERM
+	select {
+	case <-forced:
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not force an exit")
+	}
+}
+
+func TestWatchInterruptsStop(t *testing.T) {
+	signals := make(chan os.Signal, 2)
+	ctx, stop := watchInterrupts(context.Background(), signals, func() { t.Error("forced an exit after stop") })
+	if ctx.Err() != nil {
+		t.Fatalf("context cancelled before any signal: %v", ctx.Err())
+	}
+	stop()
+	signals <- os.Interrupt
+	signals <- os.Interrupt
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestGraceContext(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "debug"))
+	graceCtx, stop := graceContext(parent, 50*time.Millisecond)
+	defer stop()
+
+	if graceCtx.Value(key{}) != "debug" {
+		t.Errorf("grace context lost the values of its parent")
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if graceCtx.Err() != nil {
+		t.Fatalf("grace context ended with its parent: %v", graceCtx.Err())
+	}
+	select {
+	case <-graceCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("grace context did not end after the grace period")
+	}
+}
+
+func TestNotAttemptedResults(t *testing.T) {
+	localStates := map[string]*LocalFileState{
+		"index.html": {File: LocalFileInfo{Path: "/dist/index.html", RelPath: "index.html", Size: 4}},
+		"b.css":      {File: LocalFileInfo{Path: "/dist/b.css", RelPath: "b.css", Size: 2}},
+		"a.js":       {File: LocalFileInfo{Path: "/dist/a.js", RelPath: "a.js", Size: 3, Checksum: "AA"}},
+	}
+	tests := []struct {
+		name    string
+		results []FileUploadStatus
+		err     error
+		want    []string
+		reason  string
+	}{
+		{
+			name:    "interrupted",
+			results: []FileUploadStatus{{RelPath: "index.html", Success: true}},
+			err:     context.Canceled,
+			want:    []string{"a.js", "b.css"},
+			reason:  "not attempted (interrupted)",
+		},
+		{
+			name:    "timed out",
+			results: []FileUploadStatus{{RelPath: "b.css", Error: errors.New("upload failed")}},
+			err:     context.DeadlineExceeded,
+			want:    []string{"a.js", "index.html"},
+			reason:  "not attempted (timed out)",
+		},
+		{
+			name:    "a deletion does not count as a result of the local file",
+			results: []FileUploadStatus{{RelPath: "a.js", Success: true}, {RelPath: "b.css", Success: true, Deleted: true}},
+			err:     context.Canceled,
+			want:    []string{"b.css", "index.html"},
+			reason:  "not attempted (interrupted)",
+		},
+		{
+			name:    "every file has a result",
+			results: []FileUploadStatus{{RelPath: "a.js"}, {RelPath: "b.css"}, {RelPath: "index.html"}},
+			err:     context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remaining := notAttemptedResults(localStates, tt.results, tt.err)
+			var got []string
+			for _, result := range remaining {
+				got = append(got, result.RelPath)
+				if !result.Interrupted || result.Success || result.Reason != tt.reason || result.Error == nil {
+					t.Errorf("result %+v, want an interrupted failure with reason %q", result, tt.reason)
+				}
+				if file := localStates[result.RelPath].File; result.Path != file.Path || result.Size != file.Size || result.Checksum != file.Checksum {
+					t.Errorf("result %+v does not describe local file %+v", result, file)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("not attempted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunPushInterrupted(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	const files = 30
+	for i := range files {
+		writeTestFile(t, filepath.Join(localDir, fmt.Sprintf("page-%02d.html", i)), "content")
+	}
+	summaryPath := filepath.Join(t.TempDir(), "push.json")
+
+	// The first upload interrupts the push, uploads already running finish
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fb.onUpload = func(*http.Request) { cancel() }
+
+	args := []string{"cdn", "push", "--key", fakeAPIKey, "--zone", "site", "--from", localDir, "--summary-json", summaryPath, "--no-cache"}
+	if _, err := newTestParser(t).Parse(args); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if code, err := runPush(ctx); code != exitInterrupted || err != nil {
+		t.Fatalf("runPush() = %d, %v, want %d", code, err, exitInterrupted)
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("summary not written: %v", err)
+	}
+	var summary PushSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+	counts := summary.Counts
+	if counts.NotAttempted == 0 || counts.Failed != 0 || counts.Uploaded+counts.NotAttempted != files {
+		t.Errorf("counts = %+v, want %d files uploaded or not attempted and none failed", counts, files)
+	}
+	if stored := len(fb.fileNames()); stored != counts.Uploaded {
+		t.Errorf("%d files stored, want the %d uploaded", stored, counts.Uploaded)
+	}
+	for _, file := range summary.Files {
+		if file.Action == "not_attempted" && file.Reason != "not attempted (interrupted)" {
+			t.Errorf("file %s has reason %q", file.Path, file.Reason)
+		}
+	}
+}
+
+func TestPushInterruptAbortsUploadsAfterGrace(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	for i := range 20 {
+		writeTestFile(t, filepath.Join(localDir, fmt.Sprintf("page-%02d.html", i)), "content")
+	}
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	// Uploads hang until the client gives up on them
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var once sync.Once
+	fb.onUpload = func(r *http.Request) {
+		once.Do(cancel)
+		<-r.Context().Done()
+	}
+
+	started := time.Now()
+	results := uploadDirectoryOptimized(ctx, storageZone, localDir, "", PushOptions{InterruptGrace: 50 * time.Millisecond}).Files
+	if elapsed := time.Since(started); elapsed > pushInterruptGrace {
+		t.Errorf("push took %s, want it to end soon after the grace period", elapsed)
+	}
+
+	aborted, notAttempted := 0, 0
+	for _, result := range results {
+		switch {
+		case result.Success:
+			t.Errorf("%s succeeded, want every upload aborted", result.RelPath)
+		case result.Interrupted:
+			notAttempted++
+		default:
+			aborted++
+		}
+	}
+	if aborted == 0 || notAttempted == 0 || aborted+notAttempted != 20 {
+		t.Errorf("%d aborted and %d not attempted, want both and 20 in total", aborted, notAttempted)
+	}
+	if names := fb.fileNames(); len(names) != 0 {
+		t.Errorf("stored files = %v, want none", names)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pushProgressInterval is how often the progress bar of cdn push is redrawn
+const pushProgressInterval = 200 * time.Millisecond
+
+// pushProgress is the progress bar of cdn push on a terminal, it replaces the per-file lines.
+// Uploads report the bytes sent while the result collector reports finished files; a nil progress prints nothing.
+type pushProgress struct {
+	w     io.Writer
+	start time.Time
+	sent  atomic.Int64 // body bytes sent, failed attempts are subtracted again
+
+	mu         sync.Mutex
+	files      int   // files uploaded, skipped or failed
+	totalFiles int   // local files of the push
+	totalBytes int64 // bytes of the local files that are not skipped
+	stopped    chan struct{}
+	finished   chan struct{}
+}
+
+// newPushProgress starts redrawing the progress of a push of totalFiles local files of totalBytes on w
+func newPushProgress(w io.Writer, totalFiles int, totalBytes int64) *pushProgress {
+	p := &pushProgress{w: w, start: time.Now(), totalFiles: totalFiles, totalBytes: totalBytes, stopped: make(chan struct{}), finished: make(chan struct{})}
+	go p.run()
+	return p
+}
+
+// run redraws the progress bar until stop is called
+func (p *pushProgress) run() {
+	defer close(p.finished)
+	ticker := time.NewTicker(pushProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopped:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			p.redraw()
+			p.mu.Unlock()
+		}
+	}
+}
+
+// addBytes records n more bytes sent, or n fewer for a failed attempt
+func (p *pushProgress) addBytes(n int64) {
+	if p != nil {
+		p.sent.Add(n)
+	}
+}
+
+// fileDone records a finished file, skipped and failed files no longer count towards the bytes to upload
+func (p *pushProgress) fileDone(result FileUploadStatus) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.files++
+	if result.Skipped || !result.Success {
+		p.totalBytes -= result.Size
+	}
+}
+
+// println prints a line above the progress bar, or just the line without one
+func (p *pushProgress) println(format string, args ...interface{}) {
+	if p == nil {
+		fmt.Printf(format+"\n", args...)
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, "\r\x1b[K"+format+"\n", args...)
+	p.redraw()
+}
+
+// stop draws the final state of the progress bar and ends its line
+func (p *pushProgress) stop() {
+	if p == nil {
+		return
+	}
+	close(p.stopped)
+	<-p.finished
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.redraw()
+	fmt.Fprintln(p.w)
+}
+
+// redraw rewrites the progress line, the caller holds mu
+func (p *pushProgress) redraw() {
+	line := formatPushProgress(p.files, p.totalFiles, p.sent.Load(), p.totalBytes, time.Since(p.start))
+	fmt.Fprintf(p.w, "\r\x1b[K%s", line)
+}
+
+// progressReader reports the bytes read from an upload body as they are sent
+type progressReader struct {
+	r          io.Reader
+	onProgress func(int64)
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.onProgress(int64(n))
+	}
+	return n, err
+}
+
+// Side effect free functions
+
+// formatPushProgress renders progress like "Uploading: 120/800 files, 1.2 GiB/3.0 GiB, 12.3 MiB/s, ETA 2m30s".
+// Throughput is the average since the push started, the ETA is shown once bytes have been sent.
+func formatPushProgress(files, totalFiles int, sent, totalBytes int64, elapsed time.Duration) string {
+	sent = max(min(sent, totalBytes), 0)
+	line := fmt.Sprintf("Uploading: %d/%d files, %s/%s", files, totalFiles, formatByteSize(sent), formatByteSize(totalBytes))
+	if sent == 0 || elapsed <= 0 {
+		return line + ", ETA --"
+	}
+	throughput := float64(sent) / elapsed.Seconds()
+	eta := time.Duration(float64(totalBytes-sent) / throughput * float64(time.Second))
+	return fmt.Sprintf("%s, %s/s, ETA %s", line, formatByteSize(int64(throughput)), eta.Round(time.Second))
+}
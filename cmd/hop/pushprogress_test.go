@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatPushProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      int
+		totalFiles int
+		sent       int64
+		totalBytes int64
+		elapsed    time.Duration
+		want       string
+	}{
+		{"nothing sent yet", 0, 800, 0, 3 << 30, time.Second, "Uploading: 0/800 files, 0 B/3.0 GiB, ETA --"},
+		{"halfway", 400, 800, 1536 << 20, 3 << 30, 2 * time.Minute, "Uploading: 400/800 files, 1.5 GiB/3.0 GiB, 12.8 MiB/s, ETA 2m0s"},
+		{"done", 800, 800, 3 << 30, 3 << 30, 4 * time.Minute, "Uploading: 800/800 files, 3.0 GiB/3.0 GiB, 12.8 MiB/s, ETA 0s"},
+		{"retries counted twice are capped", 1, 2, 2048, 1024, time.Second, "Uploading: 1/2 files, 1.0 KiB/1.0 KiB, 1.0 KiB/s, ETA 0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatPushProgress(tt.files, tt.totalFiles, tt.sent, tt.totalBytes, tt.elapsed); got != tt.want {
+				t.Errorf("formatPushProgress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushProgressCountsFiles(t *testing.T) {
+	var buf bytes.Buffer
+	progress := newPushProgress(&buf, 3, 300)
+	progress.fileDone(FileUploadStatus{Success: true, Skipped: true, Size: 100})
+	progress.addBytes(100)
+	progress.fileDone(FileUploadStatus{Success: true, Size: 100})
+	progress.addBytes(40)
+	progress.addBytes(-40)
+	progress.fileDone(FileUploadStatus{Size: 100})
+	progress.println("✗ Failed: %s", "c.html")
+	progress.stop()
+
+	out := buf.String()
+	if !strings.Contains(out, "✗ Failed: c.html\n") {
+		t.Errorf("output %q lacks the failure line", out)
+	}
+	if !strings.Contains(out, "\r\x1b[KUploading: 3/3 files, 100 B/100 B") {
+		t.Errorf("final progress line of %q is not 3/3 files and 100 B of 100 B", out)
+	}
+	if !strings.HasSuffix(out, "\n") {
+		t.Errorf("output %q does not end the progress line", out)
+	}
+}
+
+func TestProgressReader(t *testing.T) {
+	var reported int64
+	reader := &progressReader{r: strings.NewReader("0123456789"), onProgress: func(n int64) { reported += n }}
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		t.Fatalf("io.Copy() error = %v", err)
+	}
+	if reported != 10 {
+		t.Errorf("reported %d bytes, want 10", reported)
+	}
+}
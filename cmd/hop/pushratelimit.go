@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// rateLimitChunk is the most an upload body reads at once under --limit-rate, and the burst the limiter allows
+const rateLimitChunk = 16 << 10
+
+// rateLimiter is a token bucket shared by all uploads of a push, it keeps their combined throughput under a rate.
+// Readers reserve the bytes they read and sleep until the bucket has refilled; a nil limiter never waits.
+type rateLimiter struct {
+	rate float64 // bytes per second
+
+	mu     sync.Mutex
+	tokens float64 // bytes that may be sent right now, negative while readers wait for reserved bytes
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter of bytesPerSecond, nil for no limit
+func newRateLimiter(bytesPerSecond int64) *rateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{rate: float64(bytesPerSecond), tokens: rateLimitChunk, last: time.Now()}
+}
+
+// wait reserves n bytes and blocks until the bucket has refilled enough to send them, or until ctx is done
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.tokens+now.Sub(l.last).Seconds()*l.rate, rateLimitChunk)
+	l.last = now
+	l.tokens -= float64(n)
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rateLimitedReader reads an upload body in chunks the limiter allows, a cancelled ctx ends the read with its error
+type rateLimitedReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rateLimiter
+}
+
+func (r *rateLimitedReader) Read(b []byte) (int, error) {
+	if len(b) > rateLimitChunk {
+		b = b[:rateLimitChunk]
+	}
+	n, err := r.r.Read(b)
+	if n > 0 {
+		if waitErr := r.limiter.wait(r.ctx, n); waitErr != nil {
+			return 0, waitErr
+		}
+	}
+	return n, err
+}
+
+// Side effect free functions
+
+// limitedMinThroughput lowers the slowest acceptable upload speed to the share of --limit-rate each uploader gets,
+// so uploads slowed down on purpose do not time out
+func limitedMinThroughput(minThroughput, limitRate int64, uploaders int) int64 {
+	if limitRate <= 0 || minThroughput <= 0 {
+		return minThroughput
+	}
+	return min(minThroughput, max(limitRate/int64(uploaders), 1))
+}
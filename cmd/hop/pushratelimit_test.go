@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedUploads(t *testing.T) {
+	var received atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := make([]byte, 4096)
+		for {
+			n, err := r.Body.Read(buf)
+			received.Add(int64(n))
+			if err != nil {
+				break
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	oldStorage := storageBaseURL
+	storageBaseURL = server.URL
+	t.Cleanup(func() { storageBaseURL = oldStorage })
+
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	writeTestFile(t, localPath, strings.Repeat("x", 4<<20))
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	// three uploads share 256 KiB/s, far less than the files need
+	const rate = 256 << 10
+	limiter := newRateLimiter(rate)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = uploadFileToStorage(ctx, storageZone, localPath, "big.bin", uploadOptions{Limiter: limiter})
+		}()
+	}
+
+	const interval = time.Second
+	time.Sleep(interval)
+	got := received.Load()
+	cancel()
+	start := time.Now()
+	wg.Wait()
+
+	// the bucket allows one chunk of burst, and every uploader may have one chunk in flight
+	if upper := int64(rate*interval.Seconds()) + 4*rateLimitChunk; got > upper {
+		t.Errorf("received %d bytes in %s, more than the limit allows (%d)", got, interval, upper)
+	}
+	if lower := int64(rate * interval.Seconds() / 2); got < lower {
+		t.Errorf("received %d bytes in %s, want at least %d", got, interval, lower)
+	}
+	if waited := time.Since(start); waited > 500*time.Millisecond {
+		t.Errorf("uploads took %s to stop after cancelling", waited)
+	}
+	for _, err := range errs {
+		if err == nil {
+			t.Error("an upload finished although it was cancelled")
+		}
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := newRateLimiter(1) // one byte per second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := limiter.wait(ctx, rateLimitChunk*2)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wait() error = %v, want the context deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("wait() returned after %s, want right after the deadline", elapsed)
+	}
+
+	var none *rateLimiter
+	if err := none.wait(ctx, 1<<30); err != nil {
+		t.Errorf("nil limiter wait() error = %v", err)
+	}
+}
+
+func TestRateLimitedReaderChunks(t *testing.T) {
+	reader := &rateLimitedReader{ctx: context.Background(), r: strings.NewReader(strings.Repeat("x", 100<<10)), limiter: newRateLimiter(1 << 30)}
+	buf := make([]byte, 64<<10)
+	n, err := reader.Read(buf)
+	if err != nil || n != rateLimitChunk {
+		t.Errorf("Read() = %d, %v, want one chunk of %d bytes", n, err, rateLimitChunk)
+	}
+	if data, err := io.ReadAll(reader); err != nil || len(data) != 100<<10-rateLimitChunk {
+		t.Errorf("ReadAll() = %d bytes, %v", len(data), err)
+	}
+}
+
+func TestLimitedMinThroughput(t *testing.T) {
+	tests := []struct {
+		name                     string
+		minThroughput, limitRate int64
+		want                     int64
+	}{
+		{"no limit", 256 << 10, 0, 256 << 10},
+		{"limit above the uploaders' needs", 256 << 10, 100 << 20, 256 << 10},
+		{"limit below", 256 << 10, 1 << 20, 128 << 10},
+		{"no throughput bound", 0, 1 << 20, 0},
+		{"tiny limit", 256 << 10, 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitedMinThroughput(tt.minThroughput, tt.limitRate, 8); got != tt.want {
+				t.Errorf("limitedMinThroughput() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
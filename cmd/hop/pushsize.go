@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// strictMaxFileSize is the --max-file-size --strict presets, no file of a website should come close to it
+const strictMaxFileSize = 512 << 20
+
+// oversizeSkipReason is the reason of files --skip-oversize skipped
+const oversizeSkipReason = "larger than --max-file-size"
+
+// resolveMaxFileSize returns the file size limit of a push in bytes, 0 for none.
+// An explicit --max-file-size wins over the preset of --strict, and --max-file-size 0 removes the limit.
+func resolveMaxFileSize(maxFileSize string, strict bool) (int64, error) {
+	if maxFileSize != "" {
+		return parseByteSize(maxFileSize)
+	}
+	if strict {
+		return strictMaxFileSize, nil
+	}
+	return 0, nil
+}
+
+// Side effect free functions
+
+// oversizeFiles returns the sorted relative paths of the local files larger than maxSize, none when maxSize is 0
+func oversizeFiles(localFiles map[string]LocalFileInfo, maxSize int64) []string {
+	if maxSize <= 0 {
+		return nil
+	}
+	var oversize []string
+	for relPath, file := range localFiles {
+		if file.Size > maxSize {
+			oversize = append(oversize, relPath)
+		}
+	}
+	sort.Strings(oversize)
+	return oversize
+}
+
+// withoutPaths returns the remote files whose path is not among paths, so --delete leaves the remote copies of oversize files alone
+func withoutPaths(files []RemoteFileInfo, paths []string) []RemoteFileInfo {
+	if len(paths) == 0 {
+		return files
+	}
+	drop := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		drop[path] = true
+	}
+	var kept []RemoteFileInfo
+	for _, file := range files {
+		if !drop[file.Path] {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// oversizeResult is the result of a file above --max-file-size: a failure, or a skip with --skip-oversize
+func oversizeResult(file LocalFileInfo, maxSize int64, skip bool) FileUploadStatus {
+	result := FileUploadStatus{Path: file.Path, RelPath: file.RelPath, Size: file.Size}
+	if skip {
+		result.Success = true
+		result.Skipped = true
+		result.Reason = oversizeSkipReason
+		return result
+	}
+	result.Error = fmt.Errorf("%s is larger than --max-file-size %s", formatByteSize(file.Size), formatByteSize(maxSize))
+	return result
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResolveMaxFileSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxFileSize string
+		strict      bool
+		want        int64
+		wantErr     bool
+	}{
+		{name: "no limit", want: 0},
+		{name: "strict preset", strict: true, want: 512 << 20},
+		{name: "explicit", maxFileSize: "100MB", want: 100 << 20},
+		{name: "explicit wins over strict", maxFileSize: "1GB", strict: true, want: 1 << 30},
+		{name: "explicit zero removes the strict limit", maxFileSize: "0", strict: true, want: 0},
+		{name: "invalid", maxFileSize: "big", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveMaxFileSize(tt.maxFileSize, tt.strict)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveMaxFileSize() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveMaxFileSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOversizeFiles(t *testing.T) {
+	localFiles := map[string]LocalFileInfo{
+		"index.html": {Size: 100},
+		"dump.sql":   {Size: 2000},
+		"video.mp4":  {Size: 1001},
+		"exact.bin":  {Size: 1000},
+	}
+	if got := oversizeFiles(localFiles, 1000); !slices.Equal(got, []string{"dump.sql", "video.mp4"}) {
+		t.Errorf("oversizeFiles() = %v", got)
+	}
+	if got := oversizeFiles(localFiles, 0); got != nil {
+		t.Errorf("oversizeFiles() without a limit = %v", got)
+	}
+}
+
+func TestPushMaxFileSize(t *testing.T) {
+	newFixture := func(t *testing.T) (*fakeBunny, string) {
+		fb := newFakeBunny(t)
+		fb.addZone(1, "site")
+		fb.putFile("site/dump.sql", []byte("an old dump"))
+		localDir := t.TempDir()
+		writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+		writeTestFile(t, filepath.Join(localDir, "dump.sql"), strings.Repeat("x", 2048))
+		return fb, localDir
+	}
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	t.Run("oversize files fail", func(t *testing.T) {
+		fb, localDir := newFixture(t)
+		results := uploadDirectoryOptimized(context.Background(), storageZone, localDir, "", PushOptions{MaxFileSize: 1024, Delete: true}).Files
+		var failed []string
+		for _, result := range results {
+			if !result.Success {
+				failed = append(failed, result.RelPath)
+				if !strings.Contains(result.Error.Error(), "larger than --max-file-size 1.0 KiB") {
+					t.Errorf("error = %v", result.Error)
+				}
+			}
+		}
+		if !slices.Equal(failed, []string{"dump.sql"}) {
+			t.Errorf("failed = %v, want dump.sql", failed)
+		}
+		if got := string(fb.files["site/dump.sql"].content); got != "an old dump" {
+			t.Errorf("remote dump.sql = %q, want it neither uploaded nor deleted", got)
+		}
+		if !slices.Contains(fb.fileNames(), "site/index.html") {
+			t.Error("index.html was not uploaded")
+		}
+	})
+
+	t.Run("skip oversize keeps the remote copy", func(t *testing.T) {
+		fb, localDir := newFixture(t)
+		results := uploadDirectoryOptimized(context.Background(), storageZone, localDir, "", PushOptions{MaxFileSize: 1024, SkipOversize: true, Delete: true, ForceDelete: true}).Files
+		for _, result := range results {
+			if !result.Success {
+				t.Errorf("%s failed: %v", result.RelPath, result.Error)
+			}
+			if result.RelPath == "dump.sql" && (!result.Skipped || result.Reason != "larger than --max-file-size") {
+				t.Errorf("dump.sql = %+v, want skipped", result)
+			}
+		}
+		if got := string(fb.files["site/dump.sql"].content); got != "an old dump" {
+			t.Errorf("remote dump.sql = %q, want it left alone by --delete", got)
+		}
+	})
+}
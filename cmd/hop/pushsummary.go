@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// PushSummary is the document --summary-json writes after a push
+type PushSummary struct {
+	Timestamp     time.Time         `json:"timestamp"`
+	Zone          string            `json:"zone"`
+	StorageZone   string            `json:"storage_zone"`
+	RemoteDir     string            `json:"remote_dir"`
+	Release       string            `json:"release,omitempty"`
+	DryRun        bool              `json:"dry_run"`
+	Counts        PushSummaryCounts `json:"counts"`
+	BytesUploaded int64             `json:"bytes_uploaded"`
+	Files         []PushSummaryFile `json:"files"`
+}
+
+// PushSummaryCounts counts the files of a push by action
+type PushSummaryCounts struct {
+	Uploaded     int `json:"uploaded"`
+	Skipped      int `json:"skipped"`
+	Failed       int `json:"failed"`
+	Deleted      int `json:"deleted"`
+	Planned      int `json:"planned,omitempty"`
+	NotAttempted int `json:"not_attempted,omitempty"`
+	Excluded     int `json:"excluded"`
+	NotIncluded  int `json:"not_included"`
+	Hidden       int `json:"hidden"`
+}
+
+// PushSummaryFile is one file of a push, Path is relative to the remote directory
+type PushSummaryFile struct {
+	Path     string `json:"path"`
+	Action   string `json:"action"` // uploaded, skipped, failed, deleted, not_attempted, or in a dry run would_upload and would_delete
+	Reason   string `json:"reason,omitempty"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// writePushSummary writes the summary of a push as indented JSON
+func writePushSummary(path string, summary PushSummary) error {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding push summary: %v", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// Side effect free functions
+
+// buildPushSummary returns the counts and file entries of a push from its results, the caller fills in the run details
+func buildPushSummary(push PushResult) PushSummary {
+	summary := PushSummary{
+		Counts: PushSummaryCounts{Excluded: push.Scan.Excluded, NotIncluded: push.Scan.NotIncluded, Hidden: len(push.Scan.Hidden)},
+		Files:  []PushSummaryFile{},
+	}
+	for _, result := range push.Files {
+		file := PushSummaryFile{
+			Path:     result.RelPath,
+			Action:   pushAction(result),
+			Reason:   result.Reason,
+			Size:     result.Size,
+			Checksum: result.Checksum,
+		}
+		if file.Path == "" {
+			file.Path = result.Path
+		}
+		if result.Error != nil {
+			file.Error = result.Error.Error()
+		}
+		switch file.Action {
+		case "uploaded":
+			summary.Counts.Uploaded++
+			summary.BytesUploaded += result.Size
+		case "skipped":
+			summary.Counts.Skipped++
+		case "failed":
+			summary.Counts.Failed++
+		case "deleted":
+			summary.Counts.Deleted++
+		case "would_upload", "would_delete":
+			summary.Counts.Planned++
+		case "not_attempted":
+			summary.Counts.NotAttempted++
+		}
+		summary.Files = append(summary.Files, file)
+	}
+	return summary
+}
+
+// pushAction names what a push did with a file
+func pushAction(result FileUploadStatus) string {
+	switch {
+	case result.Interrupted:
+		return "not_attempted"
+	case !result.Success:
+		return "failed"
+	case result.Planned && result.Deleted:
+		return "would_delete"
+	case result.Planned:
+		return "would_upload"
+	case result.Deleted:
+		return "deleted"
+	case result.Skipped:
+		return "skipped"
+	}
+	return "uploaded"
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBuildPushSummary(t *testing.T) {
+	push := PushResult{
+		Files: []FileUploadStatus{
+			{Path: "/tmp/dist/index.html", RelPath: "index.html", Size: 10, Checksum: "AA", Success: true, Skipped: true, Reason: "checksum match"},
+			{Path: "/tmp/dist/app.js", RelPath: "app.js", Size: 200, Checksum: "BB", Success: true},
+			{Path: "/tmp/dist/big.bin", RelPath: "big.bin", Size: 5000, Checksum: "CC", Error: errors.New("upload timed out")},
+			{Path: "old.html", RelPath: "old.html", Success: true, Deleted: true},
+			{Path: "/tmp/dist/about.html", RelPath: "about.html", Size: 30, Error: errors.New("not attempted (interrupted)"), Reason: "not attempted (interrupted)", Interrupted: true},
+		},
+		Scan: LocalScanStats{Excluded: 2},
+	}
+
+	summary := buildPushSummary(push)
+	wantCounts := PushSummaryCounts{Uploaded: 1, Skipped: 1, Failed: 1, Deleted: 1, NotAttempted: 1, Excluded: 2}
+	if summary.Counts != wantCounts {
+		t.Errorf("counts = %+v, want %+v", summary.Counts, wantCounts)
+	}
+	if summary.BytesUploaded != 200 {
+		t.Errorf("bytes uploaded = %d, want 200", summary.BytesUploaded)
+	}
+	wantFiles := []PushSummaryFile{
+		{Path: "index.html", Action: "skipped", Reason: "checksum match", Size: 10, Checksum: "AA"},
+		{Path: "app.js", Action: "uploaded", Size: 200, Checksum: "BB"},
+		{Path: "big.bin", Action: "failed", Size: 5000, Checksum: "CC", Error: "upload timed out"},
+		{Path: "old.html", Action: "deleted"},
+		{Path: "about.html", Action: "not_attempted", Reason: "not attempted (interrupted)", Size: 30, Error: "not attempted (interrupted)"},
+	}
+	if !reflect.DeepEqual(summary.Files, wantFiles) {
+		t.Errorf("files = %+v, want %+v", summary.Files, wantFiles)
+	}
+}
+
+func TestPushWritesSummaryJSON(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	fb.putFile("site/index.html", []byte("home"))
+
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, "app.js"), "code")
+	summaryPath := filepath.Join(t.TempDir(), "push.json")
+
+	if code := run([]string{"cdn", "push", "--key", fakeAPIKey, "--zone", "site", "--from", localDir, "--summary-json", summaryPath}); code != exitClean {
+		t.Fatalf("exit code = %d, want %d", code, exitClean)
+	}
+
+	data, err := os.ReadFile(summaryPath)
+	if err != nil {
+		t.Fatalf("summary not written: %v", err)
+	}
+	var summary PushSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+	if summary.Zone != "site" || summary.StorageZone != "site" || summary.Timestamp.IsZero() {
+		t.Errorf("run details = %+v", summary)
+	}
+	if summary.Counts.Uploaded != 1 || summary.Counts.Skipped != 1 || summary.BytesUploaded != 4 {
+		t.Errorf("counts = %+v, bytes = %d, want 1 uploaded of 4 bytes and 1 skipped", summary.Counts, summary.BytesUploaded)
+	}
+	for _, file := range summary.Files {
+		if len(file.Checksum) != 64 {
+			t.Errorf("file %s has checksum %q, want the SHA256", file.Path, file.Checksum)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LocalWalkOptions says how a push walks the --from directory, the zero value skips symlinks and hidden entries
+type LocalWalkOptions struct {
+	FollowSymlinks        bool // upload the files and directories symlinks point to, under the path of the link
+	AllowExternalSymlinks bool // follow symlinks that resolve outside the --from directory
+	IncludeHidden         bool // walk dotfiles, dot-directories and the other names of hiddenNames
+}
+
+// localWalkSkips are the entries walkLocalFiles did not visit, as relative paths with '/' separators
+type localWalkSkips struct {
+	Symlinks []string // symlinks that were not followed
+	Hidden   []string // hidden files and directories, hidden directories are not walked into
+}
+
+// localWalker walks a local directory like filepath.Walk, optionally following symlinks
+type localWalker struct {
+	root      string // the resolved --from directory, external symlinks point outside it
+	opts      LocalWalkOptions
+	visit     func(path, relPath string, info os.FileInfo) error
+	ancestors []os.FileInfo // directories being walked, a directory among its own ancestors is a symlink loop
+	skipped   localWalkSkips
+}
+
+// walkLocalFiles calls visit for every file below localDir in lexical order, with the path to open, the path relative to localDir and the file's info.
+// Hidden entries are skipped unless opts.IncludeHidden is set. Without opts.FollowSymlinks symlinks are skipped too;
+// with it files behind a symlink get the link's path, and loops and external targets are errors. It returns what it skipped.
+func walkLocalFiles(localDir string, opts LocalWalkOptions, visit func(path, relPath string, info os.FileInfo) error) (localWalkSkips, error) {
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return localWalkSkips{}, err
+	}
+	root, err := filepath.EvalSymlinks(localDir)
+	if err != nil {
+		return localWalkSkips{}, err
+	}
+	if root, err = filepath.Abs(root); err != nil {
+		return localWalkSkips{}, err
+	}
+	w := &localWalker{root: root, opts: opts, visit: visit}
+	if err := w.walkDir(localDir, "", info); err != nil {
+		return localWalkSkips{}, err
+	}
+	return w.skipped, nil
+}
+
+// walkDir visits the entries of dir, relDir is its path relative to the --from directory
+func (w *localWalker) walkDir(dir, relDir string, info os.FileInfo) error {
+	for _, ancestor := range w.ancestors {
+		if os.SameFile(ancestor, info) {
+			return fmt.Errorf("symlink loop: '%s' leads back to a directory it is in", filepath.ToSlash(relDir))
+		}
+	}
+	w.ancestors = append(w.ancestors, info)
+	defer func() { w.ancestors = w.ancestors[:len(w.ancestors)-1] }()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		relPath := filepath.Join(relDir, entry.Name())
+		if !w.opts.IncludeHidden && isHiddenName(entry.Name()) && !isHopStateFile(relPath) {
+			w.skipped.Hidden = append(w.skipped.Hidden, filepath.ToSlash(relPath))
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !w.opts.FollowSymlinks {
+				w.skipped.Symlinks = append(w.skipped.Symlinks, filepath.ToSlash(relPath))
+				continue
+			}
+			if info, err = w.resolve(path, relPath); err != nil {
+				return err
+			}
+		}
+		if info.IsDir() {
+			err = w.walkDir(path, relPath, info)
+		} else {
+			err = w.visit(path, relPath, info)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the info of what a symlink points to, refusing targets outside the --from directory unless they are allowed
+func (w *localWalker) resolve(path, relPath string) (os.FileInfo, error) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving symlink '%s': %v", filepath.ToSlash(relPath), err)
+	}
+	if target, err = filepath.Abs(target); err != nil {
+		return nil, err
+	}
+	if !w.opts.AllowExternalSymlinks && !isWithinDir(w.root, target) {
+		return nil, fmt.Errorf("symlink '%s' points outside the --from directory to '%s', pass --allow-external-symlinks to follow it", filepath.ToSlash(relPath), target)
+	}
+	return os.Stat(path)
+}
+
+// Side effect free functions
+
+// isWithinDir reports whether path is dir or below it, both absolute and clean
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && (rel == "." || filepath.IsLocal(rel))
+}
+
+// withoutTrees returns the remote files that are neither one of roots nor below one, so --delete leaves the remote copies of skipped symlinks alone
+func withoutTrees(files []RemoteFileInfo, roots []string) []RemoteFileInfo {
+	if len(roots) == 0 {
+		return files
+	}
+	var kept []RemoteFileInfo
+	for _, file := range files {
+		below := false
+		for _, root := range roots {
+			if file.Path == root || strings.HasPrefix(file.Path, root+"/") {
+				below = true
+				break
+			}
+		}
+		if !below {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// formatSkippedSymlinks renders the warning about symlinks a push did not follow, listing at most limit of them
+func formatSkippedSymlinks(symlinks []string, limit int) string {
+	shown := symlinks
+	if len(shown) > limit {
+		shown = shown[:limit]
+	}
+	word := "symlinks"
+	if len(symlinks) == 1 {
+		word = "symlink"
+	}
+	line := fmt.Sprintf("WARN: skipped %d %s, pass --follow-symlinks to upload what they point to: %s", len(symlinks), word, strings.Join(shown, ", "))
+	if len(symlinks) > limit {
+		line += fmt.Sprintf(" and %d more", len(symlinks)-limit)
+	}
+	return line
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// newSymlinkTree creates a --from directory with a symlinked file, a symlinked directory and a symlink to a directory outside of it
+func newSymlinkTree(t *testing.T) string {
+	t.Helper()
+	base := t.TempDir()
+	localDir := filepath.Join(base, "site")
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, "shared", "logo.svg"), "<svg/>")
+	writeTestFile(t, filepath.Join(base, "outside", "secret.txt"), "outside")
+	symlink(t, "index.html", filepath.Join(localDir, "home.html"))
+	symlink(t, "shared", filepath.Join(localDir, "assets"))
+	symlink(t, filepath.Join(base, "outside"), filepath.Join(localDir, "external"))
+	return localDir
+}
+
+func symlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("cannot create symlinks: %v", err)
+	}
+}
+
+func TestWalkLocalFilesSymlinks(t *testing.T) {
+	localDir := newSymlinkTree(t)
+	walk := func(opts LocalWalkOptions) ([]string, []string, error) {
+		var relPaths []string
+		skipped, err := walkLocalFiles(localDir, opts, func(path, relPath string, info os.FileInfo) error {
+			relPaths = append(relPaths, filepath.ToSlash(relPath))
+			return nil
+		})
+		return relPaths, skipped.Symlinks, err
+	}
+
+	relPaths, skipped, err := walk(LocalWalkOptions{})
+	if err != nil {
+		t.Fatalf("walkLocalFiles() error = %v", err)
+	}
+	if want := []string{"index.html", "shared/logo.svg"}; !slices.Equal(relPaths, want) {
+		t.Errorf("files without --follow-symlinks = %v, want %v", relPaths, want)
+	}
+	if want := []string{"assets", "external", "home.html"}; !slices.Equal(skipped, want) {
+		t.Errorf("skipped symlinks = %v, want %v", skipped, want)
+	}
+
+	if _, _, err := walk(LocalWalkOptions{FollowSymlinks: true}); err == nil || !strings.Contains(err.Error(), "symlink 'external' points outside") {
+		t.Errorf("walkLocalFiles() with an external symlink error = %v, want it refused", err)
+	}
+
+	relPaths, skipped, err = walk(LocalWalkOptions{FollowSymlinks: true, AllowExternalSymlinks: true})
+	if err != nil {
+		t.Fatalf("walkLocalFiles() error = %v", err)
+	}
+	if want := []string{"assets/logo.svg", "external/secret.txt", "home.html", "index.html", "shared/logo.svg"}; !slices.Equal(relPaths, want) {
+		t.Errorf("files with --follow-symlinks = %v, want %v", relPaths, want)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("skipped symlinks with --follow-symlinks = %v", skipped)
+	}
+}
+
+func TestWalkLocalFilesSymlinkLoop(t *testing.T) {
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "docs", "index.html"), "docs")
+	symlink(t, "..", filepath.Join(localDir, "docs", "up"))
+
+	_, err := walkLocalFiles(localDir, LocalWalkOptions{FollowSymlinks: true}, func(path, relPath string, info os.FileInfo) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "symlink loop: 'docs/up'") {
+		t.Errorf("walkLocalFiles() error = %v, want a symlink loop", err)
+	}
+
+	// without --follow-symlinks the loop is just a skipped symlink
+	skipped, err := walkLocalFiles(localDir, LocalWalkOptions{}, func(path, relPath string, info os.FileInfo) error { return nil })
+	if err != nil || !slices.Equal(skipped.Symlinks, []string{"docs/up"}) {
+		t.Errorf("walkLocalFiles() = %v, %v, want docs/up skipped", skipped, err)
+	}
+}
+
+func TestBuildLocalFileMapFollowsSymlinks(t *testing.T) {
+	localDir := newSymlinkTree(t)
+
+	localFiles, stats, err := buildLocalFileMap(localDir, PathFilter{Exclude: []string{"external"}}, LocalWalkOptions{})
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+	if want := []string{"assets", "home.html"}; !slices.Equal(stats.Symlinks, want) {
+		t.Errorf("reported symlinks = %v, want %v without the excluded one", stats.Symlinks, want)
+	}
+	if len(localFiles) != 2 {
+		t.Errorf("got %d local files, want 2", len(localFiles))
+	}
+
+	localFiles, _, err = buildLocalFileMap(localDir, PathFilter{Exclude: []string{"external/**"}}, LocalWalkOptions{FollowSymlinks: true, AllowExternalSymlinks: true})
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+	if file := localFiles["home.html"]; file.Size != int64(len("home")) {
+		t.Errorf("home.html size %d, want the size of the file it points to", file.Size)
+	}
+	if _, ok := localFiles["assets/logo.svg"]; !ok {
+		t.Error("the file in the symlinked directory is missing")
+	}
+}
+
+func TestPushDeleteKeepsSkippedSymlinks(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, "shared", "logo.svg"), "<svg/>")
+	symlink(t, "shared", filepath.Join(localDir, "assets"))
+	for _, name := range []string{"index.html", "shared/logo.svg", "assets/logo.svg", "old.html"} {
+		fb.putFile("site/"+name, []byte("remote"))
+	}
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	uploadDirectoryOptimized(context.Background(), storageZone, localDir, "", PushOptions{Delete: true, ForceDelete: true})
+
+	want := []string{"site/assets/logo.svg", "site/index.html", "site/shared/logo.svg"}
+	if got := fb.fileNames(); !slices.Equal(got, want) {
+		t.Errorf("stored files = %v, want %v with the copy of the skipped symlink kept", got, want)
+	}
+}
+
+func TestFormatSkippedSymlinks(t *testing.T) {
+	tests := []struct {
+		symlinks []string
+		want     string
+	}{
+		{[]string{"assets"}, "WARN: skipped 1 symlink, pass --follow-symlinks to upload what they point to: assets"},
+		{[]string{"a", "b", "c"}, "WARN: skipped 3 symlinks, pass --follow-symlinks to upload what they point to: a, b and 1 more"},
+	}
+	for _, tt := range tests {
+		if got := formatSkippedSymlinks(tt.symlinks, 2); got != tt.want {
+			t.Errorf("formatSkippedSymlinks(%v) = %q, want %q", tt.symlinks, got, tt.want)
+		}
+	}
+}
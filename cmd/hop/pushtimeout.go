@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Side effect free functions
+
+// uploadTimeout returns how long the upload of a file may take: base plus the time the file needs at minThroughput bytes per second.
+// It returns 0, no timeout, when base and minThroughput are both 0.
+func uploadTimeout(size int64, base time.Duration, minThroughput int64) time.Duration {
+	if minThroughput <= 0 {
+		return base
+	}
+	return base + time.Duration(float64(size)/float64(minThroughput)*float64(time.Second))
+}
+
+// byteUnits are the suffixes parseByteSize accepts, binary like formatByteSize prints them
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1 << 10,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1 << 20,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1 << 30,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1 << 40,
+	"tib": 1 << 40,
+}
+
+// parseByteSize parses a size like 1048576, 512KB, 1.5MiB or 2G, units are binary and case-insensitive
+func parseByteSize(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+	end := strings.LastIndexAny(trimmed, "0123456789.") + 1
+	number, unit := trimmed[:end], strings.ToLower(strings.TrimSpace(trimmed[end:]))
+	multiplier, ok := byteUnits[unit]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid size '%s', use a number of bytes or a unit like 512KB, 10MB or 1GB", s)
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size '%s', use a number of bytes or a unit like 512KB, 10MB or 1GB", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int64
+		base          time.Duration
+		minThroughput int64
+		want          time.Duration
+	}{
+		{"small file", 1024, 30 * time.Second, 256 << 10, 30*time.Second + 3906250*time.Nanosecond},
+		{"10 GB file", 10 << 30, 30 * time.Second, 1 << 20, 30*time.Second + 10240*time.Second},
+		{"no throughput bound", 10 << 30, 30 * time.Second, 0, 30 * time.Second},
+		{"no limit", 10 << 30, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uploadTimeout(tt.size, tt.base, tt.minThroughput); got != tt.want {
+				t.Errorf("uploadTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1048576", 1 << 20, false},
+		{"512KB", 512 << 10, false},
+		{"256kb", 256 << 10, false},
+		{"1.5MiB", 3 << 19, false},
+		{"2G", 2 << 30, false},
+		{"10 MB", 10 << 20, false},
+		{"0", 0, false},
+		{"MB", 0, true},
+		{"12XB", 0, true},
+		{"", 0, true},
+		{"-1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseByteSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseByteSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseByteSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadWithTimeout(t *testing.T) {
+	stalled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stalled // no response until the test ends
+	}))
+	defer server.Close()
+	defer close(stalled)
+	oldStorage := storageBaseURL
+	storageBaseURL = server.URL
+	t.Cleanup(func() { storageBaseURL = oldStorage })
+
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	writeTestFile(t, localPath, "content")
+	task := FileUploadTask{LocalFile: LocalFileInfo{Path: localPath, RelPath: "big.bin", Size: 7}, RemotePath: "big.bin"}
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	err := uploadWithTimeout(context.Background(), storageZone, task, 50*time.Millisecond, uploadOptions{})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("uploadWithTimeout() error = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// verifyWorkers is how many remote directories --verify lists at the same time
+const verifyWorkers = 4
+
+// defaultVerifyAttempts and defaultVerifyInterval are how often and how far apart --verify lists a file that does not match yet
+const (
+	defaultVerifyAttempts = 3
+	defaultVerifyInterval = 2 * time.Second
+)
+
+// verifyUploads lists the remote directories of the uploaded files again and compares size and checksum with the local files.
+// A replicated storage zone can answer from a replica that has not seen an upload yet, so files that do not match are listed again
+// up to attempts times, interval apart. Uploads still missing or differing then are turned into failures in place; skipped files are not verified.
+// It returns the number of files verified and of mismatches.
+func verifyUploads(ctx context.Context, storageZone *StorageZone, remoteDir string, results []FileUploadStatus, attempts int, interval time.Duration) (int, int) {
+	var pending []int
+	for i, result := range results {
+		if result.Success && !result.Skipped && !result.Planned && !result.Deleted {
+			pending = append(pending, i)
+		}
+	}
+	verified := len(pending)
+	attempts = max(attempts, 1)
+
+	problems := make(map[int]string)
+	tried := 0
+attempts:
+	for attempt := 1; attempt <= attempts && len(pending) > 0; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				break attempts
+			case <-time.After(interval):
+			}
+		}
+		tried = attempt
+		problems = verifyAttempt(ctx, storageZone, remoteDir, results, pending)
+		var mismatched []int
+		for _, i := range pending {
+			if problems[i] != "" {
+				mismatched = append(mismatched, i)
+			} else if attempt > 1 {
+				results[i].Reason = fmt.Sprintf("verified after %d attempts", attempt)
+				fmt.Printf("✓ Verified after %d attempts: %s\n", attempt, results[i].RelPath)
+			}
+		}
+		pending = mismatched
+	}
+
+	for _, i := range pending {
+		problem := problems[i]
+		if tried > 1 {
+			problem = fmt.Sprintf("%s after %d attempts, replication lag in the storage zone is a likely cause, try a longer --verify-interval", problem, tried)
+		}
+		fmt.Printf("✗ Verification failed: %s (%s)\n", results[i].RelPath, problem)
+		results[i].Success = false
+		results[i].Error = fmt.Errorf("verification failed: %s", problem)
+	}
+	return verified, len(pending)
+}
+
+// verifyAttempt lists the directories of the results at indexes once and returns the problem of every result whose remote copy does not match
+func verifyAttempt(ctx context.Context, storageZone *StorageZone, remoteDir string, results []FileUploadStatus, indexes []int) map[int]string {
+	byDir := make(map[string][]int)
+	for _, i := range indexes {
+		dir := path.Dir(remoteFilePath(remoteDir, results[i].RelPath))
+		if dir == "." {
+			dir = ""
+		}
+		byDir[dir] = append(byDir[dir], i)
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	listings := make([][]RemoteFileInfo, len(dirs))
+	errs := make([]error, len(dirs))
+	runWorkers(verifyWorkers, len(dirs), func(job int) {
+		listings[job], errs[job] = listRemoteFiles(ctx, storageZone, dirs[job])
+	})
+
+	problems := make(map[int]string)
+	for job, dir := range dirs {
+		remote := make(map[string]RemoteFileInfo)
+		for _, file := range listings[job] {
+			if !file.IsDirectory {
+				remote[file.Name] = file
+			}
+		}
+		for _, i := range byDir[dir] {
+			if errs[job] != nil {
+				problems[i] = fmt.Sprintf("could not list '/%s': %v", dir, errs[job])
+			} else if problem := verificationProblem(results[i], remote[path.Base(results[i].RelPath)]); problem != "" {
+				problems[i] = problem
+			}
+		}
+	}
+	return problems
+}
+
+// Side effect free functions
+
+// verificationProblem explains how the remote copy of an uploaded file differs from the local file, or returns "" when it matches.
+// A zero remote is a file missing from the listing, the checksum is only compared when both sides have one.
+func verificationProblem(uploaded FileUploadStatus, remote RemoteFileInfo) string {
+	switch {
+	case remote.Name == "":
+		return "the file is missing on the storage"
+	case remote.Size != uploaded.Size:
+		return fmt.Sprintf("remote size %d, local size %d", remote.Size, uploaded.Size)
+	case remote.Checksum != "" && uploaded.Checksum != "" && !strings.EqualFold(remote.Checksum, uploaded.Checksum):
+		return fmt.Sprintf("remote checksum %s, local checksum %s", remote.Checksum, uploaded.Checksum)
+	}
+	return ""
+}
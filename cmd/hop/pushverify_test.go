@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyUploads(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	fb.putFile("site/site-a/index.html", []byte("home"))
+	fb.putFile("site/site-a/css/site.css", []byte("body{}")) // stale: same size, other content
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+
+	checksum := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return strings.ToUpper(hex.EncodeToString(sum[:]))
+	}
+	results := []FileUploadStatus{
+		{RelPath: "index.html", Size: 4, Checksum: checksum("home"), Success: true},
+		{RelPath: "css/site.css", Size: 6, Checksum: checksum("p{x:1}"), Success: true},
+		{RelPath: "js/app.js", Size: 4, Checksum: checksum("code"), Success: true},
+		{RelPath: "skipped.html", Size: 1, Checksum: checksum("x"), Success: true, Skipped: true},
+	}
+
+	verified, mismatches := verifyUploads(context.Background(), storageZone, "site-a", results, 2, time.Millisecond)
+	if verified != 3 || mismatches != 2 {
+		t.Errorf("verified %d with %d mismatches, want 3 with 2", verified, mismatches)
+	}
+	want := []string{"", "checksum", "missing", ""}
+	for i, result := range results {
+		switch {
+		case want[i] == "" && !result.Success:
+			t.Errorf("%s failed verification: %v", result.RelPath, result.Error)
+		case want[i] != "" && (result.Success || !strings.Contains(result.Error.Error(), want[i])):
+			t.Errorf("%s: success %v, error %v, want a %s failure", result.RelPath, result.Success, result.Error, want[i])
+		case want[i] != "" && !strings.Contains(result.Error.Error(), "after 2 attempts, replication lag"):
+			t.Errorf("%s: error %v, want it to name the attempts and replication lag", result.RelPath, result.Error)
+		}
+	}
+}
+
+func TestVerifyUploadsRetriesReplicationLag(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	listings := 0
+	fb.onList = func(string) {
+		listings++
+		if listings == 2 {
+			fb.putFile("site/site-a/index.html", []byte("home")) // the replica caught up
+		}
+	}
+
+	sum := sha256.Sum256([]byte("home"))
+	results := []FileUploadStatus{
+		{RelPath: "index.html", Size: 4, Checksum: strings.ToUpper(hex.EncodeToString(sum[:])), Success: true},
+	}
+
+	verified, mismatches := verifyUploads(context.Background(), storageZone, "site-a", results, 3, time.Millisecond)
+	if verified != 1 || mismatches != 0 {
+		t.Errorf("verified %d with %d mismatches, want 1 with 0", verified, mismatches)
+	}
+	if !results[0].Success || results[0].Reason != "verified after 2 attempts" {
+		t.Errorf("success %v, reason %q, want a success verified after 2 attempts", results[0].Success, results[0].Reason)
+	}
+	if listings != 2 {
+		t.Errorf("listed %d times, want 2", listings)
+	}
+}
+
+func TestVerificationProblem(t *testing.T) {
+	uploaded := FileUploadStatus{RelPath: "a.html", Size: 4, Checksum: "ABCD"}
+	tests := []struct {
+		name   string
+		remote RemoteFileInfo
+		want   string
+	}{
+		{"match", RemoteFileInfo{Name: "a.html", Size: 4, Checksum: "abcd"}, ""},
+		{"no remote checksum", RemoteFileInfo{Name: "a.html", Size: 4}, ""},
+		{"missing", RemoteFileInfo{}, "the file is missing on the storage"},
+		{"size", RemoteFileInfo{Name: "a.html", Size: 3, Checksum: "ABCD"}, "remote size 3, local size 4"},
+		{"checksum", RemoteFileInfo{Name: "a.html", Size: 4, Checksum: "FFFF"}, "remote checksum FFFF, local checksum ABCD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verificationProblem(uploaded, tt.remote); got != tt.want {
+				t.Errorf("verificationProblem() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
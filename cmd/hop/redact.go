@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// output is where check and list reports are rendered, --redact swaps in a redacting writer
+var output io.Writer = os.Stdout
+
+// Redactor consistently replaces zone names and hostnames with pseudonyms like zone-1 and host-1.example
+type Redactor struct {
+	mu      sync.Mutex
+	aliases map[string]string // lowercase original -> alias
+	order   []string          // originals in registration order
+	zones   int
+	hosts   int
+	pattern *regexp.Regexp
+}
+
+// redactingWriter redacts everything written through it, each Write is redacted as a whole
+type redactingWriter struct {
+	w io.Writer
+	r *Redactor
+}
+
+func newRedactor() *Redactor {
+	return &Redactor{aliases: make(map[string]string)}
+}
+
+// addZone registers a zone name
+func (r *Redactor) addZone(name string) {
+	r.add(name, func() string {
+		r.zones++
+		return fmt.Sprintf("zone-%d", r.zones)
+	})
+}
+
+// addHost registers a hostname, ports and case are ignored
+func (r *Redactor) addHost(host string) {
+	r.add(canonicalHost(host), func() string {
+		r.hosts++
+		return fmt.Sprintf("host-%d.example", r.hosts)
+	})
+}
+
+// addURLHost registers the host of an absolute URL or URL pattern, relative paths are ignored
+func (r *Redactor) addURLHost(rawURL string) {
+	_, rest, found := strings.Cut(rawURL, "://")
+	if !found {
+		return
+	}
+	host, _, _ := strings.Cut(rest, "/")
+	host, _, _ = strings.Cut(host, "?")
+	host = host[strings.LastIndex(host, "@")+1:]
+	r.addHost(strings.TrimPrefix(host, "*."))
+}
+
+// addRules registers every host used in the destinations and URL triggers of rules
+func (r *Redactor) addRules(rules []EdgeRuleResponse) {
+	for _, rule := range rules {
+		r.addURLHost(rule.ActionParameter1)
+		for _, pattern := range urlTriggerPatterns(rule) {
+			r.addURLHost(pattern)
+		}
+	}
+}
+
+// addPullZone registers a pull zone's name, hostnames and rule hosts
+func (r *Redactor) addPullZone(details *PullZoneDetails) {
+	r.addZone(details.Name)
+	for _, hostname := range details.Hostnames {
+		r.addHost(hostname.Value)
+	}
+	r.addRules(details.EdgeRules)
+}
+
+func (r *Redactor) add(original string, alias func() string) {
+	key := strings.ToLower(strings.TrimSpace(original))
+	if key == "" || strings.Contains(key, "*") {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.aliases[key]; exists {
+		return
+	}
+	r.aliases[key] = alias()
+	r.order = append(r.order, key)
+
+	// Longest originals first so a hostname wins over a zone name it contains
+	originals := append([]string(nil), r.order...)
+	sort.SliceStable(originals, func(i, j int) bool { return len(originals[i]) > len(originals[j]) })
+	for i, original := range originals {
+		originals[i] = regexp.QuoteMeta(original)
+	}
+	r.pattern = regexp.MustCompile(`(?i)` + strings.Join(originals, "|"))
+}
+
+// isNameChar reports whether a byte can be part of a hostname label
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
+
+// redact replaces every registered name that is not part of a longer word
+func (r *Redactor) redact(s string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pattern == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range r.pattern.FindAllStringIndex(s, -1) {
+		start, end := match[0], match[1]
+		if (start > 0 && isNameChar(s[start-1])) || (end < len(s) && isNameChar(s[end])) {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(r.aliases[strings.ToLower(s[start:end])])
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// redactFile redacts the content of a report file, which does not pass through output; a nil redactor keeps it unchanged
+func (r *Redactor) redactFile(data []byte) []byte {
+	if r == nil {
+		return data
+	}
+	return []byte(r.redact(string(data)))
+}
+
+// writeMap writes one "alias original" line per registered name
+func (r *Redactor) writeMap(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, original := range r.order {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", r.aliases[original], original); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rw redactingWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.r.redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func newTestRedactor() *Redactor {
+	r := newRedactor()
+	r.addPullZone(&PullZoneDetails{
+		Name:      "acme-site",
+		Hostnames: []Hostname{{Value: "acme-site.b-cdn.net"}, {Value: "www.acme.com"}, {Value: "acme.com"}},
+		EdgeRules: []EdgeRuleResponse{
+			{ActionType: 1, ActionParameter1: "https://blog.acme.com/new?x=1", Triggers: []Trigger{{Type: 0, PatternMatches: []string{"https://*.acme.com/old", "/legacy/*"}}}},
+		},
+	})
+	return r
+}
+
+func TestRedactorAliases(t *testing.T) {
+	r := newTestRedactor()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"zone name", "Found pull zone 'acme-site' with ID: 42", "Found pull zone 'zone-1' with ID: 42"},
+		{"hostname wins over contained zone name", "OK acme-site.b-cdn.net", "OK host-1.example"},
+		{"case insensitive", "https://WWW.ACME.COM/path", "https://host-2.example/path"},
+		{"subdomain of registered host keeps its label", "ERROR shop.acme.com", "ERROR shop.host-3.example"},
+		{"destination host", "-> https://blog.acme.com/new", "-> https://host-4.example/new"},
+		{"longer word is left alone", "acme.community and notacme.com", "acme.community and notacme.com"},
+		{"nothing registered matches", "/legacy/page", "/legacy/page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.redact(tt.in); got != tt.want {
+				t.Errorf("redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorStableAliases(t *testing.T) {
+	r := newTestRedactor()
+	first := r.redact("www.acme.com")
+	r.addHost("WWW.acme.com:443")
+	r.addHost("other.example.org")
+	if second := r.redact("www.acme.com"); second != first {
+		t.Errorf("alias changed from %q to %q after re-registering", first, second)
+	}
+}
+
+func TestRedactorWriteMap(t *testing.T) {
+	r := newTestRedactor()
+
+	var out bytes.Buffer
+	if err := r.writeMap(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := "zone-1\tacme-site\nhost-1.example\tacme-site.b-cdn.net\nhost-2.example\twww.acme.com\nhost-3.example\tacme.com\nhost-4.example\tblog.acme.com\n"
+	if out.String() != want {
+		t.Errorf("writeMap() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRedactedReportsLeakNoHostnames(t *testing.T) {
+	r := newTestRedactor()
+	var buf bytes.Buffer
+	previous := output
+	output = redactingWriter{w: &buf, r: r}
+	t.Cleanup(func() { output = previous })
+
+	rules := []EdgeRuleResponse{
+		{Guid: "g1", ActionType: 1, ActionParameter1: "https://blog.acme.com/new", ActionParameter2: "302", Enabled: true,
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{"https://www.acme.com/old"}}}},
+	}
+	if err := writeRulesCSV(output, rules); err != nil {
+		t.Fatal(err)
+	}
+	displayCheckResults(CheckResult{Issues: []CheckIssue{
+		{Type: "health", Severity: "error", Message: "Destination https://blog.acme.com/new returned 404", Rule: &rules[0]},
+	}}, false)
+	hostnames := []Hostname{{Value: "www.acme.com"}}
+	dnsResult := CheckResult{Issues: []CheckIssue{
+		{Severity: "error", Message: "MISSING www.acme.com - No DNS record found", Details: map[string]interface{}{"hostname": "www.acme.com"}},
+	}}
+	renderHostnameReports(output, groupByHostname(hostnames, dnsResult, CheckResult{}))
+
+	got := buf.String()
+	for _, original := range []string{"acme-site", "www.acme.com", "blog.acme.com"} {
+		if strings.Contains(strings.ToLower(got), original) {
+			t.Errorf("redacted output still contains %q:\n%s", original, got)
+		}
+	}
+	if !strings.Contains(got, "host-2.example") {
+		t.Errorf("redacted output is missing the www alias:\n%s", got)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// releasesDir is the storage directory that holds one subdirectory per release
+const releasesDir = "releases"
+
+// releaseRulePrefix marks the hop-managed edge rule that points the zone at the active release
+const releaseRulePrefix = "hop-release: "
+
+var releaseIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Side effect free functions
+
+// validateReleaseID makes sure a release ID is usable as a single storage path segment
+func validateReleaseID(id string) error {
+	if !releaseIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid release ID '%s': use letters, digits, '.', '_' and '-' only", id)
+	}
+	return nil
+}
+
+// releaseRemoteDir returns the storage directory a release is uploaded to
+func releaseRemoteDir(id string) string {
+	return releasesDir + "/" + id
+}
+
+// releaseOriginURL returns the absolute origin URL a release is served from, the origin of the pull zone with the release directory appended
+func releaseOriginURL(zoneOrigin, releaseID string) (string, error) {
+	base := strings.TrimRight(zoneOrigin, "/")
+	if base == "" {
+		return "", fmt.Errorf("the pull zone has no origin URL to serve release '%s' from", releaseID)
+	}
+	origin := base + "/" + releaseRemoteDir(releaseID)
+	if err := validateOriginURL(origin); err != nil {
+		return "", err
+	}
+	return origin, nil
+}
+
+// buildReleaseRule builds the hop-managed edge rule that serves every request from origin, the URL of the release directory
+func buildReleaseRule(guid, releaseID, origin string) EdgeRule {
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          2, // OriginUrl
+		ActionParameter1:    origin,
+		TriggerMatchingType: 0, // MatchAny
+		Description:         releaseRulePrefix + releaseID,
+		Enabled:             true,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      []string{"*"},
+				PatternMatchingType: 0, // MatchAny
+			},
+		},
+	}
+}
+
+// releaseLeftOut describes the local files a push to a release left out, "" when the release holds the whole directory.
+// Activating a release that lacks files would take them off the site. Hidden files and symlinks are skipped by default
+// and never served from the live directory either, so only --include, --exclude and --max-file-size count.
+func releaseLeftOut(scan LocalScanStats, results []FileUploadStatus) string {
+	var parts []string
+	if scan.NotIncluded > 0 {
+		parts = append(parts, fmt.Sprintf("%d not matching --include", scan.NotIncluded))
+	}
+	if scan.Excluded > 0 {
+		parts = append(parts, fmt.Sprintf("%d excluded", scan.Excluded))
+	}
+	oversize := 0
+	for _, result := range results {
+		if result.Skipped && result.Reason == oversizeSkipReason {
+			oversize++
+		}
+	}
+	if oversize > 0 {
+		parts = append(parts, fmt.Sprintf("%d larger than --max-file-size", oversize))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// findReleaseRule returns the hop-managed release rule, or nil if the zone has none
+func findReleaseRule(rules []EdgeRuleResponse) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if strings.HasPrefix(rule.Description, releaseRulePrefix) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// activeReleaseID returns the release the zone currently serves, or "" if none is active
+func activeReleaseID(rules []EdgeRuleResponse) string {
+	rule := findReleaseRule(rules)
+	if rule == nil {
+		return ""
+	}
+	return strings.TrimPrefix(rule.Description, releaseRulePrefix)
+}
+
+// sortReleasesNewestFirst orders release directories by modification time, newest first
+func sortReleasesNewestFirst(releases []RemoteFileInfo) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		if !releases[i].LastModified.Equal(releases[j].LastModified.Time) {
+			return releases[i].LastModified.After(releases[j].LastModified.Time)
+		}
+		return releases[i].Name > releases[j].Name
+	})
+}
+
+// selectReleasesToPrune returns the releases to delete, keeping the newest `keep` and always the active one
+func selectReleasesToPrune(releases []RemoteFileInfo, active string, keep int) []string {
+	sorted := make([]RemoteFileInfo, len(releases))
+	copy(sorted, releases)
+	sortReleasesNewestFirst(sorted)
+
+	var prune []string
+	kept := 0
+	for _, release := range sorted {
+		if release.Name == active {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		prune = append(prune, release.Name)
+	}
+	return prune
+}
+
+// Side effect functions (HTTP calls)
+
+// listReleases returns the release directories in the storage zone
+func listReleases(ctx context.Context, storageZone *StorageZone) ([]RemoteFileInfo, error) {
+	entries, err := listRemoteFiles(ctx, storageZone, releasesDir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing releases: %v", err)
+	}
+
+	var releases []RemoteFileInfo
+	for _, entry := range entries {
+		if entry.IsDirectory {
+			releases = append(releases, entry)
+		}
+	}
+	return releases, nil
+}
+
+// activateRelease creates or updates the hop-managed release rule to serve the given release
+func activateRelease(ctx context.Context, apiKey, zoneID, releaseID string) error {
+	zone, err := getPullZoneDetails(ctx, apiKey, zoneID)
+	if err != nil {
+		return fmt.Errorf("error getting pull zone details: %v", err)
+	}
+	origin, err := releaseOriginURL(zone.OriginUrl, releaseID)
+	if err != nil {
+		return err
+	}
+
+	guid := ""
+	if existing := findReleaseRule(zone.EdgeRules); existing != nil {
+		guid = existing.Guid
+	}
+
+	if err := addEdgeRule(ctx, apiKey, zoneID, buildReleaseRule(guid, releaseID, origin)); err != nil {
+		return fmt.Errorf("error updating release rule: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateReleaseID(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		wantError bool
+	}{
+		{name: "date and commit", id: "2024-06-01-abc123", wantError: false},
+		{name: "dots and underscores", id: "v1.2.3_rc1", wantError: false},
+		{name: "empty", id: "", wantError: true},
+		{name: "path separator", id: "a/b", wantError: true},
+		{name: "parent directory", id: "..", wantError: true},
+		{name: "leading dash", id: "-abc", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateReleaseID(tt.id)
+			if (err != nil) != tt.wantError {
+				t.Errorf("validateReleaseID(%q) error = %v, wantError %v", tt.id, err, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestReleaseOriginURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		zoneOrigin string
+		want       string
+		wantError  bool
+	}{
+		{name: "origin without slash", zoneOrigin: "https://origin.example.com", want: "https://origin.example.com/releases/r1"},
+		{name: "origin with path and slash", zoneOrigin: "https://origin.example.com/site/", want: "https://origin.example.com/site/releases/r1"},
+		{name: "no origin", zoneOrigin: "", wantError: true},
+		{name: "origin without scheme", zoneOrigin: "origin.example.com", wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := releaseOriginURL(tt.zoneOrigin, "r1")
+			if (err != nil) != tt.wantError {
+				t.Fatalf("releaseOriginURL(%q) error = %v, wantError %v", tt.zoneOrigin, err, tt.wantError)
+			}
+			if got != tt.want {
+				t.Errorf("releaseOriginURL(%q) = %q, want %q", tt.zoneOrigin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseLeftOut(t *testing.T) {
+	uploaded := []FileUploadStatus{{RelPath: "index.html", Success: true}}
+	tests := []struct {
+		name    string
+		scan    LocalScanStats
+		results []FileUploadStatus
+		want    string
+	}{
+		{name: "whole directory", results: uploaded, want: ""},
+		{name: "excluded files", scan: LocalScanStats{Excluded: 2}, results: uploaded, want: "2 excluded"},
+		{name: "include and hidden", scan: LocalScanStats{NotIncluded: 3, Hidden: []string{".htaccess"}}, want: "3 not matching --include"},
+		{name: "hidden files and symlinks are not left out", scan: LocalScanStats{Hidden: []string{".DS_Store"}, Symlinks: []string{"latest"}}, results: uploaded, want: ""},
+		{
+			name:    "oversize skipped",
+			scan:    LocalScanStats{Excluded: 1, Symlinks: []string{"latest"}},
+			results: append([]FileUploadStatus{{RelPath: "video.mp4", Success: true, Skipped: true, Reason: oversizeSkipReason}}, uploaded...),
+			want:    "1 excluded, 1 larger than --max-file-size",
+		},
+		{name: "unchanged files are not left out", results: []FileUploadStatus{{RelPath: "a.css", Success: true, Skipped: true, Reason: "checksum match"}}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := releaseLeftOut(tt.scan, tt.results); got != tt.want {
+				t.Errorf("releaseLeftOut() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushReleaseWithExcludeIsNotActivated(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, "docs", "guide.html"), "guide")
+
+	code := run([]string{"cdn", "push", "--key", fakeAPIKey, "--zone", "site", "--from", localDir, "--release", "r1", "--exclude", "docs/**"})
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if got, want := fb.fileNames(), []string{"site/releases/r1/index.html"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stored files = %v, want %v", got, want)
+	}
+	if rules := fb.edgeRules(1); len(rules) != 0 {
+		t.Errorf("edge rules = %+v, want the release left inactive", rules)
+	}
+}
+
+func TestPushReleaseSkippingHiddenFilesAndSymlinksIsActivated(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site")
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "home")
+	writeTestFile(t, filepath.Join(localDir, ".DS_Store"), "finder")
+	writeTestFile(t, filepath.Join(localDir, ".git", "HEAD"), "ref: refs/heads/main")
+	symlink(t, "index.html", filepath.Join(localDir, "home.html"))
+
+	code := run([]string{"cdn", "push", "--key", fakeAPIKey, "--zone", "site", "--from", localDir, "--release", "r1"})
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+	if got, want := fb.fileNames(), []string{"site/releases/r1/index.html"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stored files = %v, want %v", got, want)
+	}
+	if rules := fb.edgeRules(1); len(rules) != 1 || !strings.HasSuffix(rules[0].ActionParameter1, "/releases/r1") {
+		t.Errorf("edge rules = %+v, want the release activated", rules)
+	}
+}
+
+func TestSelectReleasesToPrune(t *testing.T) {
+	base := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	release := func(name string, day int) RemoteFileInfo {
+		return RemoteFileInfo{Name: name, IsDirectory: true, LastModified: BunnyTime{base.AddDate(0, 0, day)}}
+	}
+	releases := []RemoteFileInfo{
+		release("r2", 2),
+		release("r4", 4),
+		release("r1", 1),
+		release("r3", 3),
+	}
+
+	tests := []struct {
+		name   string
+		active string
+		keep   int
+		want   []string
+	}{
+		{name: "keep two newest", active: "r4", keep: 2, want: []string{"r1"}},
+		{name: "active old release is never pruned", active: "r1", keep: 1, want: []string{"r3", "r2"}},
+		{name: "keep zero prunes everything but active", active: "r4", keep: 0, want: []string{"r3", "r2", "r1"}},
+		{name: "no active release", active: "", keep: 3, want: []string{"r1"}},
+		{name: "keep more than exist", active: "r4", keep: 10, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectReleasesToPrune(releases, tt.active, tt.keep)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectReleasesToPrune() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveReleaseID(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		{Guid: "a", ActionType: 1, Description: "302 redirect from /a to /b"},
+		{Guid: "b", ActionType: 2, Description: releaseRulePrefix + "2024-06-01-abc123"},
+	}
+
+	if got := activeReleaseID(rules); got != "2024-06-01-abc123" {
+		t.Errorf("activeReleaseID() = %q, want %q", got, "2024-06-01-abc123")
+	}
+	if got := activeReleaseID(rules[:1]); got != "" {
+		t.Errorf("activeReleaseID() without release rule = %q, want empty", got)
+	}
+}
+
+func TestReleaseWorkflowEndToEnd(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "<h1>v1</h1>")
+	writeTestFile(t, filepath.Join(localDir, "css", "site.css"), "body {}")
+
+	ctx := context.Background()
+
+	pullZoneID, err := findPullZoneByName(ctx, fakeAPIKey, "site")
+	if err != nil {
+		t.Fatalf("findPullZoneByName() error = %v", err)
+	}
+	storageZone, err := getStorageZoneByPullZone(ctx, fakeAPIKey, pullZoneID)
+	if err != nil {
+		t.Fatalf("getStorageZoneByPullZone() error = %v", err)
+	}
+
+	push := func(releaseID string) {
+		t.Helper()
+		for _, result := range uploadDirectoryOptimized(ctx, storageZone, localDir, releaseRemoteDir(releaseID), PushOptions{}).Files {
+			if !result.Success {
+				t.Fatalf("upload of %s failed: %v", result.Path, result.Error)
+			}
+		}
+		if err := activateRelease(ctx, fakeAPIKey, "1", releaseID); err != nil {
+			t.Fatalf("activateRelease(%s) error = %v", releaseID, err)
+		}
+	}
+
+	push("r1")
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "<h1>v2</h1>")
+	push("r2")
+
+	wantFiles := []string{
+		"site/releases/r1/css/site.css",
+		"site/releases/r1/index.html",
+		"site/releases/r2/css/site.css",
+		"site/releases/r2/index.html",
+	}
+	if got := fb.fileNames(); !reflect.DeepEqual(got, wantFiles) {
+		t.Fatalf("stored files = %v, want %v", got, wantFiles)
+	}
+
+	rules := fb.edgeRules(1)
+	if len(rules) != 1 {
+		t.Fatalf("got %d edge rules, want exactly one hop-managed release rule", len(rules))
+	}
+	if rules[0].Description != releaseRulePrefix+"r2" || rules[0].ActionParameter1 != "https://origin.example.com/releases/r2" {
+		t.Errorf("release rule = %q -> %q, want r2 active", rules[0].Description, rules[0].ActionParameter1)
+	}
+
+	releases, err := listReleases(ctx, storageZone)
+	if err != nil {
+		t.Fatalf("listReleases() error = %v", err)
+	}
+	toPrune := selectReleasesToPrune(releases, activeReleaseID(rules), 0)
+	if !reflect.DeepEqual(toPrune, []string{"r1"}) {
+		t.Fatalf("selectReleasesToPrune() = %v, want [r1]", toPrune)
+	}
+	for _, releaseID := range toPrune {
+		if err := deleteRemotePath(ctx, storageZone, releaseRemoteDir(releaseID)+"/"); err != nil {
+			t.Fatalf("deleteRemotePath(%s) error = %v", releaseID, err)
+		}
+	}
+
+	wantFiles = []string{"site/releases/r2/css/site.css", "site/releases/r2/index.html"}
+	if got := fb.fileNames(); !reflect.DeepEqual(got, wantFiles) {
+		t.Errorf("stored files after prune = %v, want %v", got, wantFiles)
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
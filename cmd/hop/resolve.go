@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// defaultParkingNetworks lists address ranges of well-known domain parking services
+var defaultParkingNetworks = []string{
+	"34.102.136.180/32", // GoDaddy parked pages
+	"91.195.240.0/23",   // Sedo
+	"185.53.176.0/22",   // ParkingCrew / Team Internet
+	"199.59.240.0/22",   // Bodis
+}
+
+// defaultParkingCNAMEs lists CNAME target suffixes of well-known domain parking services
+var defaultParkingCNAMEs = []string{
+	"parkingcrew.net",
+	"sedoparking.com",
+	"bodis.com",
+	"parklogic.com",
+	"above.com",
+}
+
+// ParkingDetector holds the address ranges and CNAME suffixes that identify parked domains
+type ParkingDetector struct {
+	Networks []*net.IPNet
+	CNAMEs   []string
+}
+
+// hostResolution is the outcome of resolving a destination host
+type hostResolution struct {
+	Addresses []string
+	CNAME     string
+	NotFound  bool
+	Err       error
+}
+
+// hostResolver resolves destination hosts and caches the result per host within one check run
+type hostResolver struct {
+	lookupHost  func(ctx context.Context, host string) ([]string, error)
+	lookupCNAME func(ctx context.Context, host string) (string, error)
+
+	mu    sync.Mutex
+	cache map[string]hostResolution
+}
+
+// Side effect free functions
+
+// newParkingDetector builds a detector from the built-in lists plus extra IPs, CIDRs or CNAME suffixes
+func newParkingDetector(extra []string) (*ParkingDetector, error) {
+	detector := &ParkingDetector{}
+
+	for _, pattern := range append(append([]string{}, defaultParkingNetworks...), defaultParkingCNAMEs...) {
+		if err := detector.add(pattern); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range extra {
+		if err := detector.add(pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	return detector, nil
+}
+
+// add registers a single IP, CIDR or CNAME suffix
+func (d *ParkingDetector) add(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil
+	}
+
+	if strings.Contains(pattern, "/") {
+		_, network, err := net.ParseCIDR(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid parking network '%s': %v", pattern, err)
+		}
+		d.Networks = append(d.Networks, network)
+		return nil
+	}
+
+	if ip := net.ParseIP(pattern); ip != nil {
+		bits := 32
+		if ip.To4() == nil {
+			bits = 128
+		}
+		d.Networks = append(d.Networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		return nil
+	}
+
+	d.CNAMEs = append(d.CNAMEs, strings.ToLower(strings.TrimSuffix(pattern, ".")))
+	return nil
+}
+
+// isParked reports whether a resolution points at a parking service, with the matched address or CNAME
+func (d *ParkingDetector) isParked(res hostResolution) (bool, string) {
+	cname := strings.ToLower(strings.TrimSuffix(res.CNAME, "."))
+	if cname != "" {
+		for _, suffix := range d.CNAMEs {
+			if cname == suffix || strings.HasSuffix(cname, "."+suffix) {
+				return true, cname
+			}
+		}
+	}
+
+	for _, address := range res.Addresses {
+		ip := net.ParseIP(address)
+		if ip == nil {
+			continue
+		}
+		for _, network := range d.Networks {
+			if network.Contains(ip) {
+				return true, address
+			}
+		}
+	}
+
+	return false, ""
+}
+
+// classifyResolution turns a resolution into a check issue, or nil when the host looks fine
+func classifyResolution(res hostResolution, detector *ParkingDetector) *CheckIssue {
+	if res.NotFound {
+		return &CheckIssue{
+			Type:     IssueURLHealth,
+			Severity: SeverityError,
+			Message:  "Destination domain does not resolve",
+		}
+	}
+
+	if detector != nil {
+		if parked, match := detector.isParked(res); parked {
+			return &CheckIssue{
+				Type:     IssueURLHealth,
+				Severity: SeverityWarning,
+				Message:  "Destination appears to be a parked domain",
+				Details:  map[string]interface{}{"parking_match": match},
+			}
+		}
+	}
+
+	return nil
+}
+
+// Side effect functions (DNS lookups)
+
+func newHostResolver() *hostResolver {
+	return &hostResolver{
+		lookupHost:  net.DefaultResolver.LookupHost,
+		lookupCNAME: net.DefaultResolver.LookupCNAME,
+		cache:       make(map[string]hostResolution),
+	}
+}
+
+// resolve looks up a host once per run, later calls return the cached result
+func (r *hostResolver) resolve(ctx context.Context, host string) hostResolution {
+	host = strings.ToLower(host)
+
+	r.mu.Lock()
+	if res, exists := r.cache[host]; exists {
+		r.mu.Unlock()
+		return res
+	}
+	r.mu.Unlock()
+
+	var res hostResolution
+	addresses, err := r.lookupHost(ctx, host)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			res.NotFound = true
+		} else {
+			res.Err = err
+		}
+	} else {
+		res.Addresses = addresses
+		if cname, err := r.lookupCNAME(ctx, host); err == nil && !strings.EqualFold(strings.TrimSuffix(cname, "."), host) {
+			res.CNAME = cname
+		}
+	}
+
+	r.mu.Lock()
+	r.cache[host] = res
+	r.mu.Unlock()
+
+	return res
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestParkingDetectorIsParked(t *testing.T) {
+	detector, err := newParkingDetector([]string{"203.0.113.7", "198.51.100.0/24", "parked.example"})
+	if err != nil {
+		t.Fatalf("newParkingDetector() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		resolution hostResolution
+		wantParked bool
+		wantMatch  string
+	}{
+		{
+			name:       "built-in parking network",
+			resolution: hostResolution{Addresses: []string{"185.53.177.31"}},
+			wantParked: true,
+			wantMatch:  "185.53.177.31",
+		},
+		{
+			name:       "built-in parking CNAME",
+			resolution: hostResolution{CNAME: "oldbrand.com.sedoparking.com.", Addresses: []string{"192.0.2.1"}},
+			wantParked: true,
+			wantMatch:  "oldbrand.com.sedoparking.com",
+		},
+		{
+			name:       "extra single IP",
+			resolution: hostResolution{Addresses: []string{"203.0.113.7"}},
+			wantParked: true,
+			wantMatch:  "203.0.113.7",
+		},
+		{
+			name:       "extra CIDR",
+			resolution: hostResolution{Addresses: []string{"192.0.2.1", "198.51.100.99"}},
+			wantParked: true,
+			wantMatch:  "198.51.100.99",
+		},
+		{
+			name:       "extra CNAME is matched case-insensitively",
+			resolution: hostResolution{CNAME: "Lander.PARKED.example."},
+			wantParked: true,
+			wantMatch:  "lander.parked.example",
+		},
+		{
+			name:       "CNAME suffix must match a whole label",
+			resolution: hostResolution{CNAME: "notbodis.com"},
+			wantParked: false,
+		},
+		{
+			name:       "regular host",
+			resolution: hostResolution{Addresses: []string{"192.0.2.1"}, CNAME: "www.example.com"},
+			wantParked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotParked, gotMatch := detector.isParked(tt.resolution)
+			if gotParked != tt.wantParked || gotMatch != tt.wantMatch {
+				t.Errorf("isParked() = (%v, %q), want (%v, %q)", gotParked, gotMatch, tt.wantParked, tt.wantMatch)
+			}
+		})
+	}
+}
+
+func TestNewParkingDetectorRejectsInvalidCIDR(t *testing.T) {
+	if _, err := newParkingDetector([]string{"10.0.0.0/99"}); err == nil {
+		t.Error("newParkingDetector() expected error for invalid CIDR")
+	}
+}
+
+func TestClassifyResolution(t *testing.T) {
+	detector, err := newParkingDetector(nil)
+	if err != nil {
+		t.Fatalf("newParkingDetector() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		resolution   hostResolution
+		wantSeverity string
+	}{
+		{name: "NXDOMAIN is an error", resolution: hostResolution{NotFound: true}, wantSeverity: "error"},
+		{name: "parked domain is a warning", resolution: hostResolution{Addresses: []string{"199.59.243.200"}}, wantSeverity: "warning"},
+		{name: "healthy host", resolution: hostResolution{Addresses: []string{"192.0.2.1"}}, wantSeverity: ""},
+		{name: "lookup failure other than NXDOMAIN is left to the HTTP check", resolution: hostResolution{Err: errors.New("timeout")}, wantSeverity: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issue := classifyResolution(tt.resolution, detector)
+			gotSeverity := ""
+			if issue != nil {
+				gotSeverity = issue.Severity
+			}
+			if gotSeverity != tt.wantSeverity {
+				t.Errorf("classifyResolution() severity = %q, want %q", gotSeverity, tt.wantSeverity)
+			}
+		})
+	}
+}
+
+func TestHostResolverCachesPerHost(t *testing.T) {
+	lookups := 0
+	resolver := newHostResolver()
+	resolver.lookupHost = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		if host == "gone.example" {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return []string{"192.0.2.1"}, nil
+	}
+	resolver.lookupCNAME = func(ctx context.Context, host string) (string, error) {
+		return host + ".", nil
+	}
+
+	ctx := context.Background()
+	for range 3 {
+		if res := resolver.resolve(ctx, "Example.com"); res.NotFound || len(res.Addresses) != 1 || res.CNAME != "" {
+			t.Fatalf("resolve(example.com) = %+v, want one address and no CNAME", res)
+		}
+		if res := resolver.resolve(ctx, "gone.example"); !res.NotFound {
+			t.Fatalf("resolve(gone.example) = %+v, want NotFound", res)
+		}
+	}
+	resolver.resolve(ctx, "example.com")
+
+	if lookups != 2 {
+		t.Errorf("lookups = %d, want 2 (one per distinct host)", lookups)
+	}
+}
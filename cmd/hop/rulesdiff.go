@@ -0,0 +1,83 @@
+package main
+
+import (
+	"sort"
+)
+
+// RedirectSnapshot is one side of a redirect in the JSON output of rules diff
+type RedirectSnapshot struct {
+	Destination string `json:"destination"`
+	StatusCode  string `json:"statusCode"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// RedirectDiffEntry is a source path whose redirect differs between two zones, a side is nil if the source is missing there
+type RedirectDiffEntry struct {
+	Source string            `json:"source"`
+	A      *RedirectSnapshot `json:"a,omitempty"`
+	B      *RedirectSnapshot `json:"b,omitempty"`
+}
+
+// RulesDiffReport is the JSON output of rules diff
+type RulesDiffReport struct {
+	ZoneA     string              `json:"zoneA"`
+	ZoneB     string              `json:"zoneB"`
+	OnlyInA   []RedirectDiffEntry `json:"onlyInA"`
+	OnlyInB   []RedirectDiffEntry `json:"onlyInB"`
+	Changed   []RedirectDiffEntry `json:"changed"`
+	Unchanged int                 `json:"unchanged"`
+}
+
+// Side effect free functions
+
+// hasDifferences reports whether the two rule sets differ in any redirect
+func (d RulesDiff) hasDifferences() bool {
+	return len(d.Missing) > 0 || len(d.Extra) > 0 || len(d.Changed) > 0
+}
+
+// sortRulesDiff orders every section of a diff by source path, so the output does not depend on API rule order
+func sortRulesDiff(diff RulesDiff) RulesDiff {
+	for _, changes := range [][]RuleChange{diff.Missing, diff.Extra, diff.Changed} {
+		sort.SliceStable(changes, func(i, j int) bool {
+			return changes[i].Source < changes[j].Source
+		})
+	}
+	return diff
+}
+
+// redirectSnapshot returns the JSON form of a redirect, nil for a missing side
+func redirectSnapshot(rule *EdgeRuleResponse) *RedirectSnapshot {
+	if rule == nil {
+		return nil
+	}
+	return &RedirectSnapshot{
+		Destination: rule.ActionParameter1,
+		StatusCode:  rule.ActionParameter2,
+		Enabled:     rule.Enabled,
+	}
+}
+
+// redirectDiffEntries converts rule changes to their JSON form, never nil so empty sections encode as []
+func redirectDiffEntries(changes []RuleChange) []RedirectDiffEntry {
+	entries := []RedirectDiffEntry{}
+	for _, change := range changes {
+		entries = append(entries, RedirectDiffEntry{
+			Source: change.Source,
+			A:      redirectSnapshot(change.From),
+			B:      redirectSnapshot(change.To),
+		})
+	}
+	return entries
+}
+
+// buildRulesDiffReport converts a sorted diff between zone A and zone B to its JSON form
+func buildRulesDiffReport(diff RulesDiff, zoneA, zoneB string) RulesDiffReport {
+	return RulesDiffReport{
+		ZoneA:     zoneA,
+		ZoneB:     zoneB,
+		OnlyInA:   redirectDiffEntries(diff.Missing),
+		OnlyInB:   redirectDiffEntries(diff.Extra),
+		Changed:   redirectDiffEntries(diff.Changed),
+		Unchanged: diff.Unchanged,
+	}
+}
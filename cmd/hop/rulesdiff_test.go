@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSortRulesDiff(t *testing.T) {
+	status := redirectRule("a3", "/status", "https://example.com/s")
+	status.ActionParameter2 = "301"
+	zoneA := []EdgeRuleResponse{
+		redirectRule("a1", "/zebra", "https://example.com/z"),
+		redirectRule("a2", "/apple", "https://example.com/a"),
+		status,
+		redirectRule("a4", "/same", "https://example.com/same"),
+	}
+	zoneB := []EdgeRuleResponse{
+		redirectRule("b1", "/same", "https://example.com/same"),
+		redirectRule("b2", "/status", "https://example.com/s"),
+		redirectRule("b3", "/mango", "https://example.com/m"),
+		redirectRule("b4", "/banana", "https://example.com/b"),
+	}
+
+	diff := sortRulesDiff(diffRedirectRules(zoneA, zoneB))
+
+	sources := func(changes []RuleChange) string {
+		var s []string
+		for _, change := range changes {
+			s = append(s, change.Source)
+		}
+		return strings.Join(s, ",")
+	}
+	if got := sources(diff.Missing); got != "/apple,/zebra" {
+		t.Errorf("only in A = %s, want /apple,/zebra", got)
+	}
+	if got := sources(diff.Extra); got != "/banana,/mango" {
+		t.Errorf("only in B = %s, want /banana,/mango", got)
+	}
+	if got := sources(diff.Changed); got != "/status" {
+		t.Errorf("changed = %s, want /status (status code differs)", got)
+	}
+	if diff.Unchanged != 1 || !diff.hasDifferences() {
+		t.Errorf("Unchanged = %d, hasDifferences = %v", diff.Unchanged, diff.hasDifferences())
+	}
+}
+
+func TestRulesDiffHasDifferences(t *testing.T) {
+	rules := []EdgeRuleResponse{redirectRule("a1", "/old", "https://example.com/new")}
+	same := []EdgeRuleResponse{redirectRule("b1", "/old", "https://example.com/new")}
+
+	if diffRedirectRules(rules, same).hasDifferences() {
+		t.Error("identical redirects should not differ")
+	}
+	if !diffRedirectRules(rules, nil).hasDifferences() {
+		t.Error("a redirect missing in one zone should differ")
+	}
+}
+
+func TestBuildRulesDiffReport(t *testing.T) {
+	changedB := redirectRule("b1", "/dest", "https://example.com/new")
+	changedB.Enabled = false
+	diff := diffRedirectRules(
+		[]EdgeRuleResponse{redirectRule("a1", "/dest", "https://example.com/old")},
+		[]EdgeRuleResponse{changedB},
+	)
+
+	data, err := json.Marshal(buildRulesDiffReport(diff, "staging", "prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"zoneA":"staging","zoneB":"prod","onlyInA":[],"onlyInB":[],` +
+		`"changed":[{"source":"/dest","a":{"destination":"https://example.com/old","statusCode":"302","enabled":true},` +
+		`"b":{"destination":"https://example.com/new","statusCode":"302","enabled":false}}],"unchanged":0}`
+	if string(data) != want {
+		t.Errorf("report =\n%s\nwant\n%s", data, want)
+	}
+}
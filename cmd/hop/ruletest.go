@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxSimulatedHops stops a simulated redirect chain like the chain check does
+const maxSimulatedHops = 10
+
+// How a simulated request chain ends
+const (
+	simulationServed     = "served"     // no redirect applies, the zone answers the request
+	simulationExternal   = "external"   // the chain leaves the zone
+	simulationLoop       = "loop"       // the chain comes back to a URL it already visited
+	simulationTooLong    = "too_long"   // more than maxSimulatedHops redirects
+	simulationUnresolved = "unresolved" // a destination uses a variable the simulation cannot fill in
+)
+
+// SimulatedHop is one request of a simulated redirect chain, Rule is nil when no redirect applies
+type SimulatedHop struct {
+	URL      string
+	Rule     *EdgeRuleResponse
+	Status   string
+	Location string
+}
+
+// Simulation is the redirect chain a request goes through in a pull zone
+type Simulation struct {
+	Hops []SimulatedHop
+	End  string
+}
+
+// LiveProbe is the first response of a real request
+type LiveProbe struct {
+	Status   int
+	Location string
+}
+
+// probeRedirect requests a URL without following redirects and returns the status code and Location header
+func probeRedirect(ctx context.Context, targetURL string) (LiveProbe, error) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", targetURL, nil)
+	if err != nil {
+		return LiveProbe{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return LiveProbe{}, err
+	}
+	defer drainAndClose(resp.Body)
+
+	return LiveProbe{Status: resp.StatusCode, Location: resp.Header.Get("Location")}, nil
+}
+
+// displaySimulation prints every hop of a simulated chain and how it ends
+func displaySimulation(w io.Writer, sim Simulation) {
+	for i, hop := range sim.Hops {
+		fmt.Fprintf(w, "\n%d. %s\n", i+1, hop.URL)
+		if hop.Rule == nil {
+			fmt.Fprintf(w, "   No redirect applies\n")
+			continue
+		}
+		fmt.Fprintf(w, "   Rule: %s\n", hop.Rule.Description)
+		fmt.Fprintf(w, "   GUID: %s\n", hop.Rule.Guid)
+		fmt.Fprintf(w, "   Order: %d\n", hop.Rule.OrderIndex)
+		fmt.Fprintf(w, "   %s Location: %s\n", hop.Status, hop.Location)
+	}
+
+	fmt.Fprintln(w)
+	last := sim.Hops[len(sim.Hops)-1]
+	switch sim.End {
+	case simulationServed:
+		fmt.Fprintf(w, "Result: %s is served by the zone\n", last.URL)
+	case simulationExternal:
+		fmt.Fprintf(w, "Result: redirected out of the zone to %s\n", last.Location)
+	case simulationLoop:
+		fmt.Fprintf(w, "Result: ERROR redirect loop, %s was already visited\n", last.Location)
+	case simulationTooLong:
+		fmt.Fprintf(w, "Result: ERROR more than %d redirects\n", maxSimulatedHops)
+	case simulationUnresolved:
+		fmt.Fprintf(w, "Result: redirected to %s, the simulation cannot fill in its variables\n", last.Location)
+	}
+}
+
+// Side effect free functions
+
+// simulateRequest follows a request through the redirect rules of a zone, rules must be in evaluation order.
+// The chain continues while redirects stay on one of the zone's hostnames.
+func simulateRequest(rules []EdgeRuleResponse, zoneHostnames []Hostname, startURL string) (Simulation, error) {
+	current, err := url.Parse(startURL)
+	if err != nil {
+		return Simulation{}, fmt.Errorf("invalid URL '%s': %v", startURL, err)
+	}
+
+	var sim Simulation
+	visited := map[string]bool{}
+	for {
+		visited[current.String()] = true
+		hop := SimulatedHop{URL: current.String()}
+
+		rule := firstRedirectForURL(rules, current)
+		if rule == nil {
+			sim.Hops = append(sim.Hops, hop)
+			sim.End = simulationServed
+			return sim, nil
+		}
+		hop.Rule = rule
+		hop.Status = rule.ActionParameter2
+		location, resolved := resolveRedirectLocation(rule.ActionParameter1, current)
+		hop.Location = location
+		sim.Hops = append(sim.Hops, hop)
+
+		next, err := url.Parse(location)
+		switch {
+		case !resolved:
+			sim.End = simulationUnresolved
+			return sim, nil
+		case err != nil || !isZoneHost(next.Host, zoneHostnames):
+			sim.End = simulationExternal
+			return sim, nil
+		case visited[next.String()]:
+			sim.End = simulationLoop
+			return sim, nil
+		case len(sim.Hops) >= maxSimulatedHops:
+			sim.End = simulationTooLong
+			return sim, nil
+		}
+		current = next
+	}
+}
+
+// firstRedirectForURL returns the first enabled redirect whose triggers apply to the URL
+func firstRedirectForURL(rules []EdgeRuleResponse, target *url.URL) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if rule.ActionType == 1 && rule.Enabled && ruleAppliesToURL(rule, target) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// resolveRedirectLocation fills in the request variables of a destination and resolves it against the request URL.
+// It reports false when the destination still contains a placeholder it cannot fill in.
+func resolveRedirectLocation(destination string, request *url.URL) (string, bool) {
+	path := request.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	location := strings.NewReplacer(
+		"%{Url.Path}", path,
+		"%{Url.Query}", request.RawQuery,
+		"%{Url.Hostname}", request.Hostname(),
+	).Replace(destination)
+	location = strings.TrimSuffix(location, "?")
+
+	if hasPathPlaceholder(location) {
+		return location, false
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return location, true
+	}
+	return request.ResolveReference(ref).String(), true
+}
+
+// simulationStartURL turns a --path value into the URL of the first request, paths go to host
+func simulationStartURL(path, host string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return "https://" + host + path
+}
+
+// compareLiveProbe returns how a real first response differs from the first simulated hop, nil when it matches
+func compareLiveProbe(sim Simulation, probe LiveProbe) []string {
+	first := sim.Hops[0]
+	observedRedirect := probe.Status >= 300 && probe.Status < 400
+
+	if first.Rule == nil {
+		if observedRedirect {
+			return []string{fmt.Sprintf("expected no redirect, got %d to %s", probe.Status, probe.Location)}
+		}
+		return nil
+	}
+
+	if !observedRedirect {
+		return []string{fmt.Sprintf("expected a %s redirect to %s, got status %d", first.Status, first.Location, probe.Status)}
+	}
+	observed := probe.Location
+	if base, err := url.Parse(first.URL); err == nil {
+		if ref, err := url.Parse(observed); err == nil {
+			observed = base.ResolveReference(ref).String()
+		}
+	}
+
+	var mismatches []string
+	if first.Status != "" && first.Status != fmt.Sprintf("%d", probe.Status) {
+		mismatches = append(mismatches, fmt.Sprintf("expected status %s, got %d", first.Status, probe.Status))
+	}
+	if first.Location != observed {
+		mismatches = append(mismatches, fmt.Sprintf("expected Location %s, got %s", first.Location, observed))
+	}
+	return mismatches
+}
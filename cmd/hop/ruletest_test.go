@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSimulateRequest(t *testing.T) {
+	hostnames := []Hostname{{Value: "example.com"}, {Value: "www.example.com"}, {Value: "site.b-cdn.net"}}
+	disabled := redirectRule("disabled", "/products/old-widget", "https://example.com/disabled")
+	disabled.Enabled = false
+	matchAll := redirectRule("match-all", "/promo/*", "https://example.com/summer")
+	matchAll.TriggerMatchingType = 1
+	matchAll.Triggers = append(matchAll.Triggers, Trigger{Type: 0, PatternMatches: []string{"*?utm_source=*"}, PatternMatchingType: 2})
+
+	rules := []EdgeRuleResponse{
+		disabled,
+		redirectRule("widget", "/products/old-widget", "https://example.com/products/widget"),
+		redirectRule("widget-2", "/products/widget", "/products/widget-v2"),
+		redirectRule("wildcard", "*/products/*", "https://shop.example.com/"),
+		redirectRule("loop-a", "/a", "https://www.example.com/b"),
+		redirectRule("loop-b", "/b", "https://example.com/a"),
+		redirectRule("host", "*://www.example.com/*", "https://example.com%{Url.Path}?%{Url.Query}"),
+		redirectRule("capture", "/docs/*", "https://docs.example.com/$1"),
+		matchAll,
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		wantEnd  string
+		wantHops []string // winning rule GUID per hop, "" when no rule applies
+		wantLast string   // Location of the last hop
+	}{
+		{
+			name:     "chain within the zone and out of it",
+			url:      "https://example.com/products/old-widget",
+			wantEnd:  simulationExternal,
+			wantHops: []string{"widget", "widget-2", "wildcard"},
+			wantLast: "https://shop.example.com/",
+		},
+		{
+			name:     "no redirect",
+			url:      "https://example.com/about",
+			wantEnd:  simulationServed,
+			wantHops: []string{""},
+		},
+		{
+			name:     "host redirect keeps path and query",
+			url:      "https://www.example.com/about?x=1",
+			wantEnd:  simulationServed,
+			wantHops: []string{"host", ""},
+		},
+		{
+			name:     "loop",
+			url:      "https://example.com/a",
+			wantEnd:  simulationLoop,
+			wantHops: []string{"loop-a", "loop-b"},
+			wantLast: "https://example.com/a",
+		},
+		{
+			name:     "unresolved capture",
+			url:      "https://example.com/docs/intro",
+			wantEnd:  simulationUnresolved,
+			wantHops: []string{"capture"},
+			wantLast: "https://docs.example.com/$1",
+		},
+		{
+			name:     "all triggers match",
+			url:      "https://example.com/promo/july",
+			wantEnd:  simulationServed,
+			wantHops: []string{"match-all", ""},
+		},
+		{
+			name:     "excluded by a MatchNone trigger",
+			url:      "https://example.com/promo/july?utm_source=mail",
+			wantEnd:  simulationServed,
+			wantHops: []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim, err := simulateRequest(rules, hostnames, tt.url)
+			if err != nil {
+				t.Fatalf("simulateRequest() error = %v", err)
+			}
+			var hops []string
+			for _, hop := range sim.Hops {
+				guid := ""
+				if hop.Rule != nil {
+					guid = hop.Rule.Guid
+				}
+				hops = append(hops, guid)
+			}
+			if sim.End != tt.wantEnd || !reflect.DeepEqual(hops, tt.wantHops) {
+				t.Errorf("simulateRequest() = %s %v, want %s %v", sim.End, hops, tt.wantEnd, tt.wantHops)
+			}
+			if last := sim.Hops[len(sim.Hops)-1]; tt.wantLast != "" && last.Location != tt.wantLast {
+				t.Errorf("last Location = %s, want %s", last.Location, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestSimulateRequestStopsLongChains(t *testing.T) {
+	var rules []EdgeRuleResponse
+	for i := 0; i < maxSimulatedHops+2; i++ {
+		rules = append(rules, redirectRule("r", "/"+strings.Repeat("x", i+1), "/"+strings.Repeat("x", i+2)))
+	}
+	sim, err := simulateRequest(rules, []Hostname{{Value: "example.com"}}, "https://example.com/x")
+	if err != nil {
+		t.Fatalf("simulateRequest() error = %v", err)
+	}
+	if sim.End != simulationTooLong || len(sim.Hops) != maxSimulatedHops {
+		t.Errorf("simulateRequest() = %s after %d hops, want %s after %d", sim.End, len(sim.Hops), simulationTooLong, maxSimulatedHops)
+	}
+}
+
+func TestResolveRedirectLocation(t *testing.T) {
+	request, _ := url.Parse("https://www.example.com/docs/a%20b?page=2")
+	tests := []struct {
+		destination  string
+		want         string
+		wantResolved bool
+	}{
+		{destination: "https://example.com/new", want: "https://example.com/new", wantResolved: true},
+		{destination: "/new", want: "https://www.example.com/new", wantResolved: true},
+		{destination: "https://example.com%{Url.Path}?%{Url.Query}", want: "https://example.com/docs/a%20b?page=2", wantResolved: true},
+		{destination: "https://%{Url.Hostname}/x", want: "https://www.example.com/x", wantResolved: true},
+		{destination: "https://example.com/{{path}}", want: "https://example.com/{{path}}"},
+	}
+	for _, tt := range tests {
+		got, resolved := resolveRedirectLocation(tt.destination, request)
+		if got != tt.want || resolved != tt.wantResolved {
+			t.Errorf("resolveRedirectLocation(%s) = %s %v, want %s %v", tt.destination, got, resolved, tt.want, tt.wantResolved)
+		}
+	}
+
+	noQuery, _ := url.Parse("https://example.com/a")
+	if got, _ := resolveRedirectLocation("https://example.com%{Url.Path}?%{Url.Query}", noQuery); got != "https://example.com/a" {
+		t.Errorf("an empty query should not leave a dangling '?', got %s", got)
+	}
+}
+
+func TestSimulationStartURL(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{path: "/products/old-widget", want: "https://example.com/products/old-widget"},
+		{path: "products", want: "https://example.com/products"},
+		{path: "http://www.example.com/x", want: "http://www.example.com/x"},
+	}
+	for _, tt := range tests {
+		if got := simulationStartURL(tt.path, "example.com"); got != tt.want {
+			t.Errorf("simulationStartURL(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCompareLiveProbe(t *testing.T) {
+	rule := redirectRule("widget", "/old", "https://example.com/new")
+	redirected := Simulation{Hops: []SimulatedHop{{URL: "https://example.com/old", Rule: &rule, Status: "302", Location: "https://example.com/new"}}}
+	served := Simulation{Hops: []SimulatedHop{{URL: "https://example.com/new"}}}
+
+	tests := []struct {
+		name  string
+		sim   Simulation
+		probe LiveProbe
+		want  int
+	}{
+		{name: "matching redirect", sim: redirected, probe: LiveProbe{Status: 302, Location: "https://example.com/new"}},
+		{name: "relative Location", sim: redirected, probe: LiveProbe{Status: 302, Location: "/new"}},
+		{name: "other status and Location", sim: redirected, probe: LiveProbe{Status: 301, Location: "https://example.com/other"}, want: 2},
+		{name: "no redirect observed", sim: redirected, probe: LiveProbe{Status: 200}, want: 1},
+		{name: "served as expected", sim: served, probe: LiveProbe{Status: 200}},
+		{name: "unexpected redirect", sim: served, probe: LiveProbe{Status: 301, Location: "/x"}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareLiveProbe(tt.sim, tt.probe); len(got) != tt.want {
+				t.Errorf("compareLiveProbe() = %v, want %d mismatches", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeRedirectDoesNotFollow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	probe, err := probeRedirect(context.Background(), server.URL+"/old")
+	if err != nil {
+		t.Fatalf("probeRedirect() error = %v", err)
+	}
+	if probe.Status != http.StatusMovedPermanently || probe.Location != "/new" {
+		t.Errorf("probeRedirect() = %+v, want 301 to /new", probe)
+	}
+}
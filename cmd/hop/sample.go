@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HealthSample limits health checks to a percentage or an absolute number of destinations
+type HealthSample struct {
+	Percent float64
+	Count   int
+}
+
+// SampleCoverage describes how much of a zone a sampled health check covered
+type SampleCoverage struct {
+	Checked      int
+	Total        int
+	HostsCovered int
+	Hosts        int
+}
+
+// Side effect free functions
+
+// parseHealthSample parses a sample size like "10%" or "200"
+func parseHealthSample(value string) (HealthSample, error) {
+	value = strings.TrimSpace(value)
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p <= 0 || p > 100 {
+			return HealthSample{}, fmt.Errorf("invalid sample percentage '%s', expected a value between 0 and 100", value)
+		}
+		return HealthSample{Percent: p}, nil
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count <= 0 {
+		return HealthSample{}, fmt.Errorf("invalid sample size '%s', expected a positive count or a percentage like 10%%", value)
+	}
+	return HealthSample{Count: count}, nil
+}
+
+// size returns how many of total destinations the sample should check
+func (s HealthSample) size(total int) int {
+	n := s.Count
+	if s.Percent > 0 {
+		n = int(math.Ceil(float64(total) * s.Percent / 100))
+	}
+	return min(max(n, 1), total)
+}
+
+// sampleLabel describes the requested sample size, e.g. "10%" or "200 destinations"
+func sampleLabel(s HealthSample) string {
+	if s.Percent > 0 {
+		return strconv.FormatFloat(s.Percent, 'f', -1, 64) + "%"
+	}
+	return formatCount(s.Count) + " destinations"
+}
+
+// sampleScore orders rules pseudo-randomly but reproducibly for a given seed
+func sampleScore(seed string, rule EdgeRuleResponse) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(seed + "|" + rule.Guid + "|" + rule.ActionParameter1))
+	return h.Sum64()
+}
+
+// sampleHealthRules deterministically picks the redirect rules whose destinations get health checked.
+// Every distinct destination host gets at least one probe, even if that exceeds the requested size;
+// the remaining slots are filled in seed order, so rotating the seed (e.g. by date) covers different rules across runs.
+// The returned rules keep their original order.
+func sampleHealthRules(rules []EdgeRuleResponse, sample HealthSample, seed string) ([]EdgeRuleResponse, SampleCoverage) {
+	type candidate struct {
+		index int
+		host  string
+		score uint64
+	}
+
+	var candidates []candidate
+	for i, rule := range rules {
+		if rule.ActionType != 1 || !strings.HasPrefix(rule.ActionParameter1, "http") {
+			continue
+		}
+		candidates = append(candidates, candidate{index: i, host: sampleHost(rule), score: sampleScore(seed, rule)})
+	}
+
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return candidates[a].score < candidates[b].score
+	})
+
+	selected := make(map[int]bool)
+	hosts := make(map[string]bool)
+	for _, c := range candidates {
+		if !hosts[c.host] {
+			hosts[c.host] = true
+			selected[c.index] = true
+		}
+	}
+
+	target := sample.size(len(candidates))
+	for _, c := range candidates {
+		if len(selected) >= target {
+			break
+		}
+		selected[c.index] = true
+	}
+
+	var sampled []EdgeRuleResponse
+	for i, rule := range rules {
+		if selected[i] {
+			sampled = append(sampled, rule)
+		}
+	}
+
+	return sampled, SampleCoverage{
+		Checked:      len(sampled),
+		Total:        len(candidates),
+		HostsCovered: countSampleHosts(sampled),
+		Hosts:        len(hosts),
+	}
+}
+
+// sampleHost returns the lowercase destination host of a redirect rule, "" when its destination does not parse
+func sampleHost(rule EdgeRuleResponse) string {
+	destURL, err := url.Parse(rule.ActionParameter1)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(destURL.Hostname())
+}
+
+// countSampleHosts returns the number of distinct destination hosts among the absolute redirects in rules
+func countSampleHosts(rules []EdgeRuleResponse) int {
+	hosts := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.ActionType == 1 && strings.HasPrefix(rule.ActionParameter1, "http") {
+			hosts[sampleHost(rule)] = true
+		}
+	}
+	return len(hosts)
+}
+
+// formatCount formats an integer with thousands separators
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	digits := strconv.Itoa(n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// String summarizes the coverage for the check report
+func (c SampleCoverage) String() string {
+	hostCoverage := 100
+	if c.Hosts > 0 {
+		hostCoverage = c.HostsCovered * 100 / c.Hosts
+	}
+	return fmt.Sprintf("checked %s of %s destinations, host coverage %d%%", formatCount(c.Checked), formatCount(c.Total), hostCoverage)
+}
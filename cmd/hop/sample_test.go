@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseHealthSample(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    HealthSample
+		wantErr bool
+	}{
+		{value: "10%", want: HealthSample{Percent: 10}},
+		{value: "2.5%", want: HealthSample{Percent: 2.5}},
+		{value: "200", want: HealthSample{Count: 200}},
+		{value: "0%", wantErr: true},
+		{value: "150%", wantErr: true},
+		{value: "0", wantErr: true},
+		{value: "ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseHealthSample(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHealthSample(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseHealthSample(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// sampleTestRules builds redirects spread over a few hosts, with one host owning most of them
+func sampleTestRules() []EdgeRuleResponse {
+	var rules []EdgeRuleResponse
+	for i := range 100 {
+		host := "main.example.com"
+		switch {
+		case i == 10:
+			host = "partner.example.org"
+		case i == 50:
+			host = "Shop.Example.net"
+		case i%20 == 0:
+			host = "blog.example.com"
+		}
+		rules = append(rules, EdgeRuleResponse{
+			Guid:             fmt.Sprintf("guid-%d", i),
+			ActionType:       1,
+			ActionParameter1: fmt.Sprintf("https://%s/page-%d", host, i),
+		})
+	}
+	rules = append(rules,
+		EdgeRuleResponse{Guid: "relative", ActionType: 1, ActionParameter1: "/local"},
+		EdgeRuleResponse{Guid: "header", ActionType: 5, ActionParameter1: "https://ignored.example"},
+	)
+	return rules
+}
+
+func sampleGuids(rules []EdgeRuleResponse) string {
+	guids := make([]string, len(rules))
+	for i, rule := range rules {
+		guids[i] = rule.Guid
+	}
+	return strings.Join(guids, ",")
+}
+
+func TestSampleHealthRulesIsDeterministic(t *testing.T) {
+	rules := sampleTestRules()
+
+	first, _ := sampleHealthRules(rules, HealthSample{Percent: 10}, "2026-10-16")
+	second, _ := sampleHealthRules(rules, HealthSample{Percent: 10}, "2026-10-16")
+	if sampleGuids(first) != sampleGuids(second) {
+		t.Errorf("same seed produced different samples:\n%s\n%s", sampleGuids(first), sampleGuids(second))
+	}
+
+	other, _ := sampleHealthRules(rules, HealthSample{Percent: 10}, "2026-10-17")
+	if sampleGuids(first) == sampleGuids(other) {
+		t.Errorf("different seeds produced the same sample: %s", sampleGuids(first))
+	}
+}
+
+func TestSampleHealthRulesCoversEveryHost(t *testing.T) {
+	rules := sampleTestRules()
+
+	for _, seed := range []string{"2026-10-16", "2026-10-17", "2026-10-18", "x"} {
+		sampled, coverage := sampleHealthRules(rules, HealthSample{Count: 1}, seed)
+
+		hosts := make(map[string]bool)
+		for _, rule := range sampled {
+			hosts[strings.ToLower(strings.Split(rule.ActionParameter1, "/")[2])] = true
+		}
+		for _, host := range []string{"main.example.com", "blog.example.com", "partner.example.org", "shop.example.net"} {
+			if !hosts[host] {
+				t.Errorf("seed %q: host %s not covered by sample %s", seed, host, sampleGuids(sampled))
+			}
+		}
+		if len(sampled) != 4 {
+			t.Errorf("seed %q: sampled %d rules, want one per host (4)", seed, len(sampled))
+		}
+		if coverage.Total != 100 || coverage.Hosts != 4 || coverage.HostsCovered != 4 {
+			t.Errorf("seed %q: coverage = %+v", seed, coverage)
+		}
+	}
+}
+
+func TestSampleHealthRulesSizeAndOrder(t *testing.T) {
+	rules := sampleTestRules()
+
+	sampled, coverage := sampleHealthRules(rules, HealthSample{Percent: 10}, "2026-10-16")
+	if len(sampled) != 10 || coverage.Checked != 10 {
+		t.Errorf("sampled %d rules (coverage %d), want 10", len(sampled), coverage.Checked)
+	}
+
+	index := make(map[string]int)
+	for i, rule := range rules {
+		index[rule.Guid] = i
+	}
+	for i := 1; i < len(sampled); i++ {
+		if index[sampled[i-1].Guid] > index[sampled[i].Guid] {
+			t.Errorf("sample is not in rule order: %s", sampleGuids(sampled))
+			break
+		}
+	}
+
+	all, _ := sampleHealthRules(rules, HealthSample{Count: 1000}, "2026-10-16")
+	if len(all) != 100 {
+		t.Errorf("oversized sample returned %d rules, want all 100 http redirects", len(all))
+	}
+}
+
+func TestCountSampleHosts(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []EdgeRuleResponse
+		want  int
+	}{
+		{name: "none"},
+		{
+			name:  "host case is ignored",
+			rules: []EdgeRuleResponse{redirectRule("a", "/a", "https://Example.com/a"), redirectRule("b", "/b", "https://example.com/b")},
+			want:  1,
+		},
+		{
+			name:  "distinct hosts",
+			rules: []EdgeRuleResponse{redirectRule("a", "/a", "https://example.com/a"), redirectRule("b", "/b", "https://partner.example.org/")},
+			want:  2,
+		},
+		{
+			name:  "relative destinations are not counted",
+			rules: []EdgeRuleResponse{redirectRule("a", "/a", "/new"), redirectRule("b", "/b", "https://example.com/b")},
+			want:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countSampleHosts(tt.rules); got != tt.want {
+				t.Errorf("countSampleHosts() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSampleCoverageString(t *testing.T) {
+	coverage := SampleCoverage{Checked: 200, Total: 1950, HostsCovered: 12, Hosts: 12}
+	want := "checked 200 of 1,950 destinations, host coverage 100%"
+	if got := coverage.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	partial := SampleCoverage{Checked: 3, Total: 40, HostsCovered: 3, Hosts: 4}
+	if got, want := partial.String(), "checked 3 of 40 destinations, host coverage 75%"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := map[int]string{0: "0", 999: "999", 1000: "1,000", 1950: "1,950", 1234567: "1,234,567", -4200: "-4,200"}
+	for n, want := range tests {
+		if got := formatCount(n); got != want {
+			t.Errorf("formatCount(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
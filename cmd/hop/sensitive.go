@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// hopStateFiles are hop's own bookkeeping files, they are never uploaded
+var hopStateFiles = []string{".hop-manifest.json", ".hop-journal.json", checksumCacheName}
+
+// defaultSensitivePatterns match files that must not end up on a public CDN by accident
+var defaultSensitivePatterns = []string{".env*", "*.pem", "*.key", "id_rsa*", "hop.yaml", ".hop-manifest.json", ".hop-journal.json", checksumCacheName}
+
+// Side effect free functions
+
+// isHopStateFile reports whether a relative path is one of hop's own state files
+func isHopStateFile(relPath string) bool {
+	base := path.Base(filepath.ToSlash(relPath))
+	for _, name := range hopStateFiles {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSensitivePattern returns the first pattern matching a relative path.
+// Patterns without a slash match the file name at any depth, patterns with a slash match the whole relative path.
+func matchSensitivePattern(relPath string, patterns []string) (string, bool) {
+	relPath = filepath.ToSlash(relPath)
+	base := path.Base(relPath)
+	for _, pattern := range patterns {
+		target := base
+		if strings.Contains(pattern, "/") {
+			target = relPath
+		}
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(target)); matched {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// findSensitiveFiles returns the sorted relative paths matching the default or extra sensitive patterns
+func findSensitiveFiles(relPaths []string, extraPatterns []string) []string {
+	patterns := append(append([]string{}, defaultSensitivePatterns...), extraPatterns...)
+
+	var sensitive []string
+	for _, relPath := range relPaths {
+		if isHopStateFile(relPath) {
+			continue
+		}
+		if _, matched := matchSensitivePattern(relPath, patterns); matched {
+			sensitive = append(sensitive, filepath.ToSlash(relPath))
+		}
+	}
+	sort.Strings(sensitive)
+	return sensitive
+}
+
+// scanSensitiveFiles walks a local directory and returns the files that must not be uploaded, files the filter leaves out are not reported
+func scanSensitiveFiles(localDir string, extraPatterns []string, filter PathFilter, walk LocalWalkOptions) ([]string, error) {
+	relPaths, err := listLocalRelPaths(localDir, walk)
+	if err != nil {
+		return nil, err
+	}
+	return findSensitiveFiles(filter.selectPaths(relPaths), extraPatterns), nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindSensitiveFiles(t *testing.T) {
+	relPaths := []string{
+		"index.html",
+		".env",
+		"config/.env.production",
+		"deep/nested/certs/server.PEM",
+		"keys/site.key",
+		"home/id_rsa.pub",
+		"hop.yaml",
+		"docs/keyboard.html",
+		".hop-manifest.json",
+		"sub/.hop-journal.json",
+		"secrets/token.txt",
+	}
+
+	tests := []struct {
+		name  string
+		extra []string
+		want  []string
+	}{
+		{
+			name: "default patterns match at any depth",
+			want: []string{".env", "config/.env.production", "deep/nested/certs/server.PEM", "home/id_rsa.pub", "hop.yaml", "keys/site.key"},
+		},
+		{
+			name:  "extra pattern with a slash matches the relative path",
+			extra: []string{"secrets/*"},
+			want:  []string{".env", "config/.env.production", "deep/nested/certs/server.PEM", "home/id_rsa.pub", "hop.yaml", "keys/site.key", "secrets/token.txt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findSensitiveFiles(relPaths, tt.extra)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("findSensitiveFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsHopStateFile(t *testing.T) {
+	tests := map[string]bool{
+		".hop-manifest.json":       true,
+		"nested/.hop-journal.json": true,
+		"hop-manifest.json":        false,
+		"index.html":               false,
+	}
+	for relPath, want := range tests {
+		if got := isHopStateFile(relPath); got != want {
+			t.Errorf("isHopStateFile(%q) = %v, want %v", relPath, got, want)
+		}
+	}
+}
+
+func TestSensitiveFileGuard(t *testing.T) {
+	localDir := t.TempDir()
+	writeTestFile(t, filepath.Join(localDir, "index.html"), "<h1>hi</h1>")
+	writeTestFile(t, filepath.Join(localDir, "assets", "private", "tls.pem"), "secret")
+	writeTestFile(t, filepath.Join(localDir, ".hop-journal.json"), "{}")
+
+	sensitive, err := scanSensitiveFiles(localDir, nil, PathFilter{}, LocalWalkOptions{})
+	if err != nil {
+		t.Fatalf("scanSensitiveFiles() error = %v", err)
+	}
+	if strings.Join(sensitive, ",") != "assets/private/tls.pem" {
+		t.Errorf("scanSensitiveFiles() = %v, want only the nested pem file", sensitive)
+	}
+
+	// With --allow-sensitive the scan is skipped and the file is uploaded, but hop's state files never are
+	localFiles, _, err := buildLocalFileMap(localDir, PathFilter{}, LocalWalkOptions{})
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+	if _, exists := localFiles["assets/private/tls.pem"]; !exists {
+		t.Error("buildLocalFileMap() dropped a sensitive file that --allow-sensitive should upload")
+	}
+	if _, exists := localFiles[".hop-journal.json"]; exists {
+		t.Error("buildLocalFileMap() included hop's own journal file")
+	}
+}
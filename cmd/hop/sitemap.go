@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxSitemapBytes is the size limit of one uncompressed sitemap file from the sitemaps.org protocol
+const maxSitemapBytes = 50 * 1024 * 1024
+
+// maxSitemapFiles limits how many sitemaps of an index are followed, so a misconfigured index cannot fetch forever
+const maxSitemapFiles = 1000
+
+// Sitemap holds the page URLs of a urlset and the child sitemap URLs of a sitemap index
+type Sitemap struct {
+	URLs     []string
+	Sitemaps []string
+}
+
+type sitemapXML struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// fetchSitemap downloads and parses one sitemap, gzip-compressed sitemaps are unpacked
+func fetchSitemap(ctx context.Context, sitemapURL string) (*Sitemap, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", sitemapURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching sitemap %s: %v", sitemapURL, err)
+	}
+	defer drainAndClose(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sitemap %s returned status %s", sitemapURL, resp.Status)
+	}
+
+	var body io.Reader = bufio.NewReader(resp.Body)
+	if magic, _ := body.(*bufio.Reader).Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("error unpacking sitemap %s: %v", sitemapURL, err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxSitemapBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading sitemap %s: %v", sitemapURL, err)
+	}
+	if len(data) > maxSitemapBytes {
+		return nil, fmt.Errorf("sitemap %s is larger than 50 MB", sitemapURL)
+	}
+
+	sitemap, err := parseSitemap(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", sitemapURL, err)
+	}
+	return sitemap, nil
+}
+
+// fetchSitemapURLs returns the page URLs of a sitemap, following sitemap indexes; every sitemap is fetched once
+func fetchSitemapURLs(ctx context.Context, sitemapURL string) ([]string, error) {
+	var urls []string
+	seen := map[string]bool{sitemapURL: true}
+	queue := []string{sitemapURL}
+	for len(queue) > 0 {
+		if len(seen) > maxSitemapFiles {
+			return nil, fmt.Errorf("sitemap index references more than %d sitemaps", maxSitemapFiles)
+		}
+		current := queue[0]
+		queue = queue[1:]
+
+		sitemap, err := fetchSitemap(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, sitemap.URLs...)
+		for _, child := range sitemap.Sitemaps {
+			if !seen[child] {
+				seen[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return urls, nil
+}
+
+// Side effect free functions
+
+// parseSitemap parses a sitemap urlset or sitemap index document
+func parseSitemap(data []byte) (*Sitemap, error) {
+	var doc sitemapXML
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing sitemap: %v", err)
+	}
+
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("unexpected sitemap root element '%s'", doc.XMLName.Local)
+	}
+
+	sitemap := &Sitemap{}
+	for _, entry := range doc.URLs {
+		if loc := strings.TrimSpace(entry.Loc); loc != "" {
+			sitemap.URLs = append(sitemap.URLs, loc)
+		}
+	}
+	for _, entry := range doc.Sitemaps {
+		if loc := strings.TrimSpace(entry.Loc); loc != "" {
+			sitemap.Sitemaps = append(sitemap.Sitemaps, loc)
+		}
+	}
+
+	return sitemap, nil
+}
+
+// sitemapURLPath returns the path of a sitemap URL, or "" if it cannot be parsed
+func sitemapURLPath(loc string) string {
+	parsed, err := url.Parse(loc)
+	if err != nil {
+		return ""
+	}
+	if parsed.Path == "" {
+		return "/"
+	}
+	return parsed.Path
+}
+
+// sitemapRedirectRule is a redirect the sitemap check matches URLs against
+type sitemapRedirectRule struct {
+	rule *EdgeRuleResponse
+	host string // host pattern of a full-URL source, "" when the source is a path
+	path string // normalized path pattern
+}
+
+// SitemapMatcher finds the redirect that applies to a URL in memory: exact sources and "*/path" sources by map lookup,
+// other wildcard sources only when the URL starts with their literal prefix
+type SitemapMatcher struct {
+	exact     map[string][]sitemapRedirectRule
+	suffix    map[string][]sitemapRedirectRule // "*/path" sources by "/path"
+	wildcards []sitemapRedirectRule
+	complex   []*EdgeRuleResponse // several triggers or exclusions, such as the slash policy, evaluated trigger by trigger
+}
+
+// newSitemapMatcher indexes the enabled redirects of the redirect map by source
+func newSitemapMatcher(rm *RedirectMap) *SitemapMatcher {
+	m := &SitemapMatcher{exact: make(map[string][]sitemapRedirectRule), suffix: make(map[string][]sitemapRedirectRule)}
+
+	sources := make([]string, 0, len(rm.Rules))
+	for source := range rm.Rules {
+		sources = append(sources, source)
+	}
+	// Evaluation order, so the first candidate that matches is the rule Bunny applies
+	sort.SliceStable(sources, func(i, j int) bool {
+		return rm.Rules[sources[i]].OrderIndex < rm.Rules[sources[j]].OrderIndex
+	})
+
+	for _, source := range sources {
+		rule := rm.Rules[source]
+		if !rule.Enabled {
+			continue
+		}
+		// Only a single MatchAny URL trigger matches by its source
+		if len(rule.Triggers) != 1 || rule.TriggerMatchingType == 2 || rule.Triggers[0].Type != 0 || rule.Triggers[0].PatternMatchingType != 0 {
+			m.complex = append(m.complex, rule)
+			continue
+		}
+		entry := sitemapRedirectRule{rule: rule, path: normalizeURL(triggerPatternPath(source))}
+		if idx := strings.Index(source, "://"); idx >= 0 {
+			host, _, _ := strings.Cut(source[idx+3:], "/")
+			entry.host = strings.ToLower(host)
+		}
+		switch rest := strings.TrimPrefix(entry.path, "*"); {
+		case !strings.Contains(entry.path, "*"):
+			m.exact[entry.path] = append(m.exact[entry.path], entry)
+		case strings.HasPrefix(rest, "/") && !strings.Contains(rest, "*"):
+			m.suffix[rest] = append(m.suffix[rest], entry)
+		default:
+			m.wildcards = append(m.wildcards, entry)
+		}
+	}
+	return m
+}
+
+// match returns the redirect that applies to a page URL, nil if none does
+func (m *SitemapMatcher) match(pageURL string) *EdgeRuleResponse {
+	parsed, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+	urlPath := normalizeURL(sitemapURLPath(pageURL))
+	host := strings.ToLower(parsed.Host)
+
+	var best *EdgeRuleResponse
+	consider := func(entry sitemapRedirectRule) {
+		if entry.host != "" && !wildcardMatch(entry.host, host) {
+			return
+		}
+		if best == nil || entry.rule.OrderIndex < best.OrderIndex {
+			best = entry.rule
+		}
+	}
+
+	for _, entry := range m.exact[urlPath] {
+		consider(entry)
+	}
+	for i := range urlPath {
+		if urlPath[i] != '/' {
+			continue
+		}
+		for _, entry := range m.suffix[urlPath[i:]] {
+			consider(entry)
+		}
+	}
+	for _, entry := range m.wildcards {
+		prefix, _, _ := strings.Cut(entry.path, "*")
+		if strings.HasPrefix(urlPath, prefix) && wildcardMatch(entry.path, urlPath) {
+			consider(entry)
+		}
+	}
+	for _, rule := range m.complex {
+		if ruleAppliesToURL(*rule, parsed) {
+			consider(sitemapRedirectRule{rule: rule})
+		}
+	}
+	return best
+}
+
+// ruleAppliesToURL evaluates all triggers of a rule for a URL, rules with trigger types other than Url and UrlExtension never apply
+func ruleAppliesToURL(rule EdgeRuleResponse, pageURL *url.URL) bool {
+	if len(rule.Triggers) == 0 {
+		return false
+	}
+	target := strings.ToLower(pageURL.Path)
+	if target == "" {
+		target = "/"
+	}
+	if pageURL.RawQuery != "" {
+		target += "?" + strings.ToLower(pageURL.RawQuery)
+	}
+	host := strings.ToLower(pageURL.Host)
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(pageURL.Path), "."))
+
+	matched := 0
+	for _, trigger := range rule.Triggers {
+		hits := 0
+		for _, pattern := range trigger.PatternMatches {
+			pattern = strings.ToLower(pattern)
+			switch trigger.Type {
+			case 0: // Url trigger
+				if idx := strings.Index(pattern, "://"); idx >= 0 {
+					patternHost, _, _ := strings.Cut(pattern[idx+3:], "/")
+					if !wildcardMatch(patternHost, host) {
+						continue
+					}
+				}
+				if wildcardMatch(triggerPatternPath(pattern), target) {
+					hits++
+				}
+			case 3: // UrlExtension trigger
+				if strings.TrimPrefix(pattern, ".") == ext {
+					hits++
+				}
+			default:
+				return false
+			}
+		}
+		if matchesByType(trigger.PatternMatchingType, hits, len(trigger.PatternMatches)) {
+			matched++
+		}
+	}
+	return matchesByType(rule.TriggerMatchingType, matched, len(rule.Triggers))
+}
+
+// matchesByType combines hits out of total by a Bunny matching type: 0 MatchAny, 1 MatchAll, 2 MatchNone
+func matchesByType(matchingType, hits, total int) bool {
+	switch matchingType {
+	case 1:
+		return hits == total
+	case 2:
+		return hits == 0
+	default:
+		return hits > 0
+	}
+}
+
+// checkSitemapURLs reports every sitemap URL a redirect applies to as an error, live pages should never redirect
+func checkSitemapURLs(urls []string, rules []EdgeRuleResponse) []CheckIssue {
+	matcher := newSitemapMatcher(buildRedirectMap(rules))
+
+	var issues []CheckIssue
+	seen := make(map[string]bool)
+	for _, pageURL := range urls {
+		if seen[pageURL] {
+			continue
+		}
+		seen[pageURL] = true
+
+		rule := matcher.match(pageURL)
+		if rule == nil {
+			continue
+		}
+		issues = append(issues, CheckIssue{
+			Type:     IssueSitemap,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Sitemap URL %s is redirected to %s", pageURL, rule.ActionParameter1),
+			Rule:     rule,
+			Details:  map[string]interface{}{"url": pageURL, "destination": rule.ActionParameter1},
+		})
+	}
+	return issues
+}
+
+// SitemapRedirect is a redirected sitemap URL in the JSON output of rules check-sitemap
+type SitemapRedirect struct {
+	URL         string `json:"url"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	StatusCode  string `json:"statusCode"`
+	Guid        string `json:"guid"`
+}
+
+// SitemapCheckReport is the JSON output of rules check-sitemap
+type SitemapCheckReport struct {
+	Sitemap    string            `json:"sitemap"`
+	URLs       int               `json:"urls"`
+	Redirected []SitemapRedirect `json:"redirected"`
+}
+
+// buildSitemapCheckReport converts the sitemap issues to their JSON form, Redirected is never nil
+func buildSitemapCheckReport(sitemapURL string, urlCount int, issues []CheckIssue) SitemapCheckReport {
+	report := SitemapCheckReport{Sitemap: sitemapURL, URLs: urlCount, Redirected: []SitemapRedirect{}}
+	for _, issue := range issues {
+		report.Redirected = append(report.Redirected, SitemapRedirect{
+			URL:         fmt.Sprint(issue.Details["url"]),
+			Source:      extractSourceURL(*issue.Rule),
+			Destination: issue.Rule.ActionParameter1,
+			StatusCode:  issue.Rule.ActionParameter2,
+			Guid:        issue.Rule.Guid,
+		})
+	}
+	return report
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSitemap(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantURLs     []string
+		wantSitemaps []string
+		wantError    bool
+	}{
+		{
+			name: "urlset",
+			data: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/</loc></url>
+  <url><loc> https://example.com/blog/2019/post/ </loc><lastmod>2019-01-01</lastmod></url>
+</urlset>`,
+			wantURLs: []string{"https://example.com/", "https://example.com/blog/2019/post/"},
+		},
+		{
+			name: "sitemap index",
+			data: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-posts.xml</loc></sitemap>
+</sitemapindex>`,
+			wantSitemaps: []string{"https://example.com/sitemap-posts.xml"},
+		},
+		{
+			name:      "not a sitemap",
+			data:      `<html><body>hello</body></html>`,
+			wantError: true,
+		},
+		{
+			name:      "malformed XML",
+			data:      `<urlset><url>`,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSitemap([]byte(tt.data))
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("parseSitemap() expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSitemap() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.URLs, tt.wantURLs) {
+				t.Errorf("parseSitemap() URLs = %v, want %v", got.URLs, tt.wantURLs)
+			}
+			if !reflect.DeepEqual(got.Sitemaps, tt.wantSitemaps) {
+				t.Errorf("parseSitemap() Sitemaps = %v, want %v", got.Sitemaps, tt.wantSitemaps)
+			}
+		})
+	}
+}
+
+func TestSitemapURLPath(t *testing.T) {
+	tests := []struct {
+		loc  string
+		want string
+	}{
+		{loc: "https://example.com/blog/post", want: "/blog/post"},
+		{loc: "https://example.com", want: "/"},
+		{loc: "https://example.com/a?b=c", want: "/a"},
+		{loc: "://bad", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.loc, func(t *testing.T) {
+			if got := sitemapURLPath(tt.loc); got != tt.want {
+				t.Errorf("sitemapURLPath(%q) = %q, want %q", tt.loc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchSitemapURLs(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			// The index lists the pages sitemap twice and itself, each sitemap is fetched once
+			w.Write([]byte(`<sitemapindex>
+  <sitemap><loc>` + server.URL + `/pages.xml</loc></sitemap>
+  <sitemap><loc>` + server.URL + `/pages.xml</loc></sitemap>
+  <sitemap><loc>` + server.URL + `/posts.xml.gz</loc></sitemap>
+  <sitemap><loc>` + server.URL + `/sitemap.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/pages.xml":
+			w.Write([]byte(`<urlset><url><loc>https://example.com/</loc></url><url><loc>https://example.com/about</loc></url></urlset>`))
+		case "/posts.xml.gz":
+			var buf bytes.Buffer
+			gz := gzip.NewWriter(&buf)
+			gz.Write([]byte(`<urlset><url><loc>https://example.com/blog/post</loc></url></urlset>`))
+			gz.Close()
+			w.Write(buf.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	urls, err := fetchSitemapURLs(context.Background(), server.URL+"/sitemap.xml")
+	if err != nil {
+		t.Fatalf("fetchSitemapURLs() error = %v", err)
+	}
+	want := []string{"https://example.com/", "https://example.com/about", "https://example.com/blog/post"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("fetchSitemapURLs() = %v, want %v", urls, want)
+	}
+
+	if _, err := fetchSitemapURLs(context.Background(), server.URL+"/missing.xml"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("fetchSitemapURLs(missing) error = %v, want the status reported", err)
+	}
+}
+
+func TestCheckSitemapURLs(t *testing.T) {
+	disabled := redirectRule("disabled", "/contact", "https://example.com/new-contact")
+	disabled.Enabled = false
+	rules := []EdgeRuleResponse{
+		redirectRule("exact", "/old-page", "https://example.com/new-page"),
+		redirectRule("suffix", "*/legacy", "https://example.com/modern"),
+		redirectRule("prefix", "/blog/2019/*", "https://example.com/archive"),
+		redirectRule("host", "*://www.example.com/*", "https://example.com/"),
+		redirectRule("wide", "/docs/*", "https://docs.example.com/"),
+		redirectRule("shadowed", "/docs/intro", "https://example.com/intro"),
+		disabled,
+	}
+	for i := range rules {
+		rules[i].OrderIndex = i
+	}
+
+	tests := []struct {
+		url  string
+		want string // winning rule GUID, "" for no redirect
+	}{
+		{url: "https://example.com/old-page", want: "exact"},
+		{url: "https://example.com/OLD-PAGE/", want: "exact"},
+		{url: "https://example.com/legacy", want: "suffix"},
+		{url: "https://example.com/shop/legacy", want: "suffix"},
+		{url: "https://example.com/notlegacy", want: ""},
+		{url: "https://example.com/blog/2019/post", want: "prefix"},
+		{url: "https://example.com/blog/2020/post", want: ""},
+		{url: "https://www.example.com/about", want: "host"},
+		{url: "https://example.com/about", want: ""},
+		{url: "https://example.com/docs/intro", want: "wide"},
+		{url: "https://example.com/contact", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			issues := checkSitemapURLs([]string{tt.url}, rules)
+			got := ""
+			if len(issues) == 1 {
+				got = issues[0].Rule.Guid
+				if issues[0].Severity != "error" || issues[0].Type != "sitemap" {
+					t.Errorf("issue = %s %s, want an error of type sitemap", issues[0].Severity, issues[0].Type)
+				}
+			}
+			if got != tt.want || len(issues) > 1 {
+				t.Errorf("checkSitemapURLs(%s) = %s (%d issues), want %s", tt.url, got, len(issues), tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSitemapURLsEvaluatesAllTriggers(t *testing.T) {
+	policy, _ := buildSlashPolicyRule("policy", "add", "301")
+	rules := []EdgeRuleResponse{{Guid: "policy", ActionType: 1, ActionParameter1: policy.ActionParameter1, ActionParameter2: "301", Triggers: policy.Triggers, TriggerMatchingType: 1, Enabled: true}}
+
+	tests := []struct {
+		url  string
+		want int
+	}{
+		{url: "https://example.com/pricing", want: 1},
+		{url: "https://example.com/pricing/", want: 0},
+		{url: "https://example.com/pricing/?plan=pro", want: 0},
+		{url: "https://example.com/app.js", want: 0},
+	}
+	for _, tt := range tests {
+		if got := len(checkSitemapURLs([]string{tt.url}, rules)); got != tt.want {
+			t.Errorf("checkSitemapURLs(%s) = %d issues, want %d", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheckSitemapURLsReportsEachURLOnce(t *testing.T) {
+	rules := []EdgeRuleResponse{redirectRule("exact", "/old-page", "https://example.com/new-page")}
+	issues := checkSitemapURLs([]string{"https://example.com/old-page", "https://example.com/old-page"}, rules)
+	if len(issues) != 1 {
+		t.Errorf("checkSitemapURLs() = %d issues, want 1", len(issues))
+	}
+}
+
+func TestBuildSitemapCheckReport(t *testing.T) {
+	rules := []EdgeRuleResponse{redirectRule("exact", "/old-page", "https://example.com/new-page")}
+	urls := []string{"https://example.com/", "https://example.com/old-page"}
+
+	report := buildSitemapCheckReport("https://example.com/sitemap.xml", len(urls), checkSitemapURLs(urls, rules))
+	want := SitemapCheckReport{
+		Sitemap: "https://example.com/sitemap.xml",
+		URLs:    2,
+		Redirected: []SitemapRedirect{{
+			URL:         "https://example.com/old-page",
+			Source:      "/old-page",
+			Destination: "https://example.com/new-page",
+			StatusCode:  "302",
+			Guid:        "exact",
+		}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("buildSitemapCheckReport() = %+v, want %+v", report, want)
+	}
+
+	if empty := buildSitemapCheckReport("s", 0, nil); empty.Redirected == nil {
+		t.Error("Redirected should encode as [] when nothing is redirected")
+	}
+}
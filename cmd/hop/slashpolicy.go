@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// slashPolicyPrefix marks the hop-managed edge rule that enforces a trailing-slash policy
+const slashPolicyPrefix = "hop-slash-policy: "
+
+// slashPolicyFileExtensions are file extensions the slash policy leaves alone, /app.js must not become /app.js/
+var slashPolicyFileExtensions = []string{
+	"html", "htm", "css", "js", "mjs", "map", "json", "xml", "txt", "webmanifest",
+	"ico", "png", "jpg", "jpeg", "gif", "svg", "webp", "avif",
+	"woff", "woff2", "ttf", "otf", "eot",
+	"pdf", "zip", "gz", "mp3", "mp4", "webm", "wasm",
+}
+
+// Side effect free functions
+
+// buildSlashPolicyRule builds the single redirect that enforces a trailing-slash policy for the whole zone.
+// Only "add" can be expressed: Bunny destinations can append to %{Url.Path} but have no variable for the path without its last character.
+func buildSlashPolicyRule(guid, policy, status string) (EdgeRule, error) {
+	if policy != "add" {
+		return EdgeRule{}, fmt.Errorf("policy '%s' cannot be expressed as a single Bunny edge rule: a redirect destination can extend the request path but not remove its trailing slash", policy)
+	}
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          1, // Redirect
+		ActionParameter1:    withPreservedQuery("%{Url.Path}/"),
+		ActionParameter2:    status,
+		TriggerMatchingType: 1, // MatchAll
+		Description:         slashPolicyPrefix + policy,
+		Enabled:             true,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      []string{"*/", "*/?*"},
+				PatternMatchingType: 2, // MatchNone: paths that already end in a slash
+			},
+			{
+				Type:                3, // UrlExtension trigger
+				PatternMatches:      slashPolicyFileExtensions,
+				PatternMatchingType: 2, // MatchNone: files keep their form
+			},
+		},
+	}, nil
+}
+
+// findSlashPolicyRule returns the hop-managed slash policy rule, or nil if there is none
+func findSlashPolicyRule(rules []EdgeRuleResponse) *EdgeRuleResponse {
+	for i, rule := range rules {
+		if strings.HasPrefix(rule.Description, slashPolicyPrefix) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// slashPolicyConflicts returns the enabled per-path redirects whose source has the slash style the policy redirects away from.
+// Such a rule competes with the policy rule for the same requests, so which one wins depends on rule order.
+func slashPolicyConflicts(rules []EdgeRuleResponse, policy string) []EdgeRuleResponse {
+	var conflicts []EdgeRuleResponse
+	for _, rule := range rules {
+		if rule.ActionType != 1 || !rule.Enabled || strings.HasPrefix(rule.Description, slashPolicyPrefix) {
+			continue
+		}
+		for _, pattern := range urlTriggerPatterns(rule) {
+			if _, ok := slashVariant(pattern); !ok {
+				continue
+			}
+			base, _, _ := strings.Cut(triggerPatternPath(pattern), "?")
+			withSlash := strings.HasSuffix(base, "/")
+			isFile := !withSlash && isSlashPolicyFile(base)
+			if (policy == "add" && !withSlash && !isFile) || (policy == "strip" && withSlash) {
+				conflicts = append(conflicts, rule)
+				break
+			}
+		}
+	}
+	return conflicts
+}
+
+// isSlashPolicyFile reports whether a path ends in a file extension the slash policy leaves alone
+func isSlashPolicyFile(urlPath string) bool {
+	ext := strings.ToLower(strings.TrimPrefix(path.Ext(urlPath), "."))
+	for _, fileExt := range slashPolicyFileExtensions {
+		if ext == fileExt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSlashPolicyRule(t *testing.T) {
+	rule, err := buildSlashPolicyRule("", "add", "301")
+	if err != nil {
+		t.Fatalf("buildSlashPolicyRule(add) error = %v", err)
+	}
+	if rule.ActionParameter1 != "%{Url.Path}/?%{Url.Query}" || rule.ActionParameter2 != "301" {
+		t.Errorf("destination = %s %s, want the path with a slash and the query kept", rule.ActionParameter2, rule.ActionParameter1)
+	}
+	if rule.TriggerMatchingType != 1 || len(rule.Triggers) != 2 {
+		t.Fatalf("rule should match all of its two triggers, got %+v", rule.Triggers)
+	}
+	for _, trigger := range rule.Triggers {
+		if trigger.PatternMatchingType != 2 {
+			t.Errorf("trigger %d should exclude its patterns (MatchNone), got %d", trigger.Type, trigger.PatternMatchingType)
+		}
+	}
+	if rule.Description != "hop-slash-policy: add" {
+		t.Errorf("description = %s", rule.Description)
+	}
+
+	if _, err := buildSlashPolicyRule("", "strip", "301"); err == nil || !strings.Contains(err.Error(), "cannot be expressed") {
+		t.Errorf("buildSlashPolicyRule(strip) error = %v, want it rejected", err)
+	}
+}
+
+func TestSlashPolicyConflicts(t *testing.T) {
+	disabled := redirectRule("disabled", "/off", "https://example.com/off")
+	disabled.Enabled = false
+	policy := redirectRule("policy", "*/", "%{Url.Path}/")
+	policy.Description = "hop-slash-policy: add"
+	rules := []EdgeRuleResponse{
+		redirectRule("no-slash", "/about", "https://example.com/about"),
+		redirectRule("slash", "/team/", "https://example.com/team"),
+		redirectRule("file", "/old.html", "https://example.com/new"),
+		redirectRule("host", "https://www.example.com/contact", "https://example.com/contact"),
+		redirectRule("root", "/", "https://example.com/"),
+		redirectRule("wildcard", "/blog/*", "https://blog.example.com/"),
+		disabled,
+		policy,
+	}
+
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{policy: "add", want: "no-slash,host"},
+		{policy: "strip", want: "slash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			if got := ruleGuids(slashPolicyConflicts(rules, tt.policy)); got != tt.want {
+				t.Errorf("slashPolicyConflicts(%s) = %s, want %s", tt.policy, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindSlashPolicyRule(t *testing.T) {
+	policy := redirectRule("policy", "*/", "%{Url.Path}/")
+	policy.Description = "hop-slash-policy: add"
+	rules := []EdgeRuleResponse{redirectRule("other", "/a", "/b"), policy}
+
+	if got := findSlashPolicyRule(rules); got == nil || got.Guid != "policy" {
+		t.Errorf("findSlashPolicyRule() = %+v, want the policy rule", got)
+	}
+	if got := findSlashPolicyRule(rules[:1]); got != nil {
+		t.Errorf("findSlashPolicyRule() = %+v, want nil", got)
+	}
+}
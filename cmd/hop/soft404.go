@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// soft404BodyLimit caps how much of a destination body --detect-soft-404 reads
+const soft404BodyLimit = 256 << 10
+
+// defaultSoft404Phrases are the phrases of common not-found templates
+var defaultSoft404Phrases = []string{"page not found", "no longer available", "page does not exist", "page doesn't exist", "404 not found"}
+
+// Soft404Detector recognizes destinations that answer HTTP 200 with a not-found page
+type Soft404Detector struct {
+	Phrases  []string // matched case-insensitively
+	MinBytes int      // bodies shorter than this look like an empty error page, 0 disables the size check
+}
+
+// newSoft404Detector returns a detector for the built-in phrases plus extra ones
+func newSoft404Detector(extraPhrases []string, minBytes int) *Soft404Detector {
+	phrases := append(append([]string{}, defaultSoft404Phrases...), extraPhrases...)
+	return &Soft404Detector{Phrases: phrases, MinBytes: minBytes}
+}
+
+// Side effect free functions
+
+// match explains why a 200 response body looks like a not-found page and returns the matched phrase, reason is "" when it looks fine.
+// body may be truncated to soft404BodyLimit, a truncated body is never tiny.
+func (d *Soft404Detector) match(body []byte) (string, string) {
+	if d == nil {
+		return "", ""
+	}
+	lower := bytes.ToLower(body)
+	for _, phrase := range d.Phrases {
+		phrase = strings.ToLower(strings.TrimSpace(phrase))
+		if phrase != "" && bytes.Contains(lower, []byte(phrase)) {
+			return fmt.Sprintf("the page contains %q", phrase), phrase
+		}
+	}
+	if len(body) < d.MinBytes {
+		return fmt.Sprintf("the page has only %d bytes", len(body)), ""
+	}
+	return "", ""
+}
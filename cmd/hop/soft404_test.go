@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSoft404Match(t *testing.T) {
+	padding := strings.Repeat("content ", 100)
+	detector := newSoft404Detector([]string{"Artikel nicht gefunden"}, 512)
+
+	tests := []struct {
+		name       string
+		detector   *Soft404Detector
+		body       string
+		wantReason string
+		wantPhrase string
+	}{
+		{name: "regular page", detector: detector, body: "<h1>Welcome</h1>" + padding},
+		{name: "built-in phrase ignores case", detector: detector, body: "<h1>Page Not Found</h1>" + padding, wantReason: `the page contains "page not found"`, wantPhrase: "page not found"},
+		{name: "custom phrase", detector: detector, body: padding + "Artikel nicht gefunden", wantReason: `the page contains "artikel nicht gefunden"`, wantPhrase: "artikel nicht gefunden"},
+		{name: "tiny page", detector: detector, body: "<html></html>", wantReason: "the page has only 13 bytes"},
+		{name: "size check disabled", detector: newSoft404Detector(nil, 0), body: ""},
+		{name: "phrase wins over size", detector: detector, body: "No longer available", wantReason: `the page contains "no longer available"`, wantPhrase: "no longer available"},
+		{name: "nil detector", detector: nil, body: "page not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, phrase := tt.detector.match([]byte(tt.body))
+			if reason != tt.wantReason || phrase != tt.wantPhrase {
+				t.Errorf("match() = (%q, %q), want (%q, %q)", reason, phrase, tt.wantReason, tt.wantPhrase)
+			}
+		})
+	}
+}
+
+func TestCheckDestinationHealthDetectsSoft404(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gone":
+			_, _ = w.Write([]byte("<title>Sorry</title><p>This page is no longer available.</p>" + strings.Repeat(" ", 600)))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("page not found"))
+		case "/huge":
+			// the phrase sits behind the body limit and is never read
+			_, _ = w.Write([]byte(strings.Repeat("x", soft404BodyLimit) + "page not found"))
+		default:
+			_, _ = w.Write([]byte(strings.Repeat("fine ", 200)))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		path       string
+		soft404    *Soft404Detector
+		wantPhrase string
+	}{
+		{path: "/gone", soft404: newSoft404Detector(nil, 512), wantPhrase: "no longer available"},
+		{path: "/gone", soft404: nil},
+		{path: "/ok", soft404: newSoft404Detector(nil, 512)},
+		{path: "/huge", soft404: newSoft404Detector(nil, 512)},
+	}
+	for _, tt := range tests {
+		rule := redirectRule("r", "/old", server.URL+tt.path)
+		issues, _, _ := checkDestinationHealth(context.Background(), &rule, nil, nil, newHealthProber(0, 0, nil, tt.soft404))
+		var phrase interface{}
+		for _, issue := range issues {
+			if strings.Contains(issue.Message, "soft 404") {
+				phrase = issue.Details["phrase"]
+			}
+		}
+		if tt.wantPhrase == "" && phrase != nil || tt.wantPhrase != "" && phrase != tt.wantPhrase {
+			t.Errorf("%s (detector %v): soft 404 phrase = %v, want %q; issues %+v", tt.path, tt.soft404 != nil, phrase, tt.wantPhrase, issues)
+		}
+	}
+
+	// a real 404 stays a broken destination, not a soft 404
+	rule := redirectRule("r", "/old", server.URL+"/missing")
+	issues, _, _ := checkDestinationHealth(context.Background(), &rule, nil, nil, newHealthProber(0, 0, nil, newSoft404Detector(nil, 512)))
+	if len(issues) != 1 || issues[0].Severity != "error" {
+		t.Errorf("404 destination = %+v, want one broken destination error", issues)
+	}
+}
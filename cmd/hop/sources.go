@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SourceVerification is the outcome of requesting redirect sources from the origin
+type SourceVerification struct {
+	Issues  []CheckIssue
+	Checked int
+	Failed  int   // requests that got no response
+	Skipped int   // requests not started because the context was cancelled
+	Err     error // first request error, if any
+}
+
+// verifySources requests every exact redirect source from the origin and warns when the origin still serves it,
+// the redirect then hides live content. It uses the worker pool of the destination health checks.
+func verifySources(ctx context.Context, rules []EdgeRuleResponse, baseURL string) SourceVerification {
+	probes := redirectSourcePaths(rules)
+	results := make([]*CheckIssue, len(probes))
+	errs := make([]error, len(probes))
+	skipped := make([]bool, len(probes))
+
+	runHealthWorkers(len(probes), func(i int) {
+		if ctx.Err() != nil {
+			skipped[i] = true
+			return
+		}
+		target := baseURL + probes[i].path
+		probe, err := probeRedirect(ctx, target)
+		if err != nil {
+			if ctx.Err() != nil {
+				skipped[i] = true
+				return
+			}
+			errs[i] = err
+			return
+		}
+		if probe.Status == 200 {
+			results[i] = &CheckIssue{
+				Type:     IssueSourceVerification,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("Origin serves %s with HTTP 200, the redirect hides live content", probes[i].path),
+				Rule:     probes[i].rule,
+				Details:  map[string]interface{}{"origin_url": target},
+			}
+		}
+	})
+
+	var verification SourceVerification
+	for i := range probes {
+		switch {
+		case skipped[i]:
+			verification.Skipped++
+		case errs[i] != nil:
+			verification.Failed++
+			if verification.Err == nil {
+				verification.Err = errs[i]
+			}
+		default:
+			verification.Checked++
+		}
+		if results[i] != nil {
+			verification.Issues = append(verification.Issues, *results[i])
+		}
+	}
+	return verification
+}
+
+// Side effect free functions
+
+// sourceProbe is an exact redirect source path and the first rule redirecting it
+type sourceProbe struct {
+	path string
+	rule *EdgeRuleResponse
+}
+
+// redirectSourcePaths returns the exact source paths of enabled redirects once each, in rule order; wildcard sources are skipped
+func redirectSourcePaths(rules []EdgeRuleResponse) []sourceProbe {
+	var probes []sourceProbe
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		if rule.ActionType != 1 || !rule.Enabled {
+			continue
+		}
+		for _, pattern := range urlTriggerPatterns(rule) {
+			path := triggerPatternPath(pattern)
+			if strings.Contains(path, "*") || !strings.HasPrefix(path, "/") || seen[path] {
+				continue
+			}
+			seen[path] = true
+			probes = append(probes, sourceProbe{path: path, rule: &rules[i]})
+		}
+	}
+	return probes
+}
+
+// originBaseURL returns the URL source paths are requested from: --origin-host when given, else the origin of the pull zone
+func originBaseURL(originHost, zoneOrigin string) (string, error) {
+	base := zoneOrigin
+	if originHost != "" {
+		base = originHost
+		if !strings.Contains(base, "://") {
+			base = "https://" + base
+		}
+	}
+	if base == "" {
+		return "", fmt.Errorf("the pull zone has no origin URL, set --origin-host")
+	}
+
+	parsed, err := url.Parse(base)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", fmt.Errorf("origin '%s' is not an http or https URL with a host", base)
+	}
+	return strings.TrimSuffix(base, "/"), nil
+}
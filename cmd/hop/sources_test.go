@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedirectSourcePaths(t *testing.T) {
+	disabled := redirectRule("off", "/off", "/x")
+	disabled.Enabled = false
+	rules := []EdgeRuleResponse{
+		redirectRule("a", "/a", "/x"),
+		redirectRule("wild", "/docs/*", "/x"),
+		redirectRule("host", "https://www.example.com/b", "/x"),
+		redirectRule("dup", "/a", "/y"),
+		disabled,
+		blockRule("block", "/c"),
+	}
+
+	var got []string
+	for _, probe := range redirectSourcePaths(rules) {
+		got = append(got, probe.path+"="+probe.rule.Guid)
+	}
+	if want := "/a=a /b=host"; strings.Join(got, " ") != want {
+		t.Errorf("redirectSourcePaths() = %v, want %s", got, want)
+	}
+}
+
+func TestOriginBaseURL(t *testing.T) {
+	tests := []struct {
+		originHost string
+		zoneOrigin string
+		want       string
+		wantErr    string
+	}{
+		{zoneOrigin: "https://origin.example.com/", want: "https://origin.example.com"},
+		{originHost: "origin.example.com", zoneOrigin: "https://other.example.com", want: "https://origin.example.com"},
+		{originHost: "http://10.0.0.5:8080", want: "http://10.0.0.5:8080"},
+		{wantErr: "set --origin-host"},
+		{originHost: "ftp://origin.example.com", wantErr: "not an http or https URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.originHost+"|"+tt.zoneOrigin, func(t *testing.T) {
+			got, err := originBaseURL(tt.originHost, tt.zoneOrigin)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("originBaseURL() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("originBaseURL() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySources(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/live":
+			fmt.Fprint(w, "still here")
+		case "/moved":
+			http.Redirect(w, r, "/live", http.StatusMovedPermanently)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer origin.Close()
+
+	rules := []EdgeRuleResponse{
+		redirectRule("gone", "/gone", "/new"),
+		redirectRule("live", "/live", "/new"),
+		redirectRule("moved", "/moved", "/new"),
+	}
+	verification := verifySources(context.Background(), rules, origin.URL)
+	if len(verification.Issues) != 1 || verification.Issues[0].Rule.Guid != "live" || verification.Issues[0].Severity != "warning" {
+		t.Errorf("issues = %+v, want one warning for the live source", verification.Issues)
+	}
+	if verification.Checked != 3 || verification.Failed != 0 || verification.Skipped != 0 {
+		t.Errorf("checked/failed/skipped = %d/%d/%d, want 3/0/0", verification.Checked, verification.Failed, verification.Skipped)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if verification := verifySources(ctx, rules, origin.URL); verification.Skipped != 3 {
+		t.Errorf("cancelled run skipped %d sources, want 3", verification.Skipped)
+	}
+}
+
+func TestRulesCheckVerifySources(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer origin.Close()
+
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod")
+	ctx := context.Background()
+	rule := EdgeRule{ActionType: 1, ActionParameter1: "/new", ActionParameter2: "302", Enabled: true,
+		Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/old"}}}}
+	if err := addEdgeRule(ctx, fakeAPIKey, "1", rule); err != nil {
+		t.Fatal(err)
+	}
+
+	sourceCoverage := func(opts RulesCheckOptions) (CheckResult, CheckCoverage) {
+		opts.VerifySources = true
+		opts.Selection = CheckSelection{"source_verification": true}
+		result, err := checkRulesStructured(ctx, fakeAPIKey, "1", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result, result.Coverage[len(result.Coverage)-1]
+	}
+
+	result, coverage := sourceCoverage(RulesCheckOptions{OriginHost: origin.URL})
+	if want := (CheckCoverage{Check: "source_verification", Status: "ran", Reason: "1 source paths on " + origin.URL}); coverage != want {
+		t.Errorf("coverage = %+v, want %+v", coverage, want)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Type != "source_verification" {
+		t.Errorf("issues = %+v, want the live source", result.Issues)
+	}
+
+	if _, coverage := sourceCoverage(RulesCheckOptions{OriginHost: origin.URL, SkipHealth: true}); coverage.Status != "skipped" || coverage.Reason != "--skip-health" {
+		t.Errorf("coverage with --skip-health = %+v", coverage)
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Endpoint classes HTTP requests are counted under
+const (
+	endpointAPI     = "api"
+	endpointStorage = "storage"
+	endpointLogging = "logging"
+	endpointOther   = "other"
+)
+
+var endpointClasses = []string{endpointAPI, endpointStorage, endpointLogging, endpointOther}
+
+// RunStats counts what a run did over HTTP. Counters are always collected and only printed with --stats, nothing is sent anywhere.
+type RunStats struct {
+	start         time.Time
+	calls         map[string]*atomic.Int64 // by endpoint class, the map itself is never written after creation
+	failures      atomic.Int64
+	bytesUploaded atomic.Int64
+	bytesRead     atomic.Int64
+	bunnyCalls    atomic.Int64
+	maxBunnyCalls int64 // --max-api-calls, set before the first request, 0 means no limit
+}
+
+// APIBudgetError is returned for every request once a run has used its --max-api-calls budget
+type APIBudgetError struct {
+	Limit int64
+	Calls map[string]int64 // by endpoint class
+}
+
+func (e *APIBudgetError) Error() string {
+	return fmt.Sprintf("API call budget of %d exhausted (--max-api-calls), calls so far: %s", e.Limit, formatClassCounts(e.Calls))
+}
+
+// runStats collects the counters of the current run
+var runStats = newRunStats(time.Now())
+
+func newRunStats(start time.Time) *RunStats {
+	stats := &RunStats{start: start, calls: make(map[string]*atomic.Int64)}
+	for _, class := range endpointClasses {
+		stats.calls[class] = &atomic.Int64{}
+	}
+	return stats
+}
+
+// countingTransport counts requests, request bodies and response bodies passing through an http.RoundTripper
+type countingTransport struct {
+	base  http.RoundTripper
+	stats *RunStats
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	class := endpointClass(req.URL.String())
+	if class != endpointOther && t.stats.maxBunnyCalls > 0 && t.stats.bunnyCalls.Add(1) > t.stats.maxBunnyCalls {
+		return nil, t.stats.budgetError()
+	}
+	t.stats.calls[class].Add(1)
+	if req.ContentLength > 0 {
+		t.stats.bytesUploaded.Add(req.ContentLength)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.stats.failures.Add(1)
+		return nil, err
+	}
+	resp.Body = &countingReadCloser{ReadCloser: resp.Body, count: &t.stats.bytesRead}
+	return resp, nil
+}
+
+// countingReadCloser adds the bytes read from a response body to a counter
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.count.Add(int64(n))
+	return n, err
+}
+
+// budgetError reports the exhausted budget with the calls made so far
+func (s *RunStats) budgetError() *APIBudgetError {
+	calls := make(map[string]int64)
+	for _, class := range endpointClasses {
+		if n := s.calls[class].Load(); n > 0 {
+			calls[class] = n
+		}
+	}
+	return &APIBudgetError{Limit: s.maxBunnyCalls, Calls: calls}
+}
+
+// installRunStats counts every request made through http.DefaultTransport, which all clients without their own transport use
+func installRunStats(stats *RunStats) {
+	http.DefaultTransport = &countingTransport{base: http.DefaultTransport, stats: stats}
+}
+
+// printRunStats prints the --stats footer
+func printRunStats(w io.Writer, stats *RunStats, now time.Time) {
+	fmt.Fprintln(w, "\nRUN STATISTICS (local only, not sent anywhere):")
+	total := int64(0)
+	for _, class := range endpointClasses {
+		total += stats.calls[class].Load()
+	}
+	fmt.Fprintf(w, "   HTTP requests: %d", total)
+	var parts []string
+	for _, class := range endpointClasses {
+		if n := stats.calls[class].Load(); n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", class, n))
+		}
+	}
+	if len(parts) > 0 {
+		fmt.Fprintf(w, " (%s)", strings.Join(parts, ", "))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "   Failed requests: %d\n", stats.failures.Load())
+	fmt.Fprintf(w, "   Uploaded: %s\n", formatByteSize(stats.bytesUploaded.Load()))
+	fmt.Fprintf(w, "   Downloaded: %s\n", formatByteSize(stats.bytesRead.Load()))
+	fmt.Fprintf(w, "   Wall time: %s\n", now.Sub(stats.start).Round(time.Millisecond))
+}
+
+// Side effect free functions
+
+// formatClassCounts lists call counts by endpoint class, largest consumer first
+func formatClassCounts(calls map[string]int64) string {
+	classes := make([]string, 0, len(calls))
+	for class := range calls {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if calls[classes[i]] != calls[classes[j]] {
+			return calls[classes[i]] > calls[classes[j]]
+		}
+		return classes[i] < classes[j]
+	})
+	parts := make([]string, 0, len(classes))
+	for _, class := range classes {
+		parts = append(parts, fmt.Sprintf("%s %d", class, calls[class]))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// endpointClass returns which Bunny endpoint a request URL goes to, anything else such as health checks is "other"
+func endpointClass(url string) string {
+	switch {
+	case strings.HasPrefix(url, apiBaseURL):
+		return endpointAPI
+	case strings.HasPrefix(url, storageBaseURL):
+		return endpointStorage
+	case strings.HasPrefix(url, logBaseURL):
+		return endpointLogging
+	default:
+		return endpointOther
+	}
+}
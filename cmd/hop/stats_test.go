@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStatsCountsRequests(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+
+	stats := newRunStats(time.Now())
+	oldTransport := http.DefaultTransport
+	installRunStats(stats)
+	t.Cleanup(func() { http.DefaultTransport = oldTransport })
+
+	ctx := context.Background()
+	if _, err := findPullZoneByName(ctx, fakeAPIKey, "site"); err != nil {
+		t.Fatalf("findPullZoneByName() error = %v", err)
+	}
+
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	localPath := filepath.Join(t.TempDir(), "index.html")
+	writeTestFile(t, localPath, "0123456789")
+	if err := uploadFileToStorage(ctx, storageZone, localPath, "index.html", uploadOptions{}); err != nil {
+		t.Fatalf("uploadFileToStorage() error = %v", err)
+	}
+	files, err := listRemoteFiles(ctx, storageZone, "")
+	if err != nil || len(files) != 1 {
+		t.Fatalf("listRemoteFiles() = %v, %v", files, err)
+	}
+
+	if got := stats.calls[endpointAPI].Load(); got != 1 {
+		t.Errorf("api calls = %d, want 1", got)
+	}
+	if got := stats.calls[endpointStorage].Load(); got != 2 {
+		t.Errorf("storage calls = %d, want 2", got)
+	}
+	if got := stats.bytesUploaded.Load(); got != 10 {
+		t.Errorf("bytes uploaded = %d, want 10", got)
+	}
+	if stats.bytesRead.Load() == 0 {
+		t.Error("bytes read = 0, want the listing responses counted")
+	}
+	if got := stats.failures.Load(); got != 0 {
+		t.Errorf("failures = %d, want 0", got)
+	}
+}
+
+func TestEndpointClass(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: apiBaseURL + "/pullzone", want: endpointAPI},
+		{url: storageBaseURL + "/site/index.html", want: endpointStorage},
+		{url: logBaseURL + "/10-16-26/1.log", want: endpointLogging},
+		{url: "https://www.example.com/", want: endpointOther},
+	}
+	for _, tt := range tests {
+		if got := endpointClass(tt.url); got != tt.want {
+			t.Errorf("endpointClass(%s) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestPrintRunStats(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	stats := newRunStats(start)
+	stats.calls[endpointAPI].Add(3)
+	stats.calls[endpointOther].Add(1)
+	stats.bytesUploaded.Add(2048)
+
+	var out bytes.Buffer
+	printRunStats(&out, stats, start.Add(1500*time.Millisecond))
+
+	for _, want := range []string{
+		"HTTP requests: 4 (api 3, other 1)",
+		"Uploaded: 2.0 KiB",
+		"Downloaded: 0 B",
+		"Wall time: 1.5s",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestAPIBudgetAbortsAtLimit(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "site", "www.example.com")
+
+	stats := newRunStats(time.Now())
+	stats.maxBunnyCalls = 3
+	oldTransport := http.DefaultTransport
+	installRunStats(stats)
+	t.Cleanup(func() { http.DefaultTransport = oldTransport })
+
+	ctx := context.Background()
+	storageZone := &StorageZone{Name: "site", Password: "site-password"}
+	for i := 0; i < 2; i++ {
+		if _, err := listRemoteFiles(ctx, storageZone, ""); err != nil {
+			t.Fatalf("call %d: listRemoteFiles() error = %v", i+1, err)
+		}
+	}
+	if _, err := findPullZoneByName(ctx, fakeAPIKey, "site"); err != nil {
+		t.Fatalf("call 3: findPullZoneByName() error = %v", err)
+	}
+
+	_, err := findPullZoneByName(ctx, fakeAPIKey, "site")
+	if want := "API call budget of 3 exhausted (--max-api-calls), calls so far: storage 2, api 1"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("call 4: error = %v, want it to contain %q", err, want)
+	}
+	if got := stats.calls[endpointAPI].Load(); got != 1 {
+		t.Errorf("api calls = %d, want the refused call not counted", got)
+	}
+}
+
+func TestAPIBudgetIgnoresOtherRequests(t *testing.T) {
+	stats := newRunStats(time.Now())
+	stats.maxBunnyCalls = 1
+	transport := &countingTransport{base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), stats: stats}
+
+	for i := 0; i < 3; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://www.example.com/", nil)
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatalf("health check request %d: error = %v, want no budget applied", i+1, err)
+		}
+	}
+}
+
+func TestFormatClassCounts(t *testing.T) {
+	tests := []struct {
+		calls map[string]int64
+		want  string
+	}{
+		{calls: map[string]int64{"api": 5, "storage": 12, "logging": 1}, want: "storage 12, api 5, logging 1"},
+		{calls: map[string]int64{"storage": 2, "api": 2}, want: "api 2, storage 2"},
+		{calls: map[string]int64{}, want: "none"},
+	}
+	for _, tt := range tests {
+		if got := formatClassCounts(tt.calls); got != tt.want {
+			t.Errorf("formatClassCounts(%v) = %s, want %s", tt.calls, got, tt.want)
+		}
+	}
+}
+
+// roundTripFunc answers requests without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
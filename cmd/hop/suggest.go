@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PathSuggestion is a known path below a prefix together with the redirect that currently handles it
+type PathSuggestion struct {
+	Path        string   `json:"path"`
+	Origins     []string `json:"origins"`
+	Redirected  bool     `json:"redirected"`
+	RuleGuid    string   `json:"rule_guid,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+}
+
+// Side effect free functions
+
+// normalizePathPrefix makes sure a prefix starts with a slash
+func normalizePathPrefix(prefix string) string {
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+// hasPathPrefix reports whether a path starts with the prefix, ignoring case
+func hasPathPrefix(urlPath, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(urlPath), strings.ToLower(normalizePathPrefix(prefix)))
+}
+
+// remoteDirForPrefix returns the storage directory that contains every file matching a path prefix
+func remoteDirForPrefix(prefix string) string {
+	trimmed := strings.Trim(prefix, "/")
+	if strings.HasSuffix(prefix, "/") || trimmed == "" {
+		return trimmed
+	}
+	dir := path.Dir(trimmed)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// storagePathToURLPath converts a storage file path into the URL path it is served under
+func storagePathToURLPath(remotePath string) string {
+	urlPath := "/" + strings.TrimPrefix(remotePath, "/")
+	if path.Base(urlPath) == "index.html" {
+		return strings.TrimSuffix(urlPath, "index.html")
+	}
+	return urlPath
+}
+
+// buildPathSuggestions merges storage and sitemap paths below a prefix and marks which ones are redirected
+func buildPathSuggestions(storagePaths, sitemapPaths []string, prefix string, rules []EdgeRuleResponse) []PathSuggestion {
+	byPath := make(map[string]*PathSuggestion)
+	var order []string
+
+	add := func(urlPath, origin string) {
+		if !hasPathPrefix(urlPath, prefix) {
+			return
+		}
+		key := normalizeURL(urlPath)
+		suggestion, exists := byPath[key]
+		if !exists {
+			suggestion = &PathSuggestion{Path: urlPath}
+			byPath[key] = suggestion
+			order = append(order, key)
+		}
+		for _, existing := range suggestion.Origins {
+			if existing == origin {
+				return
+			}
+		}
+		suggestion.Origins = append(suggestion.Origins, origin)
+	}
+
+	for _, remotePath := range storagePaths {
+		add(storagePathToURLPath(remotePath), "storage")
+	}
+	for _, sitemapPath := range sitemapPaths {
+		add(sitemapPath, "sitemap")
+	}
+
+	sort.Strings(order)
+
+	suggestions := make([]PathSuggestion, 0, len(order))
+	for _, key := range order {
+		suggestion := byPath[key]
+		if matches := findRedirectsForPath(rules, suggestion.Path); len(matches) > 0 {
+			suggestion.Redirected = true
+			suggestion.RuleGuid = matches[0].Guid
+			suggestion.Destination = matches[0].ActionParameter1
+		}
+		suggestions = append(suggestions, *suggestion)
+	}
+
+	return suggestions
+}
+
+// shellQuote quotes a value for safe use as a single POSIX shell word
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// formatAddCommand builds a ready-to-run `hop rules add` command for a path without a redirect
+func formatAddCommand(zone, urlPath string) string {
+	return fmt.Sprintf("hop rules add --key YOUR_API_KEY --zone %s --from %s --to DESTINATION_URL", shellQuote(zone), shellQuote(urlPath))
+}
+
+// formatMoveCommand builds a ready-to-run `hop rules move` command that puts a rule in front of another
+func formatMoveCommand(zone, guid, before string) string {
+	return fmt.Sprintf("hop rules move --key YOUR_API_KEY --zone %s --guid %s --before %s", shellQuote(zone), shellQuote(guid), shellQuote(before))
+}
+
+// formatAddRedirectCommand builds a ready-to-run `hop rules add` command for a path with a known destination
+func formatAddRedirectCommand(zone, urlPath, destination string) string {
+	return fmt.Sprintf("hop rules add --key YOUR_API_KEY --zone %s --from %s --to %s", shellQuote(zone), shellQuote(urlPath), shellQuote(destination))
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHasPathPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		prefix string
+		want   bool
+	}{
+		{name: "matching prefix", path: "/blog/2019/post", prefix: "/blog/2019", want: true},
+		{name: "prefix without leading slash", path: "/blog/2019/post", prefix: "blog/2019", want: true},
+		{name: "case-insensitive", path: "/Blog/2019/Post", prefix: "/blog/2019", want: true},
+		{name: "different prefix", path: "/blog/2020/post", prefix: "/blog/2019", want: false},
+		{name: "partial segment still matches", path: "/blog/20190", prefix: "/blog/2019", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasPathPrefix(tt.path, tt.prefix); got != tt.want {
+				t.Errorf("hasPathPrefix(%q, %q) = %v, want %v", tt.path, tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoteDirForPrefix(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{prefix: "/blog/2019", want: "blog"},
+		{prefix: "/blog/2019/", want: "blog/2019"},
+		{prefix: "/blog", want: ""},
+		{prefix: "/", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			if got := remoteDirForPrefix(tt.prefix); got != tt.want {
+				t.Errorf("remoteDirForPrefix(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStoragePathToURLPath(t *testing.T) {
+	tests := []struct {
+		remotePath string
+		want       string
+	}{
+		{remotePath: "blog/2019/post/index.html", want: "/blog/2019/post/"},
+		{remotePath: "index.html", want: "/"},
+		{remotePath: "blog/feed.xml", want: "/blog/feed.xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remotePath, func(t *testing.T) {
+			if got := storagePathToURLPath(tt.remotePath); got != tt.want {
+				t.Errorf("storagePathToURLPath(%q) = %q, want %q", tt.remotePath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPathSuggestions(t *testing.T) {
+	rules := []EdgeRuleResponse{
+		{
+			Guid:             "guid-1",
+			ActionType:       1,
+			ActionParameter1: "https://example.com/archive",
+			Triggers:         []Trigger{{Type: 0, PatternMatches: []string{"*/blog/2019/old-post"}}},
+		},
+	}
+	storagePaths := []string{
+		"blog/2019/old-post/index.html",
+		"blog/2019/new-post/index.html",
+		"blog/2020/other/index.html",
+	}
+	sitemapPaths := []string{"/blog/2019/new-post", "/blog/2019/sitemap-only"}
+
+	got := buildPathSuggestions(storagePaths, sitemapPaths, "/blog/2019", rules)
+
+	want := []PathSuggestion{
+		{Path: "/blog/2019/new-post/", Origins: []string{"storage", "sitemap"}},
+		{Path: "/blog/2019/old-post/", Origins: []string{"storage"}, Redirected: true, RuleGuid: "guid-1", Destination: "https://example.com/archive"},
+		{Path: "/blog/2019/sitemap-only", Origins: []string{"sitemap"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildPathSuggestions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFormatAddCommand(t *testing.T) {
+	got := formatAddCommand("site", "/it's/here")
+	want := `hop rules add --key YOUR_API_KEY --zone 'site' --from '/it'\''s/here' --to DESTINATION_URL`
+	if got != want {
+		t.Errorf("formatAddCommand() = %s, want %s", got, want)
+	}
+}
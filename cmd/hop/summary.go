@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckSummary is the recap at the end of check: the issue counts of every section, the overall result and the runtime
+type CheckSummary struct {
+	Sections  []SectionSummary `json:"sections"`
+	Passed    bool             `json:"passed"`
+	RuntimeMS int64            `json:"runtime_ms"`
+}
+
+// SectionSummary counts the issues by severity and the passed checks of one check section
+type SectionSummary struct {
+	Name     string `json:"name"`
+	Critical int    `json:"critical"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Info     int    `json:"info"`
+	Passed   int    `json:"passed"`
+	Error    string `json:"error,omitempty"` // why the section did not run
+}
+
+// summaryColumns are the count columns of the summary table
+var summaryColumns = []string{"Critical", "Errors", "Warnings", "Info", "Passed"}
+
+// displayCheckSummary prints the summary table of a combined check
+func displayCheckSummary(summary CheckSummary) {
+	writeSummaryTable(output, summary)
+}
+
+// Side effect free functions
+
+// summarizeSection counts the issues and passed checks of a section result, a non-nil err marks the section as not run
+func summarizeSection(name string, result CheckResult, err error) SectionSummary {
+	if err != nil {
+		return SectionSummary{Name: name, Error: err.Error()}
+	}
+	section := SectionSummary{Name: name, Passed: len(result.Successful)}
+	for _, issue := range result.Issues {
+		switch issue.Severity {
+		case SeverityCritical:
+			section.Critical++
+		case SeverityError:
+			section.Errors++
+		case SeverityWarning:
+			section.Warnings++
+		case SeverityInfo:
+			section.Info++
+		}
+	}
+	return section
+}
+
+// buildCheckSummary returns the summary of sections with the overall result and the runtime
+func buildCheckSummary(passed bool, runtime time.Duration, sections ...SectionSummary) CheckSummary {
+	return CheckSummary{Sections: sections, Passed: passed, RuntimeMS: runtime.Milliseconds()}
+}
+
+// summaryCells returns the count cells of a section row, "-" for a section that did not run
+func summaryCells(section SectionSummary) []string {
+	if section.Error != "" {
+		return []string{"-", "-", "-", "-", "-"}
+	}
+	var cells []string
+	for _, count := range []int{section.Critical, section.Errors, section.Warnings, section.Info, section.Passed} {
+		cells = append(cells, strconv.Itoa(count))
+	}
+	return cells
+}
+
+// writeSummaryTable writes the summary as a table with right-aligned counts sized to the widest cell, then the result and runtime
+func writeSummaryTable(w io.Writer, summary CheckSummary) {
+	nameWidth := len("Section")
+	widths := make([]int, len(summaryColumns))
+	for i, column := range summaryColumns {
+		widths[i] = len(column)
+	}
+	rows := make([][]string, len(summary.Sections))
+	for i, section := range summary.Sections {
+		nameWidth = max(nameWidth, len(section.Name))
+		rows[i] = summaryCells(section)
+		for j, cell := range rows[i] {
+			widths[j] = max(widths[j], len(cell))
+		}
+	}
+
+	writeRow := func(name string, cells []string, note string) {
+		line := fmt.Sprintf("   %-*s", nameWidth, name)
+		for i, cell := range cells {
+			line += fmt.Sprintf("  %*s", widths[i], cell)
+		}
+		if note != "" {
+			line += "  " + note
+		}
+		fmt.Fprintln(w, line)
+	}
+
+	fmt.Fprintf(w, "SUMMARY\n")
+	writeRow("Section", summaryColumns, "")
+	fmt.Fprintln(w, "   "+strings.Repeat("-", nameWidth+sumWidths(widths)+2*len(widths)))
+	for i, section := range summary.Sections {
+		note := ""
+		if section.Error != "" {
+			note = "not run: " + section.Error
+		}
+		writeRow(section.Name, rows[i], note)
+	}
+	fmt.Fprintln(w)
+	result := "passed"
+	if !summary.Passed {
+		result = "issues found"
+	}
+	fmt.Fprintf(w, "   Result: %s\n", result)
+	fmt.Fprintf(w, "   Runtime: %s\n", (time.Duration(summary.RuntimeMS) * time.Millisecond).Round(100*time.Millisecond))
+	fmt.Fprintln(w)
+}
+
+// sumWidths returns the total of column widths
+func sumWidths(widths []int) int {
+	total := 0
+	for _, width := range widths {
+		total += width
+	}
+	return total
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummarizeSection(t *testing.T) {
+	result := CheckResult{
+		Issues: []CheckIssue{
+			{Severity: SeverityCritical}, {Severity: SeverityError}, {Severity: SeverityError},
+			{Severity: SeverityWarning}, {Severity: SeverityInfo},
+		},
+		Successful: []CheckIssue{{Severity: SeverityInfo}, {Severity: SeverityInfo}, {Severity: SeverityInfo}},
+	}
+
+	tests := []struct {
+		name   string
+		result CheckResult
+		err    error
+		want   SectionSummary
+	}{
+		{
+			name:   "counts issues by severity and passed checks",
+			result: result,
+			want:   SectionSummary{Name: "Rules", Critical: 1, Errors: 2, Warnings: 1, Info: 1, Passed: 3},
+		},
+		{
+			name: "empty result",
+			want: SectionSummary{Name: "Rules"},
+		},
+		{
+			name:   "section that did not run",
+			result: result,
+			err:    errors.New("zone not found"),
+			want:   SectionSummary{Name: "Rules", Error: "zone not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeSection("Rules", tt.result, tt.err); got != tt.want {
+				t.Errorf("summarizeSection() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSummaryTableAlignment(t *testing.T) {
+	summary := buildCheckSummary(false, 12340*time.Millisecond,
+		SectionSummary{Name: "Rules", Errors: 2, Warnings: 123456, Passed: 14},
+		SectionSummary{Name: "DNS", Passed: 3},
+		SectionSummary{Name: "SSL", Error: "timeout"},
+	)
+	var buf bytes.Buffer
+	writeSummaryTable(&buf, summary)
+	lines := strings.Split(buf.String(), "\n")
+
+	header := lines[1]
+	for _, line := range lines[3:6] {
+		rowEnd := len(strings.TrimSuffix(line, "  not run: timeout"))
+		if rowEnd != len(header) {
+			t.Errorf("row %q ends at %d, header at %d", line, rowEnd, len(header))
+		}
+	}
+	if !strings.Contains(lines[3], " 123456 ") {
+		t.Errorf("rules row %q is missing the warning count", lines[3])
+	}
+	if !strings.HasSuffix(lines[5], "not run: timeout") {
+		t.Errorf("SSL row %q does not say why it did not run", lines[5])
+	}
+	for _, want := range []string{"Result: issues found", "Runtime: 12.3s"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("summary is missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestCheckReportSummaryJSON(t *testing.T) {
+	report := buildCheckReport("shop", CheckResult{}, SeverityError)
+	summary := buildCheckSummary(true, 1500*time.Millisecond, SectionSummary{Name: "Rules", Passed: 2})
+	report.Summary = &summary
+
+	var buf bytes.Buffer
+	if err := writeJSONReport(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Summary struct {
+			Sections []map[string]interface{} `json:"sections"`
+			Passed   bool                     `json:"passed"`
+			Runtime  int64                    `json:"runtime_ms"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Summary.Passed || decoded.Summary.Runtime != 1500 || len(decoded.Summary.Sections) != 1 {
+		t.Fatalf("summary = %+v", decoded.Summary)
+	}
+	section := decoded.Summary.Sections[0]
+	for _, key := range []string{"name", "critical", "errors", "warnings", "info", "passed"} {
+		if _, ok := section[key]; !ok {
+			t.Errorf("section is missing %q: %v", key, section)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Side effect free functions
+
+// isSystemHostname reports whether a host is a Bunny b-cdn.net system hostname
+func isSystemHostname(host string) bool {
+	return strings.HasSuffix(canonicalHost(host), ".b-cdn.net")
+}
+
+// firstCustomHostname returns the zone's first hostname that is not a b-cdn.net system hostname, "" if it has none
+func firstCustomHostname(hostnames []Hostname) string {
+	for _, hostname := range hostnames {
+		if !isSystemHostname(hostname.Value) {
+			return canonicalHost(hostname.Value)
+		}
+	}
+	return ""
+}
+
+// customHostnameURL returns the destination moved to the custom hostname over https, keeping path and query
+func customHostnameURL(destURL *url.URL, customHost string) string {
+	moved := *destURL
+	moved.Scheme = "https"
+	moved.Host = customHost
+	return moved.String()
+}
+
+// checkSystemHostnameDestinations flags redirects to a b-cdn.net system hostname instead of a custom hostname.
+// Destinations on this zone's system hostname are warnings, destinations on another zone of the account are info.
+// The suggested hostname is the canonical host when one is given, else the zone's first custom hostname.
+func checkSystemHostnameDestinations(rules []EdgeRuleResponse, zone *PullZoneDetails, accountZones []PullZone, canonical string) []CheckIssue {
+	ownHost := ""
+	if zone.Name != "" {
+		ownHost = canonicalHost(cdnHostname(zone))
+	}
+	customHost := firstCustomHostname(zone.Hostnames)
+	if canonical != "" {
+		customHost = canonicalHost(canonical)
+	}
+
+	otherZones := make(map[string]string)
+	for _, other := range accountZones {
+		if other.Id != zone.Id && other.Name != "" {
+			otherZones[strings.ToLower(other.Name)+".b-cdn.net"] = other.Name
+		}
+	}
+
+	var issues []CheckIssue
+	for i, rule := range rules {
+		if rule.ActionType != 1 || rule.ActionParameter1 == "" {
+			continue
+		}
+		destURL, err := url.Parse(rule.ActionParameter1)
+		if err != nil || !isSystemHostname(destURL.Host) {
+			continue
+		}
+		host := canonicalHost(destURL.Host)
+
+		switch {
+		case host == ownHost:
+			message := fmt.Sprintf("Redirect points at the zone's system hostname %s, which exposes the CDN, breaks when the zone is renamed and splits analytics", host)
+			details := map[string]interface{}{"system_hostname": host}
+			if customHost != "" {
+				suggested := customHostnameURL(destURL, customHost)
+				message += fmt.Sprintf(" - use %s instead", suggested)
+				details["suggested_destination"] = suggested
+			} else {
+				message += " - add a custom hostname to the zone and redirect to it instead"
+			}
+			issues = append(issues, CheckIssue{
+				Type:     IssueConfiguration,
+				Severity: SeverityWarning,
+				Message:  message,
+				Rule:     &rules[i],
+				Details:  details,
+			})
+		case otherZones[host] != "":
+			issues = append(issues, CheckIssue{
+				Type:     IssueConfiguration,
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("Redirect points at %s, the system hostname of pull zone '%s', consider one of that zone's custom hostnames", host, otherZones[host]),
+				Rule:     &rules[i],
+				Details:  map[string]interface{}{"system_hostname": host, "zone": otherZones[host]},
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestFirstCustomHostname(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostnames []Hostname
+		want      string
+	}{
+		{name: "custom after system", hostnames: []Hostname{{Value: "site.b-cdn.net"}, {Value: "WWW.Example.com"}, {Value: "example.com"}}, want: "www.example.com"},
+		{name: "only system hostname", hostnames: []Hostname{{Value: "site.b-cdn.net"}}, want: ""},
+		{name: "no hostnames", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := firstCustomHostname(tt.hostnames); got != tt.want {
+				t.Errorf("firstCustomHostname() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSystemHostnameDestinations(t *testing.T) {
+	zone := &PullZoneDetails{
+		Id:        1,
+		Name:      "site",
+		Hostnames: []Hostname{{Value: "site.b-cdn.net"}, {Value: "www.example.com"}},
+	}
+	accountZones := []PullZone{{Id: 1, Name: "site"}, {Id: 2, Name: "Shop"}}
+
+	tests := []struct {
+		name          string
+		zone          *PullZoneDetails
+		destination   string
+		canonical     string
+		wantSeverity  string
+		wantSuggested string
+	}{
+		{name: "own system hostname", zone: zone, destination: "http://Site.b-cdn.net/docs/?page=2", wantSeverity: "warning", wantSuggested: "https://www.example.com/docs/?page=2"},
+		{name: "own system hostname with port", zone: zone, destination: "https://site.b-cdn.net:443/a", wantSeverity: "warning", wantSuggested: "https://www.example.com/a"},
+		{name: "own system hostname with canonical host", zone: zone, destination: "https://site.b-cdn.net/a", canonical: "Example.com", wantSeverity: "warning", wantSuggested: "https://example.com/a"},
+		{name: "other zone of the account", zone: zone, destination: "https://shop.b-cdn.net/cart", wantSeverity: "info"},
+		{name: "unknown zone", zone: zone, destination: "https://someone-else.b-cdn.net/"},
+		{name: "custom hostname", zone: zone, destination: "https://www.example.com/docs"},
+		{name: "relative destination", zone: zone, destination: "/docs"},
+		{
+			name:         "zone without custom hostname",
+			zone:         &PullZoneDetails{Id: 1, Name: "site", Hostnames: []Hostname{{Value: "site.b-cdn.net"}}},
+			destination:  "https://site.b-cdn.net/docs",
+			wantSeverity: "warning",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []EdgeRuleResponse{redirectRule("r1", "/old", tt.destination)}
+			issues := checkSystemHostnameDestinations(rules, tt.zone, accountZones, tt.canonical)
+			if tt.wantSeverity == "" {
+				if len(issues) != 0 {
+					t.Errorf("got %+v, want no issues", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Severity != tt.wantSeverity {
+				t.Fatalf("got %+v, want one %s", issues, tt.wantSeverity)
+			}
+			suggested, _ := issues[0].Details["suggested_destination"].(string)
+			if suggested != tt.wantSuggested {
+				t.Errorf("suggested_destination = %q, want %q", suggested, tt.wantSuggested)
+			}
+		})
+	}
+}
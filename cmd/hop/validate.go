@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RedirectDefinition is a redirect in a hand-written rules file, the short form of an edge rule
+type RedirectDefinition struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Status      string `json:"status"`
+	Enabled     *bool  `json:"enabled"`
+	Description string `json:"description"`
+}
+
+// readRulesFile reads a rules file: a rules backup with full edge rules, redirect definitions, or a mix of both
+func readRulesFile(path string) ([]EdgeRuleResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseRulesFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing '%s': %v", path, err)
+	}
+	return rules, nil
+}
+
+// Side effect free functions
+
+// parseRulesFile parses a JSON array whose entries are edge rules (with Triggers) or redirect definitions (with from and to)
+func parseRulesFile(data []byte) ([]EdgeRuleResponse, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	rules := make([]EdgeRuleResponse, 0, len(entries))
+	for i, raw := range entries {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, fmt.Errorf("entry %d: %v", i+1, err)
+		}
+		if _, isEdgeRule := fields["Triggers"]; isEdgeRule {
+			var rule EdgeRuleResponse
+			if err := json.Unmarshal(raw, &rule); err != nil {
+				return nil, fmt.Errorf("entry %d: %v", i+1, err)
+			}
+			rules = append(rules, rule)
+			continue
+		}
+
+		var definition RedirectDefinition
+		if err := json.Unmarshal(raw, &definition); err != nil {
+			return nil, fmt.Errorf("entry %d: %v", i+1, err)
+		}
+		if definition.From == "" {
+			return nil, fmt.Errorf("entry %d: needs 'from' and 'to', or the Triggers of an edge rule", i+1)
+		}
+		rules = append(rules, definitionToRule(definition, i))
+	}
+	return rules, nil
+}
+
+// definitionToRule converts a redirect definition into the edge rule Bunny would return, so the check functions apply unchanged.
+// The entry index becomes GUID and evaluation order, so reported issues point at the file entry.
+func definitionToRule(definition RedirectDefinition, index int) EdgeRuleResponse {
+	status := definition.Status
+	if status == "" {
+		status = "302"
+	}
+	enabled := definition.Enabled == nil || *definition.Enabled
+	description := definition.Description
+	if description == "" {
+		description = fmt.Sprintf("%s redirect from %s to %s", status, definition.From, definition.To)
+	}
+	return EdgeRuleResponse{
+		Guid:             fmt.Sprintf("entry-%d", index+1),
+		ActionType:       1, // Redirect
+		ActionParameter1: definition.To,
+		ActionParameter2: status,
+		Description:      description,
+		Enabled:          enabled,
+		OrderIndex:       index,
+		Triggers: []Trigger{
+			{
+				Type:                0, // Url trigger
+				PatternMatches:      []string{definition.From},
+				PatternMatchingType: 0, // MatchAny
+			},
+		},
+	}
+}
+
+// validateRules runs the checks that need no API access on the rules of a file
+func validateRules(rules []EdgeRuleResponse, hostnames []string) []CheckIssue {
+	zoneHostnames := make([]Hostname, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		zoneHostnames = append(zoneHostnames, Hostname{Value: hostname})
+	}
+
+	ordered := append([]EdgeRuleResponse{}, rules...)
+	sortRulesByOrder(ordered)
+
+	var issues []CheckIssue
+	issues = append(issues, checkBasicRedirectIssues(rules)...)
+	issues = append(issues, checkConfigurationIssues(rules)...)
+	issues = append(issues, checkRuleOrdering(ordered, "")...)
+	issues = append(issues, checkSecurityIssues(rules, zoneHostnames, nil, "")...)
+	issues = append(issues, checkPlaceholderOpenRedirects(rules)...)
+	issues = append(issues, checkSelfRedirects(rules, zoneHostnames)...)
+	issues = append(issues, checkRedirectLoops(buildRedirectMap(rules), zoneHostnames, defaultChainLimits)...)
+	return issues
+}
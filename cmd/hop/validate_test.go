@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRulesFile(t *testing.T) {
+	data := `[
+		{"from": "/old", "to": "/new"},
+		{"from": "/gone", "to": "/", "status": "301", "enabled": false, "description": "Gone"},
+		{"Guid": "abc", "ActionType": 1, "ActionParameter1": "/b", "ActionParameter2": "308", "Enabled": true, "OrderIndex": 7,
+		 "Triggers": [{"Type": 0, "PatternMatches": ["/a"], "PatternMatchingType": 0}]}
+	]`
+
+	rules, err := parseRulesFile([]byte(data))
+	if err != nil {
+		t.Fatalf("parseRulesFile() error = %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("parseRulesFile() returned %d rules, want 3", len(rules))
+	}
+
+	first := rules[0]
+	if first.Guid != "entry-1" || first.ActionType != 1 || extractSourceURL(first) != "/old" || first.ActionParameter1 != "/new" {
+		t.Errorf("first rule = %+v, want a redirect from /old to /new with GUID entry-1", first)
+	}
+	if first.ActionParameter2 != "302" || !first.Enabled || first.OrderIndex != 0 {
+		t.Errorf("first rule status/enabled/order = %s/%v/%d, want 302/true/0", first.ActionParameter2, first.Enabled, first.OrderIndex)
+	}
+	if first.Description != "302 redirect from /old to /new" {
+		t.Errorf("first rule description = %q", first.Description)
+	}
+
+	second := rules[1]
+	if second.ActionParameter2 != "301" || second.Enabled || second.Description != "Gone" || second.OrderIndex != 1 {
+		t.Errorf("second rule = %+v, want a disabled 301 described as Gone at order 1", second)
+	}
+
+	third := rules[2]
+	if third.Guid != "abc" || third.ActionParameter2 != "308" || third.OrderIndex != 7 || extractSourceURL(third) != "/a" {
+		t.Errorf("backup entry = %+v, want it taken over unchanged", third)
+	}
+}
+
+func TestParseRulesFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "not a list", data: `{"from": "/a", "to": "/b"}`, wantErr: "cannot unmarshal"},
+		{name: "missing from", data: `[{"from": "/a", "to": "/b"}, {"to": "/b"}]`, wantErr: "entry 2"},
+		{name: "entry not an object", data: `["/a"]`, wantErr: "entry 1"},
+		{name: "broken edge rule", data: `[{"Triggers": "none"}]`, wantErr: "entry 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRulesFile([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseRulesFile() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadRulesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`[{"from": "/a"`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := readRulesFile(path)
+	if err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("readRulesFile() error = %v, want it to name the file", err)
+	}
+
+	if _, err := readRulesFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("readRulesFile() of a missing file returned no error")
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	hasIssue := func(issues []CheckIssue, issueType, guid string) bool {
+		for _, issue := range issues {
+			if issue.Type == issueType && issue.Rule != nil && issue.Rule.Guid == guid {
+				return true
+			}
+		}
+		return false
+	}
+
+	rules, err := parseRulesFile([]byte(`[
+		{"from": "/a", "to": "https://www.example.com/b"},
+		{"from": "/b", "to": "/a"},
+		{"from": "https://www.example.com/secure", "to": "http://www.example.com/plain"},
+		{"from": "/ok", "to": "/fine"},
+		{"from": "/docs/*", "to": "https://docs.example.com/"},
+		{"from": "/docs/changelog", "to": "/changelog"}
+	]`))
+	if err != nil {
+		t.Fatalf("parseRulesFile() error = %v", err)
+	}
+
+	issues := validateRules(rules, []string{"www.example.com"})
+	if !hasIssue(issues, "redirect_loop", "entry-1") && !hasIssue(issues, "redirect_loop", "entry-2") {
+		t.Errorf("validateRules() found no loop between /a and /b: %+v", issues)
+	}
+	if !hasIssue(issues, "security", "entry-3") {
+		t.Errorf("validateRules() found no HTTPS downgrade: %+v", issues)
+	}
+	if !hasIssue(issues, "ordering", "entry-6") {
+		t.Errorf("validateRules() found no redirect shadowed by the earlier wildcard: %+v", issues)
+	}
+	if hasIssue(issues, "redirect_loop", "entry-4") {
+		t.Errorf("validateRules() reported a loop for /ok")
+	}
+
+	// Without the hostnames, the absolute destination leaves the zone and there is no loop
+	issues = validateRules(rules, nil)
+	if hasIssue(issues, "redirect_loop", "entry-1") || hasIssue(issues, "redirect_loop", "entry-2") {
+		t.Errorf("validateRules() without hostnames reported a loop: %+v", issues)
+	}
+}
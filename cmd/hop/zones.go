@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HostnameChecklistItem lists the manual DNS and SSL steps to serve a production hostname's counterpart from a staging zone
+type HostnameChecklistItem struct {
+	Hostname string
+	Staging  string
+	Steps    []string
+}
+
+// cloneTagPattern matches the tag put in front of the description of cloned rules
+var cloneTagPattern = regexp.MustCompile(`^\[cloned from [^\]]*\] `)
+
+// Side effect free functions
+
+// clonedDescription tags a rule description with the zone it was cloned from, replacing an earlier tag
+func clonedDescription(sourceZone, description string) string {
+	return fmt.Sprintf("[cloned from %s] %s", sourceZone, cloneTagPattern.ReplaceAllString(description, ""))
+}
+
+// cloneRule copies a rule for another zone, guid is the target rule to update or "" to create a new one
+func cloneRule(rule EdgeRuleResponse, sourceZone, guid string) EdgeRule {
+	return EdgeRule{
+		Guid:                guid,
+		ActionType:          rule.ActionType,
+		ActionParameter1:    rule.ActionParameter1,
+		ActionParameter2:    rule.ActionParameter2,
+		Triggers:            rule.Triggers,
+		TriggerMatchingType: rule.TriggerMatchingType,
+		Description:         clonedDescription(sourceZone, rule.Description),
+		Enabled:             rule.Enabled,
+	}
+}
+
+// planRuleClone returns the rules to create or update so the target has every redirect of the source zone
+func planRuleClone(diff RulesDiff, sourceZone string) []EdgeRule {
+	var plan []EdgeRule
+	for _, change := range diff.Missing {
+		plan = append(plan, cloneRule(*change.From, sourceZone, ""))
+	}
+	for _, change := range diff.Changed {
+		plan = append(plan, cloneRule(*change.From, sourceZone, change.To.Guid))
+	}
+	return plan
+}
+
+// hostnameChecklist lists the steps to give every custom production hostname a staging counterpart on the target zone
+func hostnameChecklist(hostnames []Hostname, targetZone string) []HostnameChecklistItem {
+	var items []HostnameChecklistItem
+	for _, hostname := range hostnames {
+		host := strings.ToLower(hostname.Value)
+		if strings.HasSuffix(host, ".b-cdn.net") {
+			continue
+		}
+		staging := "staging." + host
+		items = append(items, HostnameChecklistItem{
+			Hostname: host,
+			Staging:  staging,
+			Steps: []string{
+				fmt.Sprintf("Add hostname %s to pull zone %s", staging, targetZone),
+				fmt.Sprintf("Create DNS record: %s CNAME %s.b-cdn.net", staging, targetZone),
+				fmt.Sprintf("Request a free SSL certificate for %s once DNS has propagated", staging),
+				fmt.Sprintf("Enable Force SSL for %s", staging),
+			},
+		})
+	}
+	return items
+}
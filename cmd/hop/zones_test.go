@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestClonedDescription(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{description: "302 redirect from /a to /b", want: "[cloned from prod] 302 redirect from /a to /b"},
+		{description: "[cloned from old] 302 redirect from /a to /b", want: "[cloned from prod] 302 redirect from /a to /b"},
+		{description: "", want: "[cloned from prod] "},
+	}
+
+	for _, tt := range tests {
+		if got := clonedDescription("prod", tt.description); got != tt.want {
+			t.Errorf("clonedDescription(%q) = %q, want %q", tt.description, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRuleClone(t *testing.T) {
+	source := []EdgeRuleResponse{
+		redirectRule("p1", "/new", "https://example.com/new"),
+		redirectRule("p2", "/changed", "https://example.com/v2"),
+		redirectRule("p3", "/same", "https://example.com/same"),
+	}
+	target := []EdgeRuleResponse{
+		redirectRule("s1", "/changed", "https://example.com/v1"),
+		redirectRule("s2", "/same", "https://example.com/same"),
+		redirectRule("s3", "/staging-only", "https://example.com/test"),
+	}
+
+	plan := planRuleClone(diffRedirectRules(source, target), "prod")
+	if len(plan) != 2 {
+		t.Fatalf("planRuleClone() returned %d rules, want 2", len(plan))
+	}
+	if plan[0].Guid != "" || plan[0].ActionParameter1 != "https://example.com/new" {
+		t.Errorf("plan[0] = %+v, want a new rule without GUID", plan[0])
+	}
+	if plan[1].Guid != "s1" || plan[1].ActionParameter1 != "https://example.com/v2" {
+		t.Errorf("plan[1] = %+v, want an update of the staging rule s1", plan[1])
+	}
+	if plan[0].Description != "[cloned from prod] redirect p1" {
+		t.Errorf("plan[0].Description = %q", plan[0].Description)
+	}
+}
+
+func TestHostnameChecklist(t *testing.T) {
+	hostnames := []Hostname{{Value: "prod.b-cdn.net"}, {Value: "WWW.Example.com"}}
+
+	items := hostnameChecklist(hostnames, "prod-staging")
+	if len(items) != 1 {
+		t.Fatalf("hostnameChecklist() returned %d items, want 1 (b-cdn.net is skipped)", len(items))
+	}
+	if items[0].Hostname != "www.example.com" || items[0].Staging != "staging.www.example.com" {
+		t.Errorf("item = %+v", items[0])
+	}
+	if want := "Create DNS record: staging.www.example.com CNAME prod-staging.b-cdn.net"; items[0].Steps[1] != want {
+		t.Errorf("DNS step = %q, want %q", items[0].Steps[1], want)
+	}
+}
+
+func TestZoneCloneAgainstFakeAPI(t *testing.T) {
+	fb := newFakeBunny(t)
+	fb.addZone(1, "prod", "www.example.com")
+	ctx := context.Background()
+
+	for _, rule := range []EdgeRule{
+		{ActionType: 1, ActionParameter1: "https://example.com/a", ActionParameter2: "302", Enabled: true, Description: "a",
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/a"}}}},
+		{ActionType: 1, ActionParameter1: "https://example.com/b", ActionParameter2: "302", Enabled: true, Description: "b",
+			Triggers: []Trigger{{Type: 0, PatternMatches: []string{"/b"}}}},
+	} {
+		if err := addEdgeRule(ctx, fakeAPIKey, "1", rule); err != nil {
+			t.Fatalf("addEdgeRule() error = %v", err)
+		}
+	}
+
+	stagingID, err := createPullZone(ctx, fakeAPIKey, "prod-staging", "https://origin.example.com")
+	if err != nil {
+		t.Fatalf("createPullZone() error = %v", err)
+	}
+	if id, err := findPullZoneByName(ctx, fakeAPIKey, "prod-staging"); err != nil || id != stagingID {
+		t.Fatalf("findPullZoneByName(prod-staging) = (%d, %v), want %d", id, err, stagingID)
+	}
+
+	clone := func() int {
+		t.Helper()
+		prod, err := getPullZoneDetails(ctx, fakeAPIKey, "1")
+		if err != nil {
+			t.Fatalf("getPullZoneDetails(prod) error = %v", err)
+		}
+		staging, err := getPullZoneDetails(ctx, fakeAPIKey, fmt.Sprintf("%d", stagingID))
+		if err != nil {
+			t.Fatalf("getPullZoneDetails(staging) error = %v", err)
+		}
+		plan := planRuleClone(diffRedirectRules(prod.EdgeRules, staging.EdgeRules), "prod")
+		for _, rule := range plan {
+			if err := addEdgeRule(ctx, fakeAPIKey, fmt.Sprintf("%d", stagingID), rule); err != nil {
+				t.Fatalf("addEdgeRule() error = %v", err)
+			}
+		}
+		return len(plan)
+	}
+
+	if copied := clone(); copied != 2 {
+		t.Errorf("first clone copied %d rules, want 2", copied)
+	}
+	if copied := clone(); copied != 0 {
+		t.Errorf("second clone copied %d rules, want 0", copied)
+	}
+
+	staging := fb.edgeRules(stagingID)
+	if len(staging) != 2 {
+		t.Fatalf("staging has %d rules, want 2", len(staging))
+	}
+	if staging[0].Description != "[cloned from prod] a" {
+		t.Errorf("staging rule description = %q", staging[0].Description)
+	}
+	prodGuids := map[string]bool{}
+	for _, rule := range fb.edgeRules(1) {
+		prodGuids[rule.Guid] = true
+	}
+	for _, rule := range staging {
+		if prodGuids[rule.Guid] {
+			t.Errorf("staging rule reuses production GUID %s", rule.Guid)
+		}
+	}
+}
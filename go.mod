@@ -3,9 +3,11 @@ module hop
 go 1.24
 
 require (
+	github.com/alecthomas/kong v1.12.1
 	github.com/golangci/golangci-lint v1.62.2
 	github.com/securego/gosec/v2 v2.21.4
 	go.uber.org/nilaway v0.0.0-20250821055425-361559d802f0
+	golang.org/x/net v0.43.0
 	golang.org/x/tools v0.36.0
 	golang.org/x/vuln v1.1.3
 	gotest.tools/gotestsum v1.12.0
@@ -33,7 +35,6 @@ require (
 	github.com/Masterminds/semver/v3 v3.3.0 // indirect
 	github.com/OpenPeeDeeP/depguard/v2 v2.2.0 // indirect
 	github.com/alecthomas/go-check-sumtype v0.2.0 // indirect
-	github.com/alecthomas/kong v1.12.1 // indirect
 	github.com/alexkohler/nakedret/v2 v2.0.5 // indirect
 	github.com/alexkohler/prealloc v1.0.0 // indirect
 	github.com/alingse/asasalint v0.0.11 // indirect
@@ -212,7 +213,6 @@ require (
 	golang.org/x/exp v0.0.0-20240909161429-701f63a606c0 // indirect
 	golang.org/x/exp/typeparams v0.0.0-20241108190413-2d47ceb2692f // indirect
 	golang.org/x/mod v0.27.0 // indirect
-	golang.org/x/net v0.43.0 // indirect
 	golang.org/x/oauth2 v0.23.0 // indirect
 	golang.org/x/sync v0.16.0 // indirect
 	golang.org/x/sys v0.35.0 // indirect